@@ -0,0 +1,229 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+// dateLayout is how the from and to query parameters give a local day.
+const dateLayout = "2006-01-02"
+
+// timeRange reads the from and to query parameters, each a local day such as
+// 2024-05-12 or an RFC 3339 time. A day given as to is included in full.
+// Missing values fall back to from and to.
+func timeRange(r *http.Request, from, to time.Time) (time.Time, time.Time, error) {
+	parse := func(name string, fallback time.Time, endOfDay bool) (time.Time, error) {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			return fallback, nil
+		}
+		if day, err := time.ParseInLocation(dateLayout, value, time.Local); err == nil {
+			if endOfDay {
+				day = day.AddDate(0, 0, 1)
+			}
+			return day, nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, badRequest(fmt.Errorf("%s must be a day such as 2024-05-12 or an RFC 3339 time, got %q", name, value))
+		}
+		return t, nil
+	}
+	start, err := parse("from", from, false)
+	if err != nil {
+		return start, start, err
+	}
+	end, err := parse("to", to, true)
+	if err != nil {
+		return start, end, err
+	}
+	if !end.After(start) {
+		return start, end, badRequest(errors.New("to must be after from"))
+	}
+	return start, end, nil
+}
+
+// entryFilter reads the project, tag and person query parameters. The person
+// id, zero when absent, is not part of data.EntryFilter and is returned apart.
+func (s *Server) entryFilter(r *http.Request) (data.EntryFilter, int64, error) {
+	query := r.URL.Query()
+	var filter data.EntryFilter
+	if path := query.Get("project"); path != "" {
+		project, err := s.db.FindProject(path)
+		if err != nil {
+			return filter, 0, err
+		}
+		filter.ProjectID = &project.ID
+	}
+	if tag := query.Get("tag"); tag != "" {
+		if filter.ProjectID != nil {
+			return filter, 0, badRequest(errors.New("filter by either project or tag"))
+		}
+		filter.Tag = tag
+	}
+	var person int64
+	if value := query.Get("person"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, 0, badRequest(fmt.Errorf("person must be a person id, got %q", value))
+		}
+		person = id
+	}
+	return filter, person, nil
+}
+
+// creditedTo keeps the entries credited to person; zero keeps them all.
+func creditedTo(entries []*data.Entry, person int64) []*data.Entry {
+	if person == 0 {
+		return entries
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.CreatorID != nil && *entry.CreatorID == person {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+func (s *Server) listEntries(w http.ResponseWriter, r *http.Request) {
+	today := data.StartOfDay(time.Now())
+	start, end, err := timeRange(r, today.AddDate(0, 0, -6), today.AddDate(0, 0, 1))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	filter, person, err := s.entryFilter(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	entries, err := s.db.EntriesInRange(start, end, filter)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newEntriesJSON(creditedTo(entries, person)))
+}
+
+func (s *Server) getEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.db.Entry(r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newEntryJSON(entry))
+}
+
+func (s *Server) createEntry(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ProjectID   int64  `json:"project_id"`
+		Project     string `json:"project"`
+		Description string `json:"description"`
+		Duration    string `json:"duration"`
+		At          string `json:"at"`
+		Billable    *bool  `json:"billable"`
+		Type        string `json:"type"`
+	}
+	if err := decode(r, &body); err != nil {
+		fail(w, err)
+		return
+	}
+	project, err := s.findProject(body.ProjectID, body.Project)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	entryType, err := data.ParseEntryType(body.Type)
+	if err != nil {
+		fail(w, badRequest(err))
+		return
+	}
+	if body.Duration == "" {
+		fail(w, badRequest(errors.New("duration is required, such as 45m or 9:00-10:30")))
+		return
+	}
+	interval, err := util.ParseIntervalAt(body.At, body.Duration, time.Now())
+	if err != nil {
+		fail(w, badRequest(err))
+		return
+	}
+	entry, err := project.CreateEntryAt(body.Description, interval.Start, interval.Length, body.Billable == nil || *body.Billable, entryType)
+	if err != nil {
+		if !errors.Is(err, data.ErrEntryOverlap) {
+			err = badRequest(err)
+		}
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newEntryJSON(entry))
+}
+
+func (s *Server) updateEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.db.Entry(r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	var body struct {
+		Description *string `json:"description"`
+		Billable    *bool   `json:"billable"`
+		Type        *string `json:"type"`
+		ProjectID   int64   `json:"project_id"`
+		Project     string  `json:"project"`
+	}
+	if err := decode(r, &body); err != nil {
+		fail(w, err)
+		return
+	}
+	var target *data.Project
+	if body.ProjectID != 0 || body.Project != "" {
+		if target, err = s.findProject(body.ProjectID, body.Project); err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	if body.Type != nil {
+		if entry.Type, err = data.ParseEntryType(*body.Type); err != nil {
+			fail(w, badRequest(err))
+			return
+		}
+	}
+	if body.Description != nil {
+		entry.SetContent(*body.Description)
+	}
+	if body.Billable != nil {
+		entry.Billable = *body.Billable
+	}
+	// A move saves the other changes with it, so one request is one change.
+	switch {
+	case target != nil && target.ID != entry.ProjectID:
+		err = entry.MoveTo(r.Context(), target)
+	case body.Type != nil || body.Description != nil || body.Billable != nil:
+		err = entry.Update(r.Context())
+	}
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newEntryJSON(entry))
+}
+
+func (s *Server) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.db.Entry(r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if err := entry.Delete(r.Context()); err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
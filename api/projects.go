@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/nexneo/samay/data"
+)
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	projects := s.db.Projects()
+	out := make([]projectJSON, 0, len(projects))
+	for _, project := range projects {
+		out = append(out, newProjectJSON(project))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := decode(r, &body); err != nil {
+		fail(w, err)
+		return
+	}
+	if strings.TrimSpace(body.Name) == "" {
+		fail(w, badRequest(errors.New("name is required")))
+		return
+	}
+	project, err := s.db.CreateProject(body.Name)
+	if err != nil {
+		fail(w, badRequest(err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, newProjectJSON(project))
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newProjectJSON(project))
+}
+
+func (s *Server) listProjectEntries(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newEntriesJSON(project.Entries()))
+}
+
+func (s *Server) listTimers(w http.ResponseWriter, r *http.Request) {
+	out := []timerJSON{}
+	for _, project := range s.db.Projects() {
+		if onClock, timer := project.OnClock(); onClock {
+			out = append(out, newTimerJSON(project, timer))
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) startTimer(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if onClock, _ := project.OnClock(); onClock {
+		writeError(w, http.StatusConflict, errors.New("a timer is already running for this project"))
+		return
+	}
+	if err := project.StartTimer(); err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newProjectJSON(project))
+}
+
+func (s *Server) stopTimer(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	var body struct {
+		Description string `json:"description"`
+		Billable    *bool  `json:"billable"`
+		Type        string `json:"type"`
+	}
+	if err := decode(r, &body); err != nil {
+		fail(w, err)
+		return
+	}
+	entryType, err := data.ParseEntryType(body.Type)
+	if err != nil {
+		fail(w, badRequest(err))
+		return
+	}
+	if onClock, _ := project.OnClock(); !onClock {
+		writeError(w, http.StatusConflict, errors.New("no timer is running for this project"))
+		return
+	}
+	entry, err := project.StopTimer(body.Description, body.Billable == nil || *body.Billable, entryType)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newEntryJSON(entry))
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+type projectTotalJSON struct {
+	ProjectID  int64  `json:"project_id"`
+	Project    string `json:"project"`
+	TotalMs    int64  `json:"total_ms"`
+	BillableMs int64  `json:"billable_ms"`
+	Entries    int    `json:"entries"`
+}
+
+type typeTotalJSON struct {
+	Type    data.EntryType `json:"type"`
+	TotalMs int64          `json:"total_ms"`
+}
+
+type personTotalJSON struct {
+	PersonID *int64 `json:"person_id"` // null for entries credited to nobody
+	Name     string `json:"name"`
+	TotalMs  int64  `json:"total_ms"`
+}
+
+type summaryJSON struct {
+	From       time.Time          `json:"from"`
+	To         time.Time          `json:"to"`
+	TotalMs    int64              `json:"total_ms"`
+	BillableMs int64              `json:"billable_ms"`
+	Projects   []projectTotalJSON `json:"projects"`
+	Types      []typeTotalJSON    `json:"types"`
+	People     []personTotalJSON  `json:"people"`
+}
+
+// summary totals the entries in a range, the current month by default, per
+// project, entry type and person. Each project counts only its own entries.
+func (s *Server) summary(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	start, end, err := timeRange(r, monthStart, monthStart.AddDate(0, 1, 0))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	filter, person, err := s.entryFilter(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	entries, err := s.db.EntriesInRange(start, end, filter)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+
+	out := summaryJSON{From: start, To: end, Projects: []projectTotalJSON{}, Types: []typeTotalJSON{}, People: []personTotalJSON{}}
+	projects := make(map[int64]*projectTotalJSON)
+	types := make(map[data.EntryType]int64)
+	people := make(map[int64]int64) // zero collects entries credited to nobody
+	for _, entry := range creditedTo(entries, person) {
+		ms := entry.DurationMs
+		total, ok := projects[entry.ProjectID]
+		if !ok {
+			total = &projectTotalJSON{ProjectID: entry.ProjectID}
+			if entry.Project != nil {
+				total.Project = entry.Project.Path()
+			}
+			projects[entry.ProjectID] = total
+		}
+		total.TotalMs += ms
+		total.Entries++
+		out.TotalMs += ms
+		if entry.Billable {
+			total.BillableMs += ms
+			out.BillableMs += ms
+		}
+		types[entry.Type] += ms
+		var creator int64
+		if entry.CreatorID != nil {
+			creator = *entry.CreatorID
+		}
+		people[creator] += ms
+	}
+
+	for _, total := range projects {
+		out.Projects = append(out.Projects, *total)
+	}
+	sort.Slice(out.Projects, func(i, j int) bool { return out.Projects[i].TotalMs > out.Projects[j].TotalMs })
+	for _, t := range data.EntryTypes {
+		if types[t] > 0 {
+			out.Types = append(out.Types, typeTotalJSON{Type: t, TotalMs: types[t]})
+		}
+	}
+	for id, ms := range people {
+		total := personTotalJSON{Name: "(unattributed)", TotalMs: ms}
+		if id != 0 {
+			total.PersonID = &id
+			if p, err := s.db.Person(id); err == nil {
+				total.Name = p.Name
+			}
+		}
+		out.People = append(out.People, total)
+	}
+	sort.Slice(out.People, func(i, j int) bool { return out.People[i].TotalMs > out.People[j].TotalMs })
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+type projectJSON struct {
+	ID       int64      `json:"id"`
+	Name     string     `json:"name"`
+	Path     string     `json:"path"`
+	ParentID *int64     `json:"parent_id,omitempty"`
+	Timer    *timerJSON `json:"timer,omitempty"`
+}
+
+type timerJSON struct {
+	ProjectID int64     `json:"project_id"`
+	Project   string    `json:"project"`
+	StartedAt time.Time `json:"started_at"`
+	ElapsedMs int64     `json:"elapsed_ms"`
+}
+
+type entryJSON struct {
+	ID          string         `json:"id"`
+	ProjectID   int64          `json:"project_id"`
+	Project     string         `json:"project,omitempty"`
+	Description string         `json:"description"`
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	EndedAt     *time.Time     `json:"ended_at,omitempty"`
+	DurationMs  int64          `json:"duration_ms"`
+	Type        data.EntryType `json:"type"`
+	Billable    bool           `json:"billable"`
+	Tags        []string       `json:"tags"`
+	CreatorID   *int64         `json:"creator_id,omitempty"`
+	// Overlaps lists the ids of entries this one overlapped when it was saved.
+	Overlaps []string `json:"overlaps,omitempty"`
+}
+
+func newProjectJSON(project *data.Project) projectJSON {
+	out := projectJSON{ID: project.ID, Name: project.Name, Path: project.Path(), ParentID: project.ParentID}
+	if onClock, timer := project.OnClock(); onClock {
+		t := newTimerJSON(project, timer)
+		out.Timer = &t
+	}
+	return out
+}
+
+func newTimerJSON(project *data.Project, timer *data.Timer) timerJSON {
+	return timerJSON{
+		ProjectID: project.ID,
+		Project:   project.Path(),
+		StartedAt: timer.StartedTime(),
+		ElapsedMs: timer.Duration().Milliseconds(),
+	}
+}
+
+func newEntryJSON(entry *data.Entry) entryJSON {
+	tags := entry.GetTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	out := entryJSON{
+		ID:          entry.ID,
+		ProjectID:   entry.ProjectID,
+		Description: entry.GetContent(),
+		StartedAt:   entry.StartedAt,
+		EndedAt:     entry.EndedAt,
+		DurationMs:  entry.DurationMs,
+		Type:        entry.Type,
+		Billable:    entry.GetBillable(),
+		Tags:        tags,
+		CreatorID:   entry.CreatorID,
+	}
+	if entry.Project != nil {
+		out.Project = entry.Project.Path()
+	}
+	for _, conflict := range entry.Conflicts {
+		out.Overlaps = append(out.Overlaps, conflict.ID)
+	}
+	return out
+}
+
+func newEntriesJSON(entries []*data.Entry) []entryJSON {
+	out := make([]entryJSON, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, newEntryJSON(entry))
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+// Package api serves samay's data as JSON over HTTP so dashboards and editor
+// plugins can read and record time without shelling out to the CLI.
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nexneo/samay/data"
+)
+
+// ErrNoToken is returned by New when no API token is configured.
+var ErrNoToken = errors.New("no API token configured: set api_token in config.json")
+
+// maxBodyBytes bounds the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+// Server answers API requests against one database. Every request must carry
+// the configured token as "Authorization: Bearer <token>".
+type Server struct {
+	db    *data.Database
+	token string
+	mux   *http.ServeMux
+}
+
+// New returns a server for db that accepts requests bearing token.
+func New(db *data.Database, token string) (*Server, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	if strings.TrimSpace(token) == "" {
+		return nil, ErrNoToken
+	}
+	s := &Server{db: db, token: token, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /api/projects", s.listProjects)
+	s.mux.HandleFunc("POST /api/projects", s.createProject)
+	s.mux.HandleFunc("GET /api/projects/{id}", s.getProject)
+	s.mux.HandleFunc("GET /api/projects/{id}/entries", s.listProjectEntries)
+	s.mux.HandleFunc("POST /api/projects/{id}/timer", s.startTimer)
+	s.mux.HandleFunc("DELETE /api/projects/{id}/timer", s.stopTimer)
+
+	s.mux.HandleFunc("GET /api/timers", s.listTimers)
+
+	s.mux.HandleFunc("GET /api/entries", s.listEntries)
+	s.mux.HandleFunc("POST /api/entries", s.createEntry)
+	s.mux.HandleFunc("GET /api/entries/{id}", s.getEntry)
+	s.mux.HandleFunc("PATCH /api/entries/{id}", s.updateEntry)
+	s.mux.HandleFunc("DELETE /api/entries/{id}", s.deleteEntry)
+
+	s.mux.HandleFunc("GET /api/reports/summary", s.summary)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="samay"`)
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(s.token)) == 1
+}
+
+// project loads an active project from the {id} path segment.
+func (s *Server) project(r *http.Request) (*data.Project, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, badRequest(fmt.Errorf("project id %q is not a number", r.PathValue("id")))
+	}
+	project, err := s.db.Project(id)
+	if err != nil || project.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: %d", data.ErrProjectNotFound, id)
+	}
+	return project, nil
+}
+
+// findProject resolves a project given either by id or by name or path.
+func (s *Server) findProject(id int64, path string) (*data.Project, error) {
+	switch {
+	case id != 0:
+		project, err := s.db.Project(id)
+		if err != nil || project.DeletedAt != nil {
+			return nil, fmt.Errorf("%w: %d", data.ErrProjectNotFound, id)
+		}
+		return project, nil
+	case path != "":
+		return s.db.FindProject(path)
+	default:
+		return nil, badRequest(errors.New("give a project_id or a project path"))
+	}
+}
+
+// requestError marks errors caused by the request rather than the server.
+type requestError struct{ err error }
+
+func (e requestError) Error() string { return e.err.Error() }
+func (e requestError) Unwrap() error { return e.err }
+
+func badRequest(err error) error { return requestError{err: err} }
+
+// decode reads a JSON request body into v. An empty body leaves v untouched.
+func decode(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return badRequest(fmt.Errorf("read request body: %w", err))
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
+
+// fail writes err with the status that matches what went wrong.
+func fail(w http.ResponseWriter, err error) {
+	var invalid requestError
+	switch {
+	case errors.As(err, &invalid):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, data.ErrProjectNotFound), errors.Is(err, data.ErrEntryNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, data.ErrEntryOverlap):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+const testToken = "secret-token"
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "samay-api-test-*")
+	if err != nil {
+		panic(fmt.Sprintf("create temp dir: %v", err))
+	}
+	if err := data.OpenDatabase(filepath.Join(dir, "test.db")); err != nil {
+		panic(fmt.Sprintf("open database: %v", err))
+	}
+
+	code := m.Run()
+
+	if data.DB != nil {
+		_ = data.DB.Close()
+	}
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := New(data.DB, testToken)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	return s
+}
+
+// call sends a request with the test token and decodes the JSON response into out.
+func call(t *testing.T, s *Server, method, path string, body any, out any) int {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reader)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	if _, err := New(data.DB, " "); err != ErrNoToken {
+		t.Fatalf("expected ErrNoToken without a token, got %v", err)
+	}
+	s := newTestServer(t)
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for Authorization %q, got %d", header, rec.Code)
+		}
+	}
+}
+
+func TestProjectsTimersAndEntries(t *testing.T) {
+	s := newTestServer(t)
+
+	var project projectJSON
+	if code := call(t, s, http.MethodPost, "/api/projects", map[string]string{"name": "API/Plugin"}, &project); code != http.StatusCreated {
+		t.Fatalf("create project: %d", code)
+	}
+	if project.Path != "API/Plugin" || project.ParentID == nil {
+		t.Fatalf("unexpected project %+v", project)
+	}
+	base := fmt.Sprintf("/api/projects/%d", project.ID)
+
+	if code := call(t, s, http.MethodPost, base+"/timer", nil, &project); code != http.StatusCreated || project.Timer == nil {
+		t.Fatalf("start timer: %d, %+v", code, project)
+	}
+	if code := call(t, s, http.MethodPost, base+"/timer", nil, nil); code != http.StatusConflict {
+		t.Fatalf("expected a second start to conflict, got %d", code)
+	}
+	var timers []timerJSON
+	if code := call(t, s, http.MethodGet, "/api/timers", nil, &timers); code != http.StatusOK || len(timers) != 1 || timers[0].ProjectID != project.ID {
+		t.Fatalf("list timers: %d, %+v", code, timers)
+	}
+	var stopped entryJSON
+	stop := map[string]any{"description": "wired the plugin #editor", "type": "chore", "billable": false}
+	if code := call(t, s, http.MethodDelete, base+"/timer", stop, &stopped); code != http.StatusCreated {
+		t.Fatalf("stop timer: %d", code)
+	}
+	if stopped.Type != data.EntryTypeChore || stopped.Billable || len(stopped.Tags) != 1 || stopped.Tags[0] != "editor" {
+		t.Fatalf("unexpected stopped entry %+v", stopped)
+	}
+
+	var created entryJSON
+	body := map[string]any{"project": "API/Plugin", "description": "review", "duration": "9:00-10:30", "at": "yesterday"}
+	if code := call(t, s, http.MethodPost, "/api/entries", body, &created); code != http.StatusCreated {
+		t.Fatalf("create entry: %d", code)
+	}
+	if created.DurationMs != (90*time.Minute).Milliseconds() || !created.Billable || created.Type != data.EntryTypeWork {
+		t.Fatalf("unexpected created entry %+v", created)
+	}
+	var failure map[string]string
+	if code := call(t, s, http.MethodPost, "/api/entries", map[string]any{"project": "API/Plugin", "duration": "1.5"}, &failure); code != http.StatusBadRequest || !strings.Contains(failure["error"], "no unit") {
+		t.Fatalf("expected an ambiguous duration to be rejected, got %d %v", code, failure)
+	}
+
+	var updated entryJSON
+	patch := map[string]any{"description": "code review #review", "type": "fun", "project": "API"}
+	if code := call(t, s, http.MethodPatch, "/api/entries/"+created.ID, patch, &updated); code != http.StatusOK {
+		t.Fatalf("update entry: %d", code)
+	}
+	if updated.Description != "code review #review" || updated.Type != data.EntryTypeFun || updated.Project != "API" || updated.Tags[0] != "review" {
+		t.Fatalf("unexpected updated entry %+v", updated)
+	}
+	if history, err := data.DB.EntryHistory(created.ID); err != nil || len(history) != 2 {
+		t.Fatalf("expected the edit and the move to be one change, got %d: %v", len(history), err)
+	}
+
+	var entries []entryJSON
+	if code := call(t, s, http.MethodGet, "/api/entries?project=API", nil, &entries); code != http.StatusOK || len(entries) != 2 {
+		t.Fatalf("list entries: %d, %d entries", code, len(entries))
+	}
+	if code := call(t, s, http.MethodGet, "/api/entries?tag=editor", nil, &entries); code != http.StatusOK || len(entries) != 1 || entries[0].ID != stopped.ID {
+		t.Fatalf("list tagged entries: %d, %+v", code, entries)
+	}
+	if code := call(t, s, http.MethodGet, "/api/entries?from=soon", nil, nil); code != http.StatusBadRequest {
+		t.Fatalf("expected a bad date to be rejected, got %d", code)
+	}
+
+	var summary summaryJSON
+	if code := call(t, s, http.MethodGet, "/api/reports/summary?project=API&from="+time.Now().AddDate(0, 0, -2).Format(dateLayout), nil, &summary); code != http.StatusOK {
+		t.Fatalf("summary: %d", code)
+	}
+	if summary.TotalMs != stopped.DurationMs+created.DurationMs || summary.BillableMs != created.DurationMs || len(summary.Types) != 2 || len(summary.Projects) != 2 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	if code := call(t, s, http.MethodDelete, "/api/entries/"+created.ID, nil, nil); code != http.StatusNoContent {
+		t.Fatalf("delete entry: %d", code)
+	}
+	if code := call(t, s, http.MethodGet, "/api/entries/"+created.ID, nil, nil); code != http.StatusNotFound {
+		t.Fatalf("expected the deleted entry to be gone, got %d", code)
+	}
+	if code := call(t, s, http.MethodGet, "/api/projects/99999", nil, nil); code != http.StatusNotFound {
+		t.Fatalf("expected an unknown project to be 404, got %d", code)
+	}
+}
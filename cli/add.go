@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+func init() {
+	register(command{
+		name:    "add",
+		usage:   "add [-at day|time] [-type work|chore|fun] [-nonbillable] <project> <duration|range> <description>...",
+		summary: "log time worked without a timer, today or on an earlier day",
+		run:     runAdd,
+	})
+}
+
+func runAdd(out io.Writer, args []string) error {
+	fs := newFlagSet("add", out)
+	at := fs.String("at", "", "the day or start time, such as yesterday, 2024-05-12 or \"monday 9am\" (default: now)")
+	entryType := fs.String("type", "work", "entry type: work, chore or fun")
+	nonBillable := fs.Bool("nonbillable", false, "record the entry as non-billable")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 3 {
+		return fmt.Errorf("%w: expected a project, a duration or time range, and a description", ErrUsage)
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	project, err := data.DB.FindProject(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	typ, err := data.ParseEntryType(*entryType)
+	if err != nil {
+		return err
+	}
+	interval, err := util.ParseIntervalAt(*at, fs.Arg(1), time.Now())
+	if err != nil {
+		return err
+	}
+	entry, err := project.CreateEntryAt(strings.Join(fs.Args()[2:], " "), interval.Start, interval.Length, !*nonBillable, typ)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Added to %s:\n", project.Path())
+	_, _ = fmt.Fprintln(out, entryLine(entry))
+	if len(entry.Conflicts) > 0 {
+		_, _ = fmt.Fprintf(out, "warning: it overlaps %s\n", data.DescribeConflicts(entry.Conflicts))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package cli implements samay's non-interactive subcommands.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nexneo/samay/data"
+)
+
+// ErrUsage signals that a command was invoked with invalid arguments.
+var ErrUsage = errors.New("usage")
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(out io.Writer, args []string) error
+	// standalone commands run before the database is opened and read it
+	// themselves from the path given to SetDatabasePath.
+	standalone bool
+	// local commands always run in the invoking process, never in a daemon.
+	local bool
+}
+
+var commands = map[string]command{}
+
+// databasePath is where standalone commands find the database.
+var databasePath string
+
+// SetDatabasePath tells standalone commands where the database is.
+func SetDatabasePath(path string) {
+	databasePath = path
+}
+
+// Standalone reports whether the command named by args[0] runs without the
+// database being opened first.
+func Standalone(args []string) bool {
+	return len(args) > 0 && commands[args[0]].standalone
+}
+
+// ApplyConfig hands the settings in cfg that commands depend on to the
+// database, writing a line to warn for each one that is invalid.
+func ApplyConfig(cfg data.Config, warn io.Writer) {
+	report := func(err error) {
+		if err != nil {
+			_, _ = fmt.Fprintf(warn, "config: %v\n", err)
+		}
+	}
+	policy, err := cfg.EntryOverlapPolicy()
+	report(err)
+	data.DB.SetOverlapPolicy(policy)
+	if cfg.Email != "" {
+		_, err := data.DB.SetIdentity(cfg.Name, cfg.Email)
+		report(err)
+	} else {
+		data.DB.ClearIdentity()
+	}
+	report(data.DB.SetWebhooks(cfg.Webhooks))
+	report(data.DB.SetGitRepositories(cfg.GitRepositories))
+	report(data.DB.SetReminders(cfg.Reminders))
+	report(data.DB.SetPomodoro(cfg.Pomodoro))
+}
+
+func register(cmd command) {
+	commands[cmd.name] = cmd
+}
+
+func init() {
+	register(command{
+		name:    "help",
+		usage:   "help",
+		summary: "list available commands",
+		run: func(out io.Writer, _ []string) error {
+			PrintUsage(out)
+			return nil
+		},
+	})
+}
+
+// Run executes the subcommand named by args[0] and writes its output to out.
+func Run(out io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: no command given", ErrUsage)
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", ErrUsage, args[0])
+	}
+	if err := cmd.run(out, args[1:]); err != nil {
+		if errors.Is(err, ErrUsage) {
+			return fmt.Errorf("%w: samay %s", err, cmd.usage)
+		}
+		return err
+	}
+	return nil
+}
+
+// PrintUsage lists the available subcommands.
+func PrintUsage(out io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	_, _ = fmt.Fprintln(out, "Usage: samay [flags] [command [args]]")
+	_, _ = fmt.Fprintln(out, "")
+	_, _ = fmt.Fprintln(out, "Without a command samay starts the interactive UI.")
+	_, _ = fmt.Fprintln(out, "")
+	_, _ = fmt.Fprintln(out, "Commands:")
+	for _, name := range names {
+		cmd := commands[name]
+		_, _ = fmt.Fprintf(out, "  %-28s %s\n", cmd.usage, cmd.summary)
+	}
+}
+
+// newFlagSet builds a flag set for a subcommand that reports parse errors
+// instead of exiting the process.
+func newFlagSet(name string, out io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ErrUsage
+		}
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	return nil
+}
+
+func oneLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,612 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/api"
+	"github.com/nexneo/samay/daemon"
+	"github.com/nexneo/samay/data"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "samay-cli-test-*")
+	if err != nil {
+		panic(fmt.Sprintf("create temp dir: %v", err))
+	}
+	path := filepath.Join(dir, "test.db")
+	if err := data.OpenDatabase(path); err != nil {
+		panic(fmt.Sprintf("open database: %v", err))
+	}
+
+	code := m.Run()
+
+	if data.DB != nil {
+		_ = data.DB.Close()
+	}
+	_ = os.RemoveAll(dir)
+
+	os.Exit(code)
+}
+
+func TestRunRejectsUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := Run(&out, []string{"bogus"}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestHistoryCommand(t *testing.T) {
+	project, err := data.DB.CreateProject("CLI History")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("before", 10*time.Minute, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	entry.Content = "after"
+	if err := entry.UpdateNow(); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"history", entry.ID[:8]}); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{entry.ID, "(CLI History)", "create", "update", `content: "before" -> "after"`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if err := Run(&out, []string{"history"}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error without an id, got %v", err)
+	}
+}
+
+func TestGoalsCommand(t *testing.T) {
+	project, err := data.DB.CreateProject("CLI Goals")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if _, err := project.CreateEntryWithDuration("focus block", 90*time.Minute, true, data.EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"goals", "set", "-project", "CLI Goals", "1h/day"}); err != nil {
+		t.Fatalf("goals set: %v", err)
+	}
+	if !strings.Contains(out.String(), "1h/day for CLI Goals") {
+		t.Fatalf("unexpected set output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := Run(&out, []string{"goals", "-days", "3"}); err != nil {
+		t.Fatalf("goals report: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Daily goal 1h/day for CLI Goals, streak 1", "1:30  hit", "missed"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected report to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if err := Run(&out, []string{"goals", "set", "1h/month"}); err == nil {
+		t.Fatalf("expected an invalid period to fail")
+	}
+	if err := Run(&out, []string{"goals", "clear", "-project", "CLI Goals", "day"}); err != nil {
+		t.Fatalf("goals clear: %v", err)
+	}
+	if err := Run(&out, []string{"goals", "clear", "-project", "CLI Goals", "day"}); !errors.Is(err, data.ErrGoalNotFound) {
+		t.Fatalf("expected ErrGoalNotFound, got %v", err)
+	}
+}
+
+func TestDoctorOverlapsCommand(t *testing.T) {
+	project, err := data.DB.CreateProject("CLI Doctor")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	first, err := project.CreateEntryWithDuration("standup", 30*time.Minute, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	second, err := project.CreateEntryWithDuration("review", 10*time.Minute, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create overlapping entry: %v", err)
+	}
+	if len(second.Conflicts) == 0 {
+		t.Fatalf("expected the second entry to report its conflicts")
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"doctor", "overlaps", "-days", "1"}); err != nil {
+		t.Fatalf("doctor overlaps: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"CLI Doctor: standup [" + first.ID[:8] + "]", "CLI Doctor: review [" + second.ID[:8] + "]", "counted more than once"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if err := Run(&out, []string{"doctor"}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error without a check, got %v", err)
+	}
+}
+
+func TestSplitAndMergeCommands(t *testing.T) {
+	project, err := data.DB.CreateProject("CLI Split")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("pairing", time.Hour, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"split", "-second", "review #docs", entry.ID[:8], "20m"}); err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"0:20  pairing", "0:40  review #docs"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected split output to contain %q, got:\n%s", want, got)
+		}
+	}
+	entries := project.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries after the split, got %d", len(entries))
+	}
+
+	out.Reset()
+	if err := Run(&out, []string{"merge", entries[0].ID, entries[1].ID}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if !strings.Contains(out.String(), "Merged 2 entries") || !strings.Contains(out.String(), "1:00") {
+		t.Fatalf("unexpected merge output:\n%s", out.String())
+	}
+	if entries := project.Entries(); len(entries) != 1 || !slices.Contains(entries[0].Tags, "docs") {
+		t.Fatalf("expected one merged entry carrying #docs, got %d entries", len(entries))
+	}
+
+	if err := Run(&out, []string{"merge", entry.ID}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error for a single entry, got %v", err)
+	}
+	if err := Run(&out, []string{"split", entry.ID, "3h"}); err == nil {
+		t.Fatalf("expected splitting past the end of the entry to fail")
+	}
+}
+
+func TestAddCommand(t *testing.T) {
+	project, err := data.DB.CreateProject("CLI Add")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"add", "-at", "2024-03-04", "CLI Add", "9:00-10:30", "planning", "#ops"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "2024-03-04 09:00–10:30") || !strings.Contains(got, "planning #ops") {
+		t.Fatalf("unexpected add output:\n%s", got)
+	}
+	out.Reset()
+	if err := Run(&out, []string{"add", "-nonbillable", "-type", "chore", "-at", "2024-03-05 14:00", "CLI Add", "45m", "review"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "review  (chore)") {
+		t.Fatalf("expected the entry type in the add output:\n%s", got)
+	}
+	entries := project.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Content == "review" && (entry.Billable || entry.Type != data.EntryTypeChore ||
+			entry.StartedAt.In(time.Local).Format("2006-01-02 15:04") != "2024-03-05 14:00") {
+			t.Fatalf("expected a non-billable chore at 14:00 on 2024-03-05, got %+v", entry)
+		}
+	}
+
+	if err := Run(&out, []string{"add", "CLI Add", "1.5", "standup"}); err == nil || !strings.Contains(err.Error(), "no unit") {
+		t.Fatalf("expected an ambiguous duration to fail, got %v", err)
+	}
+	if err := Run(&out, []string{"add", "-at", "tomorrow 9am", "CLI Add", "1h", "later"}); err == nil {
+		t.Fatalf("expected an entry in the future to fail")
+	}
+	if err := Run(&out, []string{"add", "-type", "nap", "CLI Add", "1h", "rest"}); err == nil {
+		t.Fatalf("expected an unknown entry type to fail")
+	}
+	if err := Run(&out, []string{"add", "CLI Add", "1h"}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error without a description, got %v", err)
+	}
+}
+
+func TestTemplateAndRecurCommands(t *testing.T) {
+	if _, err := data.DB.CreateProject("CLI Rituals"); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"template", "add", "-project", "CLI Rituals", "-repeat", "every weekday 09:30 15m",
+		"-type", "chore", "-tags", "team,daily", "standup", "daily", "standup"}); err != nil {
+		t.Fatalf("template add: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "standup") || !strings.Contains(got, "0:15, chore, every weekday 09:30") {
+		t.Fatalf("unexpected template add output:\n%s", got)
+	}
+	if err := Run(&out, []string{"template", "add", "-project", "CLI Rituals", "review"}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error without a duration, got %v", err)
+	}
+
+	out.Reset()
+	if err := Run(&out, []string{"template"}); err != nil || !strings.Contains(out.String(), "daily standup #daily #team") {
+		t.Fatalf("unexpected template list %v:\n%s", err, out.String())
+	}
+	out.Reset()
+	if err := Run(&out, []string{"recur"}); err != nil || !strings.Contains(out.String(), "every weekday 09:30") {
+		t.Fatalf("unexpected recur list %v:\n%s", err, out.String())
+	}
+	out.Reset()
+	if err := Run(&out, []string{"recur", "run"}); err != nil || !strings.Contains(out.String(), "Logged") {
+		t.Fatalf("unexpected recur run %v:\n%s", err, out.String())
+	}
+
+	out.Reset()
+	if err := Run(&out, []string{"template", "apply", "STANDUP"}); err != nil {
+		t.Fatalf("template apply: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "Logged standup to CLI Rituals") || !strings.Contains(got, "0:15  daily standup") {
+		t.Fatalf("unexpected template apply output:\n%s", got)
+	}
+
+	if err := Run(&out, []string{"template", "delete", "standup"}); err != nil {
+		t.Fatalf("template delete: %v", err)
+	}
+	if err := Run(&out, []string{"template", "apply", "standup"}); !errors.Is(err, data.ErrTemplateNotFound) {
+		t.Fatalf("expected the deleted template to be gone, got %v", err)
+	}
+}
+
+func TestWhoamiAndPeopleCommands(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	project, err := data.DB.CreateProject("CLI Team")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"whoami", "-name", "Lin"}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected a name without an email to be a usage error, got %v", err)
+	}
+	if err := Run(&out, []string{"whoami", "-name", "Lin", "-email", "lin@example.com"}); err != nil {
+		t.Fatalf("whoami: %v", err)
+	}
+	cfg, err := data.LoadConfig()
+	if err != nil || cfg.Name != "Lin" || cfg.Email != "lin@example.com" {
+		t.Fatalf("expected the identity in the config, got %+v, %v", cfg, err)
+	}
+
+	if _, err := project.CreateEntryWithDuration("planning", 2*time.Hour, true, data.EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	out.Reset()
+	if err := Run(&out, []string{"people"}); err != nil {
+		t.Fatalf("people: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "* Lin <lin@example.com>") || !strings.Contains(got, "2:00") {
+		t.Fatalf("unexpected people output:\n%s", got)
+	}
+
+	out.Reset()
+	if err := Run(&out, []string{"whoami"}); err != nil || strings.TrimSpace(out.String()) != "Lin <lin@example.com>" {
+		t.Fatalf("unexpected whoami output %q, %v", out.String(), err)
+	}
+}
+
+func TestServeRequiresToken(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var out bytes.Buffer
+	if err := Run(&out, []string{"serve", "-addr", "127.0.0.1:0"}); !errors.Is(err, api.ErrNoToken) {
+		t.Fatalf("expected serve to refuse to start without a token, got %v", err)
+	}
+}
+
+func TestServeListensOnLoopbackOnly(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:7411", ":7411", "192.168.1.10:7411", "example.com:7411"} {
+		if err := Run(&bytes.Buffer{}, []string{"serve", "-addr", addr}); !errors.Is(err, ErrUsage) || !strings.Contains(err.Error(), "loopback") {
+			t.Fatalf("expected %s to be refused, got %v", addr, err)
+		}
+	}
+	for _, addr := range []string{"127.0.0.1:0", "[::1]:0", "localhost:0"} {
+		if err := checkLoopback(addr); err != nil {
+			t.Fatalf("expected %s to be accepted, got %v", addr, err)
+		}
+	}
+}
+
+func TestWebhooksCommand(t *testing.T) {
+	const url = "http://127.0.0.1:1/cli-hook"
+	if err := data.DB.SetWebhooks([]data.Webhook{{URL: url, Events: []string{"project.*"}}}); err != nil {
+		t.Fatalf("set webhooks: %v", err)
+	}
+	t.Cleanup(func() { _ = data.DB.SetWebhooks(nil) })
+	if _, err := data.DB.CreateProject("CLI Webhooks"); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"webhooks"}); err != nil {
+		t.Fatalf("webhooks: %v", err)
+	}
+	if !strings.Contains(out.String(), url+"  (project.*, unsigned)  1 pending, 0 failed") {
+		t.Fatalf("unexpected webhooks output %q", out.String())
+	}
+	out.Reset()
+	if err := Run(&out, []string{"webhooks", "deliver", "-timeout", "5s"}); err != nil {
+		t.Fatalf("webhooks deliver: %v", err)
+	}
+	if out.String() != "Sent 0, failed 1.\n" {
+		t.Fatalf("unexpected deliver output %q", out.String())
+	}
+	if err := Run(&out, []string{"webhooks", "bogus"}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected a usage error, got %v", err)
+	}
+}
+
+func TestGitHookSwitch(t *testing.T) {
+	dir := t.TempDir()
+	if err := data.DB.SetGitRepositories([]data.GitRepository{{
+		Path:     dir,
+		Project:  "Hook Site",
+		Branches: map[string]string{"feature/*": "Hook Site/Features"},
+	}}); err != nil {
+		t.Fatalf("set repositories: %v", err)
+	}
+	t.Cleanup(func() { _ = data.DB.SetGitRepositories(nil) })
+	if _, err := data.DB.CreateProject("Hook Site/Features"); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	other, err := data.DB.CreateProject("Hook Other")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if _, err := other.CreateEntryWithDuration("inbox", time.Minute, false, data.EntryTypeChore); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if err := other.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"git-hook", "switch", dir, "feature/search"}); err != nil {
+		t.Fatalf("switch: %v", err)
+	}
+	if !strings.Contains(out.String(), "stopped Hook Other") || !strings.Contains(out.String(), "started Hook Site/Features for feature/search") {
+		t.Fatalf("unexpected switch output %q", out.String())
+	}
+	if onClock, _ := other.OnClock(); onClock {
+		t.Fatal("expected the other timer to be stopped")
+	}
+	if entries := other.Entries(); len(entries) != 2 || entries[0].Billable || entries[0].Type != data.EntryTypeChore {
+		t.Fatalf("expected the stopped timer to keep the project's non-billable chore settings, got %+v", entries)
+	}
+	features, err := data.DB.FindProject("Hook Site/Features")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	if onClock, _ := features.OnClock(); !onClock {
+		t.Fatal("expected the mapped project's timer to be running")
+	}
+
+	out.Reset()
+	if err := Run(&out, []string{"git-hook", "switch", t.TempDir(), "main"}); err != nil || out.Len() != 0 {
+		t.Fatalf("expected an unmapped repository to be ignored, got %q, %v", out.String(), err)
+	}
+	if err := Run(&out, []string{"git-hook", "switch", dir}); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected a usage error, got %v", err)
+	}
+	_, _ = features.StopTimer("", true, data.EntryTypeWork)
+}
+
+func TestPromptSegment(t *testing.T) {
+	now := time.Now()
+	timers := []data.RunningTimer{
+		{Project: "Acme/100% #1", StartedAt: now.Add(-83 * time.Minute)},
+		{Project: "Other", StartedAt: now.Add(-2 * time.Hour)},
+	}
+	for format, want := range map[string]string{
+		"plain": "⏱ Acme/100% #1 1:23 +1",
+		"ansi":  "\x1b[36m⏱ Acme/100% #1\x1b[0m \x1b[1m1:23\x1b[0m +1",
+		"bash":  "\x01\x1b[36m\x02⏱ Acme/100% #1\x01\x1b[0m\x02 \x01\x1b[1m\x021:23\x01\x1b[0m\x02 +1",
+		"zsh":   "%{\x1b[36m%}⏱ Acme/100%% #1%{\x1b[0m%} %{\x1b[1m%}1:23%{\x1b[0m%} +1",
+		"tmux":  "#[fg=cyan]⏱ Acme/100% ##1#[default] #[bold]1:23#[nobold] +1",
+	} {
+		style, err := promptStyleFor(format)
+		if err != nil {
+			t.Fatalf("style %s: %v", format, err)
+		}
+		if got := promptSegment(timers, now, false, style); got != want {
+			t.Errorf("%s: got %q, want %q", format, got, want)
+		}
+	}
+	plain, _ := promptStyleFor("plain")
+	if got := promptSegment(timers[:1], now, true, plain); got != "⏱ 100% #1 1:23" {
+		t.Errorf("short: got %q", got)
+	}
+	if got := promptSegment(nil, now, false, plain); got != "" {
+		t.Errorf("expected nothing while idle, got %q", got)
+	}
+	if _, err := promptStyleFor("fish"); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+}
+
+func TestPromptCommand(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	project, err := data.DB.CreateProject("CLI Prompt")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	t.Cleanup(func() { _, _ = project.StopTimer("", true, data.EntryTypeWork) })
+
+	var out bytes.Buffer
+	if err := Run(&out, []string{"prompt", "-format", "plain", "-cache"}); err != nil {
+		t.Fatalf("prompt: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "⏱ ") {
+		t.Fatalf("unexpected prompt output %q", out.String())
+	}
+	cachePath, err := promptCachePath()
+	if err != nil {
+		t.Fatalf("cache path: %v", err)
+	}
+	raw, err := os.ReadFile(cachePath)
+	if err != nil || !strings.Contains(string(raw), `"project":"CLI Prompt"`) {
+		t.Fatalf("expected the running timer in the cache, got %q, %v", raw, err)
+	}
+
+	// Stopping the timer changes the database, so the cache is not trusted.
+	if _, err := project.StopTimer("", true, data.EntryTypeWork); err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	out.Reset()
+	if err := Run(&out, []string{"prompt", "-format", "plain", "-cache"}); err != nil {
+		t.Fatalf("prompt: %v", err)
+	}
+	if strings.Contains(out.String(), "CLI Prompt") {
+		t.Fatalf("expected the stopped timer to be gone, got %q", out.String())
+	}
+	if !Standalone([]string{"prompt"}) || Standalone([]string{"webhooks"}) {
+		t.Fatal("expected only prompt to run without the database")
+	}
+}
+
+func TestForwardToDaemon(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	var out bytes.Buffer
+	if forwarded, err := Forward(&out, []string{"people"}, data.DB.Path()); forwarded || err != nil {
+		t.Fatalf("expected nothing to forward to, got %v, %v", forwarded, err)
+	}
+
+	socket, err := daemon.SocketPath()
+	if err != nil {
+		t.Fatalf("socket path: %v", err)
+	}
+	server, err := daemon.New(data.DB, Run, ErrUsage, nil)
+	if err != nil {
+		t.Fatalf("new daemon: %v", err)
+	}
+	listener, err := daemon.Listen(socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+
+	forwarded, err := Forward(&out, []string{"help"}, data.DB.Path())
+	if !forwarded || err != nil || !strings.Contains(out.String(), "Commands:") {
+		t.Fatalf("expected help to run in the daemon, got %v, %v, %q", forwarded, err, out.String())
+	}
+	if forwarded, err := Forward(&out, []string{"history"}, data.DB.Path()); !forwarded || !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected a forwarded usage error, got %v, %v", forwarded, err)
+	}
+	for _, args := range [][]string{{"serve"}, {"prompt"}, {"daemon", "status"}, {"bogus"}} {
+		if forwarded, _ := Forward(&out, args, data.DB.Path()); forwarded {
+			t.Errorf("expected %v to run locally", args)
+		}
+	}
+	if forwarded, _ := Forward(&out, []string{"help"}, filepath.Join(t.TempDir(), "other.db")); forwarded {
+		t.Error("expected a daemon serving another database to be ignored")
+	}
+
+	out.Reset()
+	if err := Run(&out, []string{"daemon", "status"}); err != nil || !strings.Contains(out.String(), "serving "+data.DB.Path()) {
+		t.Fatalf("unexpected daemon status %q, %v", out.String(), err)
+	}
+}
+
+func TestForwardedCommandsReadCurrentConfig(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	previous := data.DB.CurrentPerson()
+	t.Cleanup(func() {
+		data.DB.ClearIdentity()
+		if previous != nil {
+			_, _ = data.DB.SetIdentity(previous.Name, previous.Email)
+		}
+	})
+	project, err := data.DB.CreateProject("CLI Forwarded Identity")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	socket, err := daemon.SocketPath()
+	if err != nil {
+		t.Fatalf("socket path: %v", err)
+	}
+	server, err := daemon.New(data.DB, runForwarded, ErrUsage, nil)
+	if err != nil {
+		t.Fatalf("new daemon: %v", err)
+	}
+	listener, err := daemon.Listen(socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+
+	// The identity changes while the daemon runs, as whoami would change it.
+	creators := map[string]string{}
+	for _, email := range []string{"ana@example.com", "ben@example.com"} {
+		if err := data.SaveConfig(data.Config{Email: email}); err != nil {
+			t.Fatalf("save config: %v", err)
+		}
+		var out bytes.Buffer
+		if forwarded, err := Forward(&out, []string{"add", project.Name, "15m", "logged by " + email}, data.DB.Path()); !forwarded || err != nil {
+			t.Fatalf("expected add to run in the daemon, got %v, %v", forwarded, err)
+		}
+		people, err := data.DB.People()
+		if err != nil {
+			t.Fatalf("people: %v", err)
+		}
+		for _, entry := range project.Entries() {
+			for _, person := range people {
+				if entry.CreatorID != nil && *entry.CreatorID == person.ID {
+					creators[entry.Content] = person.Email
+				}
+			}
+		}
+	}
+	for _, email := range []string{"ana@example.com", "ben@example.com"} {
+		if got := creators["logged by "+email]; got != email {
+			t.Fatalf("expected the entry logged as %s to be created by them, got %q", email, got)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nexneo/samay/daemon"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/reminder"
+	"github.com/nexneo/samay/webhook"
+)
+
+func init() {
+	register(command{
+		name:    "daemon",
+		usage:   "daemon [-socket path] | daemon status|stop",
+		summary: "run samay in the background to run other samay commands and scheduled jobs",
+		run:     runDaemon,
+		local:   true,
+	})
+}
+
+func runDaemon(out io.Writer, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "status":
+			return runDaemonStatus(out, args[1:])
+		case "stop":
+			return runDaemonStop(out, args[1:])
+		}
+	}
+	defaultSocket, err := daemon.SocketPath()
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet("daemon", out)
+	socket := fs.String("socket", defaultSocket, "Unix socket to listen on; other samay processes only find the default one")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	cfg, err := data.LoadConfig()
+	if err != nil {
+		return err
+	}
+	server, err := daemon.New(data.DB, runForwarded, ErrUsage, daemonJobs(cfg))
+	if err != nil {
+		return err
+	}
+	listener, err := daemon.Listen(*socket)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(*socket) }()
+	_, _ = fmt.Fprintf(out, "samay daemon serving %s on unix:%s\n", data.DB.Path(), *socket)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// The sender runs even without hooks, since config.json may gain some.
+	sender := webhook.NewSender(data.DB, nil)
+	delivered := make(chan struct{})
+	go func() {
+		defer close(delivered)
+		sender.Run(ctx, 15*time.Second)
+	}()
+	err = server.Serve(ctx, listener)
+	stop()
+	<-delivered
+	return err
+}
+
+// runForwarded runs a command handed over by another samay process with
+// config.json as it is now, as that process would have, so a changed
+// identity, overlap policy or webhook list applies without a restart.
+func runForwarded(out io.Writer, args []string) error {
+	reloadConfig()
+	return Run(out, args)
+}
+
+// reloadConfig applies config.json to the daemon's database. Problems go to
+// the daemon's stderr, as they do at startup; a config that cannot be read
+// leaves the settings as they were.
+func reloadConfig() {
+	cfg, err := data.LoadConfig()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "load config: %v\n", err)
+		return
+	}
+	ApplyConfig(cfg, os.Stderr)
+}
+
+// daemonJobs is the scheduled work the daemon takes over from samay windows,
+// which otherwise only do it while they are open. Webhooks are delivered by
+// the daemon's sender, which runs alongside the jobs.
+func daemonJobs(cfg data.Config) []daemon.Job {
+	jobs := []daemon.Job{{
+		Name:  "recurring entries",
+		Every: time.Minute,
+		Run: func(_ context.Context, now time.Time) error {
+			// Occurrences are stamped and checked like forwarded commands.
+			reloadConfig()
+			_, err := data.DB.RunRecurrences(now)
+			return err
+		},
+	}}
+	if retention := cfg.TrashRetention(); retention > 0 {
+		jobs = append(jobs, daemon.Job{
+			Name:  "empty trash",
+			Every: time.Hour,
+			Run: func(_ context.Context, now time.Time) error {
+				return data.DB.PurgeTrash(now.Add(-retention))
+			},
+		})
+	}
+	if schedule, ok := data.DB.Reminders(); ok && len(schedule.Command) > 0 {
+		// The daemon has no terminal to ring; the command is all it can do.
+		checker := reminder.NewChecker(data.DB, reminder.Notifiers(schedule, nil)...)
+		jobs = append(jobs, daemon.Job{
+			Name:  "reminders",
+			Every: time.Minute,
+			Run: func(ctx context.Context, now time.Time) error {
+				_, err := checker.Check(ctx, now)
+				return err
+			},
+		})
+	}
+	return jobs
+}
+
+func connectDaemon() (*daemon.Client, error) {
+	path, err := daemon.SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	client, err := daemon.Dial(path)
+	if err != nil {
+		return nil, errors.New("no samay daemon is running")
+	}
+	return client, nil
+}
+
+func runDaemonStatus(out io.Writer, args []string) error {
+	fs := newFlagSet("daemon status", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	client, err := connectDaemon()
+	if err != nil {
+		return err
+	}
+	status, err := client.Status(context.Background())
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "%s, pid %d, serving %s since %s\n",
+		status.Version, status.PID, status.Database, status.StartedAt.Local().Format("2006-01-02 15:04"))
+	for _, job := range status.Jobs {
+		line := fmt.Sprintf("  %-20s every %-8s %d runs", job.Name, job.Every, job.Runs)
+		if !job.LastRun.IsZero() {
+			line += ", last " + job.LastRun.Local().Format("15:04:05")
+		}
+		if job.LastError != "" {
+			line += ": " + job.LastError
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+func runDaemonStop(out io.Writer, args []string) error {
+	fs := newFlagSet("daemon stop", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	client, err := connectDaemon()
+	if err != nil {
+		return err
+	}
+	if err := client.Stop(context.Background()); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(out, "Asked the samay daemon to stop.")
+	return nil
+}
+
+// forwardedError is an error a command returned inside the daemon.
+type forwardedError struct {
+	msg   string
+	usage bool
+}
+
+func (e forwardedError) Error() string { return e.msg }
+
+func (e forwardedError) Is(target error) bool { return e.usage && target == ErrUsage }
+
+// Forward runs the command named by args[0] in the daemon serving the
+// database at dbPath, and reports whether it did. Commands are not forwarded
+// when no such daemon is running, or when they must run in this process.
+func Forward(out io.Writer, args []string, dbPath string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok || cmd.local || cmd.standalone {
+		return false, nil
+	}
+	client, ok := daemon.Connect(dbPath)
+	if !ok {
+		return false, nil
+	}
+	result, err := client.Run(context.Background(), args)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.WriteString(out, result.Output)
+	if result.Error != "" {
+		return true, forwardedError{msg: result.Error, usage: result.Usage}
+	}
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+func init() {
+	register(command{
+		name:    "doctor",
+		usage:   "doctor overlaps [-days N]",
+		summary: "find entries whose time overlaps",
+		run:     runDoctor,
+	})
+}
+
+func runDoctor(out io.Writer, args []string) error {
+	if len(args) == 0 || args[0] != "overlaps" {
+		return fmt.Errorf("%w: expected a check to run", ErrUsage)
+	}
+	return runDoctorOverlaps(out, args[1:])
+}
+
+func runDoctorOverlaps(out io.Writer, args []string) error {
+	fs := newFlagSet("doctor overlaps", out)
+	days := fs.Int("days", 90, "how many days back to check; 0 checks everything")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *days < 0 {
+		return ErrUsage
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	now := time.Now()
+	var since time.Time
+	if *days > 0 {
+		since = data.StartOfDay(now).AddDate(0, 0, -(*days - 1))
+	}
+	// Entries still being edited may end a little in the future.
+	overlaps, err := data.DB.EntryOverlaps(since, now.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	scope := "ever"
+	if *days > 0 {
+		scope = "since " + since.Format("2006-01-02")
+	}
+	if len(overlaps) == 0 {
+		_, _ = fmt.Fprintf(out, "No overlapping entries %s\n", scope)
+		return nil
+	}
+
+	var total time.Duration
+	for _, overlap := range overlaps {
+		total += overlap.Duration()
+		names := make([]string, 0, len(overlap.Entries))
+		for _, entry := range overlap.Entries {
+			name := entry.Project.Path()
+			if content := oneLine(entry.GetContent()); content != "" {
+				name += ": " + content
+			}
+			names = append(names, fmt.Sprintf("%s [%s]", name, shortID(entry.ID)))
+		}
+		start, end := overlap.Start.In(time.Local), overlap.End.In(time.Local)
+		_, _ = fmt.Fprintf(out, "%s %s–%s  %6s  %s\n", start.Format("2006-01-02"), start.Format("15:04"), end.Format("15:04"),
+			util.HmFromD(overlap.Duration()), strings.Join(names, " / "))
+	}
+	noun := "overlaps"
+	if len(overlaps) == 1 {
+		noun = "overlap"
+	}
+	_, _ = fmt.Fprintf(out, "%d %s %s, %s counted more than once\n", len(overlaps), noun, scope, util.HmFromD(total))
+	return nil
+}
+
+// shortID abbreviates an entry id; samay history accepts the prefix.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/git"
+)
+
+func init() {
+	register(command{
+		name:    "git-hook",
+		usage:   "git-hook [-repo dir] [-uninstall] | git-hook switch <repo> <branch>",
+		summary: "install a post-checkout hook that switches the timer to the branch's project",
+		run:     runGitHook,
+		local:   true,
+	})
+}
+
+func runGitHook(out io.Writer, args []string) error {
+	if len(args) > 0 && args[0] == "switch" {
+		return runGitHookSwitch(out, args[1:])
+	}
+	fs := newFlagSet("git-hook", out)
+	repo := fs.String("repo", ".", "repository to install the hook in")
+	uninstall := fs.Bool("uninstall", false, "remove the hook instead")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+
+	ctx := context.Background()
+	dir, err := git.TopLevel(ctx, *repo)
+	if err != nil {
+		return err
+	}
+	if *uninstall {
+		removed, err := git.UninstallHook(ctx, dir)
+		if err != nil {
+			return err
+		}
+		if removed {
+			_, _ = fmt.Fprintf(out, "Removed the samay hook from %s.\n", dir)
+		} else {
+			_, _ = fmt.Fprintf(out, "%s has no samay hook.\n", dir)
+		}
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate samay: %w", err)
+	}
+	path, err := git.InstallHook(ctx, dir, executable, data.DB.Path())
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Installed %s.\n", path)
+	if _, ok := data.DB.GitRepository(dir); !ok {
+		_, _ = fmt.Fprintf(out, "Map the repository to a project under git_repositories in config.json, such as {\"path\": %q, \"project\": \"Acme/Website\"}.\n", dir)
+	}
+	return nil
+}
+
+// runGitHookSwitch is what the hook runs after a branch checkout. It starts
+// the timer of the project the branch maps to and stops every other running
+// timer, describing each stopped entry with the commits made meanwhile. A
+// timer has no billable flag or type of its own, so each stopped entry takes
+// those of the project's latest entry.
+func runGitHookSwitch(out io.Writer, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%w: expected a repository and a branch", ErrUsage)
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+	repo, ok := data.DB.GitRepository(args[0])
+	if !ok {
+		return nil
+	}
+	path := repo.ProjectFor(args[1])
+	if path == "" {
+		return nil
+	}
+	project, err := data.DB.FindProject(path)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, other := range data.DB.Projects() {
+		if other.ID == project.ID {
+			continue
+		}
+		if onClock, _ := other.OnClock(); !onClock {
+			continue
+		}
+		// Unreadable repositories only cost the description.
+		subjects, _ := git.TimerSubjects(ctx, data.DB, other, now)
+		billable, entryType := other.LastEntrySettings()
+		entry, err := other.StopTimer(git.Description(subjects), billable, entryType)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "samay: stopped %s after %s\n", other.Path(), entry.HoursMins())
+	}
+	if onClock, _ := project.OnClock(); onClock {
+		return nil
+	}
+	if err := project.StartTimer(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "samay: started %s for %s\n", project.Path(), args[1])
+	return nil
+}
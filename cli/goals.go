@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+func init() {
+	register(command{
+		name:    "goals",
+		usage:   "goals [set|clear] [flags] [target]",
+		summary: "report daily and weekly hour goals, or set and clear them",
+		run:     runGoals,
+	})
+}
+
+func runGoals(out io.Writer, args []string) error {
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "set":
+			return runGoalsSet(out, args[1:])
+		case "clear":
+			return runGoalsClear(out, args[1:])
+		}
+	}
+	return runGoalsReport(out, args)
+}
+
+// scopeFlags registers the -project and -tag flags shared by set and clear.
+func scopeFlags(fs *flag.FlagSet) (project, tag *string) {
+	project = fs.String("project", "", "limit the goal to a project path and its sub-projects")
+	tag = fs.String("tag", "", "limit the goal to entries with this #tag")
+	return project, tag
+}
+
+func goalScope(project, tag string) (data.Goal, error) {
+	var goal data.Goal
+	if project != "" && tag != "" {
+		return goal, fmt.Errorf("%w: use either -project or -tag", ErrUsage)
+	}
+	if project != "" {
+		found, err := data.DB.FindProject(project)
+		if err != nil {
+			return goal, err
+		}
+		goal.ProjectID = &found.ID
+	}
+	goal.Tag = tag
+	return goal, nil
+}
+
+func runGoalsSet(out io.Writer, args []string) error {
+	fs := newFlagSet("goals set", out)
+	project, tag := scopeFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected a target such as 6h/day or 30h/week", ErrUsage)
+	}
+	goal, err := goalScope(*project, *tag)
+	if err != nil {
+		return err
+	}
+	goal.Period, goal.Target, err = data.ParseGoalTarget(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	saved, err := data.DB.SetGoal(goal)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Goal set: %s for %s\n", saved, saved.Scope(data.DB))
+	return nil
+}
+
+func runGoalsClear(out io.Writer, args []string) error {
+	fs := newFlagSet("goals clear", out)
+	project, tag := scopeFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected day or week", ErrUsage)
+	}
+	goal, err := goalScope(*project, *tag)
+	if err != nil {
+		return err
+	}
+	switch period := data.GoalPeriod(strings.ToLower(fs.Arg(0))); period {
+	case data.GoalDaily, data.GoalWeekly:
+		goal.Period = period
+	default:
+		return fmt.Errorf("%w: expected day or week, got %q", ErrUsage, fs.Arg(0))
+	}
+	if err := data.DB.ClearGoal(goal); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Cleared the %s goal for %s\n", goal.Period.Label(), goal.Scope(data.DB))
+	return nil
+}
+
+func runGoalsReport(out io.Writer, args []string) error {
+	fs := newFlagSet("goals", out)
+	days := fs.Int("days", 7, "number of days to report for daily goals")
+	weeks := fs.Int("weeks", 4, "number of weeks to report for weekly goals")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+
+	goals, err := data.DB.Goals()
+	if err != nil {
+		return err
+	}
+	if len(goals) == 0 {
+		_, _ = fmt.Fprintln(out, "No goals set. Add one with: samay goals set 6h/day")
+		return nil
+	}
+
+	now := time.Now()
+	for i, goal := range goals {
+		periods := *days
+		if goal.Period == data.GoalWeekly {
+			periods = *weeks
+		}
+		progress, err := data.DB.GoalProgress(goal, now, periods)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			_, _ = fmt.Fprintln(out)
+		}
+		_, _ = fmt.Fprintf(out, "%s goal %s for %s, streak %d\n",
+			strings.ToUpper(goal.Period.Label()[:1])+goal.Period.Label()[1:],
+			goal, goal.Scope(data.DB), progress.Streak)
+		for _, result := range progress.Results {
+			label := result.Start.Format("2006-01-02 Mon")
+			if goal.Period == data.GoalWeekly {
+				label = "week of " + result.Start.Format("2006-01-02")
+			}
+			status := "missed"
+			switch {
+			case result.Met:
+				status = "hit"
+			case result.Current:
+				status = "in progress"
+			}
+			_, _ = fmt.Fprintf(out, "  %-20s %7s  %s\n", label, util.HmFromD(result.Tracked), status)
+		}
+	}
+	return nil
+}
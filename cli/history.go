@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+func init() {
+	register(command{
+		name:    "history",
+		usage:   "history <entry-id>",
+		summary: "show every recorded change to an entry",
+		run:     runHistory,
+	})
+}
+
+func runHistory(out io.Writer, args []string) error {
+	fs := newFlagSet("history", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected an entry id", ErrUsage)
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	id, err := data.DB.ResolveEntryID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	changes, err := data.DB.EntryHistory(id)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("%w: %s has no recorded history", data.ErrEntryNotFound, id)
+	}
+
+	header := fmt.Sprintf("Entry %s", id)
+	if projectID := changes[len(changes)-1].ProjectID(); projectID != 0 {
+		if project, err := data.DB.Project(projectID); err == nil {
+			header += fmt.Sprintf(" (%s)", project.Name)
+		}
+	}
+	_, _ = fmt.Fprintln(out, header)
+
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(out, "%s  %s\n", change.CreatedAt.In(time.Local).Format("2006-01-02 15:04:05"), change.Action)
+		if change.Action != data.ChangeUpdate && change.Action != data.ChangeMove {
+			continue
+		}
+		for _, field := range change.Fields() {
+			_, _ = fmt.Fprintf(out, "    %s: %q -> %q\n", field.Field, oneLine(field.Before), oneLine(field.After))
+		}
+	}
+	return nil
+}
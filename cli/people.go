@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+func init() {
+	register(command{
+		name:    "whoami",
+		usage:   "whoami [-name name] [-email email]",
+		summary: "show or set who new entries are credited to",
+		run:     runWhoami,
+		// The identity lives in config.json and this process; a daemon
+		// would only change its own.
+		local: true,
+	})
+	register(command{
+		name:    "people",
+		usage:   "people",
+		summary: "list everyone who logs time in this database with this month's totals",
+		run:     runPeople,
+	})
+}
+
+func runWhoami(out io.Writer, args []string) error {
+	fs := newFlagSet("whoami", out)
+	name := fs.String("name", "", "your name as shown in reports")
+	email := fs.String("email", "", "your email address, which identifies you in a shared database")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	if *name == "" && *email == "" {
+		if person := data.DB.CurrentPerson(); person != nil {
+			_, _ = fmt.Fprintln(out, person)
+			return nil
+		}
+		_, _ = fmt.Fprintln(out, "No identity set. Set one with: samay whoami -name \"Ada Lovelace\" -email ada@example.com")
+		return nil
+	}
+
+	cfg, err := data.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if *email != "" {
+		cfg.Email = *email
+	}
+	if *name != "" {
+		cfg.Name = *name
+	}
+	if cfg.Email == "" {
+		return fmt.Errorf("%w: give an -email as well", ErrUsage)
+	}
+	person, err := data.DB.SetIdentity(cfg.Name, cfg.Email)
+	if err != nil {
+		return err
+	}
+	cfg.Name, cfg.Email = person.Name, person.Email
+	if err := data.SaveConfig(cfg); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "New entries will be credited to %s\n", person)
+	return nil
+}
+
+func runPeople(out io.Writer, args []string) error {
+	fs := newFlagSet("people", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	people, err := data.DB.People()
+	if err != nil {
+		return err
+	}
+	if len(people) == 0 {
+		_, _ = fmt.Fprintln(out, "No people yet. Set who you are with: samay whoami -email you@example.com")
+		return nil
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	entries, err := data.DB.EntriesInRange(monthStart, now.Add(time.Second), data.EntryFilter{})
+	if err != nil {
+		return err
+	}
+	totals := make(map[int64]time.Duration)
+	for _, entry := range entries {
+		var id int64 // zero collects unattributed entries
+		if entry.CreatorID != nil {
+			id = *entry.CreatorID
+		}
+		totals[id] += time.Duration(entry.GetDuration())
+	}
+
+	current := data.DB.CurrentPerson()
+	_, _ = fmt.Fprintf(out, "%s:\n", now.Format("January 2006"))
+	for _, person := range people {
+		marker := " "
+		if current != nil && current.ID == person.ID {
+			marker = "*"
+		}
+		_, _ = fmt.Fprintf(out, "%s %-30s %8s\n", marker, person, util.HmFromD(totals[person.ID]))
+	}
+	if totals[0] > 0 {
+		_, _ = fmt.Fprintf(out, "  %-30s %8s\n", "(unattributed)", util.HmFromD(totals[0]))
+	}
+	return nil
+}
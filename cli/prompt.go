@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+// promptTimeout bounds how long samay prompt spends reading the database.
+const promptTimeout = 250 * time.Millisecond
+
+func init() {
+	register(command{
+		name:       "prompt",
+		usage:      "prompt [-format ansi|plain|bash|zsh|tmux] [-short] [-cache]",
+		summary:    "print the running timer as a short segment for shell prompts and tmux",
+		run:        runPrompt,
+		standalone: true,
+	})
+}
+
+// promptStyle wraps the parts of the segment in the escapes a shell or tmux
+// expects, and escapes text it would otherwise interpret.
+type promptStyle struct {
+	project func(string) string
+	elapsed func(string) string
+	escape  func(string) string
+}
+
+func ansi(code string, wrap func(string) string) func(string) string {
+	return func(s string) string {
+		return wrap("\x1b["+code+"m") + s + wrap("\x1b[0m")
+	}
+}
+
+func promptStyleFor(format string) (promptStyle, error) {
+	plain := func(s string) string { return s }
+	switch format {
+	case "plain":
+		return promptStyle{project: plain, elapsed: plain, escape: plain}, nil
+	case "ansi":
+		return promptStyle{project: ansi("36", plain), elapsed: ansi("1", plain), escape: plain}, nil
+	case "bash":
+		// Readline's own markers: \[ and \] are decoded before command
+		// substitution runs, so they would show up literally.
+		readline := func(s string) string { return "\x01" + s + "\x02" }
+		return promptStyle{project: ansi("36", readline), elapsed: ansi("1", readline), escape: plain}, nil
+	case "zsh":
+		zsh := func(s string) string { return "%{" + s + "%}" }
+		escape := func(s string) string { return strings.ReplaceAll(s, "%", "%%") }
+		return promptStyle{project: ansi("36", zsh), elapsed: ansi("1", zsh), escape: escape}, nil
+	case "tmux":
+		return promptStyle{
+			project: func(s string) string { return "#[fg=cyan]" + s + "#[default]" },
+			elapsed: func(s string) string { return "#[bold]" + s + "#[nobold]" },
+			escape:  func(s string) string { return strings.ReplaceAll(s, "#", "##") },
+		}, nil
+	}
+	return promptStyle{}, fmt.Errorf("%w: unknown format %q", ErrUsage, format)
+}
+
+func runPrompt(out io.Writer, args []string) error {
+	fs := newFlagSet("prompt", out)
+	format := fs.String("format", "ansi", "ansi, plain, or the escapes of bash, zsh or tmux")
+	short := fs.Bool("short", false, "show only the last part of the project path")
+	cache := fs.Bool("cache", false, "reuse the last answer while the database is unchanged")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	style, err := promptStyleFor(*format)
+	if err != nil {
+		return err
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		// NO_COLOR only rules out colour; the bold elapsed time stays.
+		style.project = func(s string) string { return s }
+	}
+
+	dbPath := databasePath
+	if dbPath == "" && data.DB != nil {
+		dbPath = data.DB.Path()
+	}
+	var timers []data.RunningTimer
+	if *cache {
+		timers, err = cachedRunningTimers(dbPath)
+	} else {
+		timers, err = readRunningTimers(dbPath)
+	}
+	if err != nil {
+		return err
+	}
+	if segment := promptSegment(timers, time.Now(), *short, style); segment != "" {
+		_, _ = fmt.Fprintln(out, segment)
+	}
+	return nil
+}
+
+func readRunningTimers(dbPath string) ([]data.RunningTimer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), promptTimeout)
+	defer cancel()
+	return data.ReadRunningTimers(ctx, dbPath)
+}
+
+// promptSegment renders the most recently started timer and how many others
+// are running, or "" when none is.
+func promptSegment(timers []data.RunningTimer, now time.Time, short bool, style promptStyle) string {
+	if len(timers) == 0 {
+		return ""
+	}
+	timer := timers[0]
+	name := timer.Project
+	if short {
+		name = path.Base(name)
+	}
+	segment := style.project("⏱ "+style.escape(name)) + " " + style.elapsed(util.HmFromD(timer.Elapsed(now)).String())
+	if len(timers) > 1 {
+		segment += fmt.Sprintf(" +%d", len(timers)-1)
+	}
+	return segment
+}
+
+// promptCache is what samay prompt -cache remembers between runs. It stays
+// valid while the database and its write-ahead log keep the size and
+// modification time they had when it was written; the elapsed time is
+// always worked out afresh.
+type promptCache struct {
+	Database string              `json:"database"`
+	Files    []fileStamp         `json:"files"`
+	Timers   []data.RunningTimer `json:"timers"`
+}
+
+type fileStamp struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mod_time"`
+}
+
+func databaseStamps(dbPath string) []fileStamp {
+	stamps := make([]fileStamp, 0, 2)
+	for _, name := range []string{dbPath, dbPath + "-wal"} {
+		var stamp fileStamp
+		if info, err := os.Stat(name); err == nil {
+			stamp = fileStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		}
+		stamps = append(stamps, stamp)
+	}
+	return stamps
+}
+
+func promptCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "samay", "prompt.json"), nil
+}
+
+// cachedRunningTimers answers from the cache file when the database has not
+// changed since it was written, and reads the database and rewrites the
+// cache otherwise. A cache that cannot be used only costs the speed-up.
+func cachedRunningTimers(dbPath string) ([]data.RunningTimer, error) {
+	cachePath, err := promptCachePath()
+	if err != nil {
+		return readRunningTimers(dbPath)
+	}
+	stamps := databaseStamps(dbPath)
+	if raw, err := os.ReadFile(cachePath); err == nil {
+		var cached promptCache
+		if json.Unmarshal(raw, &cached) == nil && cached.Database == dbPath && slices.Equal(cached.Files, stamps) {
+			return cached.Timers, nil
+		}
+	}
+	timers, err := readRunningTimers(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	_ = writePromptCache(cachePath, promptCache{Database: dbPath, Files: stamps, Timers: timers})
+	return timers, nil
+}
+
+// writePromptCache replaces the cache file in one step, so a prompt drawn in
+// another terminal never reads half of it.
+func writePromptCache(cachePath string, cache promptCache) (err error) {
+	raw, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), "prompt-*.json")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(raw); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cachePath)
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nexneo/samay/api"
+	"github.com/nexneo/samay/daemon"
+	"github.com/nexneo/samay/data"
+)
+
+func init() {
+	register(command{
+		name:    "serve",
+		usage:   "serve [-addr host:port | -socket path]",
+		summary: "serve the JSON API for projects, entries, timers and reports",
+		run:     runServe,
+		local:   true,
+	})
+}
+
+func runServe(out io.Writer, args []string) error {
+	fs := newFlagSet("serve", out)
+	addr := fs.String("addr", "127.0.0.1:7411", "TCP address to listen on")
+	socket := fs.String("socket", "", "listen on this Unix socket instead of TCP")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	if *socket == "" {
+		if err := checkLoopback(*addr); err != nil {
+			return err
+		}
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	cfg, err := data.LoadConfig()
+	if err != nil {
+		return err
+	}
+	server, err := api.New(data.DB, cfg.APIToken)
+	if err != nil {
+		return err
+	}
+
+	var listener net.Listener
+	if *socket != "" {
+		if listener, err = daemon.ListenPrivate(*socket); err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(*socket) }()
+		_, _ = fmt.Fprintf(out, "Serving the samay API on unix:%s\n", *socket)
+	} else {
+		if listener, err = net.Listen("tcp", *addr); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "Serving the samay API on http://%s\n", listener.Addr())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serveUntilDone(ctx, listener, server)
+}
+
+// checkLoopback refuses TCP addresses other machines could reach: the API
+// hands out every entry to anyone with the token.
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%w: -addr %q: %v", ErrUsage, addr, err)
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return nil
+	}
+	return fmt.Errorf("%w: -addr %q is not a loopback address; use 127.0.0.1, ::1 or localhost", ErrUsage, addr)
+}
+
+// serveUntilDone serves handler on listener until ctx is cancelled, then lets
+// requests in flight finish.
+func serveUntilDone(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(listener) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil {
+		return err
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+func init() {
+	register(command{
+		name:    "split",
+		usage:   "split [-first text] [-second text] <entry-id> <time|duration>",
+		summary: "cut an entry in two at a time such as 14:30 or after a duration such as 45m",
+		run:     runSplit,
+	})
+	register(command{
+		name:    "merge",
+		usage:   "merge <entry-id> <entry-id>...",
+		summary: "combine entries from one project into the first one",
+		run:     runMerge,
+	})
+}
+
+func runSplit(out io.Writer, args []string) error {
+	fs := newFlagSet("split", out)
+	first := fs.String("first", "", "description for the part before the split (default: unchanged)")
+	second := fs.String("second", "", "description for the part after the split (default: the entry's)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("%w: expected an entry id and where to split it", ErrUsage)
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	entry, err := data.DB.Entry(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	at, err := entry.SplitPoint(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	rest, err := entry.SplitAt(at, *first, *second)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(out, "Split entry into:")
+	_, _ = fmt.Fprintln(out, entryLine(entry))
+	_, _ = fmt.Fprintln(out, entryLine(rest))
+	return nil
+}
+
+func runMerge(out io.Writer, args []string) error {
+	fs := newFlagSet("merge", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("%w: expected at least two entry ids", ErrUsage)
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	entries := make([]*data.Entry, 0, fs.NArg())
+	for _, id := range fs.Args() {
+		entry, err := data.DB.Entry(id)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	target := entries[0]
+	if err := target.Merge(entries[1:]...); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Merged %d entries into:\n", len(entries))
+	_, _ = fmt.Fprintln(out, entryLine(target))
+	if len(target.Conflicts) > 0 {
+		_, _ = fmt.Fprintf(out, "warning: it overlaps %s\n", data.DescribeConflicts(target.Conflicts))
+	}
+	return nil
+}
+
+// entryLine renders an entry's short id, time and description on one line.
+func entryLine(entry *data.Entry) string {
+	when := ""
+	if span, ok := data.EntrySpan(entry); ok {
+		start, end := span.Start.In(time.Local), span.End.In(time.Local)
+		when = fmt.Sprintf("%s %s–%s", start.Format("2006-01-02"), start.Format("15:04"), end.Format("15:04"))
+	}
+	line := fmt.Sprintf("  %s  %s  %6s  %s", shortID(entry.ID), when, util.HmFromD(time.Duration(entry.GetDuration())), oneLine(entry.GetContent()))
+	if entry.Type != "" && entry.Type != data.EntryTypeWork {
+		line += "  (" + strings.ToLower(string(entry.Type)) + ")"
+	}
+	return line
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+func init() {
+	register(command{
+		name:    "template",
+		usage:   "template [add|apply|delete] [flags] [name] [description]",
+		summary: "list entry templates, save or delete one, or log one now",
+		run:     runTemplate,
+	})
+	register(command{
+		name:    "recur",
+		usage:   "recur [run]",
+		summary: "list scheduled templates, or log every occurrence that is due",
+		run:     runRecur,
+	})
+}
+
+func runTemplate(out io.Writer, args []string) error {
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "add":
+			return runTemplateAdd(out, args[1:])
+		case "apply":
+			return runTemplateApply(out, args[1:])
+		case "delete":
+			return runTemplateDelete(out, args[1:])
+		}
+	}
+	fs := newFlagSet("template", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+
+	templates, err := data.DB.Templates()
+	if err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		_, _ = fmt.Fprintln(out, "No templates. Add one with: samay template add -project Acme -duration 15m standup daily standup")
+		return nil
+	}
+	for _, t := range templates {
+		_, _ = fmt.Fprintln(out, templateLine(t))
+	}
+	return nil
+}
+
+func runTemplateAdd(out io.Writer, args []string) error {
+	fs := newFlagSet("template add", out)
+	project := fs.String("project", "", "project path the entries are logged to (required)")
+	duration := fs.String("duration", "", "how long each entry lasts, such as 15m or 1:30")
+	entryType := fs.String("type", "work", "entry type: work, chore or fun")
+	tags := fs.String("tags", "", "comma-separated tags added to each entry")
+	nonBillable := fs.Bool("nonbillable", false, "log the entries as non-billable")
+	repeat := fs.String("repeat", "", "log the template automatically, such as \"every weekday 09:30\"")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 {
+		return fmt.Errorf("%w: expected a template name", ErrUsage)
+	}
+	if *project == "" {
+		return fmt.Errorf("%w: -project is required", ErrUsage)
+	}
+
+	t := &data.Template{Name: fs.Arg(0), Content: strings.Join(fs.Args()[1:], " "), Billable: !*nonBillable}
+	var err error
+	if t.Project, err = data.DB.FindProject(*project); err != nil {
+		return err
+	}
+	if t.Type, err = data.ParseEntryType(*entryType); err != nil {
+		return err
+	}
+	if *tags != "" {
+		t.Tags = strings.Split(*tags, ",")
+	}
+	if *repeat != "" {
+		var length time.Duration
+		if t.Repeat, length, err = data.ParseRecurrence(*repeat); err != nil {
+			return err
+		}
+		t.Duration = length
+	}
+	if *duration != "" {
+		if t.Duration, err = util.ParseDuration(*duration); err != nil {
+			return err
+		}
+	}
+	if t.Duration == 0 {
+		return fmt.Errorf("%w: give a -duration such as 15m", ErrUsage)
+	}
+	if err := data.DB.SaveTemplate(t); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(out, "Template saved:")
+	_, _ = fmt.Fprintln(out, templateLine(t))
+	return nil
+}
+
+func runTemplateApply(out io.Writer, args []string) error {
+	fs := newFlagSet("template apply", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected a template name", ErrUsage)
+	}
+	t, err := data.DB.Template(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	entry, err := t.Apply(time.Now())
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Logged %s to %s:\n", t.Name, t.Project.Path())
+	_, _ = fmt.Fprintln(out, entryLine(entry))
+	if len(entry.Conflicts) > 0 {
+		_, _ = fmt.Fprintf(out, "warning: it overlaps %s\n", data.DescribeConflicts(entry.Conflicts))
+	}
+	return nil
+}
+
+func runTemplateDelete(out io.Writer, args []string) error {
+	fs := newFlagSet("template delete", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected a template name", ErrUsage)
+	}
+	if err := data.DB.DeleteTemplate(fs.Arg(0)); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Deleted template %s\n", fs.Arg(0))
+	return nil
+}
+
+func runRecur(out io.Writer, args []string) error {
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+	if len(args) > 0 && args[0] == "run" {
+		created, err := data.DB.RunRecurrences(time.Now())
+		_, _ = fmt.Fprintf(out, "Logged %d recurring entries\n", len(created))
+		for _, entry := range created {
+			_, _ = fmt.Fprintln(out, entryLine(entry))
+		}
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, args[0])
+	}
+
+	templates, err := data.DB.Templates()
+	if err != nil {
+		return err
+	}
+	scheduled := 0
+	for _, t := range templates {
+		if t.Repeat.IsZero() {
+			continue
+		}
+		scheduled++
+		_, _ = fmt.Fprintln(out, templateLine(t))
+	}
+	if scheduled == 0 {
+		_, _ = fmt.Fprintln(out, "No scheduled templates. Add one with: samay template add -repeat \"every weekday 09:30\" …")
+	}
+	return nil
+}
+
+// templateLine renders a template's name, project, length, schedule and description on one line.
+func templateLine(t *data.Template) string {
+	details := []string{t.Project.Path(), util.HmFromD(t.Duration).String()}
+	if t.Type != data.EntryTypeWork {
+		details = append(details, strings.ToLower(string(t.Type)))
+	}
+	if !t.Billable {
+		details = append(details, "non-billable")
+	}
+	if !t.Repeat.IsZero() {
+		details = append(details, t.Repeat.String())
+	}
+	line := fmt.Sprintf("  %-16s %s", t.Name, strings.Join(details, ", "))
+	if content := oneLine(t.Content); content != "" {
+		line += "  " + content
+	}
+	for _, tag := range t.Tags {
+		line += " #" + tag
+	}
+	return line
+}
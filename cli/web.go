@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/web"
+)
+
+func init() {
+	register(command{
+		name:    "web",
+		usage:   "web [-addr host:port]",
+		summary: "serve the browser UI for projects, timers, entries and reports",
+		run:     runWeb,
+		local:   true,
+	})
+}
+
+// runWeb serves the web UI. It has no login of its own, so it listens on the
+// loopback interface unless told otherwise.
+func runWeb(out io.Writer, args []string) error {
+	fs := newFlagSet("web", out)
+	addr := fs.String("addr", "127.0.0.1:7412", "TCP address to listen on")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	server, err := web.New(data.DB)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Serving samay on http://%s\n", listener.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serveUntilDone(ctx, listener, server)
+}
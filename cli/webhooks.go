@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/webhook"
+)
+
+// failedWebhooksShown caps how many failed deliveries samay webhooks lists.
+const failedWebhooksShown = 10
+
+func init() {
+	register(command{
+		name:    "webhooks",
+		usage:   "webhooks [deliver|retry]",
+		summary: "show configured webhooks and their outbox, send what is due, or retry failures",
+		run:     runWebhooks,
+	})
+}
+
+func runWebhooks(out io.Writer, args []string) error {
+	if data.DB == nil {
+		return errors.New("database not initialized")
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "deliver":
+			return runWebhooksDeliver(out, args[1:])
+		case "retry":
+			return runWebhooksRetry(out, args[1:])
+		}
+	}
+	fs := newFlagSet("webhooks", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+
+	statuses, err := data.DB.WebhookStatus()
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]data.WebhookStatus, len(statuses))
+	for _, status := range statuses {
+		counts[status.URL] = status
+	}
+	hooks := data.DB.Webhooks()
+	if len(hooks) == 0 {
+		_, _ = fmt.Fprintln(out, `No webhooks. Add them to config.json as "webhooks": [{"url": "...", "secret": "..."}]`)
+	}
+	for _, hook := range hooks {
+		events := "all events"
+		if len(hook.Events) > 0 {
+			events = strings.Join(hook.Events, ", ")
+		}
+		signed := "unsigned"
+		if hook.Secret != "" {
+			signed = "signed"
+		}
+		status := counts[hook.URL]
+		_, _ = fmt.Fprintf(out, "%s  (%s, %s)  %d pending, %d failed\n", hook.URL, events, signed, status.Pending, status.Failed)
+		delete(counts, hook.URL)
+	}
+	for _, status := range counts {
+		_, _ = fmt.Fprintf(out, "%s  (no longer configured)  %d queued\n", status.URL, status.Pending+status.Failed)
+	}
+
+	failed, err := data.DB.FailedWebhookDeliveries(failedWebhooksShown)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		_, _ = fmt.Fprintln(out, "\nFailed deliveries (samay webhooks retry sends them again):")
+	}
+	for _, delivery := range failed {
+		_, _ = fmt.Fprintf(out, "  %s  %-15s %s after %d attempts: %s\n",
+			delivery.CreatedAt.Local().Format("2006-01-02 15:04"), delivery.Event, delivery.URL, delivery.Attempts, delivery.LastError)
+	}
+	return nil
+}
+
+func runWebhooksDeliver(out io.Writer, args []string) error {
+	fs := newFlagSet("webhooks deliver", out)
+	timeout := fs.Duration("timeout", time.Minute, "give up on the remaining deliveries after this long")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	sent, failed, err := webhook.NewSender(data.DB, nil).Deliver(ctx)
+	_, _ = fmt.Fprintf(out, "Sent %d, failed %d.\n", sent, failed)
+	return err
+}
+
+func runWebhooksRetry(out io.Writer, args []string) error {
+	fs := newFlagSet("webhooks retry", out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, fs.Arg(0))
+	}
+	n, err := data.DB.RetryFailedWebhooks(time.Now())
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Queued %d failed deliveries again.\n", n)
+	return nil
+}
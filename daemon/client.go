@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dialTimeout bounds how long connecting to the daemon may take, so samay
+// falls back to opening the database itself without a noticeable pause.
+const dialTimeout = 200 * time.Millisecond
+
+// Client talks to a daemon over its socket.
+type Client struct {
+	http *http.Client
+}
+
+// Dial returns a client for the daemon listening on the socket at path. It
+// fails when there is no socket there; whether a daemon still answers on it
+// shows on the first request.
+func Dial(path string) (*Client, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	dialer := net.Dialer{Timeout: dialTimeout}
+	return &Client{http: &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}}}, nil
+}
+
+// Connect returns a client for the daemon on the default socket, provided it
+// is running and serves the database at dbPath.
+func Connect(dbPath string) (*Client, bool) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, false
+	}
+	client, err := Dial(path)
+	if err != nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := client.Status(ctx)
+	if err != nil || canonicalPath(status.Database) != canonicalPath(dbPath) {
+		return nil, false
+	}
+	return client, true
+}
+
+func canonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// Status asks the daemon for its state.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	var status Status
+	err := c.do(ctx, http.MethodGet, "/status", nil, &status)
+	return status, err
+}
+
+// Run has the daemon run the command args and returns what it printed and
+// how it ended.
+func (c *Client) Run(ctx context.Context, args []string) (Result, error) {
+	var result Result
+	err := c.do(ctx, http.MethodPost, "/run", runRequest{Args: args}, &result)
+	return result, err
+}
+
+// Stop asks the daemon to finish the work in flight and exit.
+func (c *Client) Stop(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/stop", nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+	// The host is ignored; every request goes to the socket.
+	req, err := http.NewRequestWithContext(ctx, method, "http://samay"+path, &payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("reach samay daemon: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("samay daemon answered %s", resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode daemon reply: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+// Package daemon runs samay in the background: one long-lived process keeps
+// the database open, runs scheduled jobs while no samay window is open, and
+// runs the commands other samay invocations hand it over a Unix socket. The
+// interactive UI is not served; it keeps its own connection to the database.
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util/version"
+)
+
+// ErrRunning is returned by Listen when a daemon already answers on the socket.
+var ErrRunning = errors.New("a samay daemon is already running")
+
+// maxRequestBytes bounds the size of request bodies.
+const maxRequestBytes = 1 << 20
+
+// SocketPath returns where the daemon listens: samay.sock in
+// $XDG_RUNTIME_DIR when it is set, else daemon.sock in samay's cache
+// directory.
+func SocketPath() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "samay.sock"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "samay", "daemon.sock"), nil
+}
+
+// Runner runs one samay command, writing its output to out.
+type Runner func(out io.Writer, args []string) error
+
+// Job is work the daemon repeats on a schedule. It runs once when the daemon
+// starts and then every Every.
+type Job struct {
+	Name  string
+	Every time.Duration
+	Run   func(ctx context.Context, now time.Time) error
+}
+
+// JobStatus reports how a job has fared.
+type JobStatus struct {
+	Name      string    `json:"name"`
+	Every     string    `json:"every"`
+	Runs      int       `json:"runs"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Status describes a running daemon.
+type Status struct {
+	PID       int         `json:"pid"`
+	Version   string      `json:"version"`
+	Database  string      `json:"database"`
+	StartedAt time.Time   `json:"started_at"`
+	Jobs      []JobStatus `json:"jobs"`
+}
+
+// Result is the outcome of a command run by the daemon.
+type Result struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+	// Usage is set when the command was invoked with invalid arguments.
+	Usage bool `json:"usage,omitempty"`
+}
+
+type runRequest struct {
+	Args []string `json:"args"`
+}
+
+// Server is the daemon. Commands and jobs take turns, so each sees the
+// database as the previous one left it.
+type Server struct {
+	db      *data.Database
+	run     Runner
+	usage   error
+	jobs    []Job
+	mux     *http.ServeMux
+	started time.Time
+	now     func() time.Time
+
+	// turn is held while a command or job runs.
+	turn     sync.Mutex
+	statusMu sync.Mutex
+	statuses []JobStatus
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// New returns a daemon for db that runs commands with run and repeats jobs.
+// Errors from run that wrap usage are reported as usage errors.
+func New(db *data.Database, run Runner, usage error, jobs []Job) (*Server, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	s := &Server{
+		db:      db,
+		run:     run,
+		usage:   usage,
+		jobs:    jobs,
+		mux:     http.NewServeMux(),
+		started: time.Now(),
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+	for _, job := range jobs {
+		s.statuses = append(s.statuses, JobStatus{Name: job.Name, Every: job.Every.String()})
+	}
+	s.mux.HandleFunc("GET /status", s.status)
+	s.mux.HandleFunc("POST /run", s.runCommand)
+	s.mux.HandleFunc("POST /stop", s.stopDaemon)
+	return s, nil
+}
+
+// Listen opens the Unix socket at path, readable only by its owner. A socket
+// left behind by a daemon that is gone is replaced.
+func Listen(path string) (net.Listener, error) {
+	if client, err := Dial(path); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := client.Status(ctx)
+		cancel()
+		if err == nil {
+			return nil, fmt.Errorf("%w on %s", ErrRunning, path)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create socket directory: %w", err)
+	}
+	return ListenPrivate(path)
+}
+
+// ListenPrivate opens the Unix socket at path, replacing a stale one. The
+// socket is created readable only by its owner, so no other user can connect
+// in the moment before its permissions could be changed.
+func ListenPrivate(path string) (net.Listener, error) {
+	if err := RemoveStaleSocket(path); err != nil {
+		return nil, err
+	}
+	// The umask is process-wide; the socket is the only file made meanwhile.
+	umask := syscall.Umask(0o077)
+	listener, err := net.Listen("unix", path)
+	syscall.Umask(umask)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// RemoveStaleSocket clears the way to listen on path. Only a socket that
+// nothing listens on any more is removed; any other file there, or a socket
+// in use, is an error, so a mistyped path never deletes a file.
+func RemoveStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check socket: %w", err)
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, dialTimeout); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove stale socket: %w", err)
+	}
+	return nil
+}
+
+// Serve answers requests on listener and runs the jobs until ctx is done or a
+// client asks the daemon to stop, then lets work in flight finish.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var jobs sync.WaitGroup
+	for i := range s.jobs {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			s.schedule(ctx, i)
+		}()
+	}
+	defer jobs.Wait()
+
+	srv := &http.Server{Handler: s.mux, ReadHeaderTimeout: 10 * time.Second}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(listener) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, stop := context.WithTimeout(context.Background(), 30*time.Second)
+	defer stop()
+	if err := srv.Shutdown(shutdown); err != nil {
+		return err
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// schedule runs job i now and then every interval until ctx is done.
+func (s *Server) schedule(ctx context.Context, i int) {
+	job := s.jobs[i]
+	ticker := time.NewTicker(job.Every)
+	defer ticker.Stop()
+	for {
+		s.runJob(ctx, i)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) runJob(ctx context.Context, i int) {
+	s.turn.Lock()
+	now := s.now()
+	err := s.jobs[i].Run(ctx, now)
+	s.turn.Unlock()
+
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	status := &s.statuses[i]
+	status.Runs++
+	status.LastRun = now
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+// Status reports the daemon's state.
+func (s *Server) Status() Status {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	return Status{
+		PID:       os.Getpid(),
+		Version:   version.String(),
+		Database:  s.db.Path(),
+		StartedAt: s.started,
+		Jobs:      append([]JobStatus(nil), s.statuses...),
+	}
+}
+
+func (s *Server) status(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.Status())
+}
+
+func (s *Server) runCommand(w http.ResponseWriter, r *http.Request) {
+	var req runRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil || len(req.Args) == 0 {
+		http.Error(w, "expected {\"args\": [...]}", http.StatusBadRequest)
+		return
+	}
+	var out bytes.Buffer
+	s.turn.Lock()
+	err := s.run(&out, req.Args)
+	s.turn.Unlock()
+
+	result := Result{Output: out.String()}
+	if err != nil {
+		result.Error = err.Error()
+		result.Usage = s.usage != nil && errors.Is(err, s.usage)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) stopDaemon(w http.ResponseWriter, _ *http.Request) {
+	s.stopOnce.Do(func() { close(s.stop) })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,194 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+var errUsage = errors.New("usage")
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "samay-daemon-test-*")
+	if err != nil {
+		panic(fmt.Sprintf("create temp dir: %v", err))
+	}
+	if err := data.OpenDatabase(filepath.Join(dir, "test.db")); err != nil {
+		panic(fmt.Sprintf("open database: %v", err))
+	}
+
+	code := m.Run()
+
+	if data.DB != nil {
+		_ = data.DB.Close()
+	}
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestServeRunsCommandsAndJobs(t *testing.T) {
+	run := func(out io.Writer, args []string) error {
+		switch args[0] {
+		case "echo":
+			_, _ = fmt.Fprintln(out, args[1:])
+			return nil
+		case "bogus":
+			return fmt.Errorf("%w: unknown command", errUsage)
+		}
+		_, _ = fmt.Fprintln(out, "partial")
+		return errors.New("broken")
+	}
+	var runs atomic.Int32
+	jobs := []Job{{Name: "tick", Every: time.Hour, Run: func(context.Context, time.Time) error {
+		runs.Add(1)
+		return errors.New("tick failed")
+	}}}
+	server, err := New(data.DB, run, errUsage, jobs)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	socket := filepath.Join(t.TempDir(), "samay.sock")
+	listener, err := Listen(socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(context.Background(), listener) }()
+
+	client, err := Dial(socket)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	ctx := context.Background()
+	result, err := client.Run(ctx, []string{"echo", "a", "b"})
+	if err != nil || result != (Result{Output: "[a b]\n"}) {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+	result, _ = client.Run(ctx, []string{"bogus"})
+	if !result.Usage || result.Error != "usage: unknown command" {
+		t.Fatalf("expected a usage error, got %+v", result)
+	}
+	result, _ = client.Run(ctx, []string{"fail"})
+	if result.Usage || result.Error != "broken" || result.Output != "partial\n" {
+		t.Fatalf("expected the output and the error, got %+v", result)
+	}
+
+	// The job runs once at start, alongside the first requests.
+	var status Status
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if status, err = client.Status(ctx); err != nil {
+			t.Fatalf("status: %v", err)
+		}
+		if status.Jobs[0].Runs > 0 || time.Now().After(deadline) {
+			break
+		}
+	}
+	if status.Database != data.DB.Path() || status.PID != os.Getpid() {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if runs.Load() != 1 || len(status.Jobs) != 1 || status.Jobs[0].Runs != 1 || status.Jobs[0].LastError != "tick failed" {
+		t.Fatalf("expected the job to have run once at start, got %+v", status.Jobs)
+	}
+
+	if _, err := Listen(socket); !errors.Is(err, ErrRunning) {
+		t.Fatalf("expected a second daemon to be refused, got %v", err)
+	}
+
+	if err := client.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+	if _, err := Listen(socket); err != nil {
+		t.Fatalf("expected the stale socket to be replaced, got %v", err)
+	}
+}
+
+func TestConnectMatchesDatabase(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	socket, err := SocketPath()
+	if err != nil {
+		t.Fatalf("socket path: %v", err)
+	}
+	if _, ok := Connect(data.DB.Path()); ok {
+		t.Fatal("expected no daemon before one listens")
+	}
+	server, err := New(data.DB, func(io.Writer, []string) error { return nil }, nil, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	listener, err := Listen(socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+
+	if _, ok := Connect(data.DB.Path()); !ok {
+		t.Fatal("expected to connect to the daemon serving the database")
+	}
+	if _, ok := Connect(filepath.Join(t.TempDir(), "other.db")); ok {
+		t.Fatal("expected a daemon serving another database to be ignored")
+	}
+}
+
+func TestListenPrivateCreatesOwnerOnlySocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	listener, err := ListenPrivate(socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected an owner-only socket, got %v", perm)
+	}
+}
+
+func TestListenKeepsFilesThatAreNotStaleSockets(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("keep me"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := Listen(notes); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("expected a regular file to be refused, got %v", err)
+	}
+	if got, err := os.ReadFile(notes); err != nil || string(got) != "keep me" {
+		t.Fatalf("expected the file to be left alone, got %q, %v", got, err)
+	}
+
+	socket := filepath.Join(dir, "busy.sock")
+	other, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = other.Close() })
+	if _, err := Listen(socket); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("expected a socket in use to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// EntryFilter narrows entries to a project, including its sub-projects, or to a tag.
+// The zero value matches every entry.
+type EntryFilter struct {
+	ProjectID *int64
+	Tag       string
+}
+
+// ParseEntryFilter reads "#tag" as a tag filter and anything else as a project
+// name or path. An empty spec matches everything.
+func (d *Database) ParseEntryFilter(spec string) (EntryFilter, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return EntryFilter{}, nil
+	case strings.HasPrefix(spec, "#"):
+		tag := normalizeTag(spec)
+		if tag == "" {
+			return EntryFilter{}, errors.New("tag cannot be empty")
+		}
+		return EntryFilter{Tag: tag}, nil
+	}
+	project, err := d.FindProject(spec)
+	if err != nil {
+		return EntryFilter{}, err
+	}
+	return EntryFilter{ProjectID: &project.ID}, nil
+}
+
+// IsZero reports whether the filter matches every entry.
+func (f EntryFilter) IsZero() bool {
+	return f.ProjectID == nil && f.Tag == ""
+}
+
+// Label describes the filter: a project path, a #tag, or all projects.
+func (f EntryFilter) Label(d *Database) string {
+	switch {
+	case f.ProjectID != nil:
+		if d != nil {
+			if project, err := d.Project(*f.ProjectID); err == nil {
+				return project.Path()
+			}
+		}
+		return fmt.Sprintf("project %d", *f.ProjectID)
+	case f.Tag != "":
+		return "#" + f.Tag
+	default:
+		return "all projects"
+	}
+}
+
+func normalizeTag(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+func (f EntryFilter) params() (sql.NullInt64, sql.NullString) {
+	return optionalInt64(f.ProjectID), sql.NullString{String: f.Tag, Valid: f.Tag != ""}
+}
+
+// EntriesInRange loads the entries matching filter that ended in [start, end),
+// earliest first, with their projects and tags.
+func (d *Database) EntriesInRange(start, end time.Time, filter EntryFilter) ([]*Entry, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	ctx := context.Background()
+	projectID, tag := filter.params()
+	rows, err := d.queries.ListEntriesInRange(ctx, sqlc.ListEntriesInRangeParams{
+		EndedAt:   sql.NullInt64{Int64: start.Unix(), Valid: true},
+		EndedAt_2: sql.NullInt64{Int64: end.Unix(), Valid: true},
+		ProjectID: projectID,
+		Tag:       tag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list entries: %w", err)
+	}
+
+	return d.entriesFromRows(ctx, d.queries, rows)
+}
+
+// Entry loads an entry that is not in the trash by its full or abbreviated id.
+func (d *Database) Entry(id string) (*Entry, error) {
+	id, err := d.ResolveEntryID(id)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	row, err := d.queries.GetEntry(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && row.DeletedAt.Valid) {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load entry %s: %w", id, err)
+	}
+	entries, err := d.entriesFromRows(ctx, d.queries, []sqlc.Entry{row})
+	if err != nil {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// entriesFromRows builds entries from rows, loading each project once and every entry's tags.
+func (d *Database) entriesFromRows(ctx context.Context, q *sqlc.Queries, rows []sqlc.Entry) ([]*Entry, error) {
+	projects := make(map[int64]*Project)
+	entries := make([]*Entry, 0, len(rows))
+	for _, row := range rows {
+		project, ok := projects[row.ProjectID]
+		if !ok {
+			record, err := q.GetProject(ctx, row.ProjectID)
+			if err != nil {
+				return nil, fmt.Errorf("load project %d: %w", row.ProjectID, err)
+			}
+			project = newProjectFromModel(d, record)
+			projects[row.ProjectID] = project
+		}
+		e := newEntryFromModel(d, project, row)
+		tagRows, err := q.ListTagsForEntry(ctx, row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("load tags for entry %s: %w", row.ID, err)
+		}
+		e.Tags = make([]string, 0, len(tagRows))
+		for _, t := range tagRows {
+			e.Tags = append(e.Tags, t.Tag)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// DailyTotals sums the time matching filter per local day over [start, end).
+// Days are keyed by their local midnight; days without time are left out.
+func (d *Database) DailyTotals(start, end time.Time, filter EntryFilter) (map[time.Time]time.Duration, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	projectID, tag := filter.params()
+	rows, err := d.queries.ListEntryDurationsInRange(context.Background(), sqlc.ListEntryDurationsInRangeParams{
+		EndedAt:   sql.NullInt64{Int64: start.Unix(), Valid: true},
+		EndedAt_2: sql.NullInt64{Int64: end.Unix(), Valid: true},
+		ProjectID: projectID,
+		Tag:       tag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list entry durations: %w", err)
+	}
+	totals := make(map[time.Time]time.Duration)
+	for _, row := range rows {
+		day := StartOfDay(time.Unix(row.EndedAt.Int64, 0))
+		totals[day] += time.Duration(row.DurationMs) * time.Millisecond
+	}
+	return totals, nil
+}
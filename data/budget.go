@@ -0,0 +1,333 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+	"github.com/nexneo/samay/util"
+)
+
+// BudgetUnit is what a budget is measured in.
+type BudgetUnit string
+
+const (
+	BudgetHours BudgetUnit = "hours"
+	BudgetMoney BudgetUnit = "money"
+)
+
+// BudgetPeriod is the window a budget resets over.
+type BudgetPeriod string
+
+const (
+	BudgetTotal BudgetPeriod = "total"
+	BudgetWeek  BudgetPeriod = "week"
+	BudgetMonth BudgetPeriod = "month"
+)
+
+// BudgetNearPercent is the share of a budget at which it starts warning.
+const BudgetNearPercent = 80
+
+// Budget caps the time or money spent on a project and its sub-projects.
+type Budget struct {
+	Unit   BudgetUnit
+	Period BudgetPeriod
+	// Amount is in minutes for hour budgets and cents for money budgets.
+	Amount int64
+	// HourlyRate is in cents per billable hour and only applies to money budgets.
+	HourlyRate int64
+}
+
+// ParseBudget reads a budget such as "40h", "10h/week", "$5000/month @150" or
+// "$12000 @95.50". Money budgets are spent by billable time at the given hourly rate.
+func ParseBudget(spec string) (Budget, error) {
+	original := spec
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return Budget{}, errors.New("budget cannot be empty")
+	}
+
+	budget := Budget{Unit: BudgetHours, Period: BudgetTotal}
+	if at := strings.Index(spec, "@"); at >= 0 {
+		rate, err := parseCents(strings.TrimPrefix(strings.TrimSpace(spec[at+1:]), "$"))
+		if err != nil {
+			return Budget{}, fmt.Errorf("budget %q: invalid hourly rate", original)
+		}
+		budget.HourlyRate = rate
+		spec = strings.TrimSpace(spec[:at])
+	}
+	if slash := strings.Index(spec, "/"); slash >= 0 {
+		switch period := BudgetPeriod(strings.ToLower(strings.TrimSpace(spec[slash+1:]))); period {
+		case BudgetTotal, BudgetWeek, BudgetMonth:
+			budget.Period = period
+		default:
+			return Budget{}, fmt.Errorf("budget %q: period must be total, week or month", original)
+		}
+		spec = strings.TrimSpace(spec[:slash])
+	}
+
+	if strings.HasPrefix(spec, "$") {
+		budget.Unit = BudgetMoney
+		amount, err := parseCents(strings.TrimPrefix(spec, "$"))
+		if err != nil {
+			return Budget{}, fmt.Errorf("budget %q: invalid amount", original)
+		}
+		budget.Amount = amount
+	} else {
+		minutes, err := parseHours(spec)
+		if err != nil {
+			return Budget{}, fmt.Errorf("budget %q: expected hours like 40h or an amount like $5000", original)
+		}
+		budget.Amount = minutes
+	}
+
+	if err := budget.validate(); err != nil {
+		return Budget{}, fmt.Errorf("budget %q: %w", original, err)
+	}
+	return budget, nil
+}
+
+// parseHours reads a positive number of hours such as "40h" or "7.5" into minutes.
+// A bare number counts hours; anything else, such as "7h30m", "1:30" or "90 minutes",
+// is read as a duration.
+func parseHours(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if hours, err := strconv.ParseFloat(value, 64); err == nil {
+		minutes := int64(math.Round(hours * 60))
+		if minutes <= 0 || math.IsInf(hours, 0) {
+			return 0, errors.New("invalid hours")
+		}
+		return minutes, nil
+	}
+	d, err := util.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	minutes := int64(d.Round(time.Minute) / time.Minute)
+	if minutes <= 0 {
+		return 0, errors.New("invalid hours")
+	}
+	return minutes, nil
+}
+
+func parseCents(value string) (int64, error) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+	if err != nil || amount <= 0 || math.IsInf(amount, 0) {
+		return 0, errors.New("invalid amount")
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+func (b Budget) validate() error {
+	if b.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	switch b.Unit {
+	case BudgetHours:
+		if b.HourlyRate != 0 {
+			return errors.New("an hourly rate only applies to money budgets")
+		}
+	case BudgetMoney:
+		if b.HourlyRate <= 0 {
+			return errors.New("money budgets need an hourly rate, e.g. @150")
+		}
+	default:
+		return fmt.Errorf("unknown unit %q", b.Unit)
+	}
+	switch b.Period {
+	case BudgetTotal, BudgetWeek, BudgetMonth:
+		return nil
+	default:
+		return fmt.Errorf("unknown period %q", b.Period)
+	}
+}
+
+// String renders the budget in the form accepted by ParseBudget.
+func (b Budget) String() string {
+	var sb strings.Builder
+	sb.WriteString(b.formatAmount(b.Amount))
+	if b.Period != BudgetTotal {
+		sb.WriteString("/" + string(b.Period))
+	}
+	if b.Unit == BudgetMoney {
+		sb.WriteString(" @" + formatCents(b.HourlyRate))
+	}
+	return sb.String()
+}
+
+func (b Budget) formatAmount(amount int64) string {
+	if b.Unit == BudgetMoney {
+		return "$" + formatCents(amount)
+	}
+	return formatHours(amount)
+}
+
+func formatHours(minutes int64) string {
+	return strconv.FormatFloat(float64(minutes)/60, 'f', -1, 64) + "h"
+}
+
+func formatCents(cents int64) string {
+	if cents%100 == 0 {
+		return strconv.FormatInt(cents/100, 10)
+	}
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// Window returns the range the budget counts time in at now. Total budgets
+// count everything, so start is zero.
+func (b Budget) Window(now time.Time) (start, end time.Time) {
+	switch b.Period {
+	case BudgetWeek:
+		start = StartOfWeek(now)
+		return start, start.AddDate(0, 0, 7)
+	case BudgetMonth:
+		day := StartOfDay(now)
+		start = day.AddDate(0, 0, 1-day.Day())
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return time.Time{}, StartOfDay(now).AddDate(0, 0, 1)
+	}
+}
+
+func newBudgetFromModel(model sqlc.ProjectBudget) Budget {
+	return Budget{
+		Unit:       BudgetUnit(model.Unit),
+		Period:     BudgetPeriod(model.Period),
+		Amount:     model.Amount,
+		HourlyRate: model.HourlyRate.Int64,
+	}
+}
+
+// Budget returns the project's budget, or nil when none is set.
+func (p *Project) Budget() (*Budget, error) {
+	if p == nil || p.db == nil {
+		return nil, errors.New("project not initialized")
+	}
+	record, err := p.db.queries.GetProjectBudget(context.Background(), p.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load budget: %w", err)
+	}
+	budget := newBudgetFromModel(record)
+	return &budget, nil
+}
+
+// SetBudget replaces the project's budget.
+func (p *Project) SetBudget(budget Budget) error {
+	if p == nil || p.db == nil {
+		return errors.New("project not initialized")
+	}
+	if err := budget.validate(); err != nil {
+		return err
+	}
+	var rate sql.NullInt64
+	if budget.Unit == BudgetMoney {
+		rate = sql.NullInt64{Int64: budget.HourlyRate, Valid: true}
+	}
+	_, err := p.db.queries.UpsertProjectBudget(context.Background(), sqlc.UpsertProjectBudgetParams{
+		ProjectID:  p.ID,
+		Unit:       string(budget.Unit),
+		Amount:     budget.Amount,
+		Period:     string(budget.Period),
+		HourlyRate: rate,
+	})
+	if err != nil {
+		return fmt.Errorf("save budget: %w", err)
+	}
+	return nil
+}
+
+// ClearBudget removes the project's budget, if any.
+func (p *Project) ClearBudget() error {
+	if p == nil || p.db == nil {
+		return errors.New("project not initialized")
+	}
+	if err := p.db.queries.DeleteProjectBudget(context.Background(), p.ID); err != nil {
+		return fmt.Errorf("clear budget: %w", err)
+	}
+	return nil
+}
+
+// BudgetStatus reports how much of a budget has been used.
+type BudgetStatus struct {
+	Budget   Budget
+	Start    time.Time
+	End      time.Time
+	Tracked  time.Duration
+	Billable time.Duration
+	// Used is in the budget's unit: minutes of tracked time or cents of billable spend.
+	Used int64
+}
+
+// BudgetStatus measures the project's budget at now, counting its sub-projects.
+// It returns nil when the project has no budget.
+func (p *Project) BudgetStatus(now time.Time) (*BudgetStatus, error) {
+	budget, err := p.Budget()
+	if err != nil || budget == nil {
+		return nil, err
+	}
+	start, end := budget.Window(now)
+	totals, err := p.db.queries.ProjectTreeTotalsInRange(context.Background(), sqlc.ProjectTreeTotalsInRangeParams{
+		ProjectID: p.ID,
+		EndedAt:   sql.NullInt64{Int64: start.Unix(), Valid: true},
+		EndedAt_2: sql.NullInt64{Int64: end.Unix(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sum budget time: %w", err)
+	}
+	status := &BudgetStatus{
+		Budget:   *budget,
+		Start:    start,
+		End:      end,
+		Tracked:  time.Duration(totals.TotalDurationMs) * time.Millisecond,
+		Billable: time.Duration(totals.BillableDurationMs) * time.Millisecond,
+	}
+	if budget.Unit == BudgetMoney {
+		status.Used = int64(math.Round(status.Billable.Hours() * float64(budget.HourlyRate)))
+	} else {
+		status.Used = int64(status.Tracked / time.Minute)
+	}
+	return status, nil
+}
+
+// Percent returns the share of the budget used, which exceeds 100 once over budget.
+func (s BudgetStatus) Percent() float64 {
+	if s.Budget.Amount <= 0 {
+		return 0
+	}
+	return float64(s.Used) * 100 / float64(s.Budget.Amount)
+}
+
+// Over reports whether the budget has been used up.
+func (s BudgetStatus) Over() bool {
+	return s.Used >= s.Budget.Amount
+}
+
+// Near reports whether the budget is close to running out but not yet over.
+func (s BudgetStatus) Near() bool {
+	return !s.Over() && s.Percent() >= BudgetNearPercent
+}
+
+// String summarises usage, e.g. "12:30 of 40h this week (31%)".
+func (s BudgetStatus) String() string {
+	used := util.HmFromD(s.Tracked).String()
+	if s.Budget.Unit == BudgetMoney {
+		used = "$" + formatCents(s.Used)
+	}
+	period := "total"
+	switch s.Budget.Period {
+	case BudgetWeek:
+		period = "this week"
+	case BudgetMonth:
+		period = "this month"
+	}
+	return fmt.Sprintf("%s of %s %s (%.0f%%)", used, s.Budget.formatAmount(s.Budget.Amount), period, s.Percent())
+}
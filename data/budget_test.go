@@ -0,0 +1,118 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Budget
+	}{
+		{spec: "40h", want: Budget{Unit: BudgetHours, Period: BudgetTotal, Amount: 40 * 60}},
+		{spec: "7.5h/week", want: Budget{Unit: BudgetHours, Period: BudgetWeek, Amount: 450}},
+		{spec: "7h30m/week", want: Budget{Unit: BudgetHours, Period: BudgetWeek, Amount: 450}},
+		{spec: "12", want: Budget{Unit: BudgetHours, Period: BudgetTotal, Amount: 12 * 60}},
+		{spec: "$5000/month @150", want: Budget{Unit: BudgetMoney, Period: BudgetMonth, Amount: 500000, HourlyRate: 15000}},
+		{spec: "$1,200.50 @$95.25", want: Budget{Unit: BudgetMoney, Period: BudgetTotal, Amount: 120050, HourlyRate: 9525}},
+	}
+	for _, tc := range tests {
+		got, err := ParseBudget(tc.spec)
+		if err != nil {
+			t.Fatalf("ParseBudget(%q): %v", tc.spec, err)
+		}
+		if got != tc.want {
+			t.Fatalf("ParseBudget(%q) = %+v, want %+v", tc.spec, got, tc.want)
+		}
+		again, err := ParseBudget(got.String())
+		if err != nil || again != got {
+			t.Fatalf("ParseBudget(%q) did not round-trip: %+v, %v", got.String(), again, err)
+		}
+	}
+
+	for _, spec := range []string{"", "abc", "0h", "40h/year", "$500", "40h @100", "$500 @0"} {
+		if _, err := ParseBudget(spec); err == nil {
+			t.Fatalf("expected ParseBudget(%q) to fail", spec)
+		}
+	}
+}
+
+func TestBudgetWindow(t *testing.T) {
+	now := time.Date(2024, time.May, 16, 15, 0, 0, 0, time.Local) // a Thursday
+
+	start, end := Budget{Period: BudgetWeek}.Window(now)
+	if want := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.Local); !start.Equal(want) {
+		t.Fatalf("week start = %v, want %v", start, want)
+	}
+	if want := time.Date(2024, time.May, 20, 0, 0, 0, 0, time.Local); !end.Equal(want) {
+		t.Fatalf("week end = %v, want %v", end, want)
+	}
+
+	start, end = Budget{Period: BudgetMonth}.Window(now)
+	if !start.Equal(time.Date(2024, time.May, 1, 0, 0, 0, 0, time.Local)) || !end.Equal(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("unexpected month window %v - %v", start, end)
+	}
+
+	start, _ = Budget{Period: BudgetTotal}.Window(now)
+	if !start.IsZero() {
+		t.Fatalf("expected total budgets to have no start, got %v", start)
+	}
+}
+
+func TestProjectBudgetStatus(t *testing.T) {
+	db := openTempDatabase(t)
+
+	parent, err := db.CreateProject("Client/Website")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	client, err := db.FindProject("Client")
+	if err != nil {
+		t.Fatalf("find parent: %v", err)
+	}
+
+	if status, err := client.BudgetStatus(time.Now()); err != nil || status != nil {
+		t.Fatalf("expected no budget status, got %+v, %v", status, err)
+	}
+
+	if _, err := client.CreateEntryWithDuration("kickoff", 2*time.Hour, true, EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if _, err := parent.CreateEntryWithDuration("design", 6*time.Hour, false, EntryTypeWork); err != nil {
+		t.Fatalf("create child entry: %v", err)
+	}
+
+	if err := client.SetBudget(Budget{Unit: BudgetHours, Period: BudgetTotal, Amount: 10 * 60}); err != nil {
+		t.Fatalf("set budget: %v", err)
+	}
+	status, err := client.BudgetStatus(time.Now())
+	if err != nil {
+		t.Fatalf("budget status: %v", err)
+	}
+	if status.Tracked != 8*time.Hour || status.Used != 8*60 {
+		t.Fatalf("expected sub-project time to count, got %+v", status)
+	}
+	if !status.Near() || status.Over() {
+		t.Fatalf("expected 80%% to be near but not over: %s", status)
+	}
+
+	// Money budgets only spend billable time.
+	if err := client.SetBudget(Budget{Unit: BudgetMoney, Period: BudgetWeek, Amount: 20000, HourlyRate: 10000}); err != nil {
+		t.Fatalf("replace budget: %v", err)
+	}
+	status, err = client.BudgetStatus(time.Now())
+	if err != nil {
+		t.Fatalf("money budget status: %v", err)
+	}
+	if status.Used != 20000 || !status.Over() {
+		t.Fatalf("expected $200 spent and over budget, got %s", status)
+	}
+
+	if err := client.ClearBudget(); err != nil {
+		t.Fatalf("clear budget: %v", err)
+	}
+	if budget, err := client.Budget(); err != nil || budget != nil {
+		t.Fatalf("expected budget to be cleared, got %+v, %v", budget, err)
+	}
+}
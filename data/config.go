@@ -9,15 +9,113 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 const (
 	configFileName = "config.json"
 	defaultDBName  = "Samay.db"
+
+	defaultTrashRetentionDays = 30
 )
 
-type config struct {
+// Config mirrors the JSON settings file stored in the Samay config directory.
+type Config struct {
 	DatabasePath string `json:"database_path"`
+	// TrashRetentionDays controls how long deleted items stay restorable.
+	// Unset uses the default of 30 days; zero disables automatic purging.
+	TrashRetentionDays *int `json:"trash_retention_days,omitempty"`
+	// OverlapPolicy is "allow", "warn" (the default) or "reject" and decides
+	// what saving an entry that overlaps another one does.
+	OverlapPolicy string `json:"overlap_policy,omitempty"`
+	// Name and Email identify who is using this database. When Email is set,
+	// the person is recorded in the people table and new entries credit them.
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// APIToken must be presented as a bearer token to the HTTP API served by
+	// `samay serve`; the server refuses to start without one.
+	APIToken string `json:"api_token,omitempty"`
+	// Webhooks are posted an event whenever projects, entries or timers change.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// GitRepositories offer their commit subjects when a timer stops and map
+	// checked-out branches to projects for `samay git-hook`.
+	GitRepositories []GitRepository `json:"git_repositories,omitempty"`
+	// Reminders, when set, nudge you to start a timer after a stretch of
+	// working hours without one.
+	Reminders *ReminderSettings `json:"reminders,omitempty"`
+	// Pomodoro sets the lengths of Pomodoro mode's work and breaks.
+	Pomodoro *PomodoroSettings `json:"pomodoro,omitempty"`
+	// Keys rebinds the keys of the interactive UI.
+	Keys *KeySettings `json:"keys,omitempty"`
+	// Theme names the colours of the interactive UI: "dark" (the default),
+	// "light", "high-contrast", "no-color" or one of Themes.
+	Theme string `json:"theme,omitempty"`
+	// Themes are user-defined themes, by name.
+	Themes map[string]ThemeSettings `json:"themes,omitempty"`
+}
+
+// ThemeSettings defines a theme as a built-in one with some colours changed.
+type ThemeSettings struct {
+	// Base is the built-in theme to start from; unset starts from dark.
+	Base string `json:"base,omitempty"`
+	// Colors maps roles, such as "accent" or "error", to an ANSI colour
+	// number ("170") or a hex colour ("#ff8700").
+	Colors map[string]string `json:"colors,omitempty"`
+	// Heatmap holds the five heatmap shades, from an empty day to the busiest.
+	Heatmap []string `json:"heatmap,omitempty"`
+	// Palette colours the timeline's projects in turn.
+	Palette []string `json:"palette,omitempty"`
+}
+
+// KeySettings picks the keys of the interactive UI.
+type KeySettings struct {
+	// Preset is "default", "vim" or "emacs".
+	Preset string `json:"preset,omitempty"`
+	// Bindings maps actions, such as "project.start", to the keys that
+	// trigger them, in place of the preset's keys. No keys turn an action off.
+	Bindings map[string][]string `json:"bindings,omitempty"`
+}
+
+// TrashRetention returns how long deleted items are kept, or zero when auto-purge is off.
+func (c Config) TrashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if c.TrashRetentionDays != nil {
+		days = *c.TrashRetentionDays
+	}
+	if days <= 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// EntryOverlapPolicy parses OverlapPolicy, falling back to warn when it is not recognised.
+func (c Config) EntryOverlapPolicy() (OverlapPolicy, error) {
+	return ParseOverlapPolicy(c.OverlapPolicy)
+}
+
+// LoadConfig reads the settings file, returning defaults when it does not exist yet.
+func LoadConfig() (Config, error) {
+	configDir, err := configDirectory()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, err := readConfig(filepath.Join(configDir, configFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	return cfg, err
+}
+
+// SaveConfig writes the settings file, creating the config directory if needed.
+func SaveConfig(cfg Config) error {
+	configDir, err := configDirectory()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configDir, 0o775); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	return writeConfig(filepath.Join(configDir, configFileName), cfg)
 }
 
 // ResolveDatabasePathWithOverride returns the provided override path when set,
@@ -36,6 +134,19 @@ func ResolveDatabasePathWithOverride(override string) (string, error) {
 	return ResolveDatabasePath()
 }
 
+// ConfiguredDatabasePath returns the database location samay would use
+// without asking: override when set, else the configured path, else the
+// default one. Nothing is created.
+func ConfiguredDatabasePath(override string) (string, error) {
+	if strings.TrimSpace(override) != "" {
+		return expandPath(override)
+	}
+	if cfg, err := LoadConfig(); err == nil && cfg.DatabasePath != "" {
+		return expandPath(cfg.DatabasePath)
+	}
+	return defaultDatabasePath()
+}
+
 // ResolveDatabasePath returns the persisted database location or prompts the user
 // to choose one when Samay runs for the first time.
 func ResolveDatabasePath() (string, error) {
@@ -71,7 +182,7 @@ func ResolveDatabasePath() (string, error) {
 		return "", err
 	}
 
-	if err := writeConfig(cfgPath, config{DatabasePath: chosen}); err != nil {
+	if err := writeConfig(cfgPath, Config{DatabasePath: chosen}); err != nil {
 		return "", err
 	}
 
@@ -98,10 +209,10 @@ func defaultDatabasePath() (string, error) {
 	return filepath.Join(documents, defaultDBName), nil
 }
 
-func readConfig(path string) (cfg config, err error) {
+func readConfig(path string) (cfg Config, err error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return config{}, err
+		return Config{}, err
 	}
 	defer func() {
 		if closeErr := f.Close(); closeErr != nil {
@@ -110,27 +221,40 @@ func readConfig(path string) (cfg config, err error) {
 	}()
 
 	if err := json.NewDecoder(f).Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
-		return config{}, fmt.Errorf("decode config: %w", err)
+		return Config{}, fmt.Errorf("decode config: %w", err)
 	}
 	return cfg, nil
 }
 
-func writeConfig(path string, cfg config) (err error) {
-	f, err := os.Create(path)
+// writeConfig replaces the settings file at path. The file holds the API
+// token and webhook secrets, so only its owner may read it, and it is written
+// to a temporary file first so a failed write never leaves it half done.
+func writeConfig(path string, cfg Config) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+configFileName+"-*")
 	if err != nil {
 		return fmt.Errorf("create config: %w", err)
 	}
 	defer func() {
-		if closeErr := f.Close(); closeErr != nil {
-			err = errors.Join(err, fmt.Errorf("close config file: %w", closeErr))
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
 		}
 	}()
 
+	if err := f.Chmod(0o600); err != nil {
+		return fmt.Errorf("restrict config permissions: %w", err)
+	}
 	encoder := json.NewEncoder(f)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(cfg); err != nil {
 		return fmt.Errorf("write config: %w", err)
 	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close config file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("replace config: %w", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,33 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteConfigIsPrivate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, configFileName)
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("write old config: %v", err)
+	}
+
+	if err := writeConfig(path, Config{DatabasePath: "/tmp/samay.db", APIToken: "secret"}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat config: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected the config to be readable by its owner only, got %v", perm)
+	}
+	cfg, err := readConfig(path)
+	if err != nil || cfg.APIToken != "secret" {
+		t.Fatalf("expected the config to be written, got %+v, %v", cfg, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("expected no temporary files left behind, got %v", entries)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,6 +27,20 @@ type Database struct {
 	path    string
 	sqlite  *sql.DB
 	queries *sqlc.Queries
+	// overlapPolicy decides how saving overlapping entries is handled; empty means warn.
+	overlapPolicy OverlapPolicy
+	// person is stamped onto new entries as their creator; nil leaves them unattributed.
+	person *Person
+	// webhooks are sent an event for every change; see enqueueWebhooks. They
+	// are guarded by webhooksMu because a sender reads them in the background.
+	webhooks   []Webhook
+	webhooksMu sync.RWMutex
+	// gitRepositories are read for commit subjects and map checkouts to projects.
+	gitRepositories []GitRepository
+	// reminders is the schedule of reminders to start a timer; nil turns them off.
+	reminders *ReminderSchedule
+	// pomodoro is the rhythm of Pomodoro mode; nil means DefaultPomodoro.
+	pomodoro *Pomodoro
 }
 
 // OpenDatabase initializes the global DB handle using the provided SQLite path.
@@ -49,7 +64,13 @@ func open(path string) (*Database, error) {
 		return nil, fmt.Errorf("prepare database directory: %w", err)
 	}
 
-	sqlite, err := sql.Open("sqlite", path)
+	// Write transactions take the write lock when they begin. Deferred ones
+	// read first and then fail at once with SQLITE_BUSY when another process
+	// (serve, web, the daemon or the interactive UI) wrote in between, which
+	// busy_timeout does not retry.
+	query := url.Values{}
+	query.Set("_txlock", "immediate")
+	sqlite, err := sql.Open("sqlite", sqliteDSN(path, query))
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite database: %w", err)
 	}
@@ -77,6 +98,21 @@ func open(path string) (*Database, error) {
 	return db, nil
 }
 
+// sqliteDSN returns the file: URI of the database at path with query, its
+// path escaped so that '?', '#' and '%' in a file name are not read as part of
+// the URI.
+func sqliteDSN(path string, query url.Values) string {
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	dsn := "file:" + strings.Join(segments, "/")
+	if len(query) > 0 {
+		dsn += "?" + query.Encode()
+	}
+	return dsn
+}
+
 // Close shuts down the underlying SQLite connection.
 func (d *Database) Close() error {
 	if d == nil || d.sqlite == nil {
@@ -101,6 +137,40 @@ func (d *Database) Queries() *sqlc.Queries {
 	return d.queries
 }
 
+// withTx runs fn inside a transaction, committing on success and rolling back on error.
+// The connection pool holds a single connection, so fn must only use the queries it is given.
+// Changes fn logs are queued for the configured webhooks in the same transaction.
+func (d *Database) withTx(ctx context.Context, fn func(q *sqlc.Queries) error) error {
+	tx, err := d.sqlite.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	q := d.queries.WithTx(tx)
+	err = func() error {
+		if len(d.Webhooks()) == 0 {
+			return fn(q)
+		}
+		since, err := q.LatestChangeID(ctx)
+		if err != nil {
+			return fmt.Errorf("read change log: %w", err)
+		}
+		if err := fn(q); err != nil {
+			return err
+		}
+		return d.enqueueWebhooks(ctx, q, since)
+	}()
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			err = errors.Join(err, fmt.Errorf("rollback: %w", rollbackErr))
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
 func configureSQLite(db *sql.DB) error {
 	db.SetMaxOpenConns(1)
 	db.SetMaxIdleConns(1)
@@ -120,7 +190,13 @@ func configureSQLite(db *sql.DB) error {
 }
 
 func (d *Database) ensureSchema(ctx context.Context) error {
-	if err := d.migrateProjectsTable(ctx); err != nil {
+	if err := d.migrateColumns(ctx); err != nil {
+		return err
+	}
+	if err := d.migrateProjectNameScope(ctx); err != nil {
+		return err
+	}
+	if err := d.migrateProjectNameIndex(ctx); err != nil {
 		return err
 	}
 
@@ -147,33 +223,140 @@ func splitStatements(sql string) []string {
 	return statements
 }
 
-func (d *Database) migrateProjectsTable(ctx context.Context) error {
+// columnMigration adds a column to a table created by an older schema version.
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// columnMigrations lists columns added after their table was first released.
+// Fresh databases get them from schema.sql; existing ones are altered in place.
+var columnMigrations = []columnMigration{
+	{table: "projects", column: "position", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "projects", column: "deleted_at", definition: "INTEGER"},
+	{table: "entries", column: "deleted_at", definition: "INTEGER"},
+	{table: "projects", column: "parent_id", definition: "INTEGER REFERENCES projects(id) ON DELETE CASCADE"},
+}
+
+func (d *Database) migrateColumns(ctx context.Context) error {
 	if d == nil || d.sqlite == nil {
 		return nil
 	}
+	for _, migration := range columnMigrations {
+		if err := d.addColumnIfMissing(ctx, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	const tableExistsSQL = `SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'projects'`
-	if err := d.sqlite.QueryRowContext(ctx, tableExistsSQL).Scan(new(string)); errors.Is(err, sql.ErrNoRows) {
+func (d *Database) addColumnIfMissing(ctx context.Context, m columnMigration) error {
+	const tableExistsSQL = `SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if err := d.sqlite.QueryRowContext(ctx, tableExistsSQL, m.table).Scan(new(string)); errors.Is(err, sql.ErrNoRows) {
 		return nil
-	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("check projects table existence: %w", err)
+	} else if err != nil {
+		return fmt.Errorf("check %s table existence: %w", m.table, err)
 	}
 
-	const checkColumnSQL = `SELECT 1 FROM pragma_table_info('projects') WHERE name = 'position'`
-	row := d.sqlite.QueryRowContext(ctx, checkColumnSQL)
+	const checkColumnSQL = `SELECT 1 FROM pragma_table_info(?) WHERE name = ?`
 	var exists int
-	err := row.Scan(&exists)
+	err := d.sqlite.QueryRowContext(ctx, checkColumnSQL, m.table, m.column).Scan(&exists)
 	if errors.Is(err, sql.ErrNoRows) {
-		if _, err := d.sqlite.ExecContext(ctx, `ALTER TABLE projects ADD COLUMN position INTEGER NOT NULL DEFAULT 0`); err != nil {
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)
+		if _, err := d.sqlite.ExecContext(ctx, stmt); err != nil {
 			if strings.Contains(err.Error(), "duplicate column name") {
 				return nil
 			}
-			return fmt.Errorf("add projects.position column: %w", err)
+			return fmt.Errorf("add %s.%s column: %w", m.table, m.column, err)
 		}
 		return nil
 	}
 	if err != nil {
-		return fmt.Errorf("check projects.position column: %w", err)
+		return fmt.Errorf("check %s.%s column: %w", m.table, m.column, err)
+	}
+	return nil
+}
+
+// migrateProjectNameIndex drops a project name index that also counts projects
+// in the trash, so that schema.sql recreates it for active projects only.
+func (d *Database) migrateProjectNameIndex(ctx context.Context) error {
+	if d == nil || d.sqlite == nil {
+		return nil
+	}
+	const indexSQL = `SELECT sql FROM sqlite_master WHERE type = 'index' AND name = 'idx_projects_parent_name'`
+	var definition string
+	if err := d.sqlite.QueryRowContext(ctx, indexSQL).Scan(&definition); errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("check project name index: %w", err)
+	}
+	if strings.Contains(definition, "deleted_at IS NULL") {
+		return nil
+	}
+	if _, err := d.sqlite.ExecContext(ctx, "DROP INDEX idx_projects_parent_name"); err != nil {
+		return fmt.Errorf("drop project name index: %w", err)
+	}
+	return nil
+}
+
+// migrateProjectNameScope rebuilds a projects table whose names are unique
+// globally so that uniqueness is scoped to the parent project instead.
+// SQLite cannot drop a column constraint, so the table is copied into the
+// current definition from schema.sql and swapped in place.
+func (d *Database) migrateProjectNameScope(ctx context.Context) error {
+	if d == nil || d.sqlite == nil {
+		return nil
+	}
+	const uniqueConstraintSQL = `SELECT 1 FROM pragma_index_list('projects') WHERE origin = 'u'`
+	if err := d.sqlite.QueryRowContext(ctx, uniqueConstraintSQL).Scan(new(int)); errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("check projects name constraint: %w", err)
+	}
+
+	var createSQL string
+	for _, stmt := range splitStatements(schemaSQL) {
+		if strings.HasPrefix(stmt, "CREATE TABLE IF NOT EXISTS projects ") {
+			createSQL = strings.Replace(stmt, "CREATE TABLE IF NOT EXISTS projects ", "CREATE TABLE projects_rebuild ", 1)
+			break
+		}
+	}
+	if createSQL == "" {
+		return errors.New("projects table definition missing from schema")
+	}
+
+	const columns = "id, name, company, is_hidden, position, created_at, updated_at, deleted_at, parent_id"
+	steps := []string{
+		createSQL,
+		"INSERT INTO projects_rebuild (" + columns + ") SELECT " + columns + " FROM projects",
+		"DROP TABLE projects",
+		"ALTER TABLE projects_rebuild RENAME TO projects",
+	}
+
+	// Foreign keys must be off while the referenced table is swapped out,
+	// and the pragma has no effect inside a transaction.
+	if _, err := d.sqlite.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("disable foreign keys: %w", err)
+	}
+	defer func() {
+		_, _ = d.sqlite.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}()
+
+	tx, err := d.sqlite.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin projects rebuild: %w", err)
+	}
+	for _, stmt := range steps {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				err = errors.Join(err, fmt.Errorf("rollback: %w", rollbackErr))
+			}
+			return fmt.Errorf("rebuild projects table: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit projects rebuild: %w", err)
 	}
 	return nil
 }
@@ -1,10 +1,14 @@
 package data
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func setupTestDatabase(t *testing.T) *Database {
@@ -91,3 +95,150 @@ func TestDatabaseProjectLifecycle(t *testing.T) {
 		t.Fatalf("expected projects to be empty after delete, got %d", len(remaining))
 	}
 }
+
+func TestOpenMigratesLegacyColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open legacy database: %v", err)
+	}
+	stmts := []string{
+		`CREATE TABLE projects (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL COLLATE NOCASE UNIQUE, company TEXT, is_hidden INTEGER NOT NULL DEFAULT 0, created_at INTEGER NOT NULL DEFAULT (unixepoch()), updated_at INTEGER NOT NULL DEFAULT (unixepoch())) STRICT`,
+		`CREATE TABLE entries (id TEXT PRIMARY KEY, project_id INTEGER NOT NULL, creator_id INTEGER, content TEXT NOT NULL DEFAULT '', duration_ms INTEGER NOT NULL, started_at INTEGER, ended_at INTEGER, entry_type TEXT NOT NULL DEFAULT 'WORK', is_billable INTEGER NOT NULL DEFAULT 1, created_at INTEGER NOT NULL DEFAULT (unixepoch()), updated_at INTEGER NOT NULL DEFAULT (unixepoch())) STRICT`,
+		`INSERT INTO projects (name) VALUES ('Legacy')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := legacy.Exec(stmt); err != nil {
+			t.Fatalf("prepare legacy schema: %v", err)
+		}
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatalf("close legacy database: %v", err)
+	}
+
+	db, err := open(path)
+	if err != nil {
+		t.Fatalf("open migrated database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("close migrated database: %v", err)
+		}
+	}()
+
+	projects := db.Projects()
+	if len(projects) != 1 || projects[0].Name != "Legacy" {
+		t.Fatalf("expected legacy project after migration, got %+v", projects)
+	}
+	if _, err := db.CreateProject("Legacy/Legacy"); err != nil {
+		t.Fatalf("expected names to be unique per parent after migration: %v", err)
+	}
+	if _, err := db.CreateProject("legacy"); err == nil {
+		t.Fatalf("expected top-level names to stay unique after migration")
+	}
+	parent, err := db.FindProject("/Legacy")
+	if err != nil {
+		t.Fatalf("find parent: %v", err)
+	}
+	if err := parent.Delete(); err == nil {
+		t.Fatalf("expected delete of a parent with sub-projects to fail")
+	}
+	child, err := db.FindProject("Legacy/Legacy")
+	if err != nil {
+		t.Fatalf("find migrated child: %v", err)
+	}
+	if err := child.Delete(); err != nil {
+		t.Fatalf("delete migrated child: %v", err)
+	}
+	if err := projects[0].Delete(); err != nil {
+		t.Fatalf("soft delete on migrated schema: %v", err)
+	}
+}
+
+func TestOpenFreesNamesOfTrashedProjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trash-names.db")
+	db, err := open(path)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// Databases from before the trash gave up names index every project.
+	for _, stmt := range []string{
+		"DROP INDEX idx_projects_parent_name",
+		"CREATE UNIQUE INDEX idx_projects_parent_name ON projects(ifnull(parent_id, 0), name)",
+	} {
+		if _, err := db.sqlite.Exec(stmt); err != nil {
+			t.Fatalf("prepare old index: %v", err)
+		}
+	}
+	project, err := db.CreateProject("Reused")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.Delete(); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("close database: %v", err)
+	}
+
+	db, err = open(path)
+	if err != nil {
+		t.Fatalf("reopen database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("close reopened database: %v", err)
+		}
+	}()
+	if _, err := db.CreateProject("Reused"); err != nil {
+		t.Fatalf("expected the trashed project's name to be free after migration: %v", err)
+	}
+	if _, err := db.CreateProject("reused"); err == nil {
+		t.Fatal("expected active names to stay unique")
+	}
+}
+
+func TestConcurrentWritersOnOneFile(t *testing.T) {
+	first := openTempDatabase(t)
+	second, err := open(first.Path())
+	if err != nil {
+		t.Fatalf("open second handle: %v", err)
+	}
+	t.Cleanup(func() { _ = second.Close() })
+
+	const writes = 20
+	errs := make(chan error, 2*writes)
+	var wg sync.WaitGroup
+	for i, db := range []*Database{first, second} {
+		project, err := db.CreateProject(fmt.Sprintf("Writer %d", i))
+		if err != nil {
+			t.Fatalf("create project: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now().Add(-48 * time.Hour)
+			for n := range writes {
+				_, err := project.CreateEntryAt("work", start.Add(time.Duration(n)*time.Hour), time.Minute, true, EntryTypeWork)
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected both handles to write, got %v", err)
+		}
+	}
+	for _, db := range []*Database{first, second} {
+		total := 0
+		for _, project := range db.Projects() {
+			total += len(project.Entries())
+		}
+		if total != 2*writes {
+			t.Fatalf("expected %d entries, got %d", 2*writes, total)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -22,6 +23,40 @@ const (
 	EntryTypeWork  EntryType = "WORK"
 )
 
+// ParseEntryType reads an entry type such as "work", "chore" or "fun"; empty means work.
+func ParseEntryType(s string) (EntryType, error) {
+	switch t := EntryType(strings.ToUpper(strings.TrimSpace(s))); t {
+	case "":
+		return EntryTypeWork, nil
+	case EntryTypeChore, EntryTypeFun, EntryTypeWork:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unknown entry type %q: use work, chore or fun", s)
+	}
+}
+
+// EntryTypes lists every entry type in the order forms cycle through them.
+var EntryTypes = []EntryType{EntryTypeWork, EntryTypeChore, EntryTypeFun}
+
+// Label returns the type as it is shown to people, such as "Work".
+func (t EntryType) Label() string {
+	switch t {
+	case EntryTypeChore:
+		return "Chore"
+	case EntryTypeFun:
+		return "Fun"
+	default:
+		return "Work"
+	}
+}
+
+// Next returns the type after t in EntryTypes, wrapping around to work. An
+// unknown or empty type counts as work.
+func (t EntryType) Next() EntryType {
+	i := max(slices.Index(EntryTypes, t), 0)
+	return EntryTypes[(i+1)%len(EntryTypes)]
+}
+
 var tagFinder = regexp.MustCompile(`\B#(\w\w+)`)
 
 type Entry struct {
@@ -39,6 +74,10 @@ type Entry struct {
 	Tags       []string
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+	// Conflicts lists the entries this one overlapped when it was last saved
+	// under the warn overlap policy. It is not persisted.
+	Conflicts []*Entry
 }
 
 func newEntryFromModel(db *Database, project *Project, model sqlc.Entry) *Entry {
@@ -71,6 +110,7 @@ func newEntryFromModel(db *Database, project *Project, model sqlc.Entry) *Entry
 		Billable:   model.IsBillable == 1,
 		CreatedAt:  time.Unix(model.CreatedAt, 0).UTC(),
 		UpdatedAt:  time.Unix(model.UpdatedAt, 0).UTC(),
+		DeletedAt:  unixTimePtr(model.DeletedAt),
 	}
 }
 
@@ -131,6 +171,13 @@ func (e *Entry) Save(ctx context.Context) error {
 	if e.db == nil {
 		return errors.New("entry has no database reference")
 	}
+	return e.db.withTx(ctx, func(q *sqlc.Queries) error {
+		return e.insert(ctx, q)
+	})
+}
+
+// insert writes a new entry row with its tags and records the creation in the change log.
+func (e *Entry) insert(ctx context.Context, q *sqlc.Queries) error {
 	if e.Project == nil {
 		return errors.New("entry has no project reference")
 	}
@@ -140,6 +187,13 @@ func (e *Entry) Save(ctx context.Context) error {
 	if e.ProjectID == 0 {
 		return errors.New("entry missing project id")
 	}
+	if e.Type == "" {
+		e.Type = EntryTypeWork
+	}
+	if e.CreatorID == nil && e.db != nil && e.db.person != nil {
+		id := e.db.person.ID
+		e.CreatorID = &id
+	}
 
 	if e.ID == "" {
 		if id, err := util.UUID(); err == nil {
@@ -148,6 +202,9 @@ func (e *Entry) Save(ctx context.Context) error {
 			return fmt.Errorf("generate entry id: %w", err)
 		}
 	}
+	if err := e.checkOverlaps(ctx, q); err != nil {
+		return err
+	}
 
 	var creator sql.NullInt64
 	if e.CreatorID != nil {
@@ -162,7 +219,7 @@ func (e *Entry) Save(ctx context.Context) error {
 		ended = sql.NullInt64{Int64: e.EndedAt.Unix(), Valid: true}
 	}
 
-	params := sqlc.CreateEntryParams{
+	record, err := q.CreateEntry(ctx, sqlc.CreateEntryParams{
 		ID:         e.ID,
 		ProjectID:  e.ProjectID,
 		CreatorID:  creator,
@@ -172,25 +229,35 @@ func (e *Entry) Save(ctx context.Context) error {
 		EndedAt:    ended,
 		EntryType:  string(e.Type),
 		IsBillable: boolToInt(e.Billable),
-	}
-
-	record, err := e.db.queries.CreateEntry(ctx, params)
+	})
 	if err != nil {
 		return fmt.Errorf("insert entry: %w", err)
 	}
 	e.CreatedAt = time.Unix(record.CreatedAt, 0).UTC()
 	e.UpdatedAt = time.Unix(record.UpdatedAt, 0).UTC()
 
-	if err := e.replaceTags(ctx); err != nil {
+	if err := e.replaceTags(ctx, q); err != nil {
 		return err
 	}
-	if err := e.db.queries.TouchProject(ctx, e.ProjectID); err != nil {
+	if err := q.TouchProject(ctx, e.ProjectID); err != nil {
 		return fmt.Errorf("touch project: %w", err)
 	}
-	return nil
+	return recordEntryChange(ctx, q, e.ID, ChangeCreate, nil)
+}
+
+// SetContent replaces the description and the tags taken from its hashtags.
+// Call Update to save the change.
+func (e *Entry) SetContent(content string) {
+	e.Content = strings.TrimSpace(content)
+	e.Tags = extractTags(content)
 }
 
 func (e *Entry) Update(ctx context.Context) error {
+	return e.update(ctx, UndoEntryEdit)
+}
+
+// update persists the entry and records the previous row so the change can be undone.
+func (e *Entry) update(ctx context.Context, kind UndoKind) error {
 	if e == nil {
 		return errors.New("entry is nil")
 	}
@@ -207,6 +274,35 @@ func (e *Entry) Update(ctx context.Context) error {
 		return errors.New("entry missing identifier")
 	}
 
+	return e.db.withTx(ctx, func(q *sqlc.Queries) error {
+		return e.write(ctx, q, kind)
+	})
+}
+
+// write updates the entry row inside a transaction and pushes an undo action of the given kind.
+func (e *Entry) write(ctx context.Context, q *sqlc.Queries, kind UndoKind) error {
+	action := ChangeUpdate
+	if kind == UndoEntryMove {
+		action = ChangeMove
+	}
+	previous, err := e.rewrite(ctx, q, action)
+	if err != nil {
+		return err
+	}
+
+	description := "edit " + describeEntry(previous.Content)
+	if kind == UndoEntryMove {
+		description = "move " + describeEntry(previous.Content)
+		if e.Project != nil {
+			description += fmt.Sprintf(" to '%s'", e.Project.Name)
+		}
+	}
+	return pushUndo(ctx, q, kind, description, previous)
+}
+
+// rewrite updates the entry row and its tags and records the change, returning
+// the row as it was before.
+func (e *Entry) rewrite(ctx context.Context, q *sqlc.Queries, action ChangeAction) (entrySnapshot, error) {
 	var creator sql.NullInt64
 	if e.CreatorID != nil {
 		creator = sql.NullInt64{Int64: *e.CreatorID, Valid: true}
@@ -220,7 +316,20 @@ func (e *Entry) Update(ctx context.Context) error {
 		ended = sql.NullInt64{Int64: e.EndedAt.Unix(), Valid: true}
 	}
 
-	record, err := e.db.queries.UpdateEntry(ctx, sqlc.UpdateEntryParams{
+	previous, err := snapshotEntry(ctx, q, e.ID)
+	if err != nil {
+		return previous, err
+	}
+	// Only a change of time can make an entry overlap another; moving it to
+	// another project or editing its description leaves the time alone.
+	e.Conflicts = nil
+	if action != ChangeMove && !previous.sameTime(started, ended, e.DurationMs) {
+		if err := e.checkOverlaps(ctx, q); err != nil {
+			return previous, err
+		}
+	}
+
+	record, err := q.UpdateEntry(ctx, sqlc.UpdateEntryParams{
 		ID:         e.ID,
 		ProjectID:  e.ProjectID,
 		CreatorID:  creator,
@@ -232,17 +341,17 @@ func (e *Entry) Update(ctx context.Context) error {
 		IsBillable: boolToInt(e.Billable),
 	})
 	if err != nil {
-		return fmt.Errorf("update entry: %w", err)
+		return previous, fmt.Errorf("update entry: %w", err)
 	}
 	e.CreatedAt = time.Unix(record.CreatedAt, 0).UTC()
 	e.UpdatedAt = time.Unix(record.UpdatedAt, 0).UTC()
-	if err := e.replaceTags(ctx); err != nil {
-		return err
+	if err := e.replaceTags(ctx, q); err != nil {
+		return previous, err
 	}
-	if err := e.db.queries.TouchProject(ctx, e.ProjectID); err != nil {
-		return fmt.Errorf("touch project: %w", err)
+	if err := q.TouchProject(ctx, e.ProjectID); err != nil {
+		return previous, fmt.Errorf("touch project: %w", err)
 	}
-	return nil
+	return previous, recordEntryChange(ctx, q, e.ID, action, &previous)
 }
 
 func (e *Entry) Delete(ctx context.Context) error {
@@ -252,19 +361,41 @@ func (e *Entry) Delete(ctx context.Context) error {
 	if e.ID == "" {
 		return errors.New("entry missing identifier")
 	}
-	if err := e.db.queries.DeleteEntry(ctx, e.ID); err != nil {
-		return fmt.Errorf("delete entry: %w", err)
+	return e.db.withTx(ctx, func(q *sqlc.Queries) error {
+		return e.remove(ctx, q)
+	})
+}
+
+// remove soft-deletes the entry inside a transaction and pushes an undo action.
+func (e *Entry) remove(ctx context.Context, q *sqlc.Queries) error {
+	snapshot, err := e.softDelete(ctx, q)
+	if err != nil {
+		return err
 	}
-	return nil
+	return pushUndo(ctx, q, UndoEntryDelete, "delete "+describeEntry(snapshot.Content), snapshot)
+}
+
+// softDelete moves the entry to the trash and records the change, returning the deleted row.
+func (e *Entry) softDelete(ctx context.Context, q *sqlc.Queries) (entrySnapshot, error) {
+	snapshot, err := snapshotEntry(ctx, q, e.ID)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := q.SoftDeleteEntry(ctx, e.ID); err != nil {
+		return snapshot, fmt.Errorf("delete entry: %w", err)
+	}
+	return snapshot, recordEntryChange(ctx, q, e.ID, ChangeDelete, &snapshot)
 }
 
+// MoveTo moves the entry to project, saving any other changes made to it in
+// the same transaction so a single undo reverts them all.
 func (e *Entry) MoveTo(ctx context.Context, project *Project) error {
 	if project == nil {
 		return errors.New("target project is nil")
 	}
 	e.Project = project
 	e.ProjectID = project.ID
-	return e.Update(ctx)
+	return e.update(ctx, UndoEntryMove)
 }
 
 func (e *Entry) SaveNow() error {
@@ -283,14 +414,14 @@ func (e *Entry) MoveToProject(project *Project) error {
 	return e.MoveTo(context.Background(), project)
 }
 
-func (e *Entry) replaceTags(ctx context.Context) error {
-	if err := e.db.queries.DeleteEntryTags(ctx, e.ID); err != nil {
+func (e *Entry) replaceTags(ctx context.Context, q *sqlc.Queries) error {
+	if err := q.DeleteEntryTags(ctx, e.ID); err != nil {
 		return fmt.Errorf("clear entry tags: %w", err)
 	}
 	normalized := uniqueSortedTags(e.Tags)
 	e.Tags = normalized
 	for _, tag := range normalized {
-		if err := e.db.queries.InsertEntryTag(ctx, sqlc.InsertEntryTagParams{EntryID: e.ID, Tag: tag}); err != nil {
+		if err := q.InsertEntryTag(ctx, sqlc.InsertEntryTagParams{EntryID: e.ID, Tag: tag}); err != nil {
 			return fmt.Errorf("insert tag %q: %w", tag, err)
 		}
 	}
@@ -0,0 +1,116 @@
+package data
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GitRepository maps a git checkout to the project its work is tracked on.
+type GitRepository struct {
+	Path string `json:"path"`
+	// Project is tracked while any branch without a more specific mapping
+	// is checked out. Empty leaves those branches unmapped.
+	Project string `json:"project,omitempty"`
+	// Branches maps branch names, or patterns such as "feature/*", to projects.
+	Branches map[string]string `json:"branches,omitempty"`
+}
+
+// ProjectFor returns the project path tracked while branch is checked out,
+// or "" when the branch is unmapped. An exact branch name wins over a
+// pattern, and a longer pattern over a shorter one.
+func (r GitRepository) ProjectFor(branch string) string {
+	if project, ok := r.Branches[branch]; ok {
+		return project
+	}
+	patterns := make([]string, 0, len(r.Branches))
+	for pattern := range r.Branches {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return r.Branches[pattern]
+		}
+	}
+	return r.Project
+}
+
+// Tracks reports whether work in the repository can land on the project at
+// path: the repository is mapped to it, or to nothing in particular.
+func (r GitRepository) Tracks(path string) bool {
+	if r.Project == "" && len(r.Branches) == 0 {
+		return true
+	}
+	if strings.EqualFold(r.Project, path) {
+		return true
+	}
+	for _, project := range r.Branches {
+		if strings.EqualFold(project, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGitRepositories sets the repositories commits are read from and the
+// projects they map to. Their paths may start with ~.
+func (d *Database) SetGitRepositories(repos []GitRepository) error {
+	resolved := make([]GitRepository, 0, len(repos))
+	for _, repo := range repos {
+		dir, err := expandPath(repo.Path)
+		if err != nil {
+			return err
+		}
+		repo.Path = canonicalDir(dir)
+		resolved = append(resolved, repo)
+	}
+	d.gitRepositories = resolved
+	return nil
+}
+
+// GitRepositories returns the configured repositories.
+func (d *Database) GitRepositories() []GitRepository {
+	if d == nil {
+		return nil
+	}
+	return d.gitRepositories
+}
+
+// GitRepository returns the configured repository checked out at dir.
+func (d *Database) GitRepository(dir string) (GitRepository, bool) {
+	dir = canonicalDir(dir)
+	for _, repo := range d.GitRepositories() {
+		if repo.Path == dir {
+			return repo, true
+		}
+	}
+	return GitRepository{}, false
+}
+
+// GitRepositoriesFor lists the repositories whose commits can describe work
+// on project.
+func (d *Database) GitRepositoriesFor(project *Project) []string {
+	var dirs []string
+	for _, repo := range d.GitRepositories() {
+		if repo.Tracks(project.Path()) {
+			dirs = append(dirs, repo.Path)
+		}
+	}
+	return dirs
+}
+
+// canonicalDir cleans dir and resolves symlinks when it exists, so a path
+// from the config matches the one git reports.
+func canonicalDir(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return resolved
+	}
+	return filepath.Clean(dir)
+}
@@ -0,0 +1,63 @@
+package data
+
+import "testing"
+
+func TestGitRepositoryProjectFor(t *testing.T) {
+	repo := GitRepository{
+		Path:    "/src/site",
+		Project: "Acme/Website",
+		Branches: map[string]string{
+			"release":        "Acme/Ops",
+			"feature/*":      "Acme/Features",
+			"feature/blog-*": "Acme/Blog",
+		},
+	}
+	for branch, want := range map[string]string{
+		"main":               "Acme/Website",
+		"release":            "Acme/Ops",
+		"feature/search":     "Acme/Features",
+		"feature/blog-posts": "Acme/Blog",
+		"feature/a/b":        "Acme/Website",
+	} {
+		if got := repo.ProjectFor(branch); got != want {
+			t.Errorf("ProjectFor(%q) = %q, want %q", branch, got, want)
+		}
+	}
+
+	if !repo.Tracks("acme/blog") || repo.Tracks("Acme") {
+		t.Error("expected the repository to track only the projects it maps to")
+	}
+	if !(GitRepository{Path: "/src/notes"}).Tracks("Anything") {
+		t.Error("expected an unmapped repository to track every project")
+	}
+}
+
+func TestGitRepositoryLookup(t *testing.T) {
+	db := openTempDatabase(t)
+	dir := t.TempDir()
+	if err := db.SetGitRepositories([]GitRepository{{Path: dir + "/", Project: "Site"}, {Path: t.TempDir()}}); err != nil {
+		t.Fatalf("set repositories: %v", err)
+	}
+	repo, ok := db.GitRepository(dir)
+	if !ok || repo.Project != "Site" {
+		t.Fatalf("expected to find the repository at %s, got %+v", dir, repo)
+	}
+	if _, ok := db.GitRepository(t.TempDir()); ok {
+		t.Fatal("expected an unconfigured directory not to match")
+	}
+
+	site, err := db.CreateProject("Site")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	other, err := db.CreateProject("Other")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if dirs := db.GitRepositoriesFor(site); len(dirs) != 2 {
+		t.Fatalf("expected both repositories for Site, got %v", dirs)
+	}
+	if dirs := db.GitRepositoriesFor(other); len(dirs) != 1 || dirs[0] == repo.Path {
+		t.Fatalf("expected only the unmapped repository for Other, got %v", dirs)
+	}
+}
@@ -0,0 +1,282 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// GoalPeriod is how often a goal's target resets.
+type GoalPeriod string
+
+const (
+	GoalDaily  GoalPeriod = "day"
+	GoalWeekly GoalPeriod = "week"
+)
+
+// Label names the period as an adjective, e.g. "daily".
+func (p GoalPeriod) Label() string {
+	if p == GoalWeekly {
+		return "weekly"
+	}
+	return "daily"
+}
+
+// ErrGoalNotFound is returned when clearing a goal that was never set.
+var ErrGoalNotFound = errors.New("goal not found")
+
+// Goal is a target number of tracked hours per day or week. A goal covers every
+// project unless it is scoped to a project (including its sub-projects) or to a tag.
+type Goal struct {
+	ID        int64
+	Period    GoalPeriod
+	Target    time.Duration
+	ProjectID *int64
+	Tag       string
+}
+
+// ParseGoalTarget reads a target such as "6h/day" or "30h/week".
+func ParseGoalTarget(spec string) (GoalPeriod, time.Duration, error) {
+	amount, period, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return "", 0, fmt.Errorf("goal %q: expected hours per day or week, e.g. 6h/day", spec)
+	}
+	minutes, err := parseHours(amount)
+	if err != nil {
+		return "", 0, fmt.Errorf("goal %q: expected hours like 6h", spec)
+	}
+	switch p := GoalPeriod(strings.ToLower(strings.TrimSpace(period))); p {
+	case GoalDaily, GoalWeekly:
+		return p, time.Duration(minutes) * time.Minute, nil
+	default:
+		return "", 0, fmt.Errorf("goal %q: period must be day or week", spec)
+	}
+}
+
+// String renders the target in the form accepted by ParseGoalTarget.
+func (g Goal) String() string {
+	return formatHours(int64(g.Target/time.Minute)) + "/" + string(g.Period)
+}
+
+// Filter returns the entries the goal counts.
+func (g Goal) Filter() EntryFilter {
+	return EntryFilter{ProjectID: g.ProjectID, Tag: g.Tag}
+}
+
+// Scope describes what the goal counts: a project path, a #tag, or all projects.
+func (g Goal) Scope(d *Database) string {
+	return g.Filter().Label(d)
+}
+
+// periodStart returns the start of the goal period containing t.
+func (g Goal) periodStart(t time.Time) time.Time {
+	if g.Period == GoalWeekly {
+		return StartOfWeek(t)
+	}
+	return StartOfDay(t)
+}
+
+func (g Goal) nextPeriod(start time.Time) time.Time {
+	if g.Period == GoalWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func newGoalFromModel(model sqlc.Goal) Goal {
+	return Goal{
+		ID:        model.ID,
+		Period:    GoalPeriod(model.Period),
+		Target:    time.Duration(model.Minutes) * time.Minute,
+		ProjectID: nullInt64Ptr(model.ProjectID),
+		Tag:       model.Tag.String,
+	}
+}
+
+// Goals lists every configured goal, daily goals first.
+func (d *Database) Goals() ([]Goal, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows, err := d.queries.ListGoals(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("list goals: %w", err)
+	}
+	goals := make([]Goal, 0, len(rows))
+	for _, row := range rows {
+		goals = append(goals, newGoalFromModel(row))
+	}
+	return goals, nil
+}
+
+// SetGoal creates the goal or replaces the target of the goal with the same period and scope.
+func (d *Database) SetGoal(goal Goal) (Goal, error) {
+	if d == nil {
+		return Goal{}, errors.New("database not initialized")
+	}
+	goal.Tag = normalizeTag(goal.Tag)
+	if goal.Period != GoalDaily && goal.Period != GoalWeekly {
+		return Goal{}, fmt.Errorf("unknown goal period %q", goal.Period)
+	}
+	minutes := int64(goal.Target / time.Minute)
+	if minutes <= 0 {
+		return Goal{}, errors.New("goal target must be at least a minute")
+	}
+	if goal.ProjectID != nil && goal.Tag != "" {
+		return Goal{}, errors.New("a goal can be scoped to a project or a tag, not both")
+	}
+
+	projectID, tag := goal.Filter().params()
+	ctx := context.Background()
+	var saved sqlc.Goal
+	err := d.withTx(ctx, func(q *sqlc.Queries) error {
+		existing, err := q.GetGoalByScope(ctx, sqlc.GetGoalByScopeParams{
+			Period:    string(goal.Period),
+			ProjectID: projectID,
+			Tag:       tag,
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			saved, err = q.CreateGoal(ctx, sqlc.CreateGoalParams{
+				Period:    string(goal.Period),
+				Minutes:   minutes,
+				ProjectID: projectID,
+				Tag:       tag,
+			})
+		case err == nil:
+			saved, err = q.UpdateGoalMinutes(ctx, sqlc.UpdateGoalMinutesParams{ID: existing.ID, Minutes: minutes})
+		}
+		return err
+	})
+	if err != nil {
+		return Goal{}, fmt.Errorf("save goal: %w", err)
+	}
+	return newGoalFromModel(saved), nil
+}
+
+// ClearGoal removes the goal matching goal's period and scope.
+func (d *Database) ClearGoal(goal Goal) error {
+	if d == nil {
+		return errors.New("database not initialized")
+	}
+	goal.Tag = normalizeTag(goal.Tag)
+	projectID, tag := goal.Filter().params()
+	ctx := context.Background()
+	existing, err := d.queries.GetGoalByScope(ctx, sqlc.GetGoalByScopeParams{
+		Period:    string(goal.Period),
+		ProjectID: projectID,
+		Tag:       tag,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: no %s goal for %s", ErrGoalNotFound, goal.Period.Label(), goal.Scope(d))
+	}
+	if err != nil {
+		return fmt.Errorf("find goal: %w", err)
+	}
+	if err := d.queries.DeleteGoal(ctx, existing.ID); err != nil {
+		return fmt.Errorf("clear goal: %w", err)
+	}
+	return nil
+}
+
+// GoalResult is the time tracked toward a goal in one day or week.
+type GoalResult struct {
+	Start   time.Time
+	Tracked time.Duration
+	Met     bool
+	// Current marks the period that is still in progress.
+	Current bool
+}
+
+// GoalProgress is a goal's recent history, oldest period first.
+type GoalProgress struct {
+	Goal    Goal
+	Results []GoalResult
+	// Streak counts consecutive periods that met the goal, up to the current one.
+	// The current period only adds to the streak once it is met.
+	Streak int
+}
+
+// Latest returns the most recent, in-progress period.
+func (p GoalProgress) Latest() GoalResult {
+	if len(p.Results) == 0 {
+		return GoalResult{}
+	}
+	return p.Results[len(p.Results)-1]
+}
+
+// Percent returns the share of the target reached in this period.
+func (r GoalResult) Percent(target time.Duration) float64 {
+	if target <= 0 {
+		return 0
+	}
+	return float64(r.Tracked) * 100 / float64(target)
+}
+
+// goalStreakLimit caps how far back streaks are counted.
+var goalStreakLimit = map[GoalPeriod]int{GoalDaily: 366, GoalWeekly: 53}
+
+// GoalProgress reports the last periods days or weeks of a goal up to now.
+func (d *Database) GoalProgress(goal Goal, now time.Time, periods int) (*GoalProgress, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	if periods < 1 {
+		periods = 1
+	}
+	lookback := max(periods, goalStreakLimit[goal.Period])
+
+	current := goal.periodStart(now)
+	starts := make([]time.Time, lookback)
+	starts[lookback-1] = current
+	for i := lookback - 2; i >= 0; i-- {
+		// Step back from the following period's start so DST changes stay on local midnight.
+		starts[i] = goal.periodStart(starts[i+1].Add(-time.Hour))
+	}
+	end := goal.nextPeriod(current)
+
+	projectID, tag := goal.Filter().params()
+	rows, err := d.queries.ListEntryDurationsInRange(context.Background(), sqlc.ListEntryDurationsInRangeParams{
+		EndedAt:   sql.NullInt64{Int64: starts[0].Unix(), Valid: true},
+		EndedAt_2: sql.NullInt64{Int64: end.Unix(), Valid: true},
+		ProjectID: projectID,
+		Tag:       tag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list goal entries: %w", err)
+	}
+
+	results := make([]GoalResult, lookback)
+	index := make(map[int64]int, lookback)
+	for i, start := range starts {
+		results[i] = GoalResult{Start: start, Current: i == lookback-1}
+		index[start.Unix()] = i
+	}
+	for _, row := range rows {
+		ended := time.Unix(row.EndedAt.Int64, 0)
+		if i, ok := index[goal.periodStart(ended).Unix()]; ok {
+			results[i].Tracked += time.Duration(row.DurationMs) * time.Millisecond
+		}
+	}
+
+	progress := &GoalProgress{Goal: goal}
+	for i := range results {
+		results[i].Met = results[i].Tracked >= goal.Target
+	}
+	for i := lookback - 1; i >= 0; i-- {
+		if results[i].Met {
+			progress.Streak++
+			continue
+		}
+		if !results[i].Current {
+			break
+		}
+	}
+	progress.Results = results[lookback-periods:]
+	return progress, nil
+}
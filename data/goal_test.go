@@ -0,0 +1,148 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseGoalTarget(t *testing.T) {
+	period, target, err := ParseGoalTarget("6h/day")
+	if err != nil || period != GoalDaily || target != 6*time.Hour {
+		t.Fatalf("ParseGoalTarget(6h/day) = %s, %v, %v", period, target, err)
+	}
+	period, target, err = ParseGoalTarget(" 32.5h / Week ")
+	if err != nil || period != GoalWeekly || target != 32*time.Hour+30*time.Minute {
+		t.Fatalf("ParseGoalTarget(32.5h/week) = %s, %v, %v", period, target, err)
+	}
+	for _, spec := range []string{"6h", "6h/month", "x/day", "0h/day"} {
+		if _, _, err := ParseGoalTarget(spec); err == nil {
+			t.Fatalf("expected ParseGoalTarget(%q) to fail", spec)
+		}
+	}
+}
+
+func TestGoalProgressAndStreak(t *testing.T) {
+	db := openTempDatabase(t)
+	ctx := context.Background()
+
+	project, err := db.CreateProject("Focus")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	other, err := db.CreateProject("Other")
+	if err != nil {
+		t.Fatalf("create other project: %v", err)
+	}
+
+	now := time.Now()
+	today := StartOfDay(now)
+	logAt := func(p *Project, daysAgo int, hours time.Duration, content string) {
+		t.Helper()
+		end := today.AddDate(0, 0, -daysAgo).Add(12 * time.Hour)
+		if daysAgo == 0 && end.After(now) {
+			end = now
+		}
+		start := end.Add(-hours)
+		entry := &Entry{
+			db:         db,
+			Project:    p,
+			Content:    content,
+			Tags:       extractTags(content),
+			DurationMs: hours.Milliseconds(),
+			StartedAt:  &start,
+			EndedAt:    &end,
+			Type:       EntryTypeWork,
+			Billable:   true,
+		}
+		if err := entry.Save(ctx); err != nil {
+			t.Fatalf("save entry: %v", err)
+		}
+	}
+
+	// Four days ago is met, three days ago is missed, and the two days since are met,
+	// one of them only by adding time across projects.
+	logAt(project, 4, 4*time.Hour, "deep work #focus")
+	logAt(project, 3, time.Hour, "short day")
+	logAt(project, 2, 3*time.Hour, "deep work #focus")
+	logAt(other, 2, 2*time.Hour, "meetings")
+	logAt(project, 1, 4*time.Hour, "deep work #focus")
+
+	goal, err := db.SetGoal(Goal{Period: GoalDaily, Target: 4 * time.Hour})
+	if err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	progress, err := db.GoalProgress(goal, now, 5)
+	if err != nil {
+		t.Fatalf("goal progress: %v", err)
+	}
+	if len(progress.Results) != 5 {
+		t.Fatalf("expected 5 days, got %d", len(progress.Results))
+	}
+	met := make([]bool, 0, 5)
+	for _, result := range progress.Results {
+		met = append(met, result.Met)
+	}
+	if want := []bool{true, false, true, true, false}; !slices.Equal(met, want) {
+		t.Fatalf("unexpected days met %v, want %v", met, want)
+	}
+	if !progress.Latest().Current {
+		t.Fatalf("expected last result to be today")
+	}
+	if progress.Streak != 2 {
+		t.Fatalf("expected an unfinished today to keep a 2 day streak, got %d", progress.Streak)
+	}
+
+	// Tag goals only count tagged entries; project goals only that project.
+	tagGoal, err := db.SetGoal(Goal{Period: GoalDaily, Target: 3 * time.Hour, Tag: "#focus"})
+	if err != nil {
+		t.Fatalf("set tag goal: %v", err)
+	}
+	if tagGoal.Tag != "focus" {
+		t.Fatalf("expected tag to be normalized, got %q", tagGoal.Tag)
+	}
+	progress, err = db.GoalProgress(tagGoal, now, 5)
+	if err != nil {
+		t.Fatalf("tag goal progress: %v", err)
+	}
+	if progress.Results[1].Tracked != 0 || progress.Results[2].Tracked != 3*time.Hour || progress.Streak != 2 {
+		t.Fatalf("unexpected tag goal progress: %+v", progress)
+	}
+
+	projectGoal, err := db.SetGoal(Goal{Period: GoalWeekly, Target: time.Hour, ProjectID: &other.ID})
+	if err != nil {
+		t.Fatalf("set project goal: %v", err)
+	}
+	progress, err = db.GoalProgress(projectGoal, now, 2)
+	if err != nil {
+		t.Fatalf("project goal progress: %v", err)
+	}
+	var tracked time.Duration
+	for _, result := range progress.Results {
+		tracked += result.Tracked
+	}
+	if tracked != 2*time.Hour {
+		t.Fatalf("expected only Other's time to count, got %v", tracked)
+	}
+
+	// Setting the same scope again replaces the target.
+	if _, err := db.SetGoal(Goal{Period: GoalDaily, Target: 5 * time.Hour}); err != nil {
+		t.Fatalf("replace goal: %v", err)
+	}
+	goals, err := db.Goals()
+	if err != nil {
+		t.Fatalf("list goals: %v", err)
+	}
+	if len(goals) != 3 || goals[0].Target != 5*time.Hour || goals[0].ProjectID != nil || goals[0].Tag != "" {
+		t.Fatalf("unexpected goals: %+v", goals)
+	}
+
+	if err := db.ClearGoal(Goal{Period: GoalWeekly, ProjectID: &other.ID}); err != nil {
+		t.Fatalf("clear goal: %v", err)
+	}
+	if err := db.ClearGoal(Goal{Period: GoalWeekly, ProjectID: &other.ID}); !errors.Is(err, ErrGoalNotFound) {
+		t.Fatalf("expected ErrGoalNotFound, got %v", err)
+	}
+}
@@ -1,8 +1,33 @@
 package data
 
+import (
+	"database/sql"
+	"time"
+)
+
 func boolToInt(v bool) int64 {
 	if v {
 		return 1
 	}
 	return 0
 }
+
+func unixTimePtr(value sql.NullInt64) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := time.Unix(value.Int64, 0).UTC()
+	return &t
+}
+
+// StartOfDay returns local midnight on t's day.
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// StartOfWeek returns local midnight on the Monday of t's week.
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
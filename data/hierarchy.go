@@ -0,0 +1,264 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// ProjectPathSeparator joins project names into a path such as "Acme/Website/Design".
+const ProjectPathSeparator = "/"
+
+// ErrProjectNotFound is returned when a project name or path does not match an active project.
+var ErrProjectNotFound = errors.New("project not found")
+
+// ProjectNode is a project positioned within the project hierarchy.
+type ProjectNode struct {
+	Project  *Project
+	Path     string
+	Depth    int
+	Children []*ProjectNode
+}
+
+// splitProjectPath breaks a path into trimmed names. A leading separator marks
+// the path as rooted at the top level; it is otherwise ignored.
+func splitProjectPath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, ProjectPathSeparator)
+	if path == "" {
+		return nil, errors.New("project name cannot be empty")
+	}
+	parts := strings.Split(path, ProjectPathSeparator)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+		if parts[i] == "" {
+			return nil, fmt.Errorf("project path %q has an empty name", path)
+		}
+	}
+	return parts, nil
+}
+
+// checkProjectName reports a friendly error when an active project under parentID
+// already has name. Projects in the trash do not hold on to their names.
+func checkProjectName(ctx context.Context, q *sqlc.Queries, parentID *int64, name string, exceptID int64) error {
+	record, err := q.GetProjectByParentAndName(ctx, sqlc.GetProjectByParentAndNameParams{
+		ParentID: optionalInt64(parentID),
+		Name:     name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check project name: %w", err)
+	}
+	if record.ID == exceptID {
+		return nil
+	}
+	return fmt.Errorf("project %q already exists", record.Name)
+}
+
+// checkParentActive refuses to bring a project back while its parent is in the trash.
+func checkParentActive(ctx context.Context, q *sqlc.Queries, parentID sql.NullInt64) error {
+	if !parentID.Valid {
+		return nil
+	}
+	parent, err := q.GetProject(ctx, parentID.Int64)
+	if err != nil {
+		return fmt.Errorf("load parent project %d: %w", parentID.Int64, err)
+	}
+	if parent.DeletedAt.Valid {
+		return fmt.Errorf("parent project %q is in the trash; restore it first", parent.Name)
+	}
+	return nil
+}
+
+// findNamedProject finds the active project whose own name is exactly path,
+// such as one named "Client/Acme" before names could form paths.
+func findNamedProject(ctx context.Context, q *sqlc.Queries, path string) (sqlc.Project, bool, error) {
+	path = strings.TrimSpace(path)
+	if !strings.Contains(path, ProjectPathSeparator) {
+		return sqlc.Project{}, false, nil
+	}
+	rows, err := q.ListProjectsByName(ctx, path)
+	if err != nil {
+		return sqlc.Project{}, false, fmt.Errorf("find project %q: %w", path, err)
+	}
+	for _, row := range rows {
+		if !row.DeletedAt.Valid {
+			return row, true, nil
+		}
+	}
+	return sqlc.Project{}, false, nil
+}
+
+// findProjectPath walks names from the top level through active projects.
+func findProjectPath(ctx context.Context, q *sqlc.Queries, names []string) (sqlc.Project, error) {
+	var current sqlc.Project
+	var parentID *int64
+	for _, name := range names {
+		record, err := q.GetProjectByParentAndName(ctx, sqlc.GetProjectByParentAndNameParams{
+			ParentID: optionalInt64(parentID),
+			Name:     name,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return sqlc.Project{}, fmt.Errorf("%w: %s", ErrProjectNotFound, strings.Join(names, ProjectPathSeparator))
+		}
+		if err != nil {
+			return sqlc.Project{}, fmt.Errorf("find project %q: %w", name, err)
+		}
+		current = record
+		id := record.ID
+		parentID = &id
+	}
+	return current, nil
+}
+
+// FindProject resolves a project name or a "parent/child" path to an active project.
+// A project whose own name contains the separator is matched by that name first.
+// A bare name that is not a top-level project matches a sub-project when exactly
+// one active project carries that name.
+func (d *Database) FindProject(path string) (*Project, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	names, err := splitProjectPath(path)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if record, ok, err := findNamedProject(ctx, d.queries, path); err != nil || ok {
+		if err != nil {
+			return nil, err
+		}
+		return newProjectFromModel(d, record), nil
+	}
+	record, err := findProjectPath(ctx, d.queries, names)
+	if err == nil {
+		return newProjectFromModel(d, record), nil
+	}
+	if !errors.Is(err, ErrProjectNotFound) || len(names) != 1 {
+		return nil, err
+	}
+
+	rows, listErr := d.queries.ListProjectsByName(ctx, names[0])
+	if listErr != nil {
+		return nil, fmt.Errorf("find project %q: %w", names[0], listErr)
+	}
+	var matches []*Project
+	for _, row := range rows {
+		if !row.DeletedAt.Valid {
+			matches = append(matches, newProjectFromModel(d, row))
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, err
+	case 1:
+		return matches[0], nil
+	default:
+		paths := make([]string, 0, len(matches))
+		for _, match := range matches {
+			paths = append(paths, match.Path())
+		}
+		return nil, fmt.Errorf("project %q is ambiguous: %s", names[0], strings.Join(paths, ", "))
+	}
+}
+
+// Path returns the project's names from the top level down, joined by ProjectPathSeparator.
+func (p *Project) Path() string {
+	if p == nil {
+		return ""
+	}
+	if p.ParentID == nil || p.db == nil {
+		return p.Name
+	}
+	names := []string{p.Name}
+	seen := map[int64]bool{p.ID: true}
+	ctx := context.Background()
+	parentID := *p.ParentID
+	for !seen[parentID] {
+		seen[parentID] = true
+		parent, err := p.db.queries.GetProject(ctx, parentID)
+		if err != nil {
+			break
+		}
+		names = append([]string{parent.Name}, names...)
+		if !parent.ParentID.Valid {
+			break
+		}
+		parentID = parent.ParentID.Int64
+	}
+	return strings.Join(names, ProjectPathSeparator)
+}
+
+// ProjectTree arranges the active projects into a hierarchy. Siblings keep the
+// ordering of Projects.
+func (d *Database) ProjectTree() []*ProjectNode {
+	return BuildProjectTree(d.Projects())
+}
+
+// BuildProjectTree arranges projects into a hierarchy. Projects whose parent is
+// not in the slice are treated as top-level projects.
+func BuildProjectTree(projects []*Project) []*ProjectNode {
+	nodes := make(map[int64]*ProjectNode, len(projects))
+	for _, project := range projects {
+		nodes[project.ID] = &ProjectNode{Project: project}
+	}
+
+	roots := make([]*ProjectNode, 0)
+	for _, project := range projects {
+		node := nodes[project.ID]
+		if project.ParentID != nil {
+			if parent, ok := nodes[*project.ParentID]; ok && parent != node {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	var assign func(list []*ProjectNode, prefix string, depth int)
+	assign = func(list []*ProjectNode, prefix string, depth int) {
+		for _, node := range list {
+			node.Depth = depth
+			node.Path = prefix + node.Project.Name
+			assign(node.Children, node.Path+ProjectPathSeparator, depth+1)
+		}
+	}
+	assign(roots, "", 0)
+	return roots
+}
+
+// WalkProjectTree visits nodes depth-first. Returning false from fn skips the node's children.
+func WalkProjectTree(nodes []*ProjectNode, fn func(node *ProjectNode) bool) {
+	for _, node := range nodes {
+		if fn(node) {
+			WalkProjectTree(node.Children, fn)
+		}
+	}
+}
+
+// isDescendant reports whether candidate sits somewhere below ancestorID.
+func isDescendant(ctx context.Context, q *sqlc.Queries, ancestorID, candidate int64) (bool, error) {
+	seen := map[int64]bool{}
+	current := candidate
+	for !seen[current] {
+		seen[current] = true
+		record, err := q.GetProject(ctx, current)
+		if err != nil {
+			return false, fmt.Errorf("load project %d: %w", current, err)
+		}
+		if !record.ParentID.Valid {
+			return false, nil
+		}
+		if record.ParentID.Int64 == ancestorID {
+			return true, nil
+		}
+		current = record.ParentID.Int64
+	}
+	return false, nil
+}
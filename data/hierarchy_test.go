@@ -0,0 +1,171 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+func TestCreateProjectPathAndFind(t *testing.T) {
+	db := openTempDatabase(t)
+
+	website, err := db.CreateProject("Acme/Website/Design")
+	if err != nil {
+		t.Fatalf("create nested project: %v", err)
+	}
+	if website.Name != "Design" || website.ParentID == nil {
+		t.Fatalf("expected Design under a parent, got %+v", website)
+	}
+	if got := website.Path(); got != "Acme/Website/Design" {
+		t.Fatalf("expected full path, got %q", got)
+	}
+	if _, err := db.CreateProject("Globex/Website"); err != nil {
+		t.Fatalf("same name under another parent should be allowed: %v", err)
+	}
+	if _, err := db.CreateProject("acme/website"); err == nil {
+		t.Fatalf("expected duplicate path to fail")
+	}
+
+	found, err := db.FindProject(" Acme / Website / Design ")
+	if err != nil || found.ID != website.ID {
+		t.Fatalf("expected to find Design by path, got %+v, %v", found, err)
+	}
+	if found, err := db.FindProject("design"); err != nil || found.ID != website.ID {
+		t.Fatalf("expected unique bare name to resolve, got %+v, %v", found, err)
+	}
+	if _, err := db.FindProject("Website"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("expected ambiguous bare name, got %v", err)
+	}
+	if _, err := db.FindProject("Acme/Nope"); !errors.Is(err, ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got %v", err)
+	}
+
+	tree := db.ProjectTree()
+	var paths []string
+	WalkProjectTree(tree, func(node *ProjectNode) bool {
+		paths = append(paths, strings.Repeat(">", node.Depth)+node.Path)
+		return true
+	})
+	if len(tree) != 2 || len(paths) != 5 {
+		t.Fatalf("unexpected tree: %v", paths)
+	}
+}
+
+func TestProjectNamedWithSeparator(t *testing.T) {
+	db := openTempDatabase(t)
+	// Projects named before names could form paths may contain the separator.
+	legacy, err := db.queries.CreateProject(context.Background(), sqlc.CreateProjectParams{Name: "Client/Acme"})
+	if err != nil {
+		t.Fatalf("create legacy project: %v", err)
+	}
+
+	found, err := db.FindProject("client/acme")
+	if err != nil || found.ID != legacy.ID {
+		t.Fatalf("expected the project named Client/Acme, got %+v, %v", found, err)
+	}
+	if _, err := db.CreateProject("Client/Acme"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected creating the same name as a path to be refused, got %v", err)
+	}
+
+	err = found.Rename("Client/Acme Corp")
+	if !errors.Is(err, ErrProjectNotFound) || !strings.Contains(err.Error(), "moves the project under that parent") {
+		t.Fatalf("expected renaming to a path with no parent to explain the separator, got %v", err)
+	}
+	if err := found.Rename("Acme"); err != nil || found.Path() != "Acme" {
+		t.Fatalf("expected a plain rename to work, got %q, %v", found.Path(), err)
+	}
+}
+
+func TestRenameMovesProjectWithinHierarchy(t *testing.T) {
+	db := openTempDatabase(t)
+
+	acme, err := db.CreateProject("Acme")
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := db.CreateProject("Acme/Backend")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	loose, err := db.CreateProject("Loose")
+	if err != nil {
+		t.Fatalf("create loose project: %v", err)
+	}
+
+	if err := loose.Rename("Acme/Backend/Jobs"); err != nil {
+		t.Fatalf("move under child: %v", err)
+	}
+	if loose.Path() != "Acme/Backend/Jobs" {
+		t.Fatalf("expected moved path, got %q", loose.Path())
+	}
+	if err := acme.Rename("Acme/Backend/Jobs/Acme"); err == nil {
+		t.Fatalf("expected moving a project under its own descendant to fail")
+	}
+	if err := child.Rename("Platform"); err != nil {
+		t.Fatalf("rename in place: %v", err)
+	}
+	if child.Path() != "Acme/Platform" {
+		t.Fatalf("expected rename to keep parent, got %q", child.Path())
+	}
+
+	if err := loose.Rename("/Jobs"); err != nil {
+		t.Fatalf("move to top level: %v", err)
+	}
+	if loose.ParentID != nil || loose.Path() != "Jobs" {
+		t.Fatalf("expected top-level project, got %q", loose.Path())
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("undo move: %v", err)
+	}
+	moved, err := db.FindProject("Acme/Platform/Jobs")
+	if err != nil || moved.ID != loose.ID {
+		t.Fatalf("expected undo to restore parent, got %+v, %v", moved, err)
+	}
+}
+
+func TestDeleteAndPurgeWithSubProjects(t *testing.T) {
+	db := openTempDatabase(t)
+
+	parent, err := db.CreateProject("Client")
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := db.CreateProject("Client/Stream")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if _, err := child.CreateEntryWithDuration("work", time.Hour, true, EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	if err := parent.Delete(); err == nil || !strings.Contains(err.Error(), "sub-projects") {
+		t.Fatalf("expected delete with active sub-projects to fail, got %v", err)
+	}
+	if err := child.Delete(); err != nil {
+		t.Fatalf("delete child: %v", err)
+	}
+	if err := parent.Delete(); err != nil {
+		t.Fatalf("delete parent: %v", err)
+	}
+	if err := child.Restore(); err == nil || !strings.Contains(err.Error(), "restore it first") {
+		t.Fatalf("expected restoring under a trashed parent to fail, got %v", err)
+	}
+
+	if err := db.EmptyTrash(); err != nil {
+		t.Fatalf("empty trash: %v", err)
+	}
+	projects, err := db.DeletedProjects()
+	if err != nil {
+		t.Fatalf("list deleted projects: %v", err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("expected trash to be empty, got %d projects", len(projects))
+	}
+	if _, err := db.CreateProject("Client/Stream"); err != nil {
+		t.Fatalf("expected purged path to be reusable: %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+	"github.com/nexneo/samay/util"
+)
+
+// ChangeAction names the kind of mutation recorded in the change log.
+type ChangeAction string
+
+const (
+	ChangeCreate  ChangeAction = "create"
+	ChangeUpdate  ChangeAction = "update"
+	ChangeMove    ChangeAction = "move"
+	ChangeDelete  ChangeAction = "delete"
+	ChangeRestore ChangeAction = "restore"
+	ChangePurge   ChangeAction = "purge"
+)
+
+const (
+	entityEntry   = "entry"
+	entityProject = "project"
+	entityTimer   = "timer"
+)
+
+// ErrEntryNotFound is returned when an entry identifier does not match any recorded entry.
+var ErrEntryNotFound = errors.New("entry not found")
+
+// Change is one append-only change log row with before/after JSON snapshots.
+type Change struct {
+	ID         int64
+	EntityType string
+	EntityID   string
+	Action     ChangeAction
+	Before     json.RawMessage
+	After      json.RawMessage
+	CreatedAt  time.Time
+}
+
+// FieldChange describes a single field that differs between a change's snapshots.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+func newChangeFromModel(model sqlc.ChangeLog) *Change {
+	change := &Change{
+		ID:         model.ID,
+		EntityType: model.EntityType,
+		EntityID:   model.EntityID,
+		Action:     ChangeAction(model.Action),
+		CreatedAt:  time.Unix(model.CreatedAt, 0).UTC(),
+	}
+	if model.BeforeJson.Valid {
+		change.Before = json.RawMessage(model.BeforeJson.String)
+	}
+	if model.AfterJson.Valid {
+		change.After = json.RawMessage(model.AfterJson.String)
+	}
+	return change
+}
+
+// recordChange appends a change log row; nil snapshots are stored as NULL.
+func recordChange(ctx context.Context, q *sqlc.Queries, entityType, entityID string, action ChangeAction, before, after any) error {
+	encode := func(value any) (sql.NullString, error) {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return sql.NullString{}, fmt.Errorf("encode %s snapshot: %w", entityType, err)
+		}
+		if string(raw) == "null" {
+			return sql.NullString{}, nil
+		}
+		return sql.NullString{String: string(raw), Valid: true}, nil
+	}
+	beforeJSON, err := encode(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := encode(after)
+	if err != nil {
+		return err
+	}
+	if err := q.InsertChange(ctx, sqlc.InsertChangeParams{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     string(action),
+		BeforeJson: beforeJSON,
+		AfterJson:  afterJSON,
+	}); err != nil {
+		return fmt.Errorf("record %s %s change: %w", entityType, action, err)
+	}
+	return nil
+}
+
+// recordEntryChange logs an entry change, snapshotting the row as it is after the write.
+func recordEntryChange(ctx context.Context, q *sqlc.Queries, id string, action ChangeAction, before *entrySnapshot) error {
+	var after *entrySnapshot
+	if action != ChangePurge {
+		snap, err := snapshotEntry(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		after = &snap
+	}
+	return recordChange(ctx, q, entityEntry, id, action, before, after)
+}
+
+// recordProjectChange logs a project change, snapshotting the row as it is after the write.
+func recordProjectChange(ctx context.Context, q *sqlc.Queries, id int64, action ChangeAction, before *projectSnapshot) error {
+	var after *projectSnapshot
+	if action != ChangePurge {
+		snap, err := snapshotProject(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		after = &snap
+	}
+	return recordChange(ctx, q, entityProject, strconv.FormatInt(id, 10), action, before, after)
+}
+
+func recordTimerChange(ctx context.Context, q *sqlc.Queries, projectID int64, action ChangeAction, before, after *timerSnapshot) error {
+	return recordChange(ctx, q, entityTimer, strconv.FormatInt(projectID, 10), action, before, after)
+}
+
+// ResolveEntryID expands a full or abbreviated entry identifier using the change log.
+func (d *Database) ResolveEntryID(prefix string) (string, error) {
+	if d == nil {
+		return "", errors.New("database not initialized")
+	}
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return "", errors.New("entry id cannot be empty")
+	}
+	ctx := context.Background()
+	if _, err := d.queries.GetEntry(ctx, prefix); err == nil {
+		return prefix, nil
+	}
+	ids, err := d.queries.ListChangedEntityIDsByPrefix(ctx, sqlc.ListChangedEntityIDsByPrefixParams{
+		EntityType: entityEntry,
+		EntityID:   prefix + "%",
+	})
+	if err != nil {
+		return "", fmt.Errorf("resolve entry id: %w", err)
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrEntryNotFound, prefix)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("entry id %q is ambiguous", prefix)
+	}
+}
+
+// EntryHistory returns every recorded change for an entry, oldest first.
+func (d *Database) EntryHistory(id string) ([]*Change, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows, err := d.queries.ListChangesForEntity(context.Background(), sqlc.ListChangesForEntityParams{
+		EntityType: entityEntry,
+		EntityID:   id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list entry history: %w", err)
+	}
+	changes := make([]*Change, 0, len(rows))
+	for _, row := range rows {
+		changes = append(changes, newChangeFromModel(row))
+	}
+	return changes, nil
+}
+
+// Fields lists the snapshot fields whose values differ between Before and After.
+// Bookkeeping timestamps are skipped since every write bumps them.
+func (c *Change) Fields() []FieldChange {
+	if c == nil {
+		return nil
+	}
+	before := map[string]any{}
+	after := map[string]any{}
+	if len(c.Before) > 0 {
+		_ = json.Unmarshal(c.Before, &before)
+	}
+	if len(c.After) > 0 {
+		_ = json.Unmarshal(c.After, &after)
+	}
+
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+
+	fields := make([]FieldChange, 0, len(keys))
+	for key := range keys {
+		if key == "updated_at" || key == "created_at" || key == "deleted_at" {
+			continue
+		}
+		b := formatSnapshotValue(key, before[key])
+		a := formatSnapshotValue(key, after[key])
+		if b == a {
+			continue
+		}
+		fields = append(fields, FieldChange{Field: key, Before: b, After: a})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+// ProjectID reports the project an entry change belongs to, preferring the after snapshot.
+func (c *Change) ProjectID() int64 {
+	if c == nil {
+		return 0
+	}
+	var snap struct {
+		ProjectID int64 `json:"project_id"`
+	}
+	for _, raw := range []json.RawMessage{c.After, c.Before} {
+		if len(raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(raw, &snap); err == nil && snap.ProjectID != 0 {
+			return snap.ProjectID
+		}
+	}
+	return 0
+}
+
+func formatSnapshotValue(key string, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		switch {
+		case key == "duration_ms":
+			return util.HmFromD(time.Duration(int64(v)) * time.Millisecond).String()
+		case strings.HasSuffix(key, "_at"):
+			return time.Unix(int64(v), 0).In(time.Local).Format("2006-01-02 15:04")
+		}
+		return fmt.Sprintf("%d", int64(v))
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
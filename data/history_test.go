@@ -0,0 +1,131 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+func TestEntryHistoryRecordsLifecycle(t *testing.T) {
+	db := openTempDatabase(t)
+	ctx := context.Background()
+
+	project, err := db.CreateProject("History")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	other, err := db.CreateProject("Elsewhere")
+	if err != nil {
+		t.Fatalf("create other project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("first draft", 15*time.Minute, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	entry.Content = "second draft"
+	if err := entry.Update(ctx); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+	if err := entry.MoveTo(ctx, other); err != nil {
+		t.Fatalf("move entry: %v", err)
+	}
+	if err := entry.Delete(ctx); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	if err := entry.Restore(ctx); err != nil {
+		t.Fatalf("restore entry: %v", err)
+	}
+	if err := entry.Purge(ctx); err != nil {
+		t.Fatalf("purge entry: %v", err)
+	}
+
+	changes, err := db.EntryHistory(entry.ID)
+	if err != nil {
+		t.Fatalf("entry history: %v", err)
+	}
+	want := []ChangeAction{ChangeCreate, ChangeUpdate, ChangeMove, ChangeDelete, ChangeRestore, ChangePurge}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %d", len(want), len(changes))
+	}
+	for i, action := range want {
+		if changes[i].Action != action {
+			t.Fatalf("change %d: expected %s, got %s", i, action, changes[i].Action)
+		}
+	}
+	if changes[0].Before != nil || changes[0].After == nil {
+		t.Fatalf("expected create to have only an after snapshot")
+	}
+	if changes[5].After != nil || changes[5].Before == nil {
+		t.Fatalf("expected purge to have only a before snapshot")
+	}
+
+	fields := changes[1].Fields()
+	if len(fields) != 1 || fields[0].Field != "content" || fields[0].Before != "first draft" || fields[0].After != "second draft" {
+		t.Fatalf("unexpected update fields: %+v", fields)
+	}
+	if got := changes[2].ProjectID(); got != other.ID {
+		t.Fatalf("expected moved entry to report project %d, got %d", other.ID, got)
+	}
+
+	resolved, err := db.ResolveEntryID(entry.ID[:8])
+	if err != nil {
+		t.Fatalf("resolve purged entry prefix: %v", err)
+	}
+	if resolved != entry.ID {
+		t.Fatalf("expected %s, got %s", entry.ID, resolved)
+	}
+	if _, err := db.ResolveEntryID("zzzz"); !errors.Is(err, ErrEntryNotFound) {
+		t.Fatalf("expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func TestChangeLogRecordsProjectsAndTimers(t *testing.T) {
+	db := openTempDatabase(t)
+
+	project, err := db.CreateProject("Audited")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	if _, err := project.StopTimer("done", true, EntryTypeWork); err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	if err := project.Rename("Audited Renamed"); err != nil {
+		t.Fatalf("rename project: %v", err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("undo rename: %v", err)
+	}
+
+	ctx := context.Background()
+	changes := func(entityType, id string) []string {
+		t.Helper()
+		rows, err := db.queries.ListChangesForEntity(ctx, sqlc.ListChangesForEntityParams{
+			EntityType: entityType,
+			EntityID:   id,
+		})
+		if err != nil {
+			t.Fatalf("list changes: %v", err)
+		}
+		actions := make([]string, 0, len(rows))
+		for _, row := range rows {
+			actions = append(actions, row.Action)
+		}
+		return actions
+	}
+
+	id := strconv.FormatInt(project.ID, 10)
+	if got := changes(entityProject, id); len(got) != 3 || got[0] != "create" || got[1] != "update" || got[2] != "update" {
+		t.Fatalf("unexpected project changes: %v", got)
+	}
+	if got := changes(entityTimer, id); len(got) != 2 || got[0] != "create" || got[1] != "delete" {
+		t.Fatalf("unexpected timer changes: %v", got)
+	}
+}
@@ -0,0 +1,238 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// OverlapPolicy decides what saving an entry that overlaps another one does.
+type OverlapPolicy string
+
+const (
+	// OverlapAllow saves overlapping entries without checking.
+	OverlapAllow OverlapPolicy = "allow"
+	// OverlapWarn saves the entry and lists what it overlaps in Entry.Conflicts.
+	OverlapWarn OverlapPolicy = "warn"
+	// OverlapReject refuses to save the entry with an *OverlapError.
+	OverlapReject OverlapPolicy = "reject"
+)
+
+// ErrEntryOverlap is wrapped by errors reporting that an entry overlaps another.
+var ErrEntryOverlap = errors.New("entry overlaps another entry")
+
+// ParseOverlapPolicy reads "allow", "warn" or "reject"; empty means warn.
+func ParseOverlapPolicy(value string) (OverlapPolicy, error) {
+	switch policy := OverlapPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return OverlapWarn, nil
+	case OverlapAllow, OverlapWarn, OverlapReject:
+		return policy, nil
+	default:
+		return OverlapWarn, fmt.Errorf("unknown overlap policy %q (want allow, warn or reject)", value)
+	}
+}
+
+// SetOverlapPolicy changes how saving overlapping entries is handled.
+func (d *Database) SetOverlapPolicy(policy OverlapPolicy) {
+	if d != nil {
+		d.overlapPolicy = policy
+	}
+}
+
+// OverlapPolicy returns how saving overlapping entries is handled, warn by default.
+func (d *Database) OverlapPolicy() OverlapPolicy {
+	if d == nil || d.overlapPolicy == "" {
+		return OverlapWarn
+	}
+	return d.overlapPolicy
+}
+
+// OverlapError reports the entries a rejected entry overlaps.
+type OverlapError struct {
+	Conflicts []*Entry
+}
+
+func (e *OverlapError) Error() string {
+	return "entry overlaps " + DescribeConflicts(e.Conflicts)
+}
+
+func (e *OverlapError) Unwrap() error {
+	return ErrEntryOverlap
+}
+
+// DescribeConflicts lists overlapping entries by project and local clock time.
+func DescribeConflicts(entries []*Entry) string {
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := "entry"
+		if entry.Project != nil {
+			name = entry.Project.Path()
+		}
+		if span, ok := EntrySpan(entry); ok {
+			name += fmt.Sprintf(" (%s–%s)", span.Start.In(time.Local).Format("15:04"), span.End.In(time.Local).Format("15:04"))
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkOverlaps applies the overlap policy before e is written.
+func (e *Entry) checkOverlaps(ctx context.Context, q *sqlc.Queries) error {
+	e.Conflicts = nil
+	if e.db.OverlapPolicy() == OverlapAllow {
+		return nil
+	}
+	conflicts, err := e.overlapping(ctx, q)
+	if err != nil || len(conflicts) == 0 {
+		return err
+	}
+	if e.db.OverlapPolicy() == OverlapReject {
+		return &OverlapError{Conflicts: conflicts}
+	}
+	e.Conflicts = conflicts
+	return nil
+}
+
+// overlapping loads the other entries whose time overlaps e's.
+func (e *Entry) overlapping(ctx context.Context, q *sqlc.Queries) ([]*Entry, error) {
+	span, ok := EntrySpan(e)
+	if !ok || span.Duration() <= 0 {
+		return nil, nil
+	}
+	rows, err := q.ListOverlappingEntries(ctx, sqlc.ListOverlappingEntriesParams{
+		EndedAt:   sql.NullInt64{Int64: span.Start.Unix(), Valid: true},
+		StartedAt: sql.NullInt64{Int64: span.End.Unix(), Valid: true},
+		ID:        e.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list overlapping entries: %w", err)
+	}
+	candidates, err := e.db.entriesFromRows(ctx, q, rows)
+	if err != nil {
+		return nil, err
+	}
+	conflicts := candidates[:0]
+	for _, other := range candidates {
+		if otherSpan, ok := EntrySpan(other); ok && otherSpan.Start.Before(span.End) && otherSpan.End.After(span.Start) {
+			conflicts = append(conflicts, other)
+		}
+	}
+	return conflicts, nil
+}
+
+// Overlapping returns the other entries whose time overlaps this one.
+func (e *Entry) Overlapping() ([]*Entry, error) {
+	if e == nil || e.db == nil {
+		return nil, errors.New("entry not initialized")
+	}
+	return e.overlapping(context.Background(), e.db.queries)
+}
+
+// EntryOverlaps finds every stretch in which two or more entries that ended in
+// [start, end) ran at once, earliest first.
+func (d *Database) EntryOverlaps(start, end time.Time) ([]Overlap, error) {
+	entries, err := d.EntriesInRange(start, end, EntryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	placed := make([]TimelineEntry, 0, len(entries))
+	for _, entry := range entries {
+		if span, ok := EntrySpan(entry); ok {
+			placed = append(placed, TimelineEntry{Span: span, Entry: entry})
+		}
+	}
+	sort.SliceStable(placed, func(i, j int) bool {
+		return placed[i].Start.Before(placed[j].Start)
+	})
+	return FindOverlaps(placed), nil
+}
+
+// OverlapResolution is a way of making an entry stop overlapping another.
+type OverlapResolution string
+
+const (
+	// OverlapTrim cuts the overlapping part off the entry.
+	OverlapTrim OverlapResolution = "trim"
+	// OverlapShift moves the entry to start when the other one ends.
+	OverlapShift OverlapResolution = "shift"
+	// OverlapMerge folds the other entry into this one, covering both, and trashes it.
+	OverlapMerge OverlapResolution = "merge"
+)
+
+// ResolveOverlap changes e so that it no longer overlaps other. Each change can be undone.
+// Trimming an entry around another that lies inside it splits it in two.
+// Merging, unlike Merge, keeps the time the two entries shared only once.
+func (e *Entry) ResolveOverlap(other *Entry, how OverlapResolution) error {
+	if e == nil || e.db == nil || other == nil {
+		return errors.New("entry not initialized")
+	}
+	span, ok := EntrySpan(e)
+	otherSpan, otherOK := EntrySpan(other)
+	if !ok || !otherOK {
+		return errors.New("only entries with an end time can overlap")
+	}
+	if !span.Start.Before(otherSpan.End) || !span.End.After(otherSpan.Start) {
+		return errors.New("entries do not overlap")
+	}
+
+	switch how {
+	case OverlapTrim:
+		if otherSpan.Start.After(span.Start) && otherSpan.End.Before(span.End) {
+			_, err := e.split(Span{Start: span.Start, End: otherSpan.Start}, Span{Start: otherSpan.End, End: span.End}, "", "", "trim ")
+			return err
+		}
+		if otherSpan.Start.After(span.Start) {
+			span.End = otherSpan.Start
+		} else {
+			span.Start = otherSpan.End
+		}
+		if span.Duration() <= 0 {
+			return errors.New("trimming would leave nothing of the entry; shift or merge it instead")
+		}
+		e.setSpan(span)
+		return e.Update(context.Background())
+	case OverlapShift:
+		e.setSpan(Span{Start: otherSpan.End, End: otherSpan.End.Add(span.Duration())})
+		return e.Update(context.Background())
+	case OverlapMerge:
+		ordered := []*Entry{e, other}
+		if otherSpan.Start.Before(span.Start) {
+			span.Start = otherSpan.Start
+			ordered = []*Entry{other, e}
+		}
+		if otherSpan.End.After(span.End) {
+			span.End = otherSpan.End
+		}
+		return e.merge([]*Entry{other}, span, ordered)
+	default:
+		return fmt.Errorf("unknown overlap resolution %q", how)
+	}
+}
+
+// setSpan places the entry at span, keeping its duration in step.
+func (e *Entry) setSpan(span Span) {
+	start, end := span.Start.UTC(), span.End.UTC()
+	e.StartedAt = &start
+	e.EndedAt = &end
+	e.DurationMs = span.Duration().Milliseconds()
+}
+
+// joinContent combines two descriptions, dropping empty or repeated ones.
+func joinContent(a, b string) string {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	switch {
+	case b == "" || a == b:
+		return a
+	case a == "":
+		return b
+	default:
+		return a + "; " + b
+	}
+}
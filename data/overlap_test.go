@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseOverlapPolicy(t *testing.T) {
+	for value, want := range map[string]OverlapPolicy{"": OverlapWarn, "allow": OverlapAllow, " Reject ": OverlapReject} {
+		if got, err := ParseOverlapPolicy(value); err != nil || got != want {
+			t.Fatalf("ParseOverlapPolicy(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseOverlapPolicy("ignore"); err == nil {
+		t.Fatalf("expected an unknown policy to fail")
+	}
+}
+
+func TestEntryOverlapPolicies(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Overlaps")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	day := time.Date(2024, time.April, 3, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	save := func(content string, start, end time.Time) (*Entry, error) {
+		entry := &Entry{
+			db:         db,
+			Project:    project,
+			Content:    content,
+			StartedAt:  &start,
+			EndedAt:    &end,
+			DurationMs: end.Sub(start).Milliseconds(),
+			Type:       EntryTypeWork,
+			Tags:       extractTags(content),
+		}
+		return entry, entry.Save(context.Background())
+	}
+
+	morning, err := save("morning #deep", at(9, 0), at(10, 0))
+	if err != nil || len(morning.Conflicts) != 0 {
+		t.Fatalf("save first entry: %v, conflicts %v", err, morning.Conflicts)
+	}
+
+	// Warn is the default: the entry is saved and its conflicts reported.
+	review, err := save("review", at(9, 45), at(10, 30))
+	if err != nil {
+		t.Fatalf("save overlapping entry: %v", err)
+	}
+	if len(review.Conflicts) != 1 || review.Conflicts[0].ID != morning.ID {
+		t.Fatalf("expected review to conflict with morning, got %v", review.Conflicts)
+	}
+
+	// Back-to-back entries do not overlap.
+	if call, err := save("call", at(10, 30), at(11, 0)); err != nil || len(call.Conflicts) != 0 {
+		t.Fatalf("expected adjacent entry to save cleanly: %v, %v", err, call.Conflicts)
+	}
+
+	db.SetOverlapPolicy(OverlapReject)
+	_, err = save("late", at(10, 50), at(11, 10))
+	var overlapErr *OverlapError
+	if !errors.Is(err, ErrEntryOverlap) || !errors.As(err, &overlapErr) || len(overlapErr.Conflicts) != 1 {
+		t.Fatalf("expected the reject policy to refuse the entry, got %v", err)
+	}
+	review.Content = "code review"
+	if err := review.UpdateNow(); err != nil {
+		t.Fatalf("expected editing only the description of an overlapping entry to save, got %v", err)
+	}
+	later := review.EndedAt.Add(5 * time.Minute)
+	review.EndedAt = &later
+	review.DurationMs += (5 * time.Minute).Milliseconds()
+	if err := review.UpdateNow(); !errors.Is(err, ErrEntryOverlap) {
+		t.Fatalf("expected changing the time of an overlapping entry to be rejected, got %v", err)
+	}
+
+	db.SetOverlapPolicy(OverlapAllow)
+	if late, err := save("late", at(10, 50), at(11, 10)); err != nil || late.Conflicts != nil {
+		t.Fatalf("expected allow to skip the check: %v, %v", err, late.Conflicts)
+	}
+
+	overlaps, err := db.EntryOverlaps(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("entry overlaps: %v", err)
+	}
+	if len(overlaps) != 2 || !overlaps[0].Start.Equal(at(9, 45)) || overlaps[1].Duration() != 10*time.Minute {
+		t.Fatalf("unexpected overlaps %+v", overlaps)
+	}
+}
+
+func TestResolveOverlap(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Resolve")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	day := time.Date(2024, time.April, 4, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	// pair saves two overlapping entries on a day of their own.
+	pair := func() (*Entry, *Entry) {
+		t.Helper()
+		day = day.AddDate(0, 0, 1)
+		var entries []*Entry
+		for _, spec := range []struct {
+			content    string
+			start, end time.Time
+		}{
+			{"design #ui", at(9, 0), at(10, 0)},
+			{"sync #team", at(9, 30), at(10, 30)},
+		} {
+			start, end := spec.start, spec.end
+			entry := &Entry{db: db, Project: project, Content: spec.content, StartedAt: &start, EndedAt: &end,
+				DurationMs: end.Sub(start).Milliseconds(), Type: EntryTypeWork, Tags: extractTags(spec.content)}
+			if err := entry.Save(context.Background()); err != nil {
+				t.Fatalf("save entry: %v", err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries[0], entries[1]
+	}
+
+	design, sync := pair()
+	if err := sync.ResolveOverlap(design, OverlapTrim); err != nil {
+		t.Fatalf("trim: %v", err)
+	}
+	if !sync.StartedAt.Equal(at(10, 0)) || sync.DurationMs != (30*time.Minute).Milliseconds() || len(sync.Conflicts) != 0 {
+		t.Fatalf("expected sync to start when design ends, got %v for %dms", sync.StartedAt, sync.DurationMs)
+	}
+	if err := sync.ResolveOverlap(design, OverlapTrim); err == nil {
+		t.Fatalf("expected resolving entries that no longer overlap to fail")
+	}
+
+	design, sync = pair()
+	if err := design.ResolveOverlap(sync, OverlapTrim); err != nil {
+		t.Fatalf("trim end: %v", err)
+	}
+	if !design.EndedAt.Equal(at(9, 30)) {
+		t.Fatalf("expected design to end when sync starts, got %v", design.EndedAt)
+	}
+
+	// Trimming around an entry inside this one keeps the time on either side.
+	day = day.AddDate(0, 0, 1)
+	start, end := at(9, 0), at(12, 0)
+	workshop := &Entry{db: db, Project: project, Content: "workshop #team", StartedAt: &start, EndedAt: &end,
+		DurationMs: end.Sub(start).Milliseconds(), Type: EntryTypeWork, Tags: []string{"team"}}
+	callStart, callEnd := at(10, 0), at(10, 30)
+	call := &Entry{db: db, Project: project, Content: "call", StartedAt: &callStart, EndedAt: &callEnd,
+		DurationMs: callEnd.Sub(callStart).Milliseconds(), Type: EntryTypeWork}
+	for _, entry := range []*Entry{workshop, call} {
+		if err := entry.Save(context.Background()); err != nil {
+			t.Fatalf("save entry: %v", err)
+		}
+	}
+	if err := workshop.ResolveOverlap(call, OverlapTrim); err != nil {
+		t.Fatalf("trim around: %v", err)
+	}
+	if !workshop.EndedAt.Equal(at(10, 0)) {
+		t.Fatalf("expected the workshop to end when the call starts, got %v", workshop.EndedAt)
+	}
+	var after *Entry
+	for _, entry := range project.Entries() {
+		if entry.StartedAt != nil && entry.StartedAt.Equal(at(10, 30)) {
+			after = entry
+		}
+	}
+	if after == nil || !after.EndedAt.Equal(at(12, 0)) || after.Content != "workshop #team" {
+		t.Fatalf("expected the rest of the workshop after the call, got %+v", after)
+	}
+	if conflicts, err := workshop.Overlapping(); err != nil || len(conflicts) != 0 {
+		t.Fatalf("expected the trimmed workshop to stand alone, got %v, %v", conflicts, err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("undo trim: %v", err)
+	}
+	if restored, err := db.Entry(workshop.ID); err != nil || !restored.EndedAt.Equal(at(12, 0)) {
+		t.Fatalf("expected undo to put the workshop back in one piece, got %+v, %v", restored, err)
+	}
+	for _, entry := range project.Entries() {
+		if entry.ID == after.ID {
+			t.Fatal("expected undo to remove the second half")
+		}
+	}
+
+	design, sync = pair()
+	if err := design.ResolveOverlap(sync, OverlapShift); err != nil {
+		t.Fatalf("shift: %v", err)
+	}
+	if !design.StartedAt.Equal(at(10, 30)) || !design.EndedAt.Equal(at(11, 30)) {
+		t.Fatalf("expected design to move after sync, got %v-%v", design.StartedAt, design.EndedAt)
+	}
+
+	design, sync = pair()
+	if err := design.ResolveOverlap(sync, OverlapMerge); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if !design.StartedAt.Equal(at(9, 0)) || !design.EndedAt.Equal(at(10, 30)) || design.DurationMs != (90*time.Minute).Milliseconds() {
+		t.Fatalf("expected the merged entry to cover both, got %v-%v", design.StartedAt, design.EndedAt)
+	}
+	if design.Content != "design #ui; sync #team" || !slices.Equal(design.Tags, []string{"team", "ui"}) {
+		t.Fatalf("unexpected merged content %q and tags %v", design.Content, design.Tags)
+	}
+	if conflicts, err := design.Overlapping(); err != nil || len(conflicts) != 0 {
+		t.Fatalf("expected the merged entry to stand alone, got %v, %v", conflicts, err)
+	}
+
+	// A single undo restores both entries.
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("undo merge: %v", err)
+	}
+	if conflicts, err := design.Overlapping(); err != nil || len(conflicts) != 1 || conflicts[0].ID != sync.ID {
+		t.Fatalf("expected undo to bring the overlap back, got %v, %v", conflicts, err)
+	}
+}
@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// ErrPersonNotFound is returned when no person matches an id or email.
+var ErrPersonNotFound = errors.New("person not found")
+
+// Person is someone who logs time in a shared database. Entries point at the
+// person who created them through CreatorID.
+type Person struct {
+	ID    int64
+	Name  string
+	Email string
+}
+
+func newPersonFromModel(model sqlc.Person) *Person {
+	return &Person{ID: model.ID, Name: model.Name, Email: model.Email}
+}
+
+func (p *Person) String() string {
+	return fmt.Sprintf("%s <%s>", p.Name, p.Email)
+}
+
+// SetIdentity records the person using this database, creating them in the
+// people table or updating their name, and stamps them onto every entry created
+// from now on. An empty name defaults to the part of the email before the @.
+func (d *Database) SetIdentity(name, email string) (*Person, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("identity needs an email address, got %q", email)
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	record, err := d.queries.UpsertPerson(context.Background(), sqlc.UpsertPersonParams{Email: email, Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("save person: %w", err)
+	}
+	d.person = newPersonFromModel(record)
+	return d.person, nil
+}
+
+// ClearIdentity stops stamping new entries with a creator.
+func (d *Database) ClearIdentity() {
+	if d != nil {
+		d.person = nil
+	}
+}
+
+// CurrentPerson returns the identity set with SetIdentity, or nil when new
+// entries are not attributed to anyone.
+func (d *Database) CurrentPerson() *Person {
+	if d == nil {
+		return nil
+	}
+	return d.person
+}
+
+// People lists everyone who has used the database, by name.
+func (d *Database) People() ([]*Person, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	records, err := d.queries.ListPeople(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("list people: %w", err)
+	}
+	people := make([]*Person, 0, len(records))
+	for _, record := range records {
+		people = append(people, newPersonFromModel(record))
+	}
+	return people, nil
+}
+
+// Person loads a person by id.
+func (d *Database) Person(id int64) (*Person, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	record, err := d.queries.GetPerson(context.Background(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPersonNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load person: %w", err)
+	}
+	return newPersonFromModel(record), nil
+}
+
+// SetCreator attributes the entry to person, or to nobody when person is nil.
+// The change can be undone like any other edit.
+func (e *Entry) SetCreator(ctx context.Context, person *Person) error {
+	if e == nil {
+		return errors.New("entry is nil")
+	}
+	previous := e.CreatorID
+	if person == nil {
+		e.CreatorID = nil
+	} else {
+		id := person.ID
+		e.CreatorID = &id
+	}
+	if err := e.Update(ctx); err != nil {
+		e.CreatorID = previous
+		return err
+	}
+	return nil
+}
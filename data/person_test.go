@@ -0,0 +1,72 @@
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIdentityStampsNewEntries(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Shared")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	before, err := project.CreateEntryWithDuration("before anyone", time.Hour, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if before.CreatorID != nil || db.CurrentPerson() != nil {
+		t.Fatalf("expected no identity yet, got creator %v", before.CreatorID)
+	}
+
+	if _, err := db.SetIdentity("Nobody", "not-an-email"); err == nil {
+		t.Fatalf("expected an identity without an email to fail")
+	}
+	grace, err := db.SetIdentity("", "grace@example.com")
+	if err != nil {
+		t.Fatalf("set identity: %v", err)
+	}
+	if grace.Name != "grace" {
+		t.Fatalf("expected the name to default to the email's user, got %q", grace.Name)
+	}
+	ada, err := db.SetIdentity("Ada Lovelace", "Ada@Example.com")
+	if err != nil {
+		t.Fatalf("set identity: %v", err)
+	}
+	again, err := db.SetIdentity("Ada", "ada@example.com")
+	if err != nil || again.ID != ada.ID || again.Name != "Ada" {
+		t.Fatalf("expected the same person to be renamed, got %+v, %v", again, err)
+	}
+
+	entry, err := project.CreateEntryWithDuration("after", 30*time.Minute, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if entry.CreatorID == nil || *entry.CreatorID != ada.ID {
+		t.Fatalf("expected the entry to be credited to Ada, got %v", entry.CreatorID)
+	}
+
+	people, err := db.People()
+	if err != nil || len(people) != 2 || people[0].Name != "Ada" || people[1].Name != "grace" {
+		t.Fatalf("expected Ada and grace, got %v, %v", people, err)
+	}
+
+	if err := before.SetCreator(t.Context(), grace); err != nil {
+		t.Fatalf("set creator: %v", err)
+	}
+	reloaded, err := db.Entry(before.ID)
+	if err != nil || reloaded.CreatorID == nil || *reloaded.CreatorID != grace.ID {
+		t.Fatalf("expected the entry to be credited to grace, got %+v, %v", reloaded, err)
+	}
+	if err := before.SetCreator(t.Context(), nil); err != nil {
+		t.Fatalf("clear creator: %v", err)
+	}
+	if reloaded, err := db.Entry(before.ID); err != nil || reloaded.CreatorID != nil {
+		t.Fatalf("expected the creator to be cleared, got %+v, %v", reloaded, err)
+	}
+
+	if _, err := db.Person(9999); !errors.Is(err, ErrPersonNotFound) {
+		t.Fatalf("expected ErrPersonNotFound, got %v", err)
+	}
+}
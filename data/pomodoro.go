@@ -0,0 +1,79 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// PomodoroTag is added to the entry of every completed pomodoro.
+const PomodoroTag = "pomodoro"
+
+// PomodoroSettings configures Pomodoro mode. Lengths left out take the
+// classic values: 25 minutes of work, 5 minute breaks, and a 15 minute break
+// after 4 pomodoros.
+type PomodoroSettings struct {
+	WorkMinutes       int `json:"work_minutes,omitempty"`
+	ShortBreakMinutes int `json:"short_break_minutes,omitempty"`
+	LongBreakMinutes  int `json:"long_break_minutes,omitempty"`
+	// Cycles is how many pomodoros a session has; the long break ends it.
+	Cycles int `json:"cycles,omitempty"`
+}
+
+// Pomodoro is PomodoroSettings checked and ready to use.
+type Pomodoro struct {
+	Work       time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+	Cycles     int
+}
+
+// DefaultPomodoro is the classic 25/5/15 rhythm of four pomodoros.
+var DefaultPomodoro = Pomodoro{
+	Work:       25 * time.Minute,
+	ShortBreak: 5 * time.Minute,
+	LongBreak:  15 * time.Minute,
+	Cycles:     4,
+}
+
+// Pomodoro checks the settings and returns the rhythm they describe.
+func (s PomodoroSettings) Pomodoro() (Pomodoro, error) {
+	if s.WorkMinutes < 0 || s.ShortBreakMinutes < 0 || s.LongBreakMinutes < 0 || s.Cycles < 0 {
+		return Pomodoro{}, fmt.Errorf("pomodoro: lengths and cycles cannot be negative")
+	}
+	p := DefaultPomodoro
+	if s.WorkMinutes > 0 {
+		p.Work = time.Duration(s.WorkMinutes) * time.Minute
+	}
+	if s.ShortBreakMinutes > 0 {
+		p.ShortBreak = time.Duration(s.ShortBreakMinutes) * time.Minute
+	}
+	if s.LongBreakMinutes > 0 {
+		p.LongBreak = time.Duration(s.LongBreakMinutes) * time.Minute
+	}
+	if s.Cycles > 0 {
+		p.Cycles = s.Cycles
+	}
+	return p, nil
+}
+
+// SetPomodoro sets the Pomodoro rhythm; nil restores DefaultPomodoro.
+func (d *Database) SetPomodoro(settings *PomodoroSettings) error {
+	if settings == nil {
+		d.pomodoro = nil
+		return nil
+	}
+	p, err := settings.Pomodoro()
+	if err != nil {
+		return err
+	}
+	d.pomodoro = &p
+	return nil
+}
+
+// Pomodoro returns the Pomodoro rhythm.
+func (d *Database) Pomodoro() Pomodoro {
+	if d == nil || d.pomodoro == nil {
+		return DefaultPomodoro
+	}
+	return *d.pomodoro
+}
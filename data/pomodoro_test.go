@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+func TestPomodoroSettings(t *testing.T) {
+	p, err := PomodoroSettings{WorkMinutes: 50, Cycles: 2}.Pomodoro()
+	if err != nil {
+		t.Fatalf("pomodoro: %v", err)
+	}
+	want := Pomodoro{Work: 50 * time.Minute, ShortBreak: 5 * time.Minute, LongBreak: 15 * time.Minute, Cycles: 2}
+	if p != want {
+		t.Fatalf("expected %+v, got %+v", want, p)
+	}
+	if _, err := (PomodoroSettings{ShortBreakMinutes: -1}).Pomodoro(); err == nil {
+		t.Fatal("expected negative minutes to be rejected")
+	}
+
+	db := openTempDatabase(t)
+	if db.Pomodoro() != DefaultPomodoro {
+		t.Fatalf("expected the default rhythm, got %+v", db.Pomodoro())
+	}
+	if err := db.SetPomodoro(&PomodoroSettings{LongBreakMinutes: 30}); err != nil {
+		t.Fatalf("set pomodoro: %v", err)
+	}
+	if db.Pomodoro().LongBreak != 30*time.Minute {
+		t.Fatalf("expected a 30 minute long break, got %+v", db.Pomodoro())
+	}
+	if err := db.SetPomodoro(&PomodoroSettings{Cycles: -4}); err == nil {
+		t.Fatal("expected negative cycles to be rejected")
+	}
+}
+
+func TestStopTimerAt(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Focus")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	start := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	startAt := func() {
+		t.Helper()
+		if _, err := db.queries.UpsertTimer(context.Background(), sqlc.UpsertTimerParams{ProjectID: project.ID, StartedAt: start.Unix()}); err != nil {
+			t.Fatalf("start timer: %v", err)
+		}
+	}
+
+	startAt()
+	entry, err := project.StopTimerAt("Pomodoro 1/4 #pomodoro", true, EntryTypeWork, start.Add(25*time.Minute))
+	if err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	if time.Duration(entry.GetDuration()) != 25*time.Minute || !entry.StartedAt.Equal(start) || !slices.Contains(entry.Tags, PomodoroTag) {
+		t.Fatalf("expected a 25 minute pomodoro from %v, got %v from %v tagged %v", start, time.Duration(entry.GetDuration()), entry.StartedAt, entry.Tags)
+	}
+	if onClock, _ := project.OnClock(); onClock {
+		t.Fatal("expected the timer to be stopped")
+	}
+
+	startAt()
+	entry, err = project.StopTimerAt("", true, EntryTypeWork, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	if d := time.Duration(entry.GetDuration()); d < time.Hour || d > time.Hour+time.Minute {
+		t.Fatalf("expected an end in the future to be kept to now, got %v", d)
+	}
+
+	startAt()
+	entry, err = project.StopTimerAt("", true, EntryTypeWork, start.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	if time.Duration(entry.GetDuration()) != 0 {
+		t.Fatalf("expected an end before the start to be kept to the start, got %v", time.Duration(entry.GetDuration()))
+	}
+}
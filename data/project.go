@@ -18,8 +18,10 @@ type Project struct {
 	Company   *string
 	IsHidden  bool
 	Position  int64
+	ParentID  *int64
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt *time.Time
 }
 
 func newProjectFromModel(db *Database, model sqlc.Project) *Project {
@@ -35,8 +37,10 @@ func newProjectFromModel(db *Database, model sqlc.Project) *Project {
 		Company:   company,
 		IsHidden:  model.IsHidden == 1,
 		Position:  model.Position,
+		ParentID:  nullInt64Ptr(model.ParentID),
 		CreatedAt: time.Unix(model.CreatedAt, 0).UTC(),
 		UpdatedAt: time.Unix(model.UpdatedAt, 0).UTC(),
+		DeletedAt: unixTimePtr(model.DeletedAt),
 	}
 }
 
@@ -94,34 +98,59 @@ func (p *Project) StartTimer() error {
 		return errors.New("project not initialized")
 	}
 	ctx := context.Background()
-	_, err := p.db.queries.UpsertTimer(ctx, sqlc.UpsertTimerParams{
-		ProjectID: p.ID,
-		StartedAt: time.Now().UTC().Unix(),
+	return p.db.withTx(ctx, func(q *sqlc.Queries) error {
+		var before *timerSnapshot
+		previous, err := q.GetTimer(ctx, p.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("fetch timer: %w", err)
+		}
+		if err == nil {
+			before = &timerSnapshot{ProjectID: previous.ProjectID, StartedAt: previous.StartedAt}
+		}
+		record, err := q.UpsertTimer(ctx, sqlc.UpsertTimerParams{
+			ProjectID: p.ID,
+			StartedAt: time.Now().UTC().Unix(),
+		})
+		if err != nil {
+			return fmt.Errorf("start timer: %w", err)
+		}
+		action := ChangeCreate
+		if before != nil {
+			action = ChangeUpdate
+		}
+		after := &timerSnapshot{ProjectID: record.ProjectID, StartedAt: record.StartedAt}
+		return recordTimerChange(ctx, q, p.ID, action, before, after)
 	})
-	if err != nil {
-		return fmt.Errorf("start timer: %w", err)
-	}
-	return nil
 }
 
-func (p *Project) StopTimer(content string, billable bool) error {
+// StopTimer turns the running timer into an entry and returns it.
+func (p *Project) StopTimer(content string, billable bool, entryType EntryType) (*Entry, error) {
+	return p.StopTimerAt(content, billable, entryType, time.Now())
+}
+
+// StopTimerAt stops the timer as if at end, which is kept between the timer's
+// start and now, and records the entry.
+func (p *Project) StopTimerAt(content string, billable bool, entryType EntryType, end time.Time) (*Entry, error) {
 	if p == nil || p.db == nil {
-		return errors.New("project not initialized")
+		return nil, errors.New("project not initialized")
 	}
 
 	timer, err := p.currentTimer()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if timer == nil {
-		return errors.New("no running timer for project")
+		return nil, errors.New("no running timer for project")
 	}
 
 	start := timer.StartedAt
 	if start.IsZero() {
 		start = time.Now().UTC()
 	}
-	end := time.Now().UTC()
+	end = end.UTC()
+	if now := time.Now().UTC(); end.After(now) {
+		end = now
+	}
 	if end.Before(start) {
 		end = start
 	}
@@ -135,36 +164,54 @@ func (p *Project) StopTimer(content string, billable bool) error {
 		DurationMs: duration.Milliseconds(),
 		StartedAt:  &start,
 		EndedAt:    &end,
-		Type:       EntryTypeWork,
+		Type:       entryType,
 		Billable:   billable,
 		Tags:       extractTags(content),
 	}
 
-	if err := entry.Save(context.Background()); err != nil {
-		return fmt.Errorf("persist timer entry: %w", err)
-	}
-
-	if err := p.db.queries.DeleteTimer(context.Background(), p.ID); err != nil {
-		return fmt.Errorf("clear timer: %w", err)
+	ctx := context.Background()
+	err = p.db.withTx(ctx, func(q *sqlc.Queries) error {
+		if err := entry.insert(ctx, q); err != nil {
+			return fmt.Errorf("persist timer entry: %w", err)
+		}
+		if err := q.DeleteTimer(ctx, p.ID); err != nil {
+			return fmt.Errorf("clear timer: %w", err)
+		}
+		before := &timerSnapshot{ProjectID: p.ID, StartedAt: timer.StartedAt.Unix()}
+		return recordTimerChange(ctx, q, p.ID, ChangeDelete, before, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return entry, nil
+}
 
-	fmt.Printf("%.2f mins\n", entry.Minutes())
-	return nil
+// CreateEntryWithDuration records an entry of the given length that ends now.
+func (p *Project) CreateEntryWithDuration(content string, duration time.Duration, billable bool, entryType EntryType) (*Entry, error) {
+	return p.CreateEntryAt(content, time.Now().Add(-duration), duration, billable, entryType)
 }
 
-func (p *Project) CreateEntryWithDuration(content string, duration time.Duration, billable bool) (*Entry, error) {
+// CreateEntryAt records an entry of the given length that started at start, so
+// time can be logged on the day it was worked.
+func (p *Project) CreateEntryAt(content string, start time.Time, duration time.Duration, billable bool, entryType EntryType) (*Entry, error) {
+	if duration <= 0 {
+		return nil, errors.New("duration must be longer than zero")
+	}
+	if start.After(time.Now()) {
+		return nil, errors.New("entries cannot start in the future")
+	}
 	entry := &Entry{
 		db:         p.db,
 		Project:    p,
 		ProjectID:  p.ID,
 		Content:    strings.TrimSpace(content),
 		DurationMs: duration.Milliseconds(),
-		Type:       EntryTypeWork,
+		Type:       entryType,
 		Billable:   billable,
 		Tags:       extractTags(content),
 	}
-	end := time.Now().UTC()
-	start := end.Add(-duration)
+	start = start.UTC()
+	end := start.Add(duration)
 	entry.StartedAt = &start
 	entry.EndedAt = &end
 
@@ -174,13 +221,13 @@ func (p *Project) CreateEntryWithDuration(content string, duration time.Duration
 	return entry, nil
 }
 
-func (p *Project) CreateEntry(content string, billable bool) (*Entry, error) {
+func (p *Project) CreateEntry(content string, billable bool, entryType EntryType) (*Entry, error) {
 	entry := &Entry{
 		db:        p.db,
 		Project:   p,
 		ProjectID: p.ID,
 		Content:   strings.TrimSpace(content),
-		Type:      EntryTypeWork,
+		Type:      entryType,
 		Billable:  billable,
 		Tags:      extractTags(content),
 	}
@@ -194,6 +241,20 @@ func (p *Project) CreateEntry(content string, billable bool) (*Entry, error) {
 	return entry, nil
 }
 
+// LastEntrySettings returns the billable flag and type of the project's
+// latest entry, for stopping a timer without asking; a project without
+// entries gets billable work.
+func (p *Project) LastEntrySettings() (bool, EntryType) {
+	if p == nil || p.db == nil {
+		return true, EntryTypeWork
+	}
+	entries, err := p.entries()
+	if err != nil || len(entries) == 0 {
+		return true, EntryTypeWork
+	}
+	return entries[0].Billable, entries[0].Type
+}
+
 func (p *Project) OnClock() (bool, *Timer) {
 	timer, err := p.currentTimer()
 	if err != nil || timer == nil {
@@ -218,31 +279,117 @@ func (p *Project) Delete() error {
 	if p == nil || p.db == nil {
 		return errors.New("project not initialized")
 	}
-	if err := p.db.queries.DeleteProject(context.Background(), p.ID); err != nil {
-		return fmt.Errorf("delete project: %w", err)
-	}
-	return nil
+	ctx := context.Background()
+	return p.db.withTx(ctx, func(q *sqlc.Queries) error {
+		snapshot, err := snapshotProject(ctx, q, p.ID)
+		if err != nil {
+			return err
+		}
+		children, err := q.ListChildProjects(ctx, sql.NullInt64{Int64: p.ID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("list sub-projects: %w", err)
+		}
+		for _, child := range children {
+			if !child.DeletedAt.Valid {
+				return fmt.Errorf("project %q has sub-projects; delete or move them first", snapshot.Name)
+			}
+		}
+		if err := q.SoftDeleteProject(ctx, p.ID); err != nil {
+			return fmt.Errorf("delete project: %w", err)
+		}
+		if err := q.DeleteTimer(ctx, p.ID); err != nil {
+			return fmt.Errorf("clear timer: %w", err)
+		}
+		if snapshot.TimerStartedAt != nil {
+			before := &timerSnapshot{ProjectID: p.ID, StartedAt: *snapshot.TimerStartedAt}
+			if err := recordTimerChange(ctx, q, p.ID, ChangeDelete, before, nil); err != nil {
+				return err
+			}
+		}
+		if err := recordProjectChange(ctx, q, p.ID, ChangeDelete, &snapshot); err != nil {
+			return err
+		}
+		description := fmt.Sprintf("delete project '%s'", snapshot.Name)
+		return pushUndo(ctx, q, UndoProjectDelete, description, snapshot)
+	})
 }
 
+// Rename changes the project's name. A path such as "Acme/Website" also moves
+// the project under Acme, and a leading separator ("/Website") moves it to the top level.
 func (p *Project) Rename(newName string) error {
 	if p == nil || p.db == nil {
 		return errors.New("project not initialized")
 	}
 	newName = strings.TrimSpace(newName)
-	if newName == "" {
-		return errors.New("project name cannot be empty")
+	names, err := splitProjectPath(newName)
+	if err != nil {
+		return err
 	}
-	if strings.EqualFold(newName, p.Name) {
+	name := names[len(names)-1]
+	moving := strings.Contains(newName, ProjectPathSeparator)
+	if !moving && strings.EqualFold(name, p.Name) {
 		return nil
 	}
-	record, err := p.db.queries.UpdateProject(context.Background(), sqlc.UpdateProjectParams{
-		ID:       p.ID,
-		Name:     newName,
-		Company:  optionalString(p.Company),
-		IsHidden: boolToInt(p.IsHidden),
+
+	ctx := context.Background()
+	var record sqlc.Project
+	err = p.db.withTx(ctx, func(q *sqlc.Queries) error {
+		previous, err := snapshotProject(ctx, q, p.ID)
+		if err != nil {
+			return err
+		}
+		parentID := previous.ParentID
+		if moving {
+			parentID = nil
+			if len(names) > 1 {
+				parent, err := findProjectPath(ctx, q, names[:len(names)-1])
+				if errors.Is(err, ErrProjectNotFound) {
+					return fmt.Errorf("%w; a %q in the new name moves the project under that parent", err, ProjectPathSeparator)
+				}
+				if err != nil {
+					return err
+				}
+				if parent.ID == p.ID {
+					return errors.New("a project cannot be its own parent")
+				}
+				below, err := isDescendant(ctx, q, p.ID, parent.ID)
+				if err != nil {
+					return err
+				}
+				if below {
+					return fmt.Errorf("cannot move project %q under its own sub-project %q", previous.Name, parent.Name)
+				}
+				parentID = &parent.ID
+			}
+		}
+		if strings.EqualFold(name, previous.Name) && optionalInt64(parentID) == optionalInt64(previous.ParentID) {
+			return nil
+		}
+		if err := checkProjectName(ctx, q, parentID, name, p.ID); err != nil {
+			return err
+		}
+
+		record, err = q.UpdateProject(ctx, sqlc.UpdateProjectParams{
+			ID:       p.ID,
+			Name:     name,
+			Company:  optionalString(p.Company),
+			IsHidden: boolToInt(p.IsHidden),
+			ParentID: optionalInt64(parentID),
+		})
+		if err != nil {
+			return fmt.Errorf("rename project: %w", err)
+		}
+		if err := recordProjectChange(ctx, q, p.ID, ChangeUpdate, &previous); err != nil {
+			return err
+		}
+		description := fmt.Sprintf("rename project '%s' to '%s'", previous.Name, newName)
+		return pushUndo(ctx, q, UndoProjectRename, description, previous)
 	})
 	if err != nil {
-		return fmt.Errorf("rename project: %w", err)
+		return err
+	}
+	if record.ID == 0 {
+		return nil
 	}
 	p.Name = record.Name
 	if record.Company.Valid {
@@ -253,10 +400,19 @@ func (p *Project) Rename(newName string) error {
 	}
 	p.IsHidden = record.IsHidden == 1
 	p.Position = record.Position
+	p.ParentID = nullInt64Ptr(record.ParentID)
 	p.UpdatedAt = time.Unix(record.UpdatedAt, 0).UTC()
 	return nil
 }
 
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	v := value.String
+	return &v
+}
+
 func optionalString(value *string) sql.NullString {
 	if value == nil {
 		return sql.NullString{}
@@ -281,22 +437,78 @@ func (d *Database) Projects() []*Project {
 	return projects
 }
 
+// CreateProject adds a project. A path such as "Acme/Website" creates Website
+// under Acme, creating any missing parent projects along the way.
 func (d *Database) CreateProject(name string) (*Project, error) {
 	if d == nil {
 		return nil, errors.New("database not initialized")
 	}
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return nil, errors.New("project name cannot be empty")
+	names, err := splitProjectPath(name)
+	if err != nil {
+		return nil, err
 	}
 
-	record, err := d.queries.CreateProject(context.Background(), sqlc.CreateProjectParams{
-		Name:     name,
-		Company:  sql.NullString{},
-		IsHidden: 0,
+	ctx := context.Background()
+	var record sqlc.Project
+	err = d.withTx(ctx, func(q *sqlc.Queries) error {
+		if existing, ok, err := findNamedProject(ctx, q, name); err != nil || ok {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("project %q already exists", existing.Name)
+		}
+		var parentID *int64
+		for i, segment := range names {
+			leaf := i == len(names)-1
+			if !leaf {
+				existing, err := q.GetProjectByParentAndName(ctx, sqlc.GetProjectByParentAndNameParams{
+					ParentID: optionalInt64(parentID),
+					Name:     segment,
+				})
+				if err == nil {
+					id := existing.ID
+					parentID = &id
+					continue
+				}
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("find project %q: %w", segment, err)
+				}
+			}
+			if err := checkProjectName(ctx, q, parentID, segment, 0); err != nil {
+				return err
+			}
+			created, err := q.CreateProject(ctx, sqlc.CreateProjectParams{
+				Name:     segment,
+				Company:  sql.NullString{},
+				IsHidden: 0,
+				ParentID: optionalInt64(parentID),
+			})
+			if err != nil {
+				return fmt.Errorf("create project: %w", err)
+			}
+			if err := recordProjectChange(ctx, q, created.ID, ChangeCreate, nil); err != nil {
+				return err
+			}
+			id := created.ID
+			parentID = &id
+			record = created
+		}
+		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("create project: %w", err)
+		return nil, err
+	}
+	return newProjectFromModel(d, record), nil
+}
+
+// Project loads a project by id, including projects in the trash.
+func (d *Database) Project(id int64) (*Project, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	record, err := d.queries.GetProject(context.Background(), id)
+	if err != nil {
+		return nil, fmt.Errorf("load project %d: %w", id, err)
 	}
 	return newProjectFromModel(d, record), nil
 }
@@ -24,7 +24,7 @@ func TestProjectGettersNil(t *testing.T) {
 	if err := project.StartTimer(); err == nil {
 		t.Fatalf("expected start timer on nil project to error")
 	}
-	if err := project.StopTimer("content", false); err == nil {
+	if _, err := project.StopTimer("content", false, EntryTypeWork); err == nil {
 		t.Fatalf("expected stop timer on nil project to error")
 	}
 }
@@ -56,7 +56,7 @@ func TestProjectEntriesAndTimerFlow(t *testing.T) {
 	}
 
 	content := "  Working on timers #Focus "
-	if err := project.StopTimer(content, true); err != nil {
+	if _, err := project.StopTimer(content, true, EntryTypeChore); err != nil {
 		t.Fatalf("stop timer: %v", err)
 	}
 
@@ -80,6 +80,9 @@ func TestProjectEntriesAndTimerFlow(t *testing.T) {
 	if !entry.Billable {
 		t.Fatalf("expected entry to be billable")
 	}
+	if entry.Type != EntryTypeChore {
+		t.Fatalf("expected a chore entry, got %q", entry.Type)
+	}
 	if len(entry.Tags) != 1 || entry.Tags[0] != "Focus" {
 		t.Fatalf("expected entry tags to contain Focus, got %v", entry.Tags)
 	}
@@ -96,7 +99,7 @@ func TestProjectStopTimerWithoutActive(t *testing.T) {
 		t.Fatalf("create project: %v", err)
 	}
 
-	err = project.StopTimer("content", false)
+	_, err = project.StopTimer("content", false, EntryTypeWork)
 	if err == nil {
 		t.Fatalf("expected error when stopping timer without active timer")
 	}
@@ -113,7 +116,7 @@ func TestProjectCreateEntryVariants(t *testing.T) {
 		t.Fatalf("create project: %v", err)
 	}
 
-	entry, err := project.CreateEntry("  Writing docs #Docs  ", true)
+	entry, err := project.CreateEntry("  Writing docs #Docs  ", true, EntryTypeWork)
 	if err != nil {
 		t.Fatalf("create entry: %v", err)
 	}
@@ -134,7 +137,7 @@ func TestProjectCreateEntryVariants(t *testing.T) {
 	}
 
 	duration := 45 * time.Second
-	entryWithDuration, err := project.CreateEntryWithDuration("Meeting review #Review", duration, false)
+	entryWithDuration, err := project.CreateEntryWithDuration("Meeting review #Review", duration, false, EntryTypeFun)
 	if err != nil {
 		t.Fatalf("create entry with duration: %v", err)
 	}
@@ -153,6 +156,9 @@ func TestProjectCreateEntryVariants(t *testing.T) {
 	if entryWithDuration.Billable {
 		t.Fatalf("expected entry to be non-billable")
 	}
+	if entryWithDuration.Type != EntryTypeFun {
+		t.Fatalf("expected a fun entry, got %q", entryWithDuration.Type)
+	}
 	if len(entryWithDuration.Tags) != 1 || entryWithDuration.Tags[0] != "Review" {
 		t.Fatalf("expected Review tag, got %v", entryWithDuration.Tags)
 	}
@@ -241,3 +247,18 @@ func TestProjectRenameNoOp(t *testing.T) {
 		t.Fatalf("expected name to be stored trimmed")
 	}
 }
+
+func TestEntryTypeCycle(t *testing.T) {
+	seen := map[EntryType]bool{}
+	typ := EntryTypeWork
+	for range EntryTypes {
+		seen[typ] = true
+		typ = typ.Next()
+	}
+	if typ != EntryTypeWork || len(seen) != len(EntryTypes) {
+		t.Fatalf("expected Next to visit every type once, saw %v", seen)
+	}
+	if EntryTypeFun.Label() != "Fun" || EntryType("").Label() != "Work" {
+		t.Fatalf("unexpected labels %q, %q", EntryTypeFun.Label(), EntryType("").Label())
+	}
+}
@@ -0,0 +1,256 @@
+package data
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Reminder defaults, used when the settings leave them out.
+const (
+	defaultReminderAfter = 20 * time.Minute
+	defaultWorkdayStart  = 9 * time.Hour
+	defaultWorkdayEnd    = 17 * time.Hour
+)
+
+// reminderDays maps the day keys of ReminderSettings.Days to weekdays.
+var reminderDays = map[string][]time.Weekday{
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":  {time.Saturday, time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"sun":      {time.Sunday},
+}
+
+// ReminderSettings configures the reminders to start a timer that samay
+// gives when none has run for a while.
+type ReminderSettings struct {
+	// AfterMinutes of no timer running, a reminder is due. Defaults to 20.
+	AfterMinutes int `json:"after_minutes,omitempty"`
+	// EveryMinutes repeats the reminder while still no timer runs. Defaults
+	// to AfterMinutes.
+	EveryMinutes int `json:"every_minutes,omitempty"`
+	// Days maps "mon" to "sun", "weekdays", "weekend" or "daily" to the
+	// hours reminders are given in, such as ["09:00-12:30", "13:30-17:30"].
+	// A single day beats a group, and a day with no hours gets no reminders.
+	// Without Days, reminders are given 09:00-17:00 on weekdays.
+	Days map[string][]string `json:"days,omitempty"`
+	// QuietHours silence reminders every day, such as "12:00-13:00". A range
+	// may run past midnight.
+	QuietHours []string `json:"quiet_hours,omitempty"`
+	// Command is run for each reminder, such as ["notify-send", "samay",
+	// "{message}"]. {message} and {minutes} in it are filled in.
+	Command []string `json:"command,omitempty"`
+	// Bell rings the terminal bell in the interactive UI. Defaults to true.
+	Bell *bool `json:"bell,omitempty"`
+}
+
+// ClockRange is a span of the day, as offsets from midnight. From is after
+// To for a range that runs past midnight.
+type ClockRange struct {
+	From, To time.Duration
+}
+
+// Contains reports whether the time of day of t lies in the range.
+func (r ClockRange) Contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if r.From <= r.To {
+		return offset >= r.From && offset < r.To
+	}
+	return offset >= r.From || offset < r.To
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second
+}
+
+// ReminderSchedule is ReminderSettings checked and ready to use.
+type ReminderSchedule struct {
+	After   time.Duration
+	Every   time.Duration
+	Days    [7][]ClockRange // indexed by time.Weekday
+	Quiet   []ClockRange
+	Command []string
+	Bell    bool
+}
+
+// Schedule checks the settings and returns the schedule they describe.
+func (s ReminderSettings) Schedule() (ReminderSchedule, error) {
+	if s.AfterMinutes < 0 || s.EveryMinutes < 0 {
+		return ReminderSchedule{}, fmt.Errorf("reminders: minutes cannot be negative")
+	}
+	schedule := ReminderSchedule{
+		After:   defaultReminderAfter,
+		Command: s.Command,
+		Bell:    s.Bell == nil || *s.Bell,
+	}
+	if s.AfterMinutes > 0 {
+		schedule.After = time.Duration(s.AfterMinutes) * time.Minute
+	}
+	schedule.Every = schedule.After
+	if s.EveryMinutes > 0 {
+		schedule.Every = time.Duration(s.EveryMinutes) * time.Minute
+	}
+
+	days := s.Days
+	if days == nil {
+		days = map[string][]string{"weekdays": {clockRangeString(defaultWorkdayStart, defaultWorkdayEnd)}}
+	}
+	// The widest keys go first so narrower ones override them: daily, then
+	// weekdays and weekend, then single days.
+	keys := slices.Collect(maps.Keys(days))
+	for _, key := range keys {
+		if _, ok := reminderDays[strings.ToLower(key)]; !ok {
+			return ReminderSchedule{}, fmt.Errorf("reminders: unknown day %q", key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if n := cmp.Compare(len(reminderDays[strings.ToLower(b)]), len(reminderDays[strings.ToLower(a)])); n != 0 {
+			return n
+		}
+		return strings.Compare(a, b)
+	})
+	for _, key := range keys {
+		parsed, err := parseClockRanges(days[key])
+		if err != nil {
+			return ReminderSchedule{}, err
+		}
+		for _, r := range parsed {
+			if r.From > r.To {
+				return ReminderSchedule{}, fmt.Errorf("reminders: hours of %s cannot run past midnight", key)
+			}
+		}
+		for _, day := range reminderDays[strings.ToLower(key)] {
+			schedule.Days[day] = parsed
+		}
+	}
+	quiet, err := parseClockRanges(s.QuietHours)
+	if err != nil {
+		return ReminderSchedule{}, err
+	}
+	schedule.Quiet = quiet
+	return schedule, nil
+}
+
+func clockRangeString(from, to time.Duration) string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", int(from.Hours()), int(from.Minutes())%60, int(to.Hours()), int(to.Minutes())%60)
+}
+
+func parseClockRanges(values []string) ([]ClockRange, error) {
+	ranges := make([]ClockRange, 0, len(values))
+	for _, value := range values {
+		from, to, ok := strings.Cut(value, "-")
+		if !ok {
+			return nil, fmt.Errorf("reminders: %q is not a range such as 09:00-17:00", value)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, ClockRange{From: start, To: end})
+	}
+	return ranges, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("reminders: %q is not a time such as 09:30", value)
+	}
+	return sinceMidnight(t), nil
+}
+
+// WorkingSince returns when the stretch of reminder hours containing now
+// began, or false when now is outside them or in quiet hours.
+func (s ReminderSchedule) WorkingSince(now time.Time) (time.Time, bool) {
+	for _, quiet := range s.Quiet {
+		if quiet.Contains(now) {
+			return time.Time{}, false
+		}
+	}
+	for _, r := range s.Days[now.Weekday()] {
+		if r.Contains(now) {
+			year, month, day := now.Date()
+			return time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Add(r.From), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Due reports whether a reminder should be given at now, when no timer has
+// run since idleSince and the last reminder was given at lastReminded.
+// Idle time before the reminder hours began does not count.
+func (s ReminderSchedule) Due(now, idleSince, lastReminded time.Time) bool {
+	start, ok := s.WorkingSince(now)
+	if !ok {
+		return false
+	}
+	if idleSince.After(start) {
+		start = idleSince
+	}
+	if now.Sub(start) < s.After {
+		return false
+	}
+	return !lastReminded.After(start) || now.Sub(lastReminded) >= s.Every
+}
+
+// SetReminders turns reminders on with settings, or off with nil.
+func (d *Database) SetReminders(settings *ReminderSettings) error {
+	if settings == nil {
+		d.reminders = nil
+		return nil
+	}
+	schedule, err := settings.Schedule()
+	if err != nil {
+		return err
+	}
+	d.reminders = &schedule
+	return nil
+}
+
+// Reminders returns the reminder schedule, or false when reminders are off.
+func (d *Database) Reminders() (ReminderSchedule, bool) {
+	if d == nil || d.reminders == nil {
+		return ReminderSchedule{}, false
+	}
+	return *d.reminders, true
+}
+
+// IdleSince returns when the most recent entry ended, and false while a
+// timer is running. The time is zero when nothing was ever tracked.
+func (d *Database) IdleSince() (time.Time, bool, error) {
+	ctx := context.Background()
+	timers, err := d.queries.ListRunningTimers(ctx)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("list timers: %w", err)
+	}
+	if len(timers) > 0 {
+		return time.Time{}, false, nil
+	}
+	ended, err := d.queries.LatestEntryEnd(ctx)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("find the latest entry: %w", err)
+	}
+	if ended == 0 {
+		return time.Time{}, true, nil
+	}
+	return time.Unix(ended, 0).UTC(), true, nil
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReminderSchedule(t *testing.T) {
+	bell := false
+	schedule, err := ReminderSettings{
+		AfterMinutes: 30,
+		Days: map[string][]string{
+			"weekdays": {"09:00-12:30", "13:30-17:30"},
+			"fri":      {"09:00-13:00"},
+			"Sat":      {},
+		},
+		QuietHours: []string{"16:00-16:30", "23:00-01:00"},
+		Bell:       &bell,
+	}.Schedule()
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if schedule.After != 30*time.Minute || schedule.Every != 30*time.Minute || schedule.Bell {
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+
+	// 2025-06-02 is a Monday.
+	at := func(day int, clock string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", "2025-06-"+clock, time.Local)
+		if err != nil {
+			t.Fatalf("parse %s: %v", clock, err)
+		}
+		return parsed.AddDate(0, 0, day)
+	}
+	for _, tc := range []struct {
+		name                string
+		now, idle, reminded time.Time
+		want                bool
+	}{
+		{"idle since yesterday counts from 09:00", at(0, "02 09:29"), at(-1, "02 18:00"), time.Time{}, false},
+		{"half an hour into the day", at(0, "02 09:30"), at(-1, "02 18:00"), time.Time{}, true},
+		{"after the last entry ended", at(0, "02 10:40"), at(0, "02 10:15"), time.Time{}, false},
+		{"lunch is outside the hours", at(0, "02 13:00"), at(0, "02 11:00"), time.Time{}, false},
+		{"afternoon counts from 13:30", at(0, "02 14:00"), at(0, "02 11:00"), time.Time{}, true},
+		{"reminded recently", at(0, "02 14:10"), at(0, "02 11:00"), at(0, "02 14:00"), false},
+		{"reminded long enough ago", at(0, "02 14:30"), at(0, "02 11:00"), at(0, "02 14:00"), true},
+		{"reminded before the idle stretch", at(0, "02 11:00"), at(0, "02 10:30"), at(0, "02 10:20"), true},
+		{"quiet hours", at(0, "02 16:10"), at(0, "02 11:00"), time.Time{}, false},
+		{"friday's own hours", at(4, "02 14:00"), at(4, "02 09:00"), time.Time{}, false},
+		{"saturday has none", at(5, "02 11:00"), at(4, "02 09:00"), time.Time{}, false},
+		{"sunday was never set", at(6, "02 11:00"), at(4, "02 09:00"), time.Time{}, false},
+	} {
+		if got := schedule.Due(tc.now, tc.idle, tc.reminded); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	defaults, err := ReminderSettings{}.Schedule()
+	if err != nil {
+		t.Fatalf("default schedule: %v", err)
+	}
+	if !defaults.Bell || defaults.After != 20*time.Minute || len(defaults.Days[time.Tuesday]) != 1 || len(defaults.Days[time.Sunday]) != 0 {
+		t.Fatalf("unexpected defaults %+v", defaults)
+	}
+
+	for _, settings := range []ReminderSettings{
+		{Days: map[string][]string{"someday": {"09:00-17:00"}}},
+		{Days: map[string][]string{"mon": {"9-5"}}},
+		{Days: map[string][]string{"mon": {"22:00-02:00"}}},
+		{QuietHours: []string{"noon"}},
+		{AfterMinutes: -1},
+	} {
+		if _, err := settings.Schedule(); err == nil || !strings.HasPrefix(err.Error(), "reminders:") {
+			t.Errorf("expected %+v to be refused, got %v", settings, err)
+		}
+	}
+}
+
+func TestReminderDaysApplyWidestFirst(t *testing.T) {
+	settings := ReminderSettings{Days: map[string][]string{
+		"daily":    {"10:00-14:00"},
+		"weekdays": {"09:00-17:00"},
+		"weekend":  {},
+		"wed":      {"12:00-13:00"},
+	}}
+	// Map order changes from run to run, so try a few times.
+	for range 20 {
+		schedule, err := settings.Schedule()
+		if err != nil {
+			t.Fatalf("schedule: %v", err)
+		}
+		want := map[time.Weekday][]ClockRange{
+			time.Monday:    {{From: 9 * time.Hour, To: 17 * time.Hour}},
+			time.Wednesday: {{From: 12 * time.Hour, To: 13 * time.Hour}},
+			time.Saturday:  {},
+		}
+		for day, ranges := range want {
+			if !slices.Equal(schedule.Days[day], ranges) {
+				t.Fatalf("expected %v on %s, got %v", ranges, day, schedule.Days[day])
+			}
+		}
+	}
+}
+
+func TestIdleSince(t *testing.T) {
+	db := openTempDatabase(t)
+	if since, idle, err := db.IdleSince(); err != nil || !idle || !since.IsZero() {
+		t.Fatalf("expected an empty database to be idle since ever, got %v, %v, %v", since, idle, err)
+	}
+	project, err := db.CreateProject("Idle")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	if _, idle, err := db.IdleSince(); err != nil || idle {
+		t.Fatalf("expected a running timer to end idleness, got %v, %v", idle, err)
+	}
+	entry, err := project.StopTimer("done", true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	since, idle, err := db.IdleSince()
+	if err != nil || !idle || !since.Equal(entry.EndedAt.Truncate(time.Second)) {
+		t.Fatalf("expected idleness since %v, got %v, %v, %v", entry.EndedAt, since, idle, err)
+	}
+}
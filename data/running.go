@@ -0,0 +1,63 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// readOnlyBusyTimeout is how long ReadRunningTimers waits on a writer before
+// giving up; a prompt is better blank than slow.
+const readOnlyBusyTimeout = 100 * time.Millisecond
+
+// RunningTimer is a running timer as ReadRunningTimers sees it.
+type RunningTimer struct {
+	ProjectID int64     `json:"project_id"`
+	Project   string    `json:"project"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Elapsed returns how long the timer has been running at now.
+func (t RunningTimer) Elapsed(now time.Time) time.Duration {
+	return max(now.Sub(t.StartedAt), 0)
+}
+
+// ReadRunningTimers lists the running timers in the database at path, most
+// recently started first, over a short-lived read-only connection. Unlike
+// OpenDatabase it never creates or migrates anything, so it suits callers
+// that run often and must stay fast, such as shell prompts. A database that
+// does not exist yet has no timers.
+func ReadRunningTimers(ctx context.Context, path string) ([]RunningTimer, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	query := url.Values{}
+	query.Set("mode", "ro")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", readOnlyBusyTimeout.Milliseconds()))
+	sqlite, err := sql.Open("sqlite", sqliteDSN(path, query))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	defer sqlite.Close()
+	sqlite.SetMaxOpenConns(1)
+
+	rows, err := sqlc.New(sqlite).ListRunningTimers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read timers: %w", err)
+	}
+	timers := make([]RunningTimer, 0, len(rows))
+	for _, row := range rows {
+		timers = append(timers, RunningTimer{
+			ProjectID: row.ProjectID,
+			Project:   row.Path,
+			StartedAt: time.Unix(row.StartedAt, 0).UTC(),
+		})
+	}
+	return timers, nil
+}
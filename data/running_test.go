@@ -0,0 +1,71 @@
+package data
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadRunningTimers(t *testing.T) {
+	db := openTempDatabase(t)
+	nested, err := db.CreateProject("Acme/Website")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	gone, err := db.CreateProject("Gone")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if _, err := db.CreateProject("Idle"); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	for _, project := range []*Project{nested, gone} {
+		if err := project.StartTimer(); err != nil {
+			t.Fatalf("start timer: %v", err)
+		}
+	}
+	if err := gone.Delete(); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+
+	timers, err := ReadRunningTimers(context.Background(), db.Path())
+	if err != nil {
+		t.Fatalf("read running timers: %v", err)
+	}
+	if len(timers) != 1 || timers[0].Project != "Acme/Website" || timers[0].ProjectID != nested.ID {
+		t.Fatalf("unexpected timers %+v", timers)
+	}
+	if elapsed := timers[0].Elapsed(time.Now().Add(time.Hour)); elapsed < time.Hour {
+		t.Fatalf("expected at least an hour elapsed, got %v", elapsed)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	if timers, err := ReadRunningTimers(context.Background(), missing); err != nil || timers != nil {
+		t.Fatalf("expected no timers for a missing database, got %+v, %v", timers, err)
+	}
+}
+
+func TestReadRunningTimersEscapesThePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "50% #1?", "time.db")
+	db, err := open(path)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	project, err := db.CreateProject("Odd Path")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+
+	timers, err := ReadRunningTimers(context.Background(), path)
+	if err != nil {
+		t.Fatalf("read running timers: %v", err)
+	}
+	if len(timers) != 1 || timers[0].Project != "Odd Path" {
+		t.Fatalf("expected the timer in %q, got %+v", path, timers)
+	}
+}
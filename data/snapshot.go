@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// entrySnapshot captures an entry row and its tags so it can be written back verbatim.
+type entrySnapshot struct {
+	ID         string   `json:"id"`
+	ProjectID  int64    `json:"project_id"`
+	CreatorID  *int64   `json:"creator_id,omitempty"`
+	Content    string   `json:"content"`
+	DurationMs int64    `json:"duration_ms"`
+	StartedAt  *int64   `json:"started_at,omitempty"`
+	EndedAt    *int64   `json:"ended_at,omitempty"`
+	EntryType  string   `json:"entry_type"`
+	IsBillable bool     `json:"is_billable"`
+	Tags       []string `json:"tags,omitempty"`
+	CreatedAt  int64    `json:"created_at"`
+	UpdatedAt  int64    `json:"updated_at"`
+}
+
+// sameTime reports whether the entry still starts, ends and lasts as it did.
+func (s entrySnapshot) sameTime(started, ended sql.NullInt64, durationMs int64) bool {
+	sameStamp := func(at *int64, value sql.NullInt64) bool {
+		if at == nil {
+			return !value.Valid
+		}
+		return value.Valid && *at == value.Int64
+	}
+	return s.DurationMs == durationMs && sameStamp(s.StartedAt, started) && sameStamp(s.EndedAt, ended)
+}
+
+// entryBatchSnapshot records how an action that touched several entries at once,
+// such as a split or merge, changed them.
+type entryBatchSnapshot struct {
+	Changed []entrySnapshot `json:"changed,omitempty"`
+	Created []string        `json:"created,omitempty"`
+	Deleted []string        `json:"deleted,omitempty"`
+}
+
+// projectSnapshot captures a project row and the timer that deleting it clears.
+type projectSnapshot struct {
+	ID             int64   `json:"id"`
+	Name           string  `json:"name"`
+	Company        *string `json:"company,omitempty"`
+	IsHidden       bool    `json:"is_hidden"`
+	Position       int64   `json:"position"`
+	ParentID       *int64  `json:"parent_id,omitempty"`
+	CreatedAt      int64   `json:"created_at"`
+	UpdatedAt      int64   `json:"updated_at"`
+	TimerStartedAt *int64  `json:"timer_started_at,omitempty"`
+}
+
+func nullInt64Ptr(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Int64
+	return &v
+}
+
+func optionalInt64(value *int64) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *value, Valid: true}
+}
+
+func snapshotEntry(ctx context.Context, q *sqlc.Queries, id string) (entrySnapshot, error) {
+	record, err := q.GetEntry(ctx, id)
+	if err != nil {
+		return entrySnapshot{}, fmt.Errorf("load entry %s: %w", id, err)
+	}
+	return snapshotEntryModel(ctx, q, record)
+}
+
+func snapshotEntryModel(ctx context.Context, q *sqlc.Queries, record sqlc.Entry) (entrySnapshot, error) {
+	tagRows, err := q.ListTagsForEntry(ctx, record.ID)
+	if err != nil {
+		return entrySnapshot{}, fmt.Errorf("load tags for entry %s: %w", record.ID, err)
+	}
+	tags := make([]string, 0, len(tagRows))
+	for _, tag := range tagRows {
+		tags = append(tags, tag.Tag)
+	}
+	return entrySnapshot{
+		ID:         record.ID,
+		ProjectID:  record.ProjectID,
+		CreatorID:  nullInt64Ptr(record.CreatorID),
+		Content:    record.Content,
+		DurationMs: record.DurationMs,
+		StartedAt:  nullInt64Ptr(record.StartedAt),
+		EndedAt:    nullInt64Ptr(record.EndedAt),
+		EntryType:  record.EntryType,
+		IsBillable: record.IsBillable == 1,
+		Tags:       tags,
+		CreatedAt:  record.CreatedAt,
+		UpdatedAt:  record.UpdatedAt,
+	}, nil
+}
+
+func snapshotProject(ctx context.Context, q *sqlc.Queries, id int64) (projectSnapshot, error) {
+	record, err := q.GetProject(ctx, id)
+	if err != nil {
+		return projectSnapshot{}, fmt.Errorf("load project %d: %w", id, err)
+	}
+	snap := projectSnapshot{
+		ID:        record.ID,
+		Name:      record.Name,
+		IsHidden:  record.IsHidden == 1,
+		Position:  record.Position,
+		ParentID:  nullInt64Ptr(record.ParentID),
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+	snap.Company = nullStringPtr(record.Company)
+
+	timer, err := q.GetTimer(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return projectSnapshot{}, fmt.Errorf("load timer for project %d: %w", id, err)
+	}
+	if err == nil {
+		started := timer.StartedAt
+		snap.TimerStartedAt = &started
+	}
+	return snap, nil
+}
+
+// timerSnapshot captures a running timer row.
+type timerSnapshot struct {
+	ProjectID int64 `json:"project_id"`
+	StartedAt int64 `json:"started_at"`
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+	"github.com/nexneo/samay/util"
+)
+
+// SplitPoint reads where to split the entry: a time such as "14:30" or "3pm" on
+// the day the entry started, or a duration such as "45m" or "1h15m" after its start.
+func (e *Entry) SplitPoint(spec string) (time.Time, error) {
+	span, ok := EntrySpan(e)
+	if !ok {
+		return time.Time{}, errors.New("only finished entries can be split")
+	}
+	if at, err := util.ParseTime(spec, span.Start.In(time.Local)); err == nil {
+		return at, nil
+	}
+	offset, err := util.ParseDuration(spec)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("split point %q is neither a time like 14:30 nor a duration like 45m", strings.TrimSpace(spec))
+	}
+	return span.Start.Add(offset), nil
+}
+
+// SplitAt cuts the entry in two at at. The entry keeps the part before at and
+// is described by first; the part after becomes a new entry described by second.
+// An empty description keeps the entry's own. Undoing the split puts it back together.
+func (e *Entry) SplitAt(at time.Time, first, second string) (*Entry, error) {
+	if e == nil || e.db == nil {
+		return nil, errors.New("entry not initialized")
+	}
+	span, ok := EntrySpan(e)
+	if !ok {
+		return nil, errors.New("only finished entries can be split")
+	}
+	at = at.Truncate(time.Second)
+	if !at.After(span.Start) || !at.Before(span.End) {
+		return nil, fmt.Errorf("split point %s is outside the entry (%s–%s)",
+			at.In(time.Local).Format("15:04"), span.Start.In(time.Local).Format("15:04"), span.End.In(time.Local).Format("15:04"))
+	}
+
+	return e.split(Span{Start: span.Start, End: at}, Span{Start: at, End: span.End}, first, second, "split ")
+}
+
+// split narrows the entry to before, described by first, and saves after as a
+// new entry described by second, as one undoable action described by verb.
+func (e *Entry) split(before, after Span, first, second, verb string) (*Entry, error) {
+	rest := &Entry{
+		db:        e.db,
+		Project:   e.Project,
+		ProjectID: e.ProjectID,
+		CreatorID: e.CreatorID,
+		Type:      e.Type,
+		Billable:  e.Billable,
+	}
+	rest.describe(e, second)
+	rest.setSpan(after)
+
+	original := *e
+	e.describe(&original, first)
+	e.setSpan(before)
+
+	ctx := context.Background()
+	err := e.db.withTx(ctx, func(q *sqlc.Queries) error {
+		previous, err := e.rewrite(ctx, q, ChangeUpdate)
+		if err != nil {
+			return err
+		}
+		if err := rest.insert(ctx, q); err != nil {
+			return err
+		}
+		return pushUndo(ctx, q, UndoEntrySplit, verb+describeEntry(previous.Content), entryBatchSnapshot{
+			Changed: []entrySnapshot{previous},
+			Created: []string{rest.ID},
+		})
+	})
+	if err != nil {
+		*e = original
+		return nil, err
+	}
+	return rest, nil
+}
+
+// describe gives the entry content, falling back to source's description and tags when it is empty.
+func (e *Entry) describe(source *Entry, content string) {
+	content = strings.TrimSpace(content)
+	if content == "" || content == strings.TrimSpace(source.Content) {
+		e.Content = source.Content
+		e.Tags = source.GetTags()
+		return
+	}
+	e.Content = content
+	e.Tags = extractTags(content)
+}
+
+// Merge folds others into the entry: it starts when the earliest of them started
+// and runs for their combined duration, its description joins theirs in the order
+// they ran, and it carries every tag. The others move to the trash. All entries
+// must belong to the same project, and undoing the merge restores them.
+func (e *Entry) Merge(others ...*Entry) error {
+	if e == nil || e.db == nil {
+		return errors.New("entry not initialized")
+	}
+	if len(others) == 0 {
+		return errors.New("choose at least one entry to merge")
+	}
+	all := append([]*Entry{e}, others...)
+	spans := make(map[*Entry]Span, len(all))
+	seen := make(map[string]bool, len(all))
+	var total time.Duration
+	for _, entry := range all {
+		if entry == nil {
+			return errors.New("entry not initialized")
+		}
+		if seen[entry.ID] {
+			return fmt.Errorf("entry %s is listed twice", entry.ID)
+		}
+		seen[entry.ID] = true
+		if entry.ProjectID != e.ProjectID {
+			return errors.New("only entries from the same project can be merged")
+		}
+		span, ok := EntrySpan(entry)
+		if !ok {
+			return errors.New("only finished entries can be merged")
+		}
+		spans[entry] = span
+		total += time.Duration(entry.DurationMs) * time.Millisecond
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return spans[all[i]].Start.Before(spans[all[j]].Start)
+	})
+
+	start := spans[all[0]].Start
+	return e.merge(others, Span{Start: start, End: start.Add(total)}, all)
+}
+
+// merge rewrites the entry to cover span with the descriptions and tags of
+// ordered, and trashes others, as one undoable step.
+func (e *Entry) merge(others []*Entry, span Span, ordered []*Entry) error {
+	original := *e
+	content := ""
+	tags := make([]string, 0, len(e.Tags))
+	seen := make(map[string]bool)
+	for _, entry := range ordered {
+		content = joinContent(content, entry.Content)
+		for _, tag := range entry.Tags {
+			if key := strings.ToLower(tag); !seen[key] {
+				seen[key] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	e.Content = content
+	e.Tags = tags
+	e.setSpan(span)
+
+	ctx := context.Background()
+	err := e.db.withTx(ctx, func(q *sqlc.Queries) error {
+		batch := entryBatchSnapshot{}
+		for _, other := range others {
+			if _, err := other.softDelete(ctx, q); err != nil {
+				return err
+			}
+			batch.Deleted = append(batch.Deleted, other.ID)
+		}
+		previous, err := e.rewrite(ctx, q, ChangeUpdate)
+		if err != nil {
+			return err
+		}
+		batch.Changed = append(batch.Changed, previous)
+		description := fmt.Sprintf("merge %d entries into %s", len(others)+1, describeEntry(previous.Content))
+		return pushUndo(ctx, q, UndoEntryMerge, description, batch)
+	})
+	if err != nil {
+		*e = original
+	}
+	return err
+}
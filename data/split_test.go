@@ -0,0 +1,165 @@
+package data
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSplitEntry(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Split")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	day := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	start, end := at(9, 0), at(11, 0)
+	entry := &Entry{db: db, Project: project, Content: "api work #backend", StartedAt: &start, EndedAt: &end,
+		DurationMs: (2 * time.Hour).Milliseconds(), Type: EntryTypeWork, Billable: true, Tags: []string{"backend"}}
+	if err := entry.Save(context.Background()); err != nil {
+		t.Fatalf("save entry: %v", err)
+	}
+
+	point, err := entry.SplitPoint("10:15")
+	if err != nil || !point.Equal(at(10, 15)) {
+		t.Fatalf("SplitPoint(10:15) = %v, %v", point, err)
+	}
+	if point, err := entry.SplitPoint("45m"); err != nil || !point.Equal(at(9, 45)) {
+		t.Fatalf("SplitPoint(45m) = %v, %v", point, err)
+	}
+	if point, err := entry.SplitPoint("10am"); err != nil || !point.Equal(at(10, 0)) {
+		t.Fatalf("SplitPoint(10am) = %v, %v", point, err)
+	}
+	if point, err := entry.SplitPoint("1 hour 15 minutes"); err != nil || !point.Equal(at(10, 15)) {
+		t.Fatalf("SplitPoint(1 hour 15 minutes) = %v, %v", point, err)
+	}
+	if _, err := entry.SplitPoint("soon"); err == nil {
+		t.Fatalf("expected an unreadable split point to fail")
+	}
+	if _, err := entry.SplitAt(at(11, 30), "", ""); err == nil {
+		t.Fatalf("expected a split point after the entry to fail")
+	}
+
+	rest, err := entry.SplitAt(point, "", "review #docs")
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	if entry.Content != "api work #backend" || entry.DurationMs != (75*time.Minute).Milliseconds() || !entry.EndedAt.Equal(at(10, 15)) {
+		t.Fatalf("unexpected first part %q %v for %dms", entry.Content, entry.EndedAt, entry.DurationMs)
+	}
+	if rest.Content != "review #docs" || !slices.Equal(rest.Tags, []string{"docs"}) || !rest.Billable ||
+		!rest.StartedAt.Equal(at(10, 15)) || rest.DurationMs != (45*time.Minute).Milliseconds() {
+		t.Fatalf("unexpected second part %+v", rest)
+	}
+	if conflicts, err := rest.Overlapping(); err != nil || len(conflicts) != 0 {
+		t.Fatalf("expected the parts not to overlap, got %v, %v", conflicts, err)
+	}
+	if entries := project.Entries(); len(entries) != 2 {
+		t.Fatalf("expected 2 entries after the split, got %d", len(entries))
+	}
+
+	action, err := db.Undo()
+	if err != nil || action.Kind != UndoEntrySplit {
+		t.Fatalf("undo split: %+v, %v", action, err)
+	}
+	entries := project.Entries()
+	if len(entries) != 1 || entries[0].DurationMs != (2*time.Hour).Milliseconds() || !entries[0].EndedAt.Equal(end) {
+		t.Fatalf("expected undo to restore the whole entry, got %d entries", len(entries))
+	}
+}
+
+func TestMergeEntries(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Merge")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	other, err := db.CreateProject("Elsewhere")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	day := time.Date(2024, time.June, 4, 0, 0, 0, 0, time.Local)
+	save := func(p *Project, content string, hour int, minutes int) *Entry {
+		t.Helper()
+		start := day.Add(time.Duration(hour) * time.Hour)
+		end := start.Add(time.Duration(minutes) * time.Minute)
+		entry := &Entry{db: db, Project: p, Content: content, StartedAt: &start, EndedAt: &end,
+			DurationMs: end.Sub(start).Milliseconds(), Type: EntryTypeWork, Tags: extractTags(content)}
+		if err := entry.Save(context.Background()); err != nil {
+			t.Fatalf("save entry: %v", err)
+		}
+		return entry
+	}
+	afternoon := save(project, "call #client", 14, 20)
+	morning := save(project, "email #client", 9, 10)
+	noon := save(project, "notes #admin", 12, 30)
+	stranger := save(other, "lunch", 13, 30)
+
+	if err := afternoon.Merge(stranger); err == nil {
+		t.Fatalf("expected merging across projects to fail")
+	}
+	if err := afternoon.Merge(morning, morning); err == nil {
+		t.Fatalf("expected a repeated entry to fail")
+	}
+
+	if err := afternoon.Merge(morning, noon); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if afternoon.Content != "email #client; notes #admin; call #client" {
+		t.Fatalf("expected descriptions joined in the order they ran, got %q", afternoon.Content)
+	}
+	if !slices.Equal(afternoon.Tags, []string{"admin", "client"}) {
+		t.Fatalf("expected the union of tags, got %v", afternoon.Tags)
+	}
+	if afternoon.DurationMs != (time.Hour).Milliseconds() || !afternoon.StartedAt.Equal(day.Add(9*time.Hour)) {
+		t.Fatalf("expected 1h from 09:00, got %dms from %v", afternoon.DurationMs, afternoon.StartedAt)
+	}
+	if entries := project.Entries(); len(entries) != 1 {
+		t.Fatalf("expected the merged entries to be trashed, got %d entries", len(entries))
+	}
+
+	action, err := db.Undo()
+	if err != nil || action.Kind != UndoEntryMerge {
+		t.Fatalf("undo merge: %+v, %v", action, err)
+	}
+	entries := project.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected undo to bring back all three entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.ID == afternoon.ID && entry.Content != "call #client" {
+			t.Fatalf("expected the merged entry's description back, got %q", entry.Content)
+		}
+	}
+}
+
+func TestMergeKeepsSharedTagsOnce(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Tags")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	first, err := project.CreateEntryAt("review #Client #docs", time.Now().Add(-3*time.Hour), time.Hour, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	second, err := project.CreateEntryAt("fix #client #docs", time.Now().Add(-time.Hour), 30*time.Minute, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	if err := first.Merge(second); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if !slices.Equal(first.Tags, []string{"Client", "docs"}) {
+		t.Fatalf("expected each shared tag once, got %v", first.Tags)
+	}
+	entries := project.Entries()
+	if len(entries) != 1 || !slices.Equal(entries[0].Tags, []string{"Client", "docs"}) {
+		t.Fatalf("expected each shared tag stored once, got %+v", entries)
+	}
+}
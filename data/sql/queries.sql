@@ -7,8 +7,11 @@ SELECT id,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
+WHERE deleted_at IS NULL
 ORDER BY position ASC,
          updated_at DESC;
 
@@ -19,9 +22,12 @@ SELECT id,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
 WHERE is_hidden = 0
+  AND deleted_at IS NULL
 ORDER BY position ASC,
          updated_at DESC;
 
@@ -32,37 +38,75 @@ SELECT id,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
 WHERE id = ?1;
 
--- name: GetProjectByName :one
+-- name: GetProjectByParentAndName :one
 SELECT id,
        name,
        company,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
+FROM projects
+WHERE parent_id IS ?1
+  AND name = ?2 COLLATE NOCASE
+  AND deleted_at IS NULL;
+
+-- name: ListProjectsByName :many
+SELECT id,
+       name,
+       company,
+       is_hidden,
+       position,
+       created_at,
+       updated_at,
+       deleted_at,
+       parent_id
+FROM projects
+WHERE name = ?1 COLLATE NOCASE
+ORDER BY id;
+
+-- name: ListChildProjects :many
+SELECT id,
+       name,
+       company,
+       is_hidden,
+       position,
+       created_at,
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
-WHERE name = ?1 COLLATE NOCASE;
+WHERE parent_id = ?1
+ORDER BY position ASC,
+         updated_at DESC;
 
 -- name: CreateProject :one
-INSERT INTO projects (name, company, is_hidden)
-VALUES (?1, ?2, ?3)
+INSERT INTO projects (name, company, is_hidden, parent_id)
+VALUES (?1, ?2, ?3, ?4)
 RETURNING id,
           name,
           company,
           is_hidden,
           position,
           created_at,
-          updated_at;
+          updated_at,
+          deleted_at,
+          parent_id;
 
 -- name: UpdateProject :one
 UPDATE projects
 SET name = ?2,
     company = ?3,
     is_hidden = ?4,
+    parent_id = ?5,
     updated_at = unixepoch()
 WHERE id = ?1
 RETURNING id,
@@ -71,7 +115,9 @@ RETURNING id,
           is_hidden,
           position,
           created_at,
-          updated_at;
+          updated_at,
+          deleted_at,
+          parent_id;
 
 -- name: TouchProject :exec
 UPDATE projects
@@ -82,6 +128,33 @@ WHERE id = ?1;
 DELETE FROM projects
 WHERE id = ?1;
 
+-- name: SoftDeleteProject :exec
+UPDATE projects
+SET deleted_at = unixepoch(),
+    updated_at = unixepoch()
+WHERE id = ?1;
+
+-- name: RestoreProject :exec
+UPDATE projects
+SET deleted_at = NULL,
+    updated_at = unixepoch()
+WHERE id = ?1;
+
+-- name: ListDeletedProjects :many
+SELECT id,
+       name,
+       company,
+       is_hidden,
+       position,
+       created_at,
+       updated_at,
+       deleted_at,
+       parent_id
+FROM projects
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+
 
 -- People
 
@@ -103,6 +176,15 @@ SELECT id,
 FROM people
 WHERE email = ?1 COLLATE NOCASE;
 
+-- name: ListPeople :many
+SELECT id,
+       email,
+       name,
+       created_at,
+       updated_at
+FROM people
+ORDER BY name COLLATE NOCASE, email;
+
 -- name: UpsertPerson :one
 INSERT INTO people (email, name)
 VALUES (?1, ?2)
@@ -141,9 +223,33 @@ RETURNING project_id,
 DELETE FROM timers
 WHERE project_id = ?1;
 
+-- name: ListRunningTimers :many
+WITH RECURSIVE chain(project_id, ancestor_id, path) AS (
+    SELECT t.project_id, p.parent_id, p.name
+    FROM timers t
+    JOIN projects p ON p.id = t.project_id
+    WHERE p.deleted_at IS NULL
+    UNION ALL
+    SELECT c.project_id, a.parent_id, a.name || '/' || c.path
+    FROM chain c
+    JOIN projects a ON a.id = c.ancestor_id
+)
+SELECT c.project_id,
+       CAST(c.path AS TEXT) AS path,
+       t.started_at
+FROM chain c
+JOIN timers t ON t.project_id = c.project_id
+WHERE c.ancestor_id IS NULL
+ORDER BY t.started_at DESC, c.project_id;
+
 
 -- Entries
 
+-- name: LatestEntryEnd :one
+SELECT CAST(COALESCE(MAX(ended_at), 0) AS INTEGER) AS ended_at
+FROM entries
+WHERE deleted_at IS NULL;
+
 -- name: ListEntriesByProject :many
 SELECT id,
        project_id,
@@ -155,9 +261,11 @@ SELECT id,
        entry_type,
        is_billable,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at
 FROM entries
 WHERE project_id = ?1
+  AND deleted_at IS NULL
 ORDER BY ended_at IS NULL,
          ended_at DESC,
          started_at DESC,
@@ -174,15 +282,63 @@ SELECT e.id,
        e.entry_type,
        e.is_billable,
        e.created_at,
-       e.updated_at
+       e.updated_at,
+       e.deleted_at
 FROM entries e
 JOIN entry_tags t ON t.entry_id = e.id
+JOIN projects p ON p.id = e.project_id
 WHERE t.tag = ?1 COLLATE NOCASE
+  AND e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
 ORDER BY e.ended_at IS NULL,
          e.ended_at DESC,
          e.started_at DESC,
          e.created_at DESC;
 
+-- name: ListEntriesInRange :many
+WITH RECURSIVE tree(id) AS (
+    SELECT ?3
+    UNION ALL
+    SELECT p.id
+    FROM projects p
+    JOIN tree t ON p.parent_id = t.id
+    WHERE p.deleted_at IS NULL
+)
+SELECT e.id,
+       e.project_id,
+       e.creator_id,
+       e.content,
+       e.duration_ms,
+       e.started_at,
+       e.ended_at,
+       e.entry_type,
+       e.is_billable,
+       e.created_at,
+       e.updated_at,
+       e.deleted_at
+FROM entries e
+JOIN projects p ON p.id = e.project_id
+WHERE e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND e.ended_at IS NOT NULL
+  AND e.ended_at >= ?1
+  AND e.ended_at < ?2
+  AND (?3 IS NULL OR e.project_id IN (SELECT id FROM tree))
+  AND (?4 IS NULL OR EXISTS (
+      SELECT 1
+      FROM entry_tags t
+      WHERE t.entry_id = e.id
+        AND t.tag = ?4
+  ))
+ORDER BY COALESCE(e.started_at, e.ended_at) ASC,
+         e.created_at ASC;
+
+-- name: ListEntryIDsByProject :many
+SELECT id
+FROM entries
+WHERE project_id = ?1
+ORDER BY created_at;
+
 -- name: GetEntry :one
 SELECT id,
        project_id,
@@ -194,7 +350,8 @@ SELECT id,
        entry_type,
        is_billable,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at
 FROM entries
 WHERE id = ?1;
 
@@ -220,7 +377,8 @@ RETURNING id,
           entry_type,
           is_billable,
           created_at,
-          updated_at;
+          updated_at,
+          deleted_at;
 
 -- name: UpdateEntry :one
 UPDATE entries
@@ -244,18 +402,74 @@ RETURNING id,
           entry_type,
           is_billable,
           created_at,
-          updated_at;
+          updated_at,
+          deleted_at;
 
 -- name: DeleteEntry :exec
 DELETE FROM entries
 WHERE id = ?1;
 
+-- name: SoftDeleteEntry :exec
+UPDATE entries
+SET deleted_at = unixepoch(),
+    updated_at = unixepoch()
+WHERE id = ?1;
+
+-- name: RestoreEntry :exec
+UPDATE entries
+SET deleted_at = NULL,
+    updated_at = unixepoch()
+WHERE id = ?1;
+
+-- name: ListDeletedEntries :many
+SELECT id,
+       project_id,
+       creator_id,
+       content,
+       duration_ms,
+       started_at,
+       ended_at,
+       entry_type,
+       is_billable,
+       created_at,
+       updated_at,
+       deleted_at
+FROM entries
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+
+-- name: ListOverlappingEntries :many
+SELECT e.id,
+       e.project_id,
+       e.creator_id,
+       e.content,
+       e.duration_ms,
+       e.started_at,
+       e.ended_at,
+       e.entry_type,
+       e.is_billable,
+       e.created_at,
+       e.updated_at,
+       e.deleted_at
+FROM entries e
+JOIN projects p ON p.id = e.project_id
+WHERE e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND e.id != ?3
+  AND e.ended_at > ?1
+  AND (e.started_at < ?2
+       OR (e.started_at IS NULL AND e.ended_at - e.duration_ms / 1000 < ?2))
+ORDER BY COALESCE(e.started_at, e.ended_at) ASC,
+         e.created_at ASC;
+
 -- name: ProjectTotalsInRange :one
 SELECT COALESCE(SUM(duration_ms), 0) AS total_duration_ms,
        COALESCE(SUM(CASE WHEN is_billable = 1 THEN duration_ms ELSE 0 END), 0) AS billable_duration_ms,
        COUNT(*) AS entry_count
 FROM entries
 WHERE project_id = ?1
+  AND deleted_at IS NULL
   AND ended_at IS NOT NULL
   AND ended_at >= ?2
   AND ended_at < ?3;
@@ -271,9 +485,11 @@ WHERE entry_id = ?1
 ORDER BY tag;
 
 -- name: ListAllTags :many
-SELECT DISTINCT tag
-FROM entry_tags
-ORDER BY tag;
+SELECT DISTINCT t.tag
+FROM entry_tags t
+JOIN entries e ON e.id = t.entry_id
+WHERE e.deleted_at IS NULL
+ORDER BY t.tag;
 
 -- name: InsertEntryTag :exec
 INSERT INTO entry_tags (entry_id, tag)
@@ -283,3 +499,401 @@ ON CONFLICT(entry_id, tag) DO NOTHING;
 -- name: DeleteEntryTags :exec
 DELETE FROM entry_tags
 WHERE entry_id = ?1;
+
+
+-- Undo
+
+-- name: InsertUndoAction :one
+INSERT INTO undo_actions (kind, description, payload)
+VALUES (?1, ?2, ?3)
+RETURNING id,
+          kind,
+          description,
+          payload,
+          created_at;
+
+-- name: GetLatestUndoAction :one
+SELECT id,
+       kind,
+       description,
+       payload,
+       created_at
+FROM undo_actions
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: DeleteUndoAction :exec
+DELETE FROM undo_actions
+WHERE id = ?1;
+
+-- name: PruneUndoActions :exec
+DELETE FROM undo_actions
+WHERE id NOT IN (
+    SELECT id
+    FROM undo_actions
+    ORDER BY id DESC
+    LIMIT ?1
+);
+
+
+-- Change Log
+
+-- name: InsertChange :exec
+INSERT INTO change_log (entity_type, entity_id, action, before_json, after_json)
+VALUES (?1, ?2, ?3, ?4, ?5);
+
+-- name: ListChangesForEntity :many
+SELECT id,
+       entity_type,
+       entity_id,
+       action,
+       before_json,
+       after_json,
+       created_at
+FROM change_log
+WHERE entity_type = ?1
+  AND entity_id = ?2
+ORDER BY id ASC;
+
+-- name: LatestChangeID :one
+SELECT CAST(COALESCE(MAX(id), 0) AS INTEGER)
+FROM change_log;
+
+-- name: ListChangesAfter :many
+SELECT id,
+       entity_type,
+       entity_id,
+       action,
+       before_json,
+       after_json,
+       created_at
+FROM change_log
+WHERE id > ?1
+ORDER BY id ASC;
+
+-- name: ListChangedEntityIDsByPrefix :many
+SELECT DISTINCT entity_id
+FROM change_log
+WHERE entity_type = ?1
+  AND entity_id LIKE ?2
+ORDER BY entity_id
+LIMIT 2;
+
+
+-- Budgets
+
+-- name: GetProjectBudget :one
+SELECT project_id,
+       unit,
+       amount,
+       period,
+       hourly_rate,
+       created_at,
+       updated_at
+FROM project_budgets
+WHERE project_id = ?1;
+
+-- name: ListProjectBudgets :many
+SELECT b.project_id,
+       b.unit,
+       b.amount,
+       b.period,
+       b.hourly_rate,
+       b.created_at,
+       b.updated_at
+FROM project_budgets b
+JOIN projects p ON p.id = b.project_id
+WHERE p.deleted_at IS NULL
+ORDER BY p.position ASC,
+         p.updated_at DESC;
+
+-- name: UpsertProjectBudget :one
+INSERT INTO project_budgets (project_id, unit, amount, period, hourly_rate)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT(project_id) DO UPDATE
+SET unit = excluded.unit,
+    amount = excluded.amount,
+    period = excluded.period,
+    hourly_rate = excluded.hourly_rate,
+    updated_at = unixepoch()
+RETURNING project_id,
+          unit,
+          amount,
+          period,
+          hourly_rate,
+          created_at,
+          updated_at;
+
+-- name: DeleteProjectBudget :exec
+DELETE FROM project_budgets
+WHERE project_id = ?1;
+
+-- name: ProjectTreeTotalsInRange :one
+WITH RECURSIVE tree(id) AS (
+    SELECT ?1
+    UNION ALL
+    SELECT p.id
+    FROM projects p
+    JOIN tree t ON p.parent_id = t.id
+    WHERE p.deleted_at IS NULL
+)
+SELECT CAST(COALESCE(SUM(e.duration_ms), 0) AS INTEGER) AS total_duration_ms,
+       CAST(COALESCE(SUM(CASE WHEN e.is_billable = 1 THEN e.duration_ms ELSE 0 END), 0) AS INTEGER) AS billable_duration_ms
+FROM entries e
+WHERE e.project_id IN (SELECT id FROM tree)
+  AND e.deleted_at IS NULL
+  AND e.ended_at IS NOT NULL
+  AND e.ended_at >= ?2
+  AND e.ended_at < ?3;
+
+
+-- Goals
+
+-- name: ListGoals :many
+SELECT id,
+       period,
+       minutes,
+       project_id,
+       tag,
+       created_at,
+       updated_at
+FROM goals
+ORDER BY period ASC,
+         project_id IS NOT NULL,
+         tag IS NOT NULL,
+         id ASC;
+
+-- name: GetGoalByScope :one
+SELECT id,
+       period,
+       minutes,
+       project_id,
+       tag,
+       created_at,
+       updated_at
+FROM goals
+WHERE period = ?1
+  AND project_id IS ?2
+  AND tag IS ?3;
+
+-- name: CreateGoal :one
+INSERT INTO goals (period, minutes, project_id, tag)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id,
+          period,
+          minutes,
+          project_id,
+          tag,
+          created_at,
+          updated_at;
+
+-- name: UpdateGoalMinutes :one
+UPDATE goals
+SET minutes = ?2,
+    updated_at = unixepoch()
+WHERE id = ?1
+RETURNING id,
+          period,
+          minutes,
+          project_id,
+          tag,
+          created_at,
+          updated_at;
+
+-- name: DeleteGoal :exec
+DELETE FROM goals
+WHERE id = ?1;
+
+-- name: ListEntryDurationsInRange :many
+WITH RECURSIVE tree(id) AS (
+    SELECT ?3
+    UNION ALL
+    SELECT p.id
+    FROM projects p
+    JOIN tree t ON p.parent_id = t.id
+    WHERE p.deleted_at IS NULL
+)
+SELECT e.duration_ms,
+       e.ended_at
+FROM entries e
+JOIN projects p ON p.id = e.project_id
+WHERE e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND e.ended_at IS NOT NULL
+  AND e.ended_at >= ?1
+  AND e.ended_at < ?2
+  AND (?3 IS NULL OR e.project_id IN (SELECT id FROM tree))
+  AND (?4 IS NULL OR EXISTS (
+      SELECT 1
+      FROM entry_tags t
+      WHERE t.entry_id = e.id
+        AND t.tag = ?4
+  ))
+ORDER BY e.ended_at ASC;
+
+-- Templates
+
+-- name: ListEntryTemplates :many
+SELECT t.id,
+       t.name,
+       t.project_id,
+       t.content,
+       t.duration_ms,
+       t.tags,
+       t.entry_type,
+       t.is_billable,
+       t.repeat_days,
+       t.repeat_minute,
+       t.repeat_from,
+       t.created_at,
+       t.updated_at
+FROM entry_templates t
+JOIN projects p ON p.id = t.project_id
+WHERE p.deleted_at IS NULL
+ORDER BY t.name ASC;
+
+-- name: GetEntryTemplateByName :one
+SELECT id,
+       name,
+       project_id,
+       content,
+       duration_ms,
+       tags,
+       entry_type,
+       is_billable,
+       repeat_days,
+       repeat_minute,
+       repeat_from,
+       created_at,
+       updated_at
+FROM entry_templates
+WHERE name = ?1;
+
+-- name: CreateEntryTemplate :one
+INSERT INTO entry_templates (name, project_id, content, duration_ms, tags, entry_type, is_billable, repeat_days, repeat_minute, repeat_from)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+RETURNING id,
+          name,
+          project_id,
+          content,
+          duration_ms,
+          tags,
+          entry_type,
+          is_billable,
+          repeat_days,
+          repeat_minute,
+          repeat_from,
+          created_at,
+          updated_at;
+
+-- name: UpdateEntryTemplate :one
+UPDATE entry_templates
+SET project_id = ?2,
+    content = ?3,
+    duration_ms = ?4,
+    tags = ?5,
+    entry_type = ?6,
+    is_billable = ?7,
+    repeat_days = ?8,
+    repeat_minute = ?9,
+    repeat_from = ?10,
+    updated_at = unixepoch()
+WHERE id = ?1
+RETURNING id,
+          name,
+          project_id,
+          content,
+          duration_ms,
+          tags,
+          entry_type,
+          is_billable,
+          repeat_days,
+          repeat_minute,
+          repeat_from,
+          created_at,
+          updated_at;
+
+-- name: DeleteEntryTemplate :exec
+DELETE FROM entry_templates
+WHERE id = ?1;
+
+-- name: ListTemplateOccurrenceDays :many
+SELECT occurs_on
+FROM template_occurrences
+WHERE template_id = ?1
+  AND occurs_on >= ?2
+ORDER BY occurs_on ASC;
+
+-- name: CreateTemplateOccurrence :exec
+INSERT INTO template_occurrences (template_id, occurs_on, entry_id)
+VALUES (?1, ?2, ?3);
+
+
+-- Webhooks
+
+-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_outbox (url, event, payload, next_attempt_at)
+VALUES (?1, ?2, ?3, ?4);
+
+-- name: ListDueWebhookDeliveries :many
+SELECT id,
+       url,
+       event,
+       payload,
+       attempts,
+       next_attempt_at,
+       last_error,
+       failed_at,
+       created_at
+FROM webhook_outbox
+WHERE failed_at IS NULL
+  AND next_attempt_at <= ?1
+ORDER BY id ASC
+LIMIT ?2;
+
+-- name: PostponeWebhookDelivery :exec
+UPDATE webhook_outbox
+SET next_attempt_at = ?2
+WHERE id = ?1;
+
+-- name: RecordWebhookFailure :exec
+UPDATE webhook_outbox
+SET attempts = ?2,
+    next_attempt_at = ?3,
+    last_error = ?4,
+    failed_at = ?5
+WHERE id = ?1;
+
+-- name: DeleteWebhookDelivery :exec
+DELETE FROM webhook_outbox
+WHERE id = ?1;
+
+-- name: WebhookOutboxStatus :many
+SELECT url,
+       CAST(SUM(failed_at IS NULL) AS INTEGER) AS pending,
+       CAST(SUM(failed_at IS NOT NULL) AS INTEGER) AS failed
+FROM webhook_outbox
+GROUP BY url
+ORDER BY url;
+
+-- name: ListFailedWebhookDeliveries :many
+SELECT id,
+       url,
+       event,
+       payload,
+       attempts,
+       next_attempt_at,
+       last_error,
+       failed_at,
+       created_at
+FROM webhook_outbox
+WHERE failed_at IS NOT NULL
+ORDER BY failed_at DESC, id DESC
+LIMIT ?1;
+
+-- name: RetryFailedWebhookDeliveries :execrows
+UPDATE webhook_outbox
+SET attempts = 0,
+    next_attempt_at = ?1,
+    failed_at = NULL
+WHERE failed_at IS NOT NULL;
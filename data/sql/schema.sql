@@ -4,12 +4,14 @@ PRAGMA busy_timeout = 5000;
 
 CREATE TABLE IF NOT EXISTS projects (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
-    name TEXT NOT NULL COLLATE NOCASE UNIQUE,
+    name TEXT NOT NULL COLLATE NOCASE,
     company TEXT,
     is_hidden INTEGER NOT NULL DEFAULT 0 CHECK (is_hidden IN (0, 1)),
     position INTEGER NOT NULL DEFAULT 0,
     created_at INTEGER NOT NULL DEFAULT (unixepoch()),
-    updated_at INTEGER NOT NULL DEFAULT (unixepoch())
+    updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    deleted_at INTEGER,
+    parent_id INTEGER REFERENCES projects(id) ON DELETE CASCADE
 ) STRICT;
 
 CREATE TABLE IF NOT EXISTS people (
@@ -40,6 +42,7 @@ CREATE TABLE IF NOT EXISTS entries (
     is_billable INTEGER NOT NULL DEFAULT 1 CHECK (is_billable IN (0, 1)),
     created_at INTEGER NOT NULL DEFAULT (unixepoch()),
     updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    deleted_at INTEGER,
     FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
     FOREIGN KEY (creator_id) REFERENCES people(id) ON DELETE SET NULL
 ) STRICT;
@@ -53,8 +56,94 @@ CREATE TABLE IF NOT EXISTS entry_tags (
     PRIMARY KEY (entry_id, tag)
 ) STRICT, WITHOUT ROWID;
 
+CREATE TABLE IF NOT EXISTS undo_actions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    description TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at INTEGER NOT NULL DEFAULT (unixepoch())
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS change_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('entry', 'project', 'timer')),
+    entity_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    before_json TEXT,
+    after_json TEXT,
+    created_at INTEGER NOT NULL DEFAULT (unixepoch())
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS project_budgets (
+    project_id INTEGER PRIMARY KEY,
+    unit TEXT NOT NULL CHECK (unit IN ('hours', 'money')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    period TEXT NOT NULL DEFAULT 'total' CHECK (period IN ('total', 'week', 'month')),
+    hourly_rate INTEGER CHECK (hourly_rate IS NULL OR hourly_rate > 0),
+    created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+) STRICT, WITHOUT ROWID;
+
+CREATE TABLE IF NOT EXISTS goals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    period TEXT NOT NULL CHECK (period IN ('day', 'week')),
+    minutes INTEGER NOT NULL CHECK (minutes > 0),
+    project_id INTEGER,
+    tag TEXT COLLATE NOCASE,
+    created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    CHECK (project_id IS NULL OR tag IS NULL),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS entry_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL COLLATE NOCASE UNIQUE,
+    project_id INTEGER NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL CHECK (duration_ms > 0),
+    tags TEXT NOT NULL DEFAULT '',
+    entry_type TEXT NOT NULL DEFAULT 'WORK' CHECK (entry_type IN ('CHORE', 'FUN', 'WORK')),
+    is_billable INTEGER NOT NULL DEFAULT 1 CHECK (is_billable IN (0, 1)),
+    repeat_days INTEGER NOT NULL DEFAULT 0 CHECK (repeat_days BETWEEN 0 AND 127),
+    repeat_minute INTEGER NOT NULL DEFAULT 0 CHECK (repeat_minute BETWEEN 0 AND 1439),
+    repeat_from TEXT,
+    created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS template_occurrences (
+    template_id INTEGER NOT NULL,
+    occurs_on TEXT NOT NULL,
+    entry_id TEXT,
+    created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (template_id, occurs_on),
+    FOREIGN KEY (template_id) REFERENCES entry_templates(id) ON DELETE CASCADE,
+    FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE SET NULL
+) STRICT, WITHOUT ROWID;
+
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER NOT NULL,
+    last_error TEXT,
+    failed_at INTEGER,
+    created_at INTEGER NOT NULL DEFAULT (unixepoch())
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_parent_name ON projects(ifnull(parent_id, 0), name) WHERE deleted_at IS NULL;
 CREATE INDEX IF NOT EXISTS idx_projects_ordering ON projects(position ASC, updated_at DESC);
 CREATE INDEX IF NOT EXISTS idx_entries_project ON entries(project_id);
 CREATE INDEX IF NOT EXISTS idx_entries_project_ended ON entries(project_id, ended_at DESC, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_entries_creator ON entries(creator_id);
 CREATE INDEX IF NOT EXISTS idx_entry_tags_tag ON entry_tags(tag);
+CREATE INDEX IF NOT EXISTS idx_projects_deleted ON projects(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_entries_deleted ON entries(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_change_log_entity ON change_log(entity_type, entity_id, id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_goals_scope ON goals(period, ifnull(project_id, 0), ifnull(tag, ''));
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_due ON webhook_outbox(next_attempt_at) WHERE failed_at IS NULL;
@@ -8,6 +8,16 @@ import (
 	"database/sql"
 )
 
+type ChangeLog struct {
+	ID         int64
+	EntityType string
+	EntityID   string
+	Action     string
+	BeforeJson sql.NullString
+	AfterJson  sql.NullString
+	CreatedAt  int64
+}
+
 type Entry struct {
 	ID         string
 	ProjectID  int64
@@ -20,6 +30,7 @@ type Entry struct {
 	IsBillable int64
 	CreatedAt  int64
 	UpdatedAt  int64
+	DeletedAt  sql.NullInt64
 }
 
 type EntryTag struct {
@@ -28,6 +39,32 @@ type EntryTag struct {
 	CreatedAt int64
 }
 
+type EntryTemplate struct {
+	ID           int64
+	Name         string
+	ProjectID    int64
+	Content      string
+	DurationMs   int64
+	Tags         string
+	EntryType    string
+	IsBillable   int64
+	RepeatDays   int64
+	RepeatMinute int64
+	RepeatFrom   sql.NullString
+	CreatedAt    int64
+	UpdatedAt    int64
+}
+
+type Goal struct {
+	ID        int64
+	Period    string
+	Minutes   int64
+	ProjectID sql.NullInt64
+	Tag       sql.NullString
+	CreatedAt int64
+	UpdatedAt int64
+}
+
 type Person struct {
 	ID        int64
 	Email     string
@@ -44,6 +81,25 @@ type Project struct {
 	Position  int64
 	CreatedAt int64
 	UpdatedAt int64
+	DeletedAt sql.NullInt64
+	ParentID  sql.NullInt64
+}
+
+type ProjectBudget struct {
+	ProjectID  int64
+	Unit       string
+	Amount     int64
+	Period     string
+	HourlyRate sql.NullInt64
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+type TemplateOccurrence struct {
+	TemplateID int64
+	OccursOn   string
+	EntryID    sql.NullString
+	CreatedAt  int64
 }
 
 type Timer struct {
@@ -52,3 +108,23 @@ type Timer struct {
 	CreatedAt int64
 	UpdatedAt int64
 }
+
+type UndoAction struct {
+	ID          int64
+	Kind        string
+	Description string
+	Payload     string
+	CreatedAt   int64
+}
+
+type WebhookOutbox struct {
+	ID            int64
+	Url           string
+	Event         string
+	Payload       string
+	Attempts      int64
+	NextAttemptAt int64
+	LastError     sql.NullString
+	FailedAt      sql.NullInt64
+	CreatedAt     int64
+}
@@ -32,7 +32,8 @@ RETURNING id,
           entry_type,
           is_billable,
           created_at,
-          updated_at
+          updated_at,
+          deleted_at
 `
 
 type CreateEntryParams struct {
@@ -72,30 +73,141 @@ func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry
 		&i.IsBillable,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const CreateEntryTemplate = `-- name: CreateEntryTemplate :one
+INSERT INTO entry_templates (name, project_id, content, duration_ms, tags, entry_type, is_billable, repeat_days, repeat_minute, repeat_from)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+RETURNING id,
+          name,
+          project_id,
+          content,
+          duration_ms,
+          tags,
+          entry_type,
+          is_billable,
+          repeat_days,
+          repeat_minute,
+          repeat_from,
+          created_at,
+          updated_at
+`
+
+type CreateEntryTemplateParams struct {
+	Name         string
+	ProjectID    int64
+	Content      string
+	DurationMs   int64
+	Tags         string
+	EntryType    string
+	IsBillable   int64
+	RepeatDays   int64
+	RepeatMinute int64
+	RepeatFrom   sql.NullString
+}
+
+func (q *Queries) CreateEntryTemplate(ctx context.Context, arg CreateEntryTemplateParams) (EntryTemplate, error) {
+	row := q.db.QueryRowContext(ctx, CreateEntryTemplate,
+		arg.Name,
+		arg.ProjectID,
+		arg.Content,
+		arg.DurationMs,
+		arg.Tags,
+		arg.EntryType,
+		arg.IsBillable,
+		arg.RepeatDays,
+		arg.RepeatMinute,
+		arg.RepeatFrom,
+	)
+	var i EntryTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProjectID,
+		&i.Content,
+		&i.DurationMs,
+		&i.Tags,
+		&i.EntryType,
+		&i.IsBillable,
+		&i.RepeatDays,
+		&i.RepeatMinute,
+		&i.RepeatFrom,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const CreateGoal = `-- name: CreateGoal :one
+INSERT INTO goals (period, minutes, project_id, tag)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id,
+          period,
+          minutes,
+          project_id,
+          tag,
+          created_at,
+          updated_at
+`
+
+type CreateGoalParams struct {
+	Period    string
+	Minutes   int64
+	ProjectID sql.NullInt64
+	Tag       sql.NullString
+}
+
+func (q *Queries) CreateGoal(ctx context.Context, arg CreateGoalParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, CreateGoal,
+		arg.Period,
+		arg.Minutes,
+		arg.ProjectID,
+		arg.Tag,
+	)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.Period,
+		&i.Minutes,
+		&i.ProjectID,
+		&i.Tag,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const CreateProject = `-- name: CreateProject :one
-INSERT INTO projects (name, company, is_hidden)
-VALUES (?1, ?2, ?3)
+INSERT INTO projects (name, company, is_hidden, parent_id)
+VALUES (?1, ?2, ?3, ?4)
 RETURNING id,
           name,
           company,
           is_hidden,
           position,
           created_at,
-          updated_at
+          updated_at,
+          deleted_at,
+          parent_id
 `
 
 type CreateProjectParams struct {
 	Name     string
 	Company  sql.NullString
 	IsHidden int64
+	ParentID sql.NullInt64
 }
 
 func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
-	row := q.db.QueryRowContext(ctx, CreateProject, arg.Name, arg.Company, arg.IsHidden)
+	row := q.db.QueryRowContext(ctx, CreateProject,
+		arg.Name,
+		arg.Company,
+		arg.IsHidden,
+		arg.ParentID,
+	)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -105,10 +217,28 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.Position,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
 
+const CreateTemplateOccurrence = `-- name: CreateTemplateOccurrence :exec
+INSERT INTO template_occurrences (template_id, occurs_on, entry_id)
+VALUES (?1, ?2, ?3)
+`
+
+type CreateTemplateOccurrenceParams struct {
+	TemplateID int64
+	OccursOn   string
+	EntryID    sql.NullString
+}
+
+func (q *Queries) CreateTemplateOccurrence(ctx context.Context, arg CreateTemplateOccurrenceParams) error {
+	_, err := q.db.ExecContext(ctx, CreateTemplateOccurrence, arg.TemplateID, arg.OccursOn, arg.EntryID)
+	return err
+}
+
 const DeleteEntry = `-- name: DeleteEntry :exec
 DELETE FROM entries
 WHERE id = ?1
@@ -129,6 +259,26 @@ func (q *Queries) DeleteEntryTags(ctx context.Context, entryID string) error {
 	return err
 }
 
+const DeleteEntryTemplate = `-- name: DeleteEntryTemplate :exec
+DELETE FROM entry_templates
+WHERE id = ?1
+`
+
+func (q *Queries) DeleteEntryTemplate(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteEntryTemplate, id)
+	return err
+}
+
+const DeleteGoal = `-- name: DeleteGoal :exec
+DELETE FROM goals
+WHERE id = ?1
+`
+
+func (q *Queries) DeleteGoal(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteGoal, id)
+	return err
+}
+
 const DeleteProject = `-- name: DeleteProject :exec
 DELETE FROM projects
 WHERE id = ?1
@@ -139,6 +289,16 @@ func (q *Queries) DeleteProject(ctx context.Context, id int64) error {
 	return err
 }
 
+const DeleteProjectBudget = `-- name: DeleteProjectBudget :exec
+DELETE FROM project_budgets
+WHERE project_id = ?1
+`
+
+func (q *Queries) DeleteProjectBudget(ctx context.Context, projectID int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteProjectBudget, projectID)
+	return err
+}
+
 const DeleteTimer = `-- name: DeleteTimer :exec
 DELETE FROM timers
 WHERE project_id = ?1
@@ -149,6 +309,26 @@ func (q *Queries) DeleteTimer(ctx context.Context, projectID int64) error {
 	return err
 }
 
+const DeleteUndoAction = `-- name: DeleteUndoAction :exec
+DELETE FROM undo_actions
+WHERE id = ?1
+`
+
+func (q *Queries) DeleteUndoAction(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteUndoAction, id)
+	return err
+}
+
+const DeleteWebhookDelivery = `-- name: DeleteWebhookDelivery :exec
+DELETE FROM webhook_outbox
+WHERE id = ?1
+`
+
+func (q *Queries) DeleteWebhookDelivery(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteWebhookDelivery, id)
+	return err
+}
+
 const GetEntry = `-- name: GetEntry :one
 SELECT id,
        project_id,
@@ -160,7 +340,8 @@ SELECT id,
        entry_type,
        is_billable,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at
 FROM entries
 WHERE id = ?1
 `
@@ -180,6 +361,105 @@ func (q *Queries) GetEntry(ctx context.Context, id string) (Entry, error) {
 		&i.IsBillable,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetEntryTemplateByName = `-- name: GetEntryTemplateByName :one
+SELECT id,
+       name,
+       project_id,
+       content,
+       duration_ms,
+       tags,
+       entry_type,
+       is_billable,
+       repeat_days,
+       repeat_minute,
+       repeat_from,
+       created_at,
+       updated_at
+FROM entry_templates
+WHERE name = ?1
+`
+
+func (q *Queries) GetEntryTemplateByName(ctx context.Context, name string) (EntryTemplate, error) {
+	row := q.db.QueryRowContext(ctx, GetEntryTemplateByName, name)
+	var i EntryTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProjectID,
+		&i.Content,
+		&i.DurationMs,
+		&i.Tags,
+		&i.EntryType,
+		&i.IsBillable,
+		&i.RepeatDays,
+		&i.RepeatMinute,
+		&i.RepeatFrom,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const GetGoalByScope = `-- name: GetGoalByScope :one
+SELECT id,
+       period,
+       minutes,
+       project_id,
+       tag,
+       created_at,
+       updated_at
+FROM goals
+WHERE period = ?1
+  AND project_id IS ?2
+  AND tag IS ?3
+`
+
+type GetGoalByScopeParams struct {
+	Period    string
+	ProjectID sql.NullInt64
+	Tag       sql.NullString
+}
+
+func (q *Queries) GetGoalByScope(ctx context.Context, arg GetGoalByScopeParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, GetGoalByScope, arg.Period, arg.ProjectID, arg.Tag)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.Period,
+		&i.Minutes,
+		&i.ProjectID,
+		&i.Tag,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const GetLatestUndoAction = `-- name: GetLatestUndoAction :one
+SELECT id,
+       kind,
+       description,
+       payload,
+       created_at
+FROM undo_actions
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestUndoAction(ctx context.Context) (UndoAction, error) {
+	row := q.db.QueryRowContext(ctx, GetLatestUndoAction)
+	var i UndoAction
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Description,
+		&i.Payload,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -239,7 +519,9 @@ SELECT id,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
 WHERE id = ?1
 `
@@ -255,24 +537,62 @@ func (q *Queries) GetProject(ctx context.Context, id int64) (Project, error) {
 		&i.Position,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const GetProjectBudget = `-- name: GetProjectBudget :one
+SELECT project_id,
+       unit,
+       amount,
+       period,
+       hourly_rate,
+       created_at,
+       updated_at
+FROM project_budgets
+WHERE project_id = ?1
+`
+
+func (q *Queries) GetProjectBudget(ctx context.Context, projectID int64) (ProjectBudget, error) {
+	row := q.db.QueryRowContext(ctx, GetProjectBudget, projectID)
+	var i ProjectBudget
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Unit,
+		&i.Amount,
+		&i.Period,
+		&i.HourlyRate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const GetProjectByName = `-- name: GetProjectByName :one
+const GetProjectByParentAndName = `-- name: GetProjectByParentAndName :one
 SELECT id,
        name,
        company,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
-WHERE name = ?1 COLLATE NOCASE
+WHERE parent_id IS ?1
+  AND name = ?2 COLLATE NOCASE
+  AND deleted_at IS NULL
 `
 
-func (q *Queries) GetProjectByName(ctx context.Context, name string) (Project, error) {
-	row := q.db.QueryRowContext(ctx, GetProjectByName, name)
+type GetProjectByParentAndNameParams struct {
+	ParentID sql.NullInt64
+	Name     string
+}
+
+func (q *Queries) GetProjectByParentAndName(ctx context.Context, arg GetProjectByParentAndNameParams) (Project, error) {
+	row := q.db.QueryRowContext(ctx, GetProjectByParentAndName, arg.ParentID, arg.Name)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -282,6 +602,8 @@ func (q *Queries) GetProjectByName(ctx context.Context, name string) (Project, e
 		&i.Position,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
@@ -309,6 +631,30 @@ func (q *Queries) GetTimer(ctx context.Context, projectID int64) (Timer, error)
 	return i, err
 }
 
+const InsertChange = `-- name: InsertChange :exec
+INSERT INTO change_log (entity_type, entity_id, action, before_json, after_json)
+VALUES (?1, ?2, ?3, ?4, ?5)
+`
+
+type InsertChangeParams struct {
+	EntityType string
+	EntityID   string
+	Action     string
+	BeforeJson sql.NullString
+	AfterJson  sql.NullString
+}
+
+func (q *Queries) InsertChange(ctx context.Context, arg InsertChangeParams) error {
+	_, err := q.db.ExecContext(ctx, InsertChange,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Action,
+		arg.BeforeJson,
+		arg.AfterJson,
+	)
+	return err
+}
+
 const InsertEntryTag = `-- name: InsertEntryTag :exec
 INSERT INTO entry_tags (entry_id, tag)
 VALUES (?1, ?2)
@@ -325,10 +671,88 @@ func (q *Queries) InsertEntryTag(ctx context.Context, arg InsertEntryTagParams)
 	return err
 }
 
+const InsertUndoAction = `-- name: InsertUndoAction :one
+INSERT INTO undo_actions (kind, description, payload)
+VALUES (?1, ?2, ?3)
+RETURNING id,
+          kind,
+          description,
+          payload,
+          created_at
+`
+
+type InsertUndoActionParams struct {
+	Kind        string
+	Description string
+	Payload     string
+}
+
+func (q *Queries) InsertUndoAction(ctx context.Context, arg InsertUndoActionParams) (UndoAction, error) {
+	row := q.db.QueryRowContext(ctx, InsertUndoAction, arg.Kind, arg.Description, arg.Payload)
+	var i UndoAction
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Description,
+		&i.Payload,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const InsertWebhookDelivery = `-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_outbox (url, event, payload, next_attempt_at)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type InsertWebhookDeliveryParams struct {
+	Url           string
+	Event         string
+	Payload       string
+	NextAttemptAt int64
+}
+
+func (q *Queries) InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, InsertWebhookDelivery,
+		arg.Url,
+		arg.Event,
+		arg.Payload,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const LatestChangeID = `-- name: LatestChangeID :one
+SELECT CAST(COALESCE(MAX(id), 0) AS INTEGER)
+FROM change_log
+`
+
+func (q *Queries) LatestChangeID(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, LatestChangeID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const LatestEntryEnd = `-- name: LatestEntryEnd :one
+SELECT CAST(COALESCE(MAX(ended_at), 0) AS INTEGER) AS ended_at
+FROM entries
+WHERE deleted_at IS NULL
+`
+
+func (q *Queries) LatestEntryEnd(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, LatestEntryEnd)
+	var ended_at int64
+	err := row.Scan(&ended_at)
+	return ended_at, err
+}
+
 const ListAllTags = `-- name: ListAllTags :many
-SELECT DISTINCT tag
-FROM entry_tags
-ORDER BY tag
+SELECT DISTINCT t.tag
+FROM entry_tags t
+JOIN entries e ON e.id = t.entry_id
+WHERE e.deleted_at IS NULL
+ORDER BY t.tag
 `
 
 func (q *Queries) ListAllTags(ctx context.Context) ([]string, error) {
@@ -354,53 +778,33 @@ func (q *Queries) ListAllTags(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
-const ListEntriesByProject = `-- name: ListEntriesByProject :many
-
-SELECT id,
-       project_id,
-       creator_id,
-       content,
-       duration_ms,
-       started_at,
-       ended_at,
-       entry_type,
-       is_billable,
-       created_at,
-       updated_at
-FROM entries
-WHERE project_id = ?1
-ORDER BY ended_at IS NULL,
-         ended_at DESC,
-         started_at DESC,
-         created_at DESC
+const ListChangedEntityIDsByPrefix = `-- name: ListChangedEntityIDsByPrefix :many
+SELECT DISTINCT entity_id
+FROM change_log
+WHERE entity_type = ?1
+  AND entity_id LIKE ?2
+ORDER BY entity_id
+LIMIT 2
 `
 
-// Entries
-func (q *Queries) ListEntriesByProject(ctx context.Context, projectID int64) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, ListEntriesByProject, projectID)
+type ListChangedEntityIDsByPrefixParams struct {
+	EntityType string
+	EntityID   string
+}
+
+func (q *Queries) ListChangedEntityIDsByPrefix(ctx context.Context, arg ListChangedEntityIDsByPrefixParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, ListChangedEntityIDsByPrefix, arg.EntityType, arg.EntityID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Entry
+	var items []string
 	for rows.Next() {
-		var i Entry
-		if err := rows.Scan(
-			&i.ID,
-			&i.ProjectID,
-			&i.CreatorID,
-			&i.Content,
-			&i.DurationMs,
-			&i.StartedAt,
-			&i.EndedAt,
-			&i.EntryType,
-			&i.IsBillable,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
+		var entity_id string
+		if err := rows.Scan(&entity_id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, entity_id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -411,9 +815,767 @@ func (q *Queries) ListEntriesByProject(ctx context.Context, projectID int64) ([]
 	return items, nil
 }
 
-const ListEntriesByTag = `-- name: ListEntriesByTag :many
-SELECT e.id,
-       e.project_id,
+const ListChangesAfter = `-- name: ListChangesAfter :many
+SELECT id,
+       entity_type,
+       entity_id,
+       action,
+       before_json,
+       after_json,
+       created_at
+FROM change_log
+WHERE id > ?1
+ORDER BY id ASC
+`
+
+func (q *Queries) ListChangesAfter(ctx context.Context, id int64) ([]ChangeLog, error) {
+	rows, err := q.db.QueryContext(ctx, ListChangesAfter, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChangeLog
+	for rows.Next() {
+		var i ChangeLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Action,
+			&i.BeforeJson,
+			&i.AfterJson,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListChangesForEntity = `-- name: ListChangesForEntity :many
+SELECT id,
+       entity_type,
+       entity_id,
+       action,
+       before_json,
+       after_json,
+       created_at
+FROM change_log
+WHERE entity_type = ?1
+  AND entity_id = ?2
+ORDER BY id ASC
+`
+
+type ListChangesForEntityParams struct {
+	EntityType string
+	EntityID   string
+}
+
+func (q *Queries) ListChangesForEntity(ctx context.Context, arg ListChangesForEntityParams) ([]ChangeLog, error) {
+	rows, err := q.db.QueryContext(ctx, ListChangesForEntity, arg.EntityType, arg.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChangeLog
+	for rows.Next() {
+		var i ChangeLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Action,
+			&i.BeforeJson,
+			&i.AfterJson,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListChildProjects = `-- name: ListChildProjects :many
+SELECT id,
+       name,
+       company,
+       is_hidden,
+       position,
+       created_at,
+       updated_at,
+       deleted_at,
+       parent_id
+FROM projects
+WHERE parent_id = ?1
+ORDER BY position ASC,
+         updated_at DESC
+`
+
+func (q *Queries) ListChildProjects(ctx context.Context, parentID sql.NullInt64) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, ListChildProjects, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Company,
+			&i.IsHidden,
+			&i.Position,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedEntries = `-- name: ListDeletedEntries :many
+SELECT id,
+       project_id,
+       creator_id,
+       content,
+       duration_ms,
+       started_at,
+       ended_at,
+       entry_type,
+       is_billable,
+       created_at,
+       updated_at,
+       deleted_at
+FROM entries
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListDeletedEntries(ctx context.Context) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, ListDeletedEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.CreatorID,
+			&i.Content,
+			&i.DurationMs,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.EntryType,
+			&i.IsBillable,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedProjects = `-- name: ListDeletedProjects :many
+SELECT id,
+       name,
+       company,
+       is_hidden,
+       position,
+       created_at,
+       updated_at,
+       deleted_at,
+       parent_id
+FROM projects
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListDeletedProjects(ctx context.Context) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, ListDeletedProjects)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Company,
+			&i.IsHidden,
+			&i.Position,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDueWebhookDeliveries = `-- name: ListDueWebhookDeliveries :many
+SELECT id,
+       url,
+       event,
+       payload,
+       attempts,
+       next_attempt_at,
+       last_error,
+       failed_at,
+       created_at
+FROM webhook_outbox
+WHERE failed_at IS NULL
+  AND next_attempt_at <= ?1
+ORDER BY id ASC
+LIMIT ?2
+`
+
+type ListDueWebhookDeliveriesParams struct {
+	NextAttemptAt int64
+	Limit         int64
+}
+
+func (q *Queries) ListDueWebhookDeliveries(ctx context.Context, arg ListDueWebhookDeliveriesParams) ([]WebhookOutbox, error) {
+	rows, err := q.db.QueryContext(ctx, ListDueWebhookDeliveries, arg.NextAttemptAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookOutbox
+	for rows.Next() {
+		var i WebhookOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Event,
+			&i.Payload,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+			&i.FailedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEntriesByProject = `-- name: ListEntriesByProject :many
+
+SELECT id,
+       project_id,
+       creator_id,
+       content,
+       duration_ms,
+       started_at,
+       ended_at,
+       entry_type,
+       is_billable,
+       created_at,
+       updated_at,
+       deleted_at
+FROM entries
+WHERE project_id = ?1
+  AND deleted_at IS NULL
+ORDER BY ended_at IS NULL,
+         ended_at DESC,
+         started_at DESC,
+         created_at DESC
+`
+
+// Entries
+func (q *Queries) ListEntriesByProject(ctx context.Context, projectID int64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, ListEntriesByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.CreatorID,
+			&i.Content,
+			&i.DurationMs,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.EntryType,
+			&i.IsBillable,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEntriesByTag = `-- name: ListEntriesByTag :many
+SELECT e.id,
+       e.project_id,
+       e.creator_id,
+       e.content,
+       e.duration_ms,
+       e.started_at,
+       e.ended_at,
+       e.entry_type,
+       e.is_billable,
+       e.created_at,
+       e.updated_at,
+       e.deleted_at
+FROM entries e
+JOIN entry_tags t ON t.entry_id = e.id
+JOIN projects p ON p.id = e.project_id
+WHERE t.tag = ?1 COLLATE NOCASE
+  AND e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+ORDER BY e.ended_at IS NULL,
+         e.ended_at DESC,
+         e.started_at DESC,
+         e.created_at DESC
+`
+
+func (q *Queries) ListEntriesByTag(ctx context.Context, tag string) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, ListEntriesByTag, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.CreatorID,
+			&i.Content,
+			&i.DurationMs,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.EntryType,
+			&i.IsBillable,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEntriesInRange = `-- name: ListEntriesInRange :many
+WITH RECURSIVE tree(id) AS (
+    SELECT ?3
+    UNION ALL
+    SELECT p.id
+    FROM projects p
+    JOIN tree t ON p.parent_id = t.id
+    WHERE p.deleted_at IS NULL
+)
+SELECT e.id,
+       e.project_id,
+       e.creator_id,
+       e.content,
+       e.duration_ms,
+       e.started_at,
+       e.ended_at,
+       e.entry_type,
+       e.is_billable,
+       e.created_at,
+       e.updated_at,
+       e.deleted_at
+FROM entries e
+JOIN projects p ON p.id = e.project_id
+WHERE e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND e.ended_at IS NOT NULL
+  AND e.ended_at >= ?1
+  AND e.ended_at < ?2
+  AND (?3 IS NULL OR e.project_id IN (SELECT id FROM tree))
+  AND (?4 IS NULL OR EXISTS (
+      SELECT 1
+      FROM entry_tags t
+      WHERE t.entry_id = e.id
+        AND t.tag = ?4
+  ))
+ORDER BY COALESCE(e.started_at, e.ended_at) ASC,
+         e.created_at ASC
+`
+
+type ListEntriesInRangeParams struct {
+	EndedAt   sql.NullInt64
+	EndedAt_2 sql.NullInt64
+	ProjectID sql.NullInt64
+	Tag       sql.NullString
+}
+
+func (q *Queries) ListEntriesInRange(ctx context.Context, arg ListEntriesInRangeParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, ListEntriesInRange,
+		arg.EndedAt,
+		arg.EndedAt_2,
+		arg.ProjectID,
+		arg.Tag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.CreatorID,
+			&i.Content,
+			&i.DurationMs,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.EntryType,
+			&i.IsBillable,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEntryDurationsInRange = `-- name: ListEntryDurationsInRange :many
+WITH RECURSIVE tree(id) AS (
+    SELECT ?3
+    UNION ALL
+    SELECT p.id
+    FROM projects p
+    JOIN tree t ON p.parent_id = t.id
+    WHERE p.deleted_at IS NULL
+)
+SELECT e.duration_ms,
+       e.ended_at
+FROM entries e
+JOIN projects p ON p.id = e.project_id
+WHERE e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND e.ended_at IS NOT NULL
+  AND e.ended_at >= ?1
+  AND e.ended_at < ?2
+  AND (?3 IS NULL OR e.project_id IN (SELECT id FROM tree))
+  AND (?4 IS NULL OR EXISTS (
+      SELECT 1
+      FROM entry_tags t
+      WHERE t.entry_id = e.id
+        AND t.tag = ?4
+  ))
+ORDER BY e.ended_at ASC
+`
+
+type ListEntryDurationsInRangeParams struct {
+	EndedAt   sql.NullInt64
+	EndedAt_2 sql.NullInt64
+	ProjectID sql.NullInt64
+	Tag       sql.NullString
+}
+
+type ListEntryDurationsInRangeRow struct {
+	DurationMs int64
+	EndedAt    sql.NullInt64
+}
+
+func (q *Queries) ListEntryDurationsInRange(ctx context.Context, arg ListEntryDurationsInRangeParams) ([]ListEntryDurationsInRangeRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListEntryDurationsInRange,
+		arg.EndedAt,
+		arg.EndedAt_2,
+		arg.ProjectID,
+		arg.Tag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEntryDurationsInRangeRow
+	for rows.Next() {
+		var i ListEntryDurationsInRangeRow
+		if err := rows.Scan(&i.DurationMs, &i.EndedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEntryIDsByProject = `-- name: ListEntryIDsByProject :many
+SELECT id
+FROM entries
+WHERE project_id = ?1
+ORDER BY created_at
+`
+
+func (q *Queries) ListEntryIDsByProject(ctx context.Context, projectID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, ListEntryIDsByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEntryTemplates = `-- name: ListEntryTemplates :many
+SELECT t.id,
+       t.name,
+       t.project_id,
+       t.content,
+       t.duration_ms,
+       t.tags,
+       t.entry_type,
+       t.is_billable,
+       t.repeat_days,
+       t.repeat_minute,
+       t.repeat_from,
+       t.created_at,
+       t.updated_at
+FROM entry_templates t
+JOIN projects p ON p.id = t.project_id
+WHERE p.deleted_at IS NULL
+ORDER BY t.name ASC
+`
+
+func (q *Queries) ListEntryTemplates(ctx context.Context) ([]EntryTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, ListEntryTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EntryTemplate
+	for rows.Next() {
+		var i EntryTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ProjectID,
+			&i.Content,
+			&i.DurationMs,
+			&i.Tags,
+			&i.EntryType,
+			&i.IsBillable,
+			&i.RepeatDays,
+			&i.RepeatMinute,
+			&i.RepeatFrom,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListFailedWebhookDeliveries = `-- name: ListFailedWebhookDeliveries :many
+SELECT id,
+       url,
+       event,
+       payload,
+       attempts,
+       next_attempt_at,
+       last_error,
+       failed_at,
+       created_at
+FROM webhook_outbox
+WHERE failed_at IS NOT NULL
+ORDER BY failed_at DESC, id DESC
+LIMIT ?1
+`
+
+func (q *Queries) ListFailedWebhookDeliveries(ctx context.Context, limit int64) ([]WebhookOutbox, error) {
+	rows, err := q.db.QueryContext(ctx, ListFailedWebhookDeliveries, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookOutbox
+	for rows.Next() {
+		var i WebhookOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Event,
+			&i.Payload,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+			&i.FailedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListGoals = `-- name: ListGoals :many
+SELECT id,
+       period,
+       minutes,
+       project_id,
+       tag,
+       created_at,
+       updated_at
+FROM goals
+ORDER BY period ASC,
+         project_id IS NOT NULL,
+         tag IS NOT NULL,
+         id ASC
+`
+
+func (q *Queries) ListGoals(ctx context.Context) ([]Goal, error) {
+	rows, err := q.db.QueryContext(ctx, ListGoals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Goal
+	for rows.Next() {
+		var i Goal
+		if err := rows.Scan(
+			&i.ID,
+			&i.Period,
+			&i.Minutes,
+			&i.ProjectID,
+			&i.Tag,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListOverlappingEntries = `-- name: ListOverlappingEntries :many
+SELECT e.id,
+       e.project_id,
        e.creator_id,
        e.content,
        e.duration_ms,
@@ -422,37 +1584,188 @@ SELECT e.id,
        e.entry_type,
        e.is_billable,
        e.created_at,
-       e.updated_at
+       e.updated_at,
+       e.deleted_at
 FROM entries e
-JOIN entry_tags t ON t.entry_id = e.id
-WHERE t.tag = ?1 COLLATE NOCASE
-ORDER BY e.ended_at IS NULL,
-         e.ended_at DESC,
-         e.started_at DESC,
-         e.created_at DESC
+JOIN projects p ON p.id = e.project_id
+WHERE e.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND e.id != ?3
+  AND e.ended_at > ?1
+  AND (e.started_at < ?2
+       OR (e.started_at IS NULL AND e.ended_at - e.duration_ms / 1000 < ?2))
+ORDER BY COALESCE(e.started_at, e.ended_at) ASC,
+         e.created_at ASC
+`
+
+type ListOverlappingEntriesParams struct {
+	EndedAt   sql.NullInt64
+	StartedAt sql.NullInt64
+	ID        string
+}
+
+func (q *Queries) ListOverlappingEntries(ctx context.Context, arg ListOverlappingEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, ListOverlappingEntries,
+		arg.EndedAt,
+		arg.StartedAt,
+		arg.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.CreatorID,
+			&i.Content,
+			&i.DurationMs,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.EntryType,
+			&i.IsBillable,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListPeople = `-- name: ListPeople :many
+SELECT id,
+       email,
+       name,
+       created_at,
+       updated_at
+FROM people
+ORDER BY name COLLATE NOCASE, email
+`
+
+func (q *Queries) ListPeople(ctx context.Context) ([]Person, error) {
+	rows, err := q.db.QueryContext(ctx, ListPeople)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Person
+	for rows.Next() {
+		var i Person
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProjectBudgets = `-- name: ListProjectBudgets :many
+SELECT b.project_id,
+       b.unit,
+       b.amount,
+       b.period,
+       b.hourly_rate,
+       b.created_at,
+       b.updated_at
+FROM project_budgets b
+JOIN projects p ON p.id = b.project_id
+WHERE p.deleted_at IS NULL
+ORDER BY p.position ASC,
+         p.updated_at DESC
+`
+
+func (q *Queries) ListProjectBudgets(ctx context.Context) ([]ProjectBudget, error) {
+	rows, err := q.db.QueryContext(ctx, ListProjectBudgets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProjectBudget
+	for rows.Next() {
+		var i ProjectBudget
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.Unit,
+			&i.Amount,
+			&i.Period,
+			&i.HourlyRate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProjects = `-- name: ListProjects :many
+
+SELECT id,
+       name,
+       company,
+       is_hidden,
+       position,
+       created_at,
+       updated_at,
+       deleted_at,
+       parent_id
+FROM projects
+WHERE deleted_at IS NULL
+ORDER BY position ASC,
+         updated_at DESC
 `
 
-func (q *Queries) ListEntriesByTag(ctx context.Context, tag string) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, ListEntriesByTag, tag)
+// Projects
+func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, ListProjects)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Entry
+	var items []Project
 	for rows.Next() {
-		var i Entry
+		var i Project
 		if err := rows.Scan(
 			&i.ID,
-			&i.ProjectID,
-			&i.CreatorID,
-			&i.Content,
-			&i.DurationMs,
-			&i.StartedAt,
-			&i.EndedAt,
-			&i.EntryType,
-			&i.IsBillable,
+			&i.Name,
+			&i.Company,
+			&i.IsHidden,
+			&i.Position,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -467,23 +1780,23 @@ func (q *Queries) ListEntriesByTag(ctx context.Context, tag string) ([]Entry, er
 	return items, nil
 }
 
-const ListProjects = `-- name: ListProjects :many
-
+const ListProjectsByName = `-- name: ListProjectsByName :many
 SELECT id,
        name,
        company,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
-ORDER BY position ASC,
-         updated_at DESC
+WHERE name = ?1 COLLATE NOCASE
+ORDER BY id
 `
 
-// Projects
-func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
-	rows, err := q.db.QueryContext(ctx, ListProjects)
+func (q *Queries) ListProjectsByName(ctx context.Context, name string) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, ListProjectsByName, name)
 	if err != nil {
 		return nil, err
 	}
@@ -499,6 +1812,8 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 			&i.Position,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -513,6 +1828,55 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 	return items, nil
 }
 
+const ListRunningTimers = `-- name: ListRunningTimers :many
+WITH RECURSIVE chain(project_id, ancestor_id, path) AS (
+    SELECT t.project_id, p.parent_id, p.name
+    FROM timers t
+    JOIN projects p ON p.id = t.project_id
+    WHERE p.deleted_at IS NULL
+    UNION ALL
+    SELECT c.project_id, a.parent_id, a.name || '/' || c.path
+    FROM chain c
+    JOIN projects a ON a.id = c.ancestor_id
+)
+SELECT c.project_id,
+       CAST(c.path AS TEXT) AS path,
+       t.started_at
+FROM chain c
+JOIN timers t ON t.project_id = c.project_id
+WHERE c.ancestor_id IS NULL
+ORDER BY t.started_at DESC, c.project_id
+`
+
+type ListRunningTimersRow struct {
+	ProjectID int64
+	Path      string
+	StartedAt int64
+}
+
+func (q *Queries) ListRunningTimers(ctx context.Context) ([]ListRunningTimersRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListRunningTimers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRunningTimersRow
+	for rows.Next() {
+		var i ListRunningTimersRow
+		if err := rows.Scan(&i.ProjectID, &i.Path, &i.StartedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListTagsForEntry = `-- name: ListTagsForEntry :many
 
 SELECT tag,
@@ -551,6 +1915,42 @@ func (q *Queries) ListTagsForEntry(ctx context.Context, entryID string) ([]ListT
 	return items, nil
 }
 
+const ListTemplateOccurrenceDays = `-- name: ListTemplateOccurrenceDays :many
+SELECT occurs_on
+FROM template_occurrences
+WHERE template_id = ?1
+  AND occurs_on >= ?2
+ORDER BY occurs_on ASC
+`
+
+type ListTemplateOccurrenceDaysParams struct {
+	TemplateID int64
+	OccursOn   string
+}
+
+func (q *Queries) ListTemplateOccurrenceDays(ctx context.Context, arg ListTemplateOccurrenceDaysParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, ListTemplateOccurrenceDays, arg.TemplateID, arg.OccursOn)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var occurs_on string
+		if err := rows.Scan(&occurs_on); err != nil {
+			return nil, err
+		}
+		items = append(items, occurs_on)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListVisibleProjects = `-- name: ListVisibleProjects :many
 SELECT id,
        name,
@@ -558,9 +1958,12 @@ SELECT id,
        is_hidden,
        position,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       parent_id
 FROM projects
 WHERE is_hidden = 0
+  AND deleted_at IS NULL
 ORDER BY position ASC,
          updated_at DESC
 `
@@ -582,6 +1985,8 @@ func (q *Queries) ListVisibleProjects(ctx context.Context) ([]Project, error) {
 			&i.Position,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -596,12 +2001,29 @@ func (q *Queries) ListVisibleProjects(ctx context.Context) ([]Project, error) {
 	return items, nil
 }
 
+const PostponeWebhookDelivery = `-- name: PostponeWebhookDelivery :exec
+UPDATE webhook_outbox
+SET next_attempt_at = ?2
+WHERE id = ?1
+`
+
+type PostponeWebhookDeliveryParams struct {
+	ID            int64
+	NextAttemptAt int64
+}
+
+func (q *Queries) PostponeWebhookDelivery(ctx context.Context, arg PostponeWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, PostponeWebhookDelivery, arg.ID, arg.NextAttemptAt)
+	return err
+}
+
 const ProjectTotalsInRange = `-- name: ProjectTotalsInRange :one
 SELECT COALESCE(SUM(duration_ms), 0) AS total_duration_ms,
        COALESCE(SUM(CASE WHEN is_billable = 1 THEN duration_ms ELSE 0 END), 0) AS billable_duration_ms,
        COUNT(*) AS entry_count
 FROM entries
 WHERE project_id = ?1
+  AND deleted_at IS NULL
   AND ended_at IS NOT NULL
   AND ended_at >= ?2
   AND ended_at < ?3
@@ -626,6 +2048,150 @@ func (q *Queries) ProjectTotalsInRange(ctx context.Context, arg ProjectTotalsInR
 	return i, err
 }
 
+const ProjectTreeTotalsInRange = `-- name: ProjectTreeTotalsInRange :one
+WITH RECURSIVE tree(id) AS (
+    SELECT ?1
+    UNION ALL
+    SELECT p.id
+    FROM projects p
+    JOIN tree t ON p.parent_id = t.id
+    WHERE p.deleted_at IS NULL
+)
+SELECT CAST(COALESCE(SUM(e.duration_ms), 0) AS INTEGER) AS total_duration_ms,
+       CAST(COALESCE(SUM(CASE WHEN e.is_billable = 1 THEN e.duration_ms ELSE 0 END), 0) AS INTEGER) AS billable_duration_ms
+FROM entries e
+WHERE e.project_id IN (SELECT id FROM tree)
+  AND e.deleted_at IS NULL
+  AND e.ended_at IS NOT NULL
+  AND e.ended_at >= ?2
+  AND e.ended_at < ?3
+`
+
+type ProjectTreeTotalsInRangeParams struct {
+	ProjectID int64
+	EndedAt   sql.NullInt64
+	EndedAt_2 sql.NullInt64
+}
+
+type ProjectTreeTotalsInRangeRow struct {
+	TotalDurationMs    int64
+	BillableDurationMs int64
+}
+
+func (q *Queries) ProjectTreeTotalsInRange(ctx context.Context, arg ProjectTreeTotalsInRangeParams) (ProjectTreeTotalsInRangeRow, error) {
+	row := q.db.QueryRowContext(ctx, ProjectTreeTotalsInRange, arg.ProjectID, arg.EndedAt, arg.EndedAt_2)
+	var i ProjectTreeTotalsInRangeRow
+	err := row.Scan(&i.TotalDurationMs, &i.BillableDurationMs)
+	return i, err
+}
+
+const PruneUndoActions = `-- name: PruneUndoActions :exec
+DELETE FROM undo_actions
+WHERE id NOT IN (
+    SELECT id
+    FROM undo_actions
+    ORDER BY id DESC
+    LIMIT ?1
+)
+`
+
+func (q *Queries) PruneUndoActions(ctx context.Context, limit int64) error {
+	_, err := q.db.ExecContext(ctx, PruneUndoActions, limit)
+	return err
+}
+
+const RecordWebhookFailure = `-- name: RecordWebhookFailure :exec
+UPDATE webhook_outbox
+SET attempts = ?2,
+    next_attempt_at = ?3,
+    last_error = ?4,
+    failed_at = ?5
+WHERE id = ?1
+`
+
+type RecordWebhookFailureParams struct {
+	ID            int64
+	Attempts      int64
+	NextAttemptAt int64
+	LastError     sql.NullString
+	FailedAt      sql.NullInt64
+}
+
+func (q *Queries) RecordWebhookFailure(ctx context.Context, arg RecordWebhookFailureParams) error {
+	_, err := q.db.ExecContext(ctx, RecordWebhookFailure,
+		arg.ID,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.LastError,
+		arg.FailedAt,
+	)
+	return err
+}
+
+const RestoreEntry = `-- name: RestoreEntry :exec
+UPDATE entries
+SET deleted_at = NULL,
+    updated_at = unixepoch()
+WHERE id = ?1
+`
+
+func (q *Queries) RestoreEntry(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, RestoreEntry, id)
+	return err
+}
+
+const RestoreProject = `-- name: RestoreProject :exec
+UPDATE projects
+SET deleted_at = NULL,
+    updated_at = unixepoch()
+WHERE id = ?1
+`
+
+func (q *Queries) RestoreProject(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, RestoreProject, id)
+	return err
+}
+
+const RetryFailedWebhookDeliveries = `-- name: RetryFailedWebhookDeliveries :execrows
+UPDATE webhook_outbox
+SET attempts = 0,
+    next_attempt_at = ?1,
+    failed_at = NULL
+WHERE failed_at IS NOT NULL
+`
+
+func (q *Queries) RetryFailedWebhookDeliveries(ctx context.Context, nextAttemptAt int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, RetryFailedWebhookDeliveries, nextAttemptAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const SoftDeleteEntry = `-- name: SoftDeleteEntry :exec
+UPDATE entries
+SET deleted_at = unixepoch(),
+    updated_at = unixepoch()
+WHERE id = ?1
+`
+
+func (q *Queries) SoftDeleteEntry(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, SoftDeleteEntry, id)
+	return err
+}
+
+const SoftDeleteProject = `-- name: SoftDeleteProject :exec
+UPDATE projects
+SET deleted_at = unixepoch(),
+    updated_at = unixepoch()
+WHERE id = ?1
+`
+
+func (q *Queries) SoftDeleteProject(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, SoftDeleteProject, id)
+	return err
+}
+
 const TouchProject = `-- name: TouchProject :exec
 UPDATE projects
 SET updated_at = unixepoch()
@@ -659,7 +2225,8 @@ RETURNING id,
           entry_type,
           is_billable,
           created_at,
-          updated_at
+          updated_at,
+          deleted_at
 `
 
 type UpdateEntryParams struct {
@@ -699,6 +2266,114 @@ func (q *Queries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry
 		&i.IsBillable,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpdateEntryTemplate = `-- name: UpdateEntryTemplate :one
+UPDATE entry_templates
+SET project_id = ?2,
+    content = ?3,
+    duration_ms = ?4,
+    tags = ?5,
+    entry_type = ?6,
+    is_billable = ?7,
+    repeat_days = ?8,
+    repeat_minute = ?9,
+    repeat_from = ?10,
+    updated_at = unixepoch()
+WHERE id = ?1
+RETURNING id,
+          name,
+          project_id,
+          content,
+          duration_ms,
+          tags,
+          entry_type,
+          is_billable,
+          repeat_days,
+          repeat_minute,
+          repeat_from,
+          created_at,
+          updated_at
+`
+
+type UpdateEntryTemplateParams struct {
+	ID           int64
+	ProjectID    int64
+	Content      string
+	DurationMs   int64
+	Tags         string
+	EntryType    string
+	IsBillable   int64
+	RepeatDays   int64
+	RepeatMinute int64
+	RepeatFrom   sql.NullString
+}
+
+func (q *Queries) UpdateEntryTemplate(ctx context.Context, arg UpdateEntryTemplateParams) (EntryTemplate, error) {
+	row := q.db.QueryRowContext(ctx, UpdateEntryTemplate,
+		arg.ID,
+		arg.ProjectID,
+		arg.Content,
+		arg.DurationMs,
+		arg.Tags,
+		arg.EntryType,
+		arg.IsBillable,
+		arg.RepeatDays,
+		arg.RepeatMinute,
+		arg.RepeatFrom,
+	)
+	var i EntryTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProjectID,
+		&i.Content,
+		&i.DurationMs,
+		&i.Tags,
+		&i.EntryType,
+		&i.IsBillable,
+		&i.RepeatDays,
+		&i.RepeatMinute,
+		&i.RepeatFrom,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const UpdateGoalMinutes = `-- name: UpdateGoalMinutes :one
+UPDATE goals
+SET minutes = ?2,
+    updated_at = unixepoch()
+WHERE id = ?1
+RETURNING id,
+          period,
+          minutes,
+          project_id,
+          tag,
+          created_at,
+          updated_at
+`
+
+type UpdateGoalMinutesParams struct {
+	ID      int64
+	Minutes int64
+}
+
+func (q *Queries) UpdateGoalMinutes(ctx context.Context, arg UpdateGoalMinutesParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, UpdateGoalMinutes, arg.ID, arg.Minutes)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.Period,
+		&i.Minutes,
+		&i.ProjectID,
+		&i.Tag,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -708,6 +2383,7 @@ UPDATE projects
 SET name = ?2,
     company = ?3,
     is_hidden = ?4,
+    parent_id = ?5,
     updated_at = unixepoch()
 WHERE id = ?1
 RETURNING id,
@@ -716,7 +2392,9 @@ RETURNING id,
           is_hidden,
           position,
           created_at,
-          updated_at
+          updated_at,
+          deleted_at,
+          parent_id
 `
 
 type UpdateProjectParams struct {
@@ -724,6 +2402,7 @@ type UpdateProjectParams struct {
 	Name     string
 	Company  sql.NullString
 	IsHidden int64
+	ParentID sql.NullInt64
 }
 
 func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error) {
@@ -732,6 +2411,7 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		arg.Name,
 		arg.Company,
 		arg.IsHidden,
+		arg.ParentID,
 	)
 	var i Project
 	err := row.Scan(
@@ -742,6 +2422,8 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.Position,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
@@ -777,6 +2459,53 @@ func (q *Queries) UpsertPerson(ctx context.Context, arg UpsertPersonParams) (Per
 	return i, err
 }
 
+const UpsertProjectBudget = `-- name: UpsertProjectBudget :one
+INSERT INTO project_budgets (project_id, unit, amount, period, hourly_rate)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT(project_id) DO UPDATE
+SET unit = excluded.unit,
+    amount = excluded.amount,
+    period = excluded.period,
+    hourly_rate = excluded.hourly_rate,
+    updated_at = unixepoch()
+RETURNING project_id,
+          unit,
+          amount,
+          period,
+          hourly_rate,
+          created_at,
+          updated_at
+`
+
+type UpsertProjectBudgetParams struct {
+	ProjectID  int64
+	Unit       string
+	Amount     int64
+	Period     string
+	HourlyRate sql.NullInt64
+}
+
+func (q *Queries) UpsertProjectBudget(ctx context.Context, arg UpsertProjectBudgetParams) (ProjectBudget, error) {
+	row := q.db.QueryRowContext(ctx, UpsertProjectBudget,
+		arg.ProjectID,
+		arg.Unit,
+		arg.Amount,
+		arg.Period,
+		arg.HourlyRate,
+	)
+	var i ProjectBudget
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Unit,
+		&i.Amount,
+		&i.Period,
+		&i.HourlyRate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const UpsertTimer = `-- name: UpsertTimer :one
 INSERT INTO timers (project_id, started_at)
 VALUES (?1, ?2)
@@ -805,3 +2534,41 @@ func (q *Queries) UpsertTimer(ctx context.Context, arg UpsertTimerParams) (Timer
 	)
 	return i, err
 }
+
+const WebhookOutboxStatus = `-- name: WebhookOutboxStatus :many
+SELECT url,
+       CAST(SUM(failed_at IS NULL) AS INTEGER) AS pending,
+       CAST(SUM(failed_at IS NOT NULL) AS INTEGER) AS failed
+FROM webhook_outbox
+GROUP BY url
+ORDER BY url
+`
+
+type WebhookOutboxStatusRow struct {
+	Url     string
+	Pending int64
+	Failed  int64
+}
+
+func (q *Queries) WebhookOutboxStatus(ctx context.Context) ([]WebhookOutboxStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, WebhookOutboxStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookOutboxStatusRow
+	for rows.Next() {
+		var i WebhookOutboxStatusRow
+		if err := rows.Scan(&i.Url, &i.Pending, &i.Failed); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,410 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+	"github.com/nexneo/samay/util"
+)
+
+// ErrTemplateNotFound is returned when no template has the requested name.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// occurrenceDay is how template occurrences record the local day they ran on.
+const occurrenceDay = "2006-01-02"
+
+// Recurrence repeats a template on some days of the week at a time of day.
+type Recurrence struct {
+	Days   uint8 // bit n is set when the template repeats on time.Weekday(n)
+	Minute int   // minutes after local midnight
+}
+
+const (
+	everyDay     uint8 = 1<<7 - 1
+	everyWeekday uint8 = everyDay &^ (1<<time.Sunday | 1<<time.Saturday)
+	everyWeekend uint8 = 1<<time.Sunday | 1<<time.Saturday
+)
+
+// ParseRecurrence reads a rule such as "every weekday 09:30", "every day 8am",
+// "every mon, wed 14:00" or "weekends 10:00". A trailing duration, as in
+// "every weekday 09:30 15m", is returned as well; it is zero when absent.
+func ParseRecurrence(spec string) (Recurrence, time.Duration, error) {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(spec, ",", " ")))
+	if len(words) > 0 && words[0] == "every" {
+		words = words[1:]
+	}
+	var r Recurrence
+days:
+	for len(words) > 0 {
+		switch word := words[0]; word {
+		case "day", "daily":
+			r.Days |= everyDay
+		case "weekday", "weekdays":
+			r.Days |= everyWeekday
+		case "weekend", "weekends":
+			r.Days |= everyWeekend
+		case "and":
+		default:
+			weekday, ok := util.ParseWeekday(word)
+			if !ok {
+				weekday, ok = util.ParseWeekday(strings.TrimSuffix(word, "s"))
+			}
+			if !ok {
+				break days
+			}
+			r.Days |= 1 << weekday
+		}
+		words = words[1:]
+	}
+	if r.Days == 0 {
+		return Recurrence{}, 0, fmt.Errorf("recurrence %q: start with the days, such as every weekday, every day or every mon, wed", spec)
+	}
+
+	var length time.Duration
+	if len(words) > 1 {
+		if d, err := util.ParseDuration(words[len(words)-1]); err == nil {
+			if _, err := recurrenceClock(strings.Join(words[:len(words)-1], " ")); err == nil {
+				length = d
+				words = words[:len(words)-1]
+			}
+		}
+	}
+	minute, err := recurrenceClock(strings.Join(words, " "))
+	if err != nil {
+		return Recurrence{}, 0, fmt.Errorf("recurrence %q: give the time it starts, such as 09:30 or 2pm", spec)
+	}
+	r.Minute = minute
+	return r, length, nil
+}
+
+func recurrenceClock(s string) (int, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, errors.New("missing time")
+	}
+	midnight := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	at, err := util.ParseTime(s, midnight)
+	if err != nil {
+		return 0, err
+	}
+	if at.YearDay() != midnight.YearDay() {
+		return 0, errors.New("not a time of day")
+	}
+	return at.Hour()*60 + at.Minute(), nil
+}
+
+var mondayFirst = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// IsZero reports whether the rule never repeats.
+func (r Recurrence) IsZero() bool {
+	return r.Days == 0
+}
+
+// On reports whether the rule repeats on weekday.
+func (r Recurrence) On(weekday time.Weekday) bool {
+	return r.Days&(1<<weekday) != 0
+}
+
+// At returns when the rule fires on day, in day's location.
+func (r Recurrence) At(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), r.Minute/60, r.Minute%60, 0, 0, day.Location())
+}
+
+// String renders the rule in the form accepted by ParseRecurrence.
+func (r Recurrence) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	var days string
+	switch r.Days {
+	case everyDay:
+		days = "day"
+	case everyWeekday:
+		days = "weekday"
+	case everyWeekend:
+		days = "weekend"
+	default:
+		var names []string
+		for _, weekday := range mondayFirst {
+			if r.On(weekday) {
+				names = append(names, strings.ToLower(weekday.String()[:3]))
+			}
+		}
+		days = strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("every %s %02d:%02d", days, r.Minute/60, r.Minute%60)
+}
+
+// Template is a ready-made entry that can be logged in one step, and optionally
+// logged automatically on a schedule.
+type Template struct {
+	db       *Database
+	ID       int64
+	Name     string
+	Project  *Project
+	Content  string
+	Duration time.Duration
+	Tags     []string
+	Type     EntryType
+	Billable bool
+	Repeat   Recurrence
+	// RepeatFrom is the first day the schedule applies to; days before it are never filled in.
+	RepeatFrom time.Time
+}
+
+func newTemplateFromModel(d *Database, model sqlc.EntryTemplate) (*Template, error) {
+	project, err := d.Project(model.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	t := &Template{
+		db:       d,
+		ID:       model.ID,
+		Name:     model.Name,
+		Project:  project,
+		Content:  model.Content,
+		Duration: time.Duration(model.DurationMs) * time.Millisecond,
+		Tags:     strings.Fields(model.Tags),
+		Type:     EntryType(model.EntryType),
+		Billable: model.IsBillable == 1,
+		Repeat:   Recurrence{Days: uint8(model.RepeatDays), Minute: int(model.RepeatMinute)},
+	}
+	if model.RepeatFrom.Valid {
+		t.RepeatFrom, err = time.ParseInLocation(occurrenceDay, model.RepeatFrom.String, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("template %q: %w", model.Name, err)
+		}
+	}
+	return t, nil
+}
+
+// Templates lists every template whose project is not in the trash, by name.
+func (d *Database) Templates() ([]*Template, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows, err := d.queries.ListEntryTemplates(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	templates := make([]*Template, 0, len(rows))
+	for _, row := range rows {
+		t, err := newTemplateFromModel(d, row)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// Template finds a template by name, ignoring case.
+func (d *Database) Template(name string) (*Template, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	row, err := d.queries.GetEntryTemplateByName(context.Background(), strings.TrimSpace(name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %q", ErrTemplateNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find template: %w", err)
+	}
+	return newTemplateFromModel(d, row)
+}
+
+// SaveTemplate creates the template or replaces the one with the same name. A new
+// or changed schedule starts today, so saving never fills in days gone by.
+func (d *Database) SaveTemplate(t *Template) error {
+	if d == nil {
+		return errors.New("database not initialized")
+	}
+	t.Name = strings.TrimSpace(t.Name)
+	t.Content = strings.TrimSpace(t.Content)
+	switch {
+	case t.Name == "":
+		return errors.New("template needs a name")
+	case t.Project == nil:
+		return errors.New("template needs a project")
+	case t.Duration < time.Minute:
+		return errors.New("template duration must be at least a minute")
+	}
+	if t.Type == "" {
+		t.Type = EntryTypeWork
+	}
+	if _, err := ParseEntryType(string(t.Type)); err != nil {
+		return err
+	}
+	tags := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		tags = append(tags, normalizeTag(tag))
+	}
+	t.Tags = uniqueSortedTags(tags)
+
+	ctx := context.Background()
+	return d.withTx(ctx, func(q *sqlc.Queries) error {
+		existing, err := q.GetEntryTemplateByName(ctx, t.Name)
+		found := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("find template: %w", err)
+		}
+		repeatFrom := sql.NullString{}
+		if !t.Repeat.IsZero() {
+			from := StartOfDay(time.Now()).Format(occurrenceDay)
+			if found && existing.RepeatFrom.Valid && existing.RepeatDays == int64(t.Repeat.Days) && existing.RepeatMinute == int64(t.Repeat.Minute) {
+				from = existing.RepeatFrom.String
+			}
+			repeatFrom = sql.NullString{String: from, Valid: true}
+		}
+
+		var saved sqlc.EntryTemplate
+		if found {
+			saved, err = q.UpdateEntryTemplate(ctx, sqlc.UpdateEntryTemplateParams{
+				ID:           existing.ID,
+				ProjectID:    t.Project.ID,
+				Content:      t.Content,
+				DurationMs:   t.Duration.Milliseconds(),
+				Tags:         strings.Join(t.Tags, " "),
+				EntryType:    string(t.Type),
+				IsBillable:   boolToInt(t.Billable),
+				RepeatDays:   int64(t.Repeat.Days),
+				RepeatMinute: int64(t.Repeat.Minute),
+				RepeatFrom:   repeatFrom,
+			})
+		} else {
+			saved, err = q.CreateEntryTemplate(ctx, sqlc.CreateEntryTemplateParams{
+				Name:         t.Name,
+				ProjectID:    t.Project.ID,
+				Content:      t.Content,
+				DurationMs:   t.Duration.Milliseconds(),
+				Tags:         strings.Join(t.Tags, " "),
+				EntryType:    string(t.Type),
+				IsBillable:   boolToInt(t.Billable),
+				RepeatDays:   int64(t.Repeat.Days),
+				RepeatMinute: int64(t.Repeat.Minute),
+				RepeatFrom:   repeatFrom,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("save template: %w", err)
+		}
+		t.db = d
+		t.ID = saved.ID
+		t.RepeatFrom = time.Time{}
+		if repeatFrom.Valid {
+			t.RepeatFrom, _ = time.ParseInLocation(occurrenceDay, repeatFrom.String, time.Local)
+		}
+		return nil
+	})
+}
+
+// DeleteTemplate removes a template by name. Entries it already logged stay.
+func (d *Database) DeleteTemplate(name string) error {
+	t, err := d.Template(name)
+	if err != nil {
+		return err
+	}
+	if err := d.queries.DeleteEntryTemplate(context.Background(), t.ID); err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	return nil
+}
+
+// entry builds the entry the template logs when started at start.
+func (t *Template) entry(start time.Time) *Entry {
+	start = start.UTC().Truncate(time.Second)
+	end := start.Add(t.Duration)
+	return &Entry{
+		db:         t.db,
+		Project:    t.Project,
+		ProjectID:  t.Project.ID,
+		Content:    t.Content,
+		DurationMs: t.Duration.Milliseconds(),
+		StartedAt:  &start,
+		EndedAt:    &end,
+		Type:       t.Type,
+		Billable:   t.Billable,
+		Tags:       append(extractTags(t.Content), t.Tags...),
+	}
+}
+
+// Apply logs the template as an entry that ends at end.
+func (t *Template) Apply(end time.Time) (*Entry, error) {
+	if t == nil || t.db == nil {
+		return nil, errors.New("template not initialized")
+	}
+	entry := t.entry(end.Add(-t.Duration))
+	if err := entry.Save(context.Background()); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RunRecurrences logs every scheduled occurrence that has finished by now and
+// was not logged before. Each occurrence is logged at most once, even when its
+// entry is later edited or deleted. Occurrences refused by the overlap policy
+// are skipped for good and reported in the returned error.
+func (d *Database) RunRecurrences(now time.Time) ([]*Entry, error) {
+	templates, err := d.Templates()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	var created []*Entry
+	var skipped []error
+	for _, t := range templates {
+		if t.Repeat.IsZero() || t.RepeatFrom.IsZero() {
+			continue
+		}
+		days, err := d.queries.ListTemplateOccurrenceDays(ctx, sqlc.ListTemplateOccurrenceDaysParams{
+			TemplateID: t.ID,
+			OccursOn:   t.RepeatFrom.Format(occurrenceDay),
+		})
+		if err != nil {
+			return created, fmt.Errorf("list occurrences of %q: %w", t.Name, err)
+		}
+		logged := make(map[string]bool, len(days))
+		for _, day := range days {
+			logged[day] = true
+		}
+
+		for day := StartOfDay(t.RepeatFrom); !day.After(now); day = day.AddDate(0, 0, 1) {
+			key := day.Format(occurrenceDay)
+			start := t.Repeat.At(day)
+			if logged[key] || !t.Repeat.On(day.Weekday()) || start.Add(t.Duration).After(now) {
+				continue
+			}
+			entry := t.entry(start)
+			err := d.withTx(ctx, func(q *sqlc.Queries) error {
+				if err := entry.insert(ctx, q); err != nil {
+					return err
+				}
+				return q.CreateTemplateOccurrence(ctx, sqlc.CreateTemplateOccurrenceParams{
+					TemplateID: t.ID,
+					OccursOn:   key,
+					EntryID:    sql.NullString{String: entry.ID, Valid: true},
+				})
+			})
+			if errors.Is(err, ErrEntryOverlap) {
+				// Remember the occurrence so the same conflict is reported only once.
+				err = d.queries.CreateTemplateOccurrence(ctx, sqlc.CreateTemplateOccurrenceParams{TemplateID: t.ID, OccursOn: key})
+				if err != nil {
+					return created, fmt.Errorf("skip %s on %s: %w", t.Name, key, err)
+				}
+				skipped = append(skipped, fmt.Errorf("skipped %s on %s: %w", t.Name, key, ErrEntryOverlap))
+				continue
+			}
+			if err != nil {
+				return created, fmt.Errorf("log %s on %s: %w", t.Name, key, err)
+			}
+			created = append(created, entry)
+		}
+	}
+	return created, errors.Join(skipped...)
+}
@@ -0,0 +1,156 @@
+package data
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		spec   string
+		want   string
+		length time.Duration
+	}{
+		{spec: "every weekday 09:30", want: "every weekday 09:30"},
+		{spec: "every weekday 09:30 15m", want: "every weekday 09:30", length: 15 * time.Minute},
+		{spec: "every day 8am", want: "every day 08:00"},
+		{spec: "weekends 10:00 2h", want: "every weekend 10:00", length: 2 * time.Hour},
+		{spec: "every mon, wed and fri 2:30 pm", want: "every mon, wed, fri 14:30"},
+		{spec: "every Tuesdays 16:00 1:00", want: "every tue 16:00", length: time.Hour},
+		{spec: "every sunday 9", want: "every sun 09:00"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			r, length, err := ParseRecurrence(tc.spec)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q): %v", tc.spec, err)
+			}
+			if r.String() != tc.want || length != tc.length {
+				t.Fatalf("ParseRecurrence(%q) = %q, %v; want %q, %v", tc.spec, r, length, tc.want, tc.length)
+			}
+			if again, _, err := ParseRecurrence(r.String()); err != nil || again != r {
+				t.Fatalf("expected %q to round-trip, got %v, %v", r, again, err)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "every 09:30", "every weekday", "every weekday soon", "every fortnight 9:00"} {
+		if _, _, err := ParseRecurrence(spec); err == nil {
+			t.Fatalf("expected ParseRecurrence(%q) to fail", spec)
+		}
+	}
+}
+
+// backdateSchedule makes the template's schedule apply from day onwards.
+func backdateSchedule(t *testing.T, db *Database, template *Template, day time.Time) {
+	t.Helper()
+	_, err := db.sqlite.Exec(`UPDATE entry_templates SET repeat_from = ? WHERE id = ?`, day.Format(occurrenceDay), template.ID)
+	if err != nil {
+		t.Fatalf("backdate schedule: %v", err)
+	}
+	template.RepeatFrom = day
+}
+
+func TestTemplatesApplyAndRecur(t *testing.T) {
+	db := openTempDatabase(t)
+	project, err := db.CreateProject("Rituals")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	repeat, _, err := ParseRecurrence("every weekday 09:30")
+	if err != nil {
+		t.Fatalf("parse recurrence: %v", err)
+	}
+	standup := &Template{Name: "standup", Project: project, Content: "daily standup #team",
+		Duration: 15 * time.Minute, Tags: []string{"#meeting"}, Type: EntryTypeChore, Repeat: repeat}
+	if err := db.SaveTemplate(standup); err != nil {
+		t.Fatalf("save template: %v", err)
+	}
+	if err := db.SaveTemplate(&Template{Name: "broken", Project: project}); err == nil {
+		t.Fatalf("expected a template without a duration to fail")
+	}
+
+	found, err := db.Template("STANDUP")
+	if err != nil || found.Repeat != repeat || found.Type != EntryTypeChore || found.Billable {
+		t.Fatalf("unexpected template %+v, %v", found, err)
+	}
+	if _, err := db.Template("nope"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+
+	end := time.Date(2024, time.April, 2, 17, 0, 0, 0, time.Local)
+	entry, err := found.Apply(end)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !entry.EndedAt.Equal(end) || entry.DurationMs != (15*time.Minute).Milliseconds() ||
+		entry.Type != EntryTypeChore || !slices.Equal(entry.Tags, []string{"meeting", "team"}) {
+		t.Fatalf("unexpected applied entry %+v", entry)
+	}
+
+	// Pretend the schedule started on a Friday and run it the following Tuesday at 09:40.
+	friday := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local)
+	backdateSchedule(t, db, found, friday)
+	now := time.Date(2024, time.March, 5, 9, 40, 0, 0, time.Local)
+	created, err := db.RunRecurrences(now)
+	if err != nil {
+		t.Fatalf("run recurrences: %v", err)
+	}
+	var days []string
+	for _, e := range created {
+		days = append(days, e.StartedAt.In(time.Local).Format("Mon 15:04"))
+	}
+	if !slices.Equal(days, []string{"Fri 09:30", "Mon 09:30"}) {
+		t.Fatalf("expected Friday and Monday (Tuesday's has not finished), got %v", days)
+	}
+
+	if err := created[0].Delete(t.Context()); err != nil {
+		t.Fatalf("delete occurrence: %v", err)
+	}
+	created, err = db.RunRecurrences(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("run recurrences again: %v", err)
+	}
+	if len(created) != 1 || created[0].StartedAt.In(time.Local).Weekday() != time.Tuesday {
+		t.Fatalf("expected only Tuesday's occurrence on the second run, got %d entries", len(created))
+	}
+
+	if err := db.DeleteTemplate("standup"); err != nil {
+		t.Fatalf("delete template: %v", err)
+	}
+	if templates, err := db.Templates(); err != nil || len(templates) != 0 {
+		t.Fatalf("expected no templates, got %d, %v", len(templates), err)
+	}
+}
+
+func TestRecurrenceRespectsOverlapPolicy(t *testing.T) {
+	db := openTempDatabase(t)
+	db.SetOverlapPolicy(OverlapReject)
+	project, err := db.CreateProject("Busy")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	monday := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.Local)
+	start, end := monday.Add(9*time.Hour), monday.Add(11*time.Hour)
+	meeting := &Entry{db: db, Project: project, Content: "offsite", StartedAt: &start, EndedAt: &end,
+		DurationMs: (2 * time.Hour).Milliseconds(), Type: EntryTypeWork}
+	if err := meeting.SaveNow(); err != nil {
+		t.Fatalf("save entry: %v", err)
+	}
+	review := &Template{Name: "review", Project: project, Duration: 30 * time.Minute,
+		Repeat: Recurrence{Days: 1 << time.Monday, Minute: 10 * 60}}
+	if err := db.SaveTemplate(review); err != nil {
+		t.Fatalf("save template: %v", err)
+	}
+	backdateSchedule(t, db, review, monday)
+
+	created, err := db.RunRecurrences(monday.Add(12 * time.Hour))
+	if len(created) != 0 || !errors.Is(err, ErrEntryOverlap) {
+		t.Fatalf("expected the overlapping occurrence to be skipped, got %d entries, %v", len(created), err)
+	}
+	if created, err := db.RunRecurrences(monday.Add(13 * time.Hour)); len(created) != 0 || err != nil {
+		t.Fatalf("expected the skipped occurrence to stay skipped, got %d entries, %v", len(created), err)
+	}
+}
@@ -0,0 +1,184 @@
+package data
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// TimelineMinGap is the shortest untracked stretch a day timeline reports as a gap.
+const TimelineMinGap = 5 * time.Minute
+
+// Span is a stretch of time between Start and End.
+type Span struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the span.
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Overlap is a stretch of time logged by more than one entry at once.
+type Overlap struct {
+	Span
+	Entries []*Entry
+}
+
+// TimelineEntry places an entry on a day's timeline, clipped to that day.
+type TimelineEntry struct {
+	Span
+	Entry *Entry
+}
+
+// DayTimeline lays out one local day's entries from every project.
+type DayTimeline struct {
+	Day     time.Time
+	Entries []TimelineEntry
+	// Gaps are untracked stretches between the first and last entry of the day.
+	Gaps     []Span
+	Overlaps []Overlap
+}
+
+// Tracked returns how much of the day is covered by at least one entry.
+func (t DayTimeline) Tracked() time.Duration {
+	var total time.Duration
+	for _, span := range mergeSpans(t.Entries) {
+		total += span.Duration()
+	}
+	return total
+}
+
+// EntrySpan returns when an entry ran. Entries without a start time are placed
+// to end at their end time, as manual entries are.
+func EntrySpan(entry *Entry) (Span, bool) {
+	if entry == nil || entry.EndedAt == nil {
+		return Span{}, false
+	}
+	end := *entry.EndedAt
+	start := end.Add(-time.Duration(entry.DurationMs) * time.Millisecond)
+	if entry.StartedAt != nil && !entry.StartedAt.After(end) {
+		start = *entry.StartedAt
+	}
+	return Span{Start: start, End: end}, true
+}
+
+// DayTimeline loads every entry that ran during day's local date.
+func (d *Database) DayTimeline(day time.Time) (*DayTimeline, error) {
+	start := StartOfDay(day)
+	end := start.AddDate(0, 0, 1)
+	// Entries that run past midnight end on the following day.
+	entries, err := d.EntriesInRange(start, end.AddDate(0, 0, 1), EntryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	timeline := BuildDayTimeline(start, entries)
+	return &timeline, nil
+}
+
+// BuildDayTimeline places entries on day's timeline, dropping those that did not run
+// during it, and works out the gaps and overlaps between them.
+func BuildDayTimeline(day time.Time, entries []*Entry) DayTimeline {
+	dayStart := StartOfDay(day)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	timeline := DayTimeline{Day: dayStart}
+	for _, entry := range entries {
+		span, ok := EntrySpan(entry)
+		if !ok || !span.End.After(dayStart) || !span.Start.Before(dayEnd) {
+			continue
+		}
+		if span.Start.Before(dayStart) {
+			span.Start = dayStart
+		}
+		if span.End.After(dayEnd) {
+			span.End = dayEnd
+		}
+		timeline.Entries = append(timeline.Entries, TimelineEntry{Span: span, Entry: entry})
+	}
+	sort.SliceStable(timeline.Entries, func(i, j int) bool {
+		return timeline.Entries[i].Start.Before(timeline.Entries[j].Start)
+	})
+
+	merged := mergeSpans(timeline.Entries)
+	for i := 1; i < len(merged); i++ {
+		gap := Span{Start: merged[i-1].End, End: merged[i].Start}
+		if gap.Duration() >= TimelineMinGap {
+			timeline.Gaps = append(timeline.Gaps, gap)
+		}
+	}
+	timeline.Overlaps = FindOverlaps(timeline.Entries)
+	return timeline
+}
+
+// mergeSpans joins touching or overlapping entry spans. Entries must be sorted by start.
+func mergeSpans(entries []TimelineEntry) []Span {
+	var merged []Span
+	for _, entry := range entries {
+		if entry.Duration() <= 0 {
+			continue
+		}
+		if n := len(merged); n > 0 && !entry.Start.After(merged[n-1].End) {
+			if entry.End.After(merged[n-1].End) {
+				merged[n-1].End = entry.End
+			}
+			continue
+		}
+		merged = append(merged, entry.Span)
+	}
+	return merged
+}
+
+// FindOverlaps returns the stretches where two or more entries ran at once. Each
+// overlap lists the entries involved; adjacent stretches with the same entries are joined.
+func FindOverlaps(entries []TimelineEntry) []Overlap {
+	type edge struct {
+		at    time.Time
+		index int
+		start bool
+	}
+	edges := make([]edge, 0, len(entries)*2)
+	for i, entry := range entries {
+		if entry.Duration() <= 0 {
+			continue
+		}
+		edges = append(edges, edge{at: entry.Start, index: i, start: true}, edge{at: entry.End, index: i})
+	}
+	// Ends sort before starts at the same instant so back-to-back entries do not overlap.
+	sort.SliceStable(edges, func(i, j int) bool {
+		if !edges[i].at.Equal(edges[j].at) {
+			return edges[i].at.Before(edges[j].at)
+		}
+		return !edges[i].start && edges[j].start
+	})
+
+	var overlaps []Overlap
+	active := map[int]bool{}
+	for i, e := range edges {
+		if e.start {
+			active[e.index] = true
+		} else {
+			delete(active, e.index)
+		}
+		if len(active) < 2 || i+1 >= len(edges) || !edges[i+1].at.After(e.at) {
+			continue
+		}
+		indexes := make([]int, 0, len(active))
+		for index := range active {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		involved := make([]*Entry, 0, len(indexes))
+		for _, index := range indexes {
+			involved = append(involved, entries[index].Entry)
+		}
+
+		span := Span{Start: e.at, End: edges[i+1].at}
+		if n := len(overlaps); n > 0 && overlaps[n-1].End.Equal(span.Start) && slices.Equal(overlaps[n-1].Entries, involved) {
+			overlaps[n-1].End = span.End
+			continue
+		}
+		overlaps = append(overlaps, Overlap{Span: span, Entries: involved})
+	}
+	return overlaps
+}
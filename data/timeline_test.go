@@ -0,0 +1,69 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildDayTimelineGapsAndOverlaps(t *testing.T) {
+	day := time.Date(2024, time.March, 12, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	entry := func(id string, start, end time.Time) *Entry {
+		return &Entry{ID: id, StartedAt: &start, EndedAt: &end, DurationMs: end.Sub(start).Milliseconds()}
+	}
+
+	overnight := entry("overnight", at(-1, 0), at(0, 30))
+	morning := entry("morning", at(9, 0), at(10, 0))
+	review := entry("review", at(9, 45), at(10, 30))
+	call := entry("call", at(10, 30), at(10, 32)) // back to back with review
+	afternoon := entry("afternoon", at(13, 0), at(15, 0))
+	// Manual entries carry no start time and end where they were logged.
+	manual := &Entry{ID: "manual", EndedAt: ptrTime(at(14, 30)), DurationMs: (30 * time.Minute).Milliseconds()}
+	tomorrow := entry("tomorrow", at(24, 10), at(25, 0))
+
+	timeline := BuildDayTimeline(at(12, 0), []*Entry{afternoon, tomorrow, review, call, manual, morning, overnight})
+
+	var ids []string
+	for _, e := range timeline.Entries {
+		ids = append(ids, e.Entry.ID)
+	}
+	if want := "overnight morning review call afternoon manual"; strings.Join(ids, " ") != want {
+		t.Fatalf("entries = %q, want %q", strings.Join(ids, " "), want)
+	}
+	if !timeline.Entries[0].Start.Equal(day) {
+		t.Fatalf("expected the overnight entry to be clipped to midnight, got %v", timeline.Entries[0].Start)
+	}
+
+	if len(timeline.Gaps) != 2 {
+		t.Fatalf("expected 2 gaps, got %+v", timeline.Gaps)
+	}
+	if !timeline.Gaps[0].Start.Equal(at(0, 30)) || !timeline.Gaps[0].End.Equal(at(9, 0)) {
+		t.Fatalf("unexpected first gap %+v", timeline.Gaps[0])
+	}
+	if !timeline.Gaps[1].Start.Equal(at(10, 32)) || timeline.Gaps[1].Duration() != 2*time.Hour+28*time.Minute {
+		t.Fatalf("unexpected second gap %+v", timeline.Gaps[1])
+	}
+
+	if len(timeline.Overlaps) != 2 {
+		t.Fatalf("expected 2 overlaps, got %+v", timeline.Overlaps)
+	}
+	first := timeline.Overlaps[0]
+	if !first.Start.Equal(at(9, 45)) || first.Duration() != 15*time.Minute || len(first.Entries) != 2 ||
+		first.Entries[0] != morning || first.Entries[1] != review {
+		t.Fatalf("unexpected first overlap %+v", first)
+	}
+	if second := timeline.Overlaps[1]; !second.Start.Equal(at(14, 0)) || second.Duration() != 30*time.Minute {
+		t.Fatalf("unexpected second overlap %+v", second)
+	}
+
+	if got, want := timeline.Tracked(), 30*time.Minute+92*time.Minute+2*time.Hour; got != want {
+		t.Fatalf("tracked = %v, want %v", got, want)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
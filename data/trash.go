@@ -0,0 +1,283 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// DeletedProjects lists projects in the trash, most recently deleted first.
+func (d *Database) DeletedProjects() ([]*Project, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows, err := d.queries.ListDeletedProjects(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("list deleted projects: %w", err)
+	}
+	projects := make([]*Project, 0, len(rows))
+	for _, row := range rows {
+		projects = append(projects, newProjectFromModel(d, row))
+	}
+	return projects, nil
+}
+
+// DeletedEntries lists individually deleted entries, most recently deleted first.
+// Entries hidden only because their project is in the trash are not listed;
+// restoring the project brings them back.
+func (d *Database) DeletedEntries() ([]*Entry, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	ctx := context.Background()
+	rows, err := d.queries.ListDeletedEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list deleted entries: %w", err)
+	}
+	projects := make(map[int64]*Project)
+	entries := make([]*Entry, 0, len(rows))
+	for _, row := range rows {
+		project, ok := projects[row.ProjectID]
+		if !ok {
+			record, err := d.queries.GetProject(ctx, row.ProjectID)
+			if err != nil {
+				return nil, fmt.Errorf("load project %d: %w", row.ProjectID, err)
+			}
+			project = newProjectFromModel(d, record)
+			projects[row.ProjectID] = project
+		}
+		entry := newEntryFromModel(d, project, row)
+		tagRows, err := d.queries.ListTagsForEntry(ctx, row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("load tags for entry %s: %w", row.ID, err)
+		}
+		for _, tag := range tagRows {
+			entry.Tags = append(entry.Tags, tag.Tag)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// PurgeTrash permanently removes projects and entries deleted before cutoff.
+func (d *Database) PurgeTrash(cutoff time.Time) error {
+	if d == nil {
+		return errors.New("database not initialized")
+	}
+	ctx := context.Background()
+	return d.withTx(ctx, func(q *sqlc.Queries) error {
+		entries, err := q.ListDeletedEntries(ctx)
+		if err != nil {
+			return fmt.Errorf("list deleted entries: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.DeletedAt.Int64 >= cutoff.Unix() {
+				continue
+			}
+			if err := purgeEntry(ctx, q, entry.ID); err != nil {
+				return err
+			}
+		}
+
+		projects, err := q.ListDeletedProjects(ctx)
+		if err != nil {
+			return fmt.Errorf("list deleted projects: %w", err)
+		}
+		for _, project := range projects {
+			if project.DeletedAt.Int64 >= cutoff.Unix() {
+				continue
+			}
+			// Sub-projects are purged along with their parent and may already be gone.
+			if _, err := q.GetProject(ctx, project.ID); errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err := purgeProject(ctx, q, project.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// EmptyTrash permanently removes everything in the trash.
+func (d *Database) EmptyTrash() error {
+	return d.PurgeTrash(time.Now().Add(time.Second))
+}
+
+func purgeEntry(ctx context.Context, q *sqlc.Queries, id string) error {
+	snapshot, err := snapshotEntry(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if err := q.DeleteEntry(ctx, id); err != nil {
+		return fmt.Errorf("purge entry: %w", err)
+	}
+	return recordEntryChange(ctx, q, id, ChangePurge, &snapshot)
+}
+
+// purgeProject hard-deletes a project and its sub-projects; entries and tags go with
+// them via ON DELETE CASCADE, so each entry gets its own purge record first.
+func purgeProject(ctx context.Context, q *sqlc.Queries, id int64) error {
+	snapshot, err := snapshotProject(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	children, err := q.ListChildProjects(ctx, sql.NullInt64{Int64: id, Valid: true})
+	if err != nil {
+		return fmt.Errorf("list sub-projects: %w", err)
+	}
+	for _, child := range children {
+		if err := purgeProject(ctx, q, child.ID); err != nil {
+			return err
+		}
+	}
+	entries, err := q.ListEntryIDsByProject(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list project entries: %w", err)
+	}
+	for _, entryID := range entries {
+		before, err := snapshotEntry(ctx, q, entryID)
+		if err != nil {
+			return err
+		}
+		if err := recordEntryChange(ctx, q, entryID, ChangePurge, &before); err != nil {
+			return err
+		}
+	}
+	if err := q.DeleteProject(ctx, id); err != nil {
+		return fmt.Errorf("purge project: %w", err)
+	}
+	return recordProjectChange(ctx, q, id, ChangePurge, &snapshot)
+}
+
+// restoreProject brings record back from the trash, along with its timer when
+// timerStartedAt is set. An active project that took its name meanwhile must
+// be renamed first.
+func restoreProject(ctx context.Context, q *sqlc.Queries, record sqlc.Project, timerStartedAt *int64) error {
+	if err := checkParentActive(ctx, q, record.ParentID); err != nil {
+		return err
+	}
+	if err := checkProjectName(ctx, q, nullInt64Ptr(record.ParentID), record.Name, record.ID); err != nil {
+		return fmt.Errorf("%w; rename it before restoring this one", err)
+	}
+	before, err := snapshotProject(ctx, q, record.ID)
+	if err != nil {
+		return err
+	}
+	if err := q.RestoreProject(ctx, record.ID); err != nil {
+		return fmt.Errorf("restore project %q: %w", record.Name, err)
+	}
+	if timerStartedAt != nil {
+		if _, err := q.UpsertTimer(ctx, sqlc.UpsertTimerParams{
+			ProjectID: record.ID,
+			StartedAt: *timerStartedAt,
+		}); err != nil {
+			return fmt.Errorf("restore timer: %w", err)
+		}
+		timer := &timerSnapshot{ProjectID: record.ID, StartedAt: *timerStartedAt}
+		if err := recordTimerChange(ctx, q, record.ID, ChangeCreate, nil, timer); err != nil {
+			return err
+		}
+	}
+	return recordProjectChange(ctx, q, record.ID, ChangeRestore, &before)
+}
+
+// deletedTimer returns when the timer that was running on project id at its
+// latest deletion started, or nil when none was.
+func deletedTimer(ctx context.Context, q *sqlc.Queries, id int64) (*int64, error) {
+	rows, err := q.ListChangesForEntity(ctx, sqlc.ListChangesForEntityParams{
+		EntityType: entityProject,
+		EntityID:   strconv.FormatInt(id, 10),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load project history: %w", err)
+	}
+	for i := len(rows) - 1; i >= 0; i-- {
+		if ChangeAction(rows[i].Action) != ChangeDelete || !rows[i].BeforeJson.Valid {
+			continue
+		}
+		var snap projectSnapshot
+		if err := json.Unmarshal([]byte(rows[i].BeforeJson.String), &snap); err != nil {
+			return nil, fmt.Errorf("decode project snapshot: %w", err)
+		}
+		return snap.TimerStartedAt, nil
+	}
+	return nil, nil
+}
+
+// Restore brings a deleted project back from the trash, with the timer it had
+// running when it was deleted.
+func (p *Project) Restore() error {
+	if p == nil || p.db == nil {
+		return errors.New("project not initialized")
+	}
+	ctx := context.Background()
+	err := p.db.withTx(ctx, func(q *sqlc.Queries) error {
+		record, err := q.GetProject(ctx, p.ID)
+		if err != nil {
+			return fmt.Errorf("load project %d: %w", p.ID, err)
+		}
+		timer, err := deletedTimer(ctx, q, p.ID)
+		if err != nil {
+			return err
+		}
+		return restoreProject(ctx, q, record, timer)
+	})
+	if err != nil {
+		return err
+	}
+	p.DeletedAt = nil
+	return nil
+}
+
+// Purge permanently deletes the project along with its entries and tags.
+func (p *Project) Purge() error {
+	if p == nil || p.db == nil {
+		return errors.New("project not initialized")
+	}
+	ctx := context.Background()
+	return p.db.withTx(ctx, func(q *sqlc.Queries) error {
+		return purgeProject(ctx, q, p.ID)
+	})
+}
+
+// Restore brings a deleted entry back from the trash.
+func (e *Entry) Restore(ctx context.Context) error {
+	if e == nil || e.db == nil {
+		return errors.New("entry not initialized")
+	}
+	err := e.db.withTx(ctx, func(q *sqlc.Queries) error {
+		before, err := snapshotEntry(ctx, q, e.ID)
+		if err != nil {
+			return err
+		}
+		if err := q.RestoreEntry(ctx, e.ID); err != nil {
+			return fmt.Errorf("restore entry: %w", err)
+		}
+		if err := q.TouchProject(ctx, e.ProjectID); err != nil {
+			return fmt.Errorf("touch project: %w", err)
+		}
+		return recordEntryChange(ctx, q, e.ID, ChangeRestore, &before)
+	})
+	if err != nil {
+		return err
+	}
+	e.DeletedAt = nil
+	return nil
+}
+
+// Purge permanently deletes the entry and its tags.
+func (e *Entry) Purge(ctx context.Context) error {
+	if e == nil || e.db == nil {
+		return errors.New("entry not initialized")
+	}
+	return e.db.withTx(ctx, func(q *sqlc.Queries) error {
+		return purgeEntry(ctx, q, e.ID)
+	})
+}
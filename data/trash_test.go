@@ -0,0 +1,127 @@
+package data
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	db := openTempDatabase(t)
+	ctx := context.Background()
+
+	project, err := db.CreateProject("Trash Project")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("trash me #gone", 20*time.Minute, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	if err := entry.Delete(ctx); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	if len(project.Entries()) != 0 {
+		t.Fatalf("expected deleted entry to be hidden from project entries")
+	}
+	deleted, err := db.DeletedEntries()
+	if err != nil {
+		t.Fatalf("list deleted entries: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != entry.ID || deleted[0].DeletedAt == nil {
+		t.Fatalf("expected entry in trash, got %+v", deleted)
+	}
+	if len(deleted[0].Tags) != 1 || deleted[0].Tags[0] != "gone" {
+		t.Fatalf("expected tags on trashed entry, got %v", deleted[0].Tags)
+	}
+
+	if err := deleted[0].Restore(ctx); err != nil {
+		t.Fatalf("restore entry: %v", err)
+	}
+	if len(project.Entries()) != 1 {
+		t.Fatalf("expected restored entry to be visible")
+	}
+
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	_, timer := project.OnClock()
+	if err := project.Delete(); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+	if len(db.Projects()) != 0 {
+		t.Fatalf("expected deleted project to be hidden")
+	}
+
+	// A project in the trash gives up its name until it is restored.
+	namesake, err := db.CreateProject("trash project")
+	if err != nil {
+		t.Fatalf("expected the name of a trashed project to be free, got %v", err)
+	}
+	trashed, err := db.DeletedProjects()
+	if err != nil {
+		t.Fatalf("list deleted projects: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != project.ID {
+		t.Fatalf("expected project in trash, got %+v", trashed)
+	}
+	if err := trashed[0].Restore(); err == nil || !strings.Contains(err.Error(), "already exists; rename it") {
+		t.Fatalf("expected restoring over an active namesake to be refused, got %v", err)
+	}
+	if err := namesake.Rename("Other Project"); err != nil {
+		t.Fatalf("rename namesake: %v", err)
+	}
+
+	if err := trashed[0].Restore(); err != nil {
+		t.Fatalf("restore project: %v", err)
+	}
+	restored, err := db.FindProject("Trash Project")
+	if err != nil || restored.ID != project.ID || len(restored.Entries()) != 1 {
+		t.Fatalf("expected project and its entries to be restored, got %+v, %v", restored, err)
+	}
+	if onClock, running := restored.OnClock(); !onClock || !running.StartedAt.Equal(timer.StartedAt) {
+		t.Fatalf("expected the timer running at deletion to come back, got %+v", running)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	db := openTempDatabase(t)
+	ctx := context.Background()
+
+	project, err := db.CreateProject("Purge Project")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("old", time.Minute, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if err := entry.Delete(ctx); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+
+	if err := db.PurgeTrash(time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("purge with old cutoff: %v", err)
+	}
+	if deleted, _ := db.DeletedEntries(); len(deleted) != 1 {
+		t.Fatalf("expected recent deletion to survive retention purge, got %d", len(deleted))
+	}
+
+	if err := project.Delete(); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+	if err := db.EmptyTrash(); err != nil {
+		t.Fatalf("empty trash: %v", err)
+	}
+	if deleted, _ := db.DeletedEntries(); len(deleted) != 0 {
+		t.Fatalf("expected trash to be empty, got %d entries", len(deleted))
+	}
+	if trashed, _ := db.DeletedProjects(); len(trashed) != 0 {
+		t.Fatalf("expected trash to be empty, got %d projects", len(trashed))
+	}
+	if _, err := db.CreateProject("Purge Project"); err != nil {
+		t.Fatalf("expected purged name to be reusable: %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// undoHistoryLimit caps how many undoable actions are kept in the database.
+const undoHistoryLimit = 50
+
+// ErrNothingToUndo is returned by Undo when the undo stack is empty.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// UndoKind identifies the mutation an UndoAction reverts.
+type UndoKind string
+
+const (
+	UndoEntryDelete   UndoKind = "entry.delete"
+	UndoEntryEdit     UndoKind = "entry.edit"
+	UndoEntryMerge    UndoKind = "entry.merge"
+	UndoEntryMove     UndoKind = "entry.move"
+	UndoEntrySplit    UndoKind = "entry.split"
+	UndoProjectDelete UndoKind = "project.delete"
+	UndoProjectRename UndoKind = "project.rename"
+)
+
+// UndoAction is a persisted record of a destructive change that can be reverted.
+type UndoAction struct {
+	ID          int64
+	Kind        UndoKind
+	Description string
+	CreatedAt   time.Time
+	payload     string
+}
+
+func newUndoActionFromModel(model sqlc.UndoAction) *UndoAction {
+	return &UndoAction{
+		ID:          model.ID,
+		Kind:        UndoKind(model.Kind),
+		Description: model.Description,
+		CreatedAt:   time.Unix(model.CreatedAt, 0).UTC(),
+		payload:     model.Payload,
+	}
+}
+
+// describeEntry renders a short, human readable reference to an entry for undo messages.
+func describeEntry(content string) string {
+	content = strings.TrimSpace(strings.ReplaceAll(content, "\n", " "))
+	if content == "" {
+		return "entry"
+	}
+	runes := []rune(content)
+	if len(runes) > 30 {
+		content = string(runes[:27]) + "..."
+	}
+	return fmt.Sprintf("entry '%s'", content)
+}
+
+func restoreEntryTags(ctx context.Context, q *sqlc.Queries, snap entrySnapshot) error {
+	if err := q.DeleteEntryTags(ctx, snap.ID); err != nil {
+		return fmt.Errorf("clear entry tags: %w", err)
+	}
+	for _, tag := range snap.Tags {
+		if err := q.InsertEntryTag(ctx, sqlc.InsertEntryTagParams{EntryID: snap.ID, Tag: tag}); err != nil {
+			return fmt.Errorf("insert tag %q: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func rewriteEntry(ctx context.Context, q *sqlc.Queries, snap entrySnapshot) error {
+	_, err := q.UpdateEntry(ctx, sqlc.UpdateEntryParams{
+		ID:         snap.ID,
+		ProjectID:  snap.ProjectID,
+		CreatorID:  optionalInt64(snap.CreatorID),
+		Content:    snap.Content,
+		DurationMs: snap.DurationMs,
+		StartedAt:  optionalInt64(snap.StartedAt),
+		EndedAt:    optionalInt64(snap.EndedAt),
+		EntryType:  snap.EntryType,
+		IsBillable: boolToInt(snap.IsBillable),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("entry %s no longer exists", snap.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("revert entry %s: %w", snap.ID, err)
+	}
+	return restoreEntryTags(ctx, q, snap)
+}
+
+// revert trashes the entries the action created, restores the ones it deleted
+// and writes back the ones it changed.
+func (b entryBatchSnapshot) revert(ctx context.Context, q *sqlc.Queries) error {
+	for _, id := range b.Created {
+		before, err := snapshotEntry(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		if err := q.SoftDeleteEntry(ctx, id); err != nil {
+			return fmt.Errorf("delete entry %s: %w", id, err)
+		}
+		if err := recordEntryChange(ctx, q, id, ChangeDelete, &before); err != nil {
+			return err
+		}
+	}
+	for _, id := range b.Deleted {
+		before, err := snapshotEntry(ctx, q, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("entry %s was permanently deleted", id)
+		} else if err != nil {
+			return err
+		}
+		if err := q.RestoreEntry(ctx, id); err != nil {
+			return fmt.Errorf("restore entry %s: %w", id, err)
+		}
+		if err := recordEntryChange(ctx, q, id, ChangeRestore, &before); err != nil {
+			return err
+		}
+	}
+	for _, snap := range b.Changed {
+		before, err := snapshotEntry(ctx, q, snap.ID)
+		if err != nil {
+			return err
+		}
+		if err := rewriteEntry(ctx, q, snap); err != nil {
+			return err
+		}
+		if err := q.TouchProject(ctx, snap.ProjectID); err != nil {
+			return fmt.Errorf("touch project: %w", err)
+		}
+		if err := recordEntryChange(ctx, q, snap.ID, ChangeUpdate, &before); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushUndo records an undoable action and trims the stack to undoHistoryLimit.
+func pushUndo(ctx context.Context, q *sqlc.Queries, kind UndoKind, description string, snapshot any) error {
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("encode undo snapshot: %w", err)
+	}
+	if _, err := q.InsertUndoAction(ctx, sqlc.InsertUndoActionParams{
+		Kind:        string(kind),
+		Description: description,
+		Payload:     string(payload),
+	}); err != nil {
+		return fmt.Errorf("record undo action: %w", err)
+	}
+	if err := q.PruneUndoActions(ctx, undoHistoryLimit); err != nil {
+		return fmt.Errorf("prune undo actions: %w", err)
+	}
+	return nil
+}
+
+// LastUndo returns the most recent undoable action, or nil when there is none.
+func (d *Database) LastUndo() (*UndoAction, error) {
+	if d == nil {
+		return nil, errors.New("database not initialized")
+	}
+	record, err := d.queries.GetLatestUndoAction(context.Background())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load undo action: %w", err)
+	}
+	return newUndoActionFromModel(record), nil
+}
+
+// Undo reverts the most recent undoable action and removes it from the stack.
+func (d *Database) Undo() (*UndoAction, error) {
+	action, err := d.LastUndo()
+	if err != nil {
+		return nil, err
+	}
+	if action == nil {
+		return nil, ErrNothingToUndo
+	}
+
+	ctx := context.Background()
+	err = d.withTx(ctx, func(q *sqlc.Queries) error {
+		if err := action.apply(ctx, q); err != nil {
+			return err
+		}
+		if err := q.DeleteUndoAction(ctx, action.ID); err != nil {
+			return fmt.Errorf("remove undo action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("undo %s: %w", action.Description, err)
+	}
+	return action, nil
+}
+
+func (a *UndoAction) apply(ctx context.Context, q *sqlc.Queries) error {
+	switch a.Kind {
+	case UndoEntryDelete:
+		var snap entrySnapshot
+		if err := json.Unmarshal([]byte(a.payload), &snap); err != nil {
+			return fmt.Errorf("decode undo snapshot: %w", err)
+		}
+		if _, err := q.GetEntry(ctx, snap.ID); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("entry %s was permanently deleted", snap.ID)
+		} else if err != nil {
+			return fmt.Errorf("load entry %s: %w", snap.ID, err)
+		}
+		before, err := snapshotEntry(ctx, q, snap.ID)
+		if err != nil {
+			return err
+		}
+		if err := q.RestoreEntry(ctx, snap.ID); err != nil {
+			return fmt.Errorf("restore entry %s: %w", snap.ID, err)
+		}
+		if err := q.TouchProject(ctx, snap.ProjectID); err != nil {
+			return fmt.Errorf("touch project: %w", err)
+		}
+		return recordEntryChange(ctx, q, snap.ID, ChangeRestore, &before)
+	case UndoEntryEdit, UndoEntryMove:
+		var snap entrySnapshot
+		if err := json.Unmarshal([]byte(a.payload), &snap); err != nil {
+			return fmt.Errorf("decode undo snapshot: %w", err)
+		}
+		before, err := snapshotEntry(ctx, q, snap.ID)
+		if err != nil {
+			return err
+		}
+		if err := rewriteEntry(ctx, q, snap); err != nil {
+			return err
+		}
+		if err := q.TouchProject(ctx, snap.ProjectID); err != nil {
+			return fmt.Errorf("touch project: %w", err)
+		}
+		action := ChangeUpdate
+		if before.ProjectID != snap.ProjectID {
+			action = ChangeMove
+		}
+		return recordEntryChange(ctx, q, snap.ID, action, &before)
+	case UndoEntrySplit, UndoEntryMerge:
+		var snap entryBatchSnapshot
+		if err := json.Unmarshal([]byte(a.payload), &snap); err != nil {
+			return fmt.Errorf("decode undo snapshot: %w", err)
+		}
+		return snap.revert(ctx, q)
+	case UndoProjectDelete:
+		var snap projectSnapshot
+		if err := json.Unmarshal([]byte(a.payload), &snap); err != nil {
+			return fmt.Errorf("decode undo snapshot: %w", err)
+		}
+		record, err := q.GetProject(ctx, snap.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("project %q was permanently deleted", snap.Name)
+		} else if err != nil {
+			return fmt.Errorf("load project %q: %w", snap.Name, err)
+		}
+		return restoreProject(ctx, q, record, snap.TimerStartedAt)
+	case UndoProjectRename:
+		var snap projectSnapshot
+		if err := json.Unmarshal([]byte(a.payload), &snap); err != nil {
+			return fmt.Errorf("decode undo snapshot: %w", err)
+		}
+		before, err := snapshotProject(ctx, q, snap.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := q.UpdateProject(ctx, sqlc.UpdateProjectParams{
+			ID:       snap.ID,
+			Name:     snap.Name,
+			Company:  optionalString(snap.Company),
+			IsHidden: boolToInt(snap.IsHidden),
+			ParentID: optionalInt64(snap.ParentID),
+		}); err != nil {
+			return fmt.Errorf("restore project name: %w", err)
+		}
+		return recordProjectChange(ctx, q, snap.ID, ChangeUpdate, &before)
+	default:
+		return fmt.Errorf("unsupported undo action %q", a.Kind)
+	}
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUndoEntryDeleteAndEdit(t *testing.T) {
+	db := openTempDatabase(t)
+
+	if _, err := db.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected ErrNothingToUndo on empty stack, got %v", err)
+	}
+
+	project, err := db.CreateProject("Undo Project")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("Original #keep", 30*time.Minute, true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	entry.Content = "Edited"
+	entry.Tags = nil
+	if err := entry.UpdateNow(); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+
+	last, err := db.LastUndo()
+	if err != nil {
+		t.Fatalf("last undo: %v", err)
+	}
+	if last == nil || last.Kind != UndoEntryEdit {
+		t.Fatalf("expected pending edit undo, got %+v", last)
+	}
+
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("undo edit: %v", err)
+	}
+	entries := project.Entries()
+	if len(entries) != 1 || entries[0].Content != "Original #keep" {
+		t.Fatalf("expected original content after undo, got %+v", entries)
+	}
+	if len(entries[0].Tags) != 1 || entries[0].Tags[0] != "keep" {
+		t.Fatalf("expected tags restored after undo, got %v", entries[0].Tags)
+	}
+
+	if err := entries[0].DeleteNow(); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	if len(project.Entries()) != 0 {
+		t.Fatalf("expected entry to be deleted")
+	}
+
+	action, err := db.Undo()
+	if err != nil {
+		t.Fatalf("undo delete: %v", err)
+	}
+	if action.Kind != UndoEntryDelete {
+		t.Fatalf("expected delete undo, got %s", action.Kind)
+	}
+	restored := project.Entries()
+	if len(restored) != 1 || restored[0].ID != entry.ID {
+		t.Fatalf("expected deleted entry to be restored with same id, got %+v", restored)
+	}
+	if !restored[0].CreatedAt.Equal(entry.CreatedAt) {
+		t.Fatalf("expected created_at to be preserved, got %v want %v", restored[0].CreatedAt, entry.CreatedAt)
+	}
+}
+
+func TestUndoProjectDeleteAndRename(t *testing.T) {
+	db := openTempDatabase(t)
+
+	project, err := db.CreateProject("Before")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if _, err := project.CreateEntryWithDuration("kept work", time.Hour, true, EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+
+	if err := project.Rename("After"); err != nil {
+		t.Fatalf("rename project: %v", err)
+	}
+	if err := project.Delete(); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+	if len(db.Projects()) != 0 {
+		t.Fatalf("expected no projects after delete")
+	}
+
+	action, err := db.Undo()
+	if err != nil {
+		t.Fatalf("undo project delete: %v", err)
+	}
+	if !strings.Contains(action.Description, "After") {
+		t.Fatalf("expected description to mention project, got %q", action.Description)
+	}
+	projects := db.Projects()
+	if len(projects) != 1 || projects[0].ID != project.ID {
+		t.Fatalf("expected project to be restored with original id, got %+v", projects)
+	}
+	if entries := projects[0].Entries(); len(entries) != 1 {
+		t.Fatalf("expected cascaded entries to be restored, got %d", len(entries))
+	}
+	if onClock, _ := projects[0].OnClock(); !onClock {
+		t.Fatalf("expected running timer to be restored")
+	}
+
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("undo rename: %v", err)
+	}
+	if name := db.Projects()[0].Name; name != "Before" {
+		t.Fatalf("expected original name after undo, got %q", name)
+	}
+}
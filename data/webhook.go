@@ -0,0 +1,302 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+// Events published to webhooks. Projects and entries are also published as
+// .restored and .purged when they come back from or leave the trash.
+const (
+	EventTimerStarted   = "timer.started"
+	EventTimerStopped   = "timer.stopped"
+	EventEntryCreated   = "entry.created"
+	EventEntryUpdated   = "entry.updated"
+	EventEntryDeleted   = "entry.deleted"
+	EventProjectCreated = "project.created"
+	EventProjectUpdated = "project.updated"
+	EventProjectDeleted = "project.deleted"
+)
+
+const (
+	// MaxWebhookAttempts is how many times a delivery is tried before it is
+	// marked failed and left for RetryFailedWebhooks.
+	MaxWebhookAttempts = 8
+	// webhookLease is how long a claimed delivery is hidden from other senders.
+	webhookLease = 2 * time.Minute
+)
+
+// Webhook is a URL that is posted a signed JSON event whenever projects,
+// entries or timers change.
+type Webhook struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+	// Events limits the hook to these events; "timer.*" matches a whole
+	// group. Empty sends every event.
+	Events []string `json:"events,omitempty"`
+}
+
+// Wants reports whether the hook subscribes to event.
+func (w Webhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, pattern := range w.Events {
+		if pattern == "*" || pattern == event {
+			return true
+		}
+		if group, ok := strings.CutSuffix(pattern, ".*"); ok && strings.HasPrefix(event, group+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the JSON body posted to webhooks.
+type WebhookEvent struct {
+	// ID is the change log id, the same for every hook sent the event.
+	ID         int64     `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data is the project, entry or timer as it is after the change, or as it
+	// was before for deletions.
+	Data     json.RawMessage `json:"data"`
+	Previous json.RawMessage `json:"previous,omitempty"`
+}
+
+// WebhookDelivery is one event waiting in the outbox for one hook.
+type WebhookDelivery struct {
+	ID        int64
+	URL       string
+	Event     string
+	Payload   []byte
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	FailedAt  *time.Time
+}
+
+func newWebhookDeliveryFromModel(model sqlc.WebhookOutbox) WebhookDelivery {
+	return WebhookDelivery{
+		ID:        model.ID,
+		URL:       model.Url,
+		Event:     model.Event,
+		Payload:   []byte(model.Payload),
+		Attempts:  int(model.Attempts),
+		LastError: model.LastError.String,
+		CreatedAt: time.Unix(model.CreatedAt, 0).UTC(),
+		FailedAt:  unixTimePtr(model.FailedAt),
+	}
+}
+
+// WebhookStatus counts a hook's deliveries still in the outbox.
+type WebhookStatus struct {
+	URL     string
+	Pending int
+	Failed  int
+}
+
+// SetWebhooks sets the hooks that changes are published to from now on.
+func (d *Database) SetWebhooks(hooks []Webhook) error {
+	for _, hook := range hooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook url %q must be an http or https URL", hook.URL)
+		}
+	}
+	d.webhooksMu.Lock()
+	defer d.webhooksMu.Unlock()
+	d.webhooks = append([]Webhook(nil), hooks...)
+	return nil
+}
+
+// Webhooks returns the hooks changes are published to.
+func (d *Database) Webhooks() []Webhook {
+	if d == nil {
+		return nil
+	}
+	d.webhooksMu.RLock()
+	defer d.webhooksMu.RUnlock()
+	return d.webhooks
+}
+
+// Webhook returns the configured hook for url.
+func (d *Database) Webhook(url string) (Webhook, bool) {
+	for _, hook := range d.Webhooks() {
+		if hook.URL == url {
+			return hook, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// eventName names the event a change log row is published as.
+func eventName(entityType string, action ChangeAction) string {
+	if entityType == entityTimer {
+		if action == ChangeDelete {
+			return EventTimerStopped
+		}
+		return EventTimerStarted
+	}
+	switch action {
+	case ChangeCreate:
+		return entityType + ".created"
+	case ChangeUpdate, ChangeMove:
+		return entityType + ".updated"
+	case ChangeDelete:
+		return entityType + ".deleted"
+	case ChangeRestore:
+		return entityType + ".restored"
+	case ChangePurge:
+		return entityType + ".purged"
+	default:
+		return entityType + "." + string(action)
+	}
+}
+
+// enqueueWebhooks adds an outbox delivery for every hook that wants one of the
+// changes logged after change id since. It runs inside the transaction that
+// made the changes, so an event is queued exactly when its change commits.
+func (d *Database) enqueueWebhooks(ctx context.Context, q *sqlc.Queries, since int64) error {
+	changes, err := q.ListChangesAfter(ctx, since)
+	if err != nil {
+		return fmt.Errorf("list changes for webhooks: %w", err)
+	}
+	hooks := d.Webhooks()
+	for _, change := range changes {
+		event := WebhookEvent{
+			ID:         change.ID,
+			Event:      eventName(change.EntityType, ChangeAction(change.Action)),
+			OccurredAt: time.Unix(change.CreatedAt, 0).UTC(),
+		}
+		switch {
+		case change.AfterJson.Valid:
+			event.Data = json.RawMessage(change.AfterJson.String)
+			if change.BeforeJson.Valid {
+				event.Previous = json.RawMessage(change.BeforeJson.String)
+			}
+		case change.BeforeJson.Valid:
+			event.Data = json.RawMessage(change.BeforeJson.String)
+		}
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("encode %s event: %w", event.Event, err)
+		}
+		for _, hook := range hooks {
+			if !hook.Wants(event.Event) {
+				continue
+			}
+			if err := q.InsertWebhookDelivery(ctx, sqlc.InsertWebhookDeliveryParams{
+				Url:           hook.URL,
+				Event:         event.Event,
+				Payload:       string(payload),
+				NextAttemptAt: change.CreatedAt,
+			}); err != nil {
+				return fmt.Errorf("queue %s webhook: %w", event.Event, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries returns up to limit deliveries that are due at now,
+// oldest first, and holds them back from other senders for a while.
+func (d *Database) ClaimWebhookDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	ctx := context.Background()
+	var deliveries []WebhookDelivery
+	err := d.withTx(ctx, func(q *sqlc.Queries) error {
+		rows, err := q.ListDueWebhookDeliveries(ctx, sqlc.ListDueWebhookDeliveriesParams{
+			NextAttemptAt: now.Unix(),
+			Limit:         int64(limit),
+		})
+		if err != nil {
+			return fmt.Errorf("list due webhooks: %w", err)
+		}
+		for _, row := range rows {
+			if err := q.PostponeWebhookDelivery(ctx, sqlc.PostponeWebhookDeliveryParams{
+				ID:            row.ID,
+				NextAttemptAt: now.Add(webhookLease).Unix(),
+			}); err != nil {
+				return fmt.Errorf("claim webhook %d: %w", row.ID, err)
+			}
+			deliveries = append(deliveries, newWebhookDeliveryFromModel(row))
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+// RemoveWebhookDelivery takes a delivery out of the outbox once it has been
+// sent, or when its hook is no longer configured.
+func (d *Database) RemoveWebhookDelivery(id int64) error {
+	if err := d.queries.DeleteWebhookDelivery(context.Background(), id); err != nil {
+		return fmt.Errorf("remove webhook %d: %w", id, err)
+	}
+	return nil
+}
+
+// WebhookFailed records a failed attempt at delivery and schedules the next
+// one, backing off from 30 seconds to an hour. After MaxWebhookAttempts the
+// delivery is marked failed instead.
+func (d *Database) WebhookFailed(delivery WebhookDelivery, cause error, now time.Time) error {
+	attempts := delivery.Attempts + 1
+	params := sqlc.RecordWebhookFailureParams{
+		ID:            delivery.ID,
+		Attempts:      int64(attempts),
+		NextAttemptAt: now.Add(webhookBackoff(attempts)).Unix(),
+		LastError:     sql.NullString{String: cause.Error(), Valid: true},
+	}
+	if attempts >= MaxWebhookAttempts {
+		params.FailedAt = sql.NullInt64{Int64: now.Unix(), Valid: true}
+	}
+	if err := d.queries.RecordWebhookFailure(context.Background(), params); err != nil {
+		return fmt.Errorf("record webhook %d failure: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	return min(30*time.Second<<min(attempts-1, 7), time.Hour)
+}
+
+// WebhookStatus counts the deliveries in the outbox per URL.
+func (d *Database) WebhookStatus() ([]WebhookStatus, error) {
+	rows, err := d.queries.WebhookOutboxStatus(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("webhook status: %w", err)
+	}
+	statuses := make([]WebhookStatus, 0, len(rows))
+	for _, row := range rows {
+		statuses = append(statuses, WebhookStatus{URL: row.Url, Pending: int(row.Pending), Failed: int(row.Failed)})
+	}
+	return statuses, nil
+}
+
+// FailedWebhookDeliveries lists up to limit deliveries that gave up, latest first.
+func (d *Database) FailedWebhookDeliveries(limit int) ([]WebhookDelivery, error) {
+	rows, err := d.queries.ListFailedWebhookDeliveries(context.Background(), int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list failed webhooks: %w", err)
+	}
+	deliveries := make([]WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, newWebhookDeliveryFromModel(row))
+	}
+	return deliveries, nil
+}
+
+// RetryFailedWebhooks queues every failed delivery again and returns how many there were.
+func (d *Database) RetryFailedWebhooks(now time.Time) (int, error) {
+	n, err := d.queries.RetryFailedWebhookDeliveries(context.Background(), now.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("retry failed webhooks: %w", err)
+	}
+	return int(n), nil
+}
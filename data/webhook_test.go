@@ -0,0 +1,135 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWebhookWants(t *testing.T) {
+	hook := Webhook{URL: "https://example.com", Events: []string{"timer.*", EventEntryCreated}}
+	for event, want := range map[string]bool{
+		EventTimerStarted:   true,
+		EventTimerStopped:   true,
+		EventEntryCreated:   true,
+		EventEntryUpdated:   false,
+		EventProjectCreated: false,
+		"timers.started":    false,
+	} {
+		if got := hook.Wants(event); got != want {
+			t.Errorf("Wants(%q) = %v, want %v", event, got, want)
+		}
+	}
+	if !(Webhook{}).Wants(EventProjectDeleted) {
+		t.Error("expected a hook without events to want everything")
+	}
+}
+
+func TestChangesQueueWebhookDeliveries(t *testing.T) {
+	db := openTempDatabase(t)
+	if err := db.SetWebhooks([]Webhook{{URL: "ftp://example.com"}}); err == nil {
+		t.Fatal("expected a non-http webhook to be rejected")
+	}
+	if err := db.SetWebhooks([]Webhook{
+		{URL: "https://chat.example.com/hook", Events: []string{"timer.*"}},
+		{URL: "https://status.example.com/hook"},
+	}); err != nil {
+		t.Fatalf("set webhooks: %v", err)
+	}
+
+	project, err := db.CreateProject("Hooks")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	entry, err := project.StopTimer("shipped #release", true, EntryTypeWork)
+	if err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+
+	now := time.Now()
+	deliveries, err := db.ClaimWebhookDeliveries(now, 100)
+	if err != nil {
+		t.Fatalf("claim deliveries: %v", err)
+	}
+	got := make(map[string][]string)
+	var created WebhookEvent
+	for _, delivery := range deliveries {
+		got[delivery.URL] = append(got[delivery.URL], delivery.Event)
+		if delivery.Event == EventEntryCreated {
+			if err := json.Unmarshal(delivery.Payload, &created); err != nil {
+				t.Fatalf("decode payload: %v", err)
+			}
+		}
+	}
+	if chat := got["https://chat.example.com/hook"]; len(chat) != 2 || chat[0] != EventTimerStarted || chat[1] != EventTimerStopped {
+		t.Fatalf("unexpected chat events %v", chat)
+	}
+	if status := got["https://status.example.com/hook"]; len(status) != 4 || status[0] != EventProjectCreated {
+		t.Fatalf("unexpected status events %v", status)
+	}
+	var snapshot entrySnapshot
+	if err := json.Unmarshal(created.Data, &snapshot); err != nil || snapshot.ID != entry.ID || snapshot.Content != "shipped #release" {
+		t.Fatalf("unexpected entry.created data %s (%v)", created.Data, err)
+	}
+
+	if again, err := db.ClaimWebhookDeliveries(now, 100); err != nil || len(again) != 0 {
+		t.Fatalf("expected claimed deliveries to be held back, got %d (%v)", len(again), err)
+	}
+	if later, err := db.ClaimWebhookDeliveries(now.Add(webhookLease+time.Second), 100); err != nil || len(later) != len(deliveries) {
+		t.Fatalf("expected the claims to lapse, got %d (%v)", len(later), err)
+	}
+}
+
+func TestWebhookFailuresBackOffAndGiveUp(t *testing.T) {
+	db := openTempDatabase(t)
+	if err := db.SetWebhooks([]Webhook{{URL: "https://example.com/hook", Events: []string{"project.*"}}}); err != nil {
+		t.Fatalf("set webhooks: %v", err)
+	}
+	if _, err := db.CreateProject("Flaky"); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	now := time.Now()
+	cause := errors.New("example.com answered 502 Bad Gateway")
+	for attempt := 1; attempt <= MaxWebhookAttempts; attempt++ {
+		deliveries, err := db.ClaimWebhookDeliveries(now, 10)
+		if err != nil || len(deliveries) != 1 {
+			t.Fatalf("attempt %d: expected one due delivery, got %d (%v)", attempt, len(deliveries), err)
+		}
+		if err := db.WebhookFailed(deliveries[0], cause, now); err != nil {
+			t.Fatalf("record failure: %v", err)
+		}
+		if early, _ := db.ClaimWebhookDeliveries(now.Add(webhookBackoff(attempt)-time.Second), 10); len(early) != 0 {
+			t.Fatalf("attempt %d: expected the retry to wait %v", attempt, webhookBackoff(attempt))
+		}
+		now = now.Add(webhookBackoff(attempt))
+	}
+	if webhookBackoff(1) != 30*time.Second || webhookBackoff(MaxWebhookAttempts) != time.Hour {
+		t.Fatalf("unexpected backoff %v to %v", webhookBackoff(1), webhookBackoff(MaxWebhookAttempts))
+	}
+
+	if due, _ := db.ClaimWebhookDeliveries(now.Add(24*time.Hour), 10); len(due) != 0 {
+		t.Fatal("expected a delivery that gave up to stay out of the queue")
+	}
+	failed, err := db.FailedWebhookDeliveries(10)
+	if err != nil || len(failed) != 1 || failed[0].Attempts != MaxWebhookAttempts || failed[0].LastError != cause.Error() {
+		t.Fatalf("unexpected failed deliveries %+v (%v)", failed, err)
+	}
+	if n, err := db.RetryFailedWebhooks(now); err != nil || n != 1 {
+		t.Fatalf("retry failed: %d (%v)", n, err)
+	}
+	due, err := db.ClaimWebhookDeliveries(now, 10)
+	if err != nil || len(due) != 1 || due[0].Attempts != 0 {
+		t.Fatalf("expected the retried delivery to be due afresh, got %+v (%v)", due, err)
+	}
+	if err := db.RemoveWebhookDelivery(due[0].ID); err != nil {
+		t.Fatalf("remove delivery: %v", err)
+	}
+	if statuses, err := db.WebhookStatus(); err != nil || len(statuses) != 0 {
+		t.Fatalf("expected an empty outbox, got %+v (%v)", statuses, err)
+	}
+}
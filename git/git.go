@@ -0,0 +1,182 @@
+// Package git reads commits from local repositories and installs the hook that
+// lets checkouts switch samay's timer. It runs the git command line tool.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hookMarker identifies hooks written by InstallHook.
+const hookMarker = "# installed by samay git-hook"
+
+// ErrForeignHook is returned when a post-checkout hook that samay did not
+// write is in the way.
+var ErrForeignHook = errors.New("a post-checkout hook samay did not install already exists")
+
+// Commit is one commit read from a repository.
+type Commit struct {
+	Hash    string
+	Subject string
+	When    time.Time
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s in %s: %s", args[0], dir, msg)
+		}
+		return "", fmt.Errorf("git %s in %s: %w", args[0], dir, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Log returns the commits on local branches of the repository at dir that
+// were committed between since and until, oldest first, leaving out merges.
+// A non-empty author keeps only commits whose author matches it.
+func Log(ctx context.Context, dir string, since, until time.Time, author string) ([]Commit, error) {
+	args := []string{
+		"log", "--branches", "--no-merges", "--format=%H%x1f%ct%x1f%s",
+		"--since=" + since.Format(time.RFC3339),
+		"--until=" + until.Format(time.RFC3339),
+	}
+	if author != "" {
+		args = append(args, "--author="+author)
+	}
+	out, err := run(ctx, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		unix, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, Commit{Hash: fields[0], Subject: fields[2], When: time.Unix(unix, 0)})
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].When.Before(commits[j].When) })
+	return commits, nil
+}
+
+// Subjects returns the distinct subjects of the commits Log finds across
+// dirs, oldest first. Repositories that cannot be read are reported in the
+// error while the others are still used.
+func Subjects(ctx context.Context, dirs []string, since, until time.Time, author string) ([]string, error) {
+	var (
+		commits []Commit
+		errs    []error
+	)
+	for _, dir := range dirs {
+		found, err := Log(ctx, dir, since, until, author)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		commits = append(commits, found...)
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].When.Before(commits[j].When) })
+	seen := make(map[string]bool, len(commits))
+	var subjects []string
+	for _, commit := range commits {
+		if subject := strings.TrimSpace(commit.Subject); subject != "" && !seen[subject] {
+			seen[subject] = true
+			subjects = append(subjects, subject)
+		}
+	}
+	return subjects, errors.Join(errs...)
+}
+
+// TopLevel returns the root of the working tree containing dir.
+func TopLevel(ctx context.Context, dir string) (string, error) {
+	return run(ctx, dir, "rev-parse", "--show-toplevel")
+}
+
+// hookPath returns where the post-checkout hook of the repository at dir
+// lives, honouring core.hooksPath and worktrees.
+func hookPath(ctx context.Context, dir string) (string, error) {
+	hooks, err := run(ctx, dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return filepath.Join(hooks, "post-checkout"), nil
+}
+
+// hookScript runs samay, at executable and on the database at database,
+// after every branch checkout.
+func hookScript(executable, database string) string {
+	command := shellQuote(executable)
+	if database != "" {
+		command += " -database " + shellQuote(database)
+	}
+	return "#!/bin/sh\n" + hookMarker + "\n" +
+		"# Switches the samay timer to the project mapped to the new branch.\n" +
+		"[ \"$3\" = 1 ] || exit 0\n" +
+		"branch=$(git rev-parse --abbrev-ref HEAD) || exit 0\n" +
+		command + " git-hook switch \"$(git rev-parse --show-toplevel)\" \"$branch\" || true\n"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// InstallHook writes a post-checkout hook into the repository at dir that
+// runs executable on every branch checkout, and returns its path. The hook
+// uses the database at database, or samay's configured one when it is empty.
+// A hook samay wrote before is replaced; any other hook is left alone.
+func InstallHook(ctx context.Context, dir, executable, database string) (string, error) {
+	path, err := hookPath(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), hookMarker) {
+		return path, fmt.Errorf("%w: %s", ErrForeignHook, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return path, fmt.Errorf("create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hookScript(executable, database)), 0o755); err != nil {
+		return path, fmt.Errorf("write hook: %w", err)
+	}
+	return path, nil
+}
+
+// UninstallHook removes the post-checkout hook InstallHook wrote into the
+// repository at dir, and reports whether there was one.
+func UninstallHook(ctx context.Context, dir string) (bool, error) {
+	path, err := hookPath(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !strings.Contains(string(existing), hookMarker) {
+		return false, fmt.Errorf("%w: %s", ErrForeignHook, path)
+	}
+	return true, os.Remove(path)
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRepo creates a repository in a temp dir, skipping the test without git.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	gitIn(t, dir, nil, "init", "-q", "-b", "main")
+	return dir
+}
+
+func gitIn(t *testing.T, dir string, env []string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	cmd.Env = append(cmd.Env, env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// commit records an empty commit by email at when.
+func commit(t *testing.T, dir, email, subject string, when time.Time) {
+	t.Helper()
+	date := when.Format(time.RFC3339)
+	gitIn(t, dir, []string{
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=" + email, "GIT_AUTHOR_DATE=" + date,
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=" + email, "GIT_COMMITTER_DATE=" + date,
+	}, "commit", "-q", "--allow-empty", "-m", subject)
+}
+
+func TestSubjectsBetween(t *testing.T) {
+	dir := newRepo(t)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	commit(t, dir, "ada@example.com", "before the timer", start.Add(-time.Hour))
+	commit(t, dir, "ada@example.com", "fix login redirect", start.Add(time.Hour))
+	commit(t, dir, "bob@example.com", "bump dependencies", start.Add(90*time.Minute))
+	commit(t, dir, "ada@example.com", "add login tests", start.Add(2*time.Hour))
+	commit(t, dir, "ada@example.com", "fix login redirect", start.Add(150*time.Minute))
+
+	ctx := context.Background()
+	subjects, err := Subjects(ctx, []string{dir}, start, time.Now(), "ada@example.com")
+	if err != nil {
+		t.Fatalf("subjects: %v", err)
+	}
+	if got := Description(subjects); got != "fix login redirect; add login tests" {
+		t.Fatalf("unexpected description %q", got)
+	}
+	everyone, err := Subjects(ctx, []string{dir, filepath.Join(dir, "missing")}, start, time.Now(), "")
+	if err == nil || len(everyone) != 3 {
+		t.Fatalf("expected 3 subjects and an error for the missing repository, got %v, %v", everyone, err)
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	dir := newRepo(t)
+	ctx := context.Background()
+	path, err := InstallHook(ctx, dir, "/opt/samay's/samay", "/data/my time.db")
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if path != filepath.Join(dir, ".git", "hooks", "post-checkout") {
+		t.Fatalf("unexpected hook path %s", path)
+	}
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read hook: %v", err)
+	}
+	if !strings.Contains(string(script), `'/opt/samay'\''s/samay' -database '/data/my time.db' git-hook switch`) {
+		t.Fatalf("unexpected hook script:\n%s", script)
+	}
+	if _, err := InstallHook(ctx, dir, "/usr/local/bin/samay", ""); err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+	if removed, err := UninstallHook(ctx, dir); err != nil || !removed {
+		t.Fatalf("uninstall: %v, %v", removed, err)
+	}
+	if removed, err := UninstallHook(ctx, dir); err != nil || removed {
+		t.Fatalf("second uninstall: %v, %v", removed, err)
+	}
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho mine\n"), 0o755); err != nil {
+		t.Fatalf("write foreign hook: %v", err)
+	}
+	if _, err := InstallHook(ctx, dir, "/usr/local/bin/samay", ""); !errors.Is(err, ErrForeignHook) {
+		t.Fatalf("expected a foreign hook to be left alone, got %v", err)
+	}
+}
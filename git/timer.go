@@ -0,0 +1,33 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+// TimerSubjects returns the subjects of the commits made, while project's
+// timer has been running, in the configured repositories that track it.
+// Only the current person's commits count when an identity is set.
+func TimerSubjects(ctx context.Context, db *data.Database, project *data.Project, until time.Time) ([]string, error) {
+	onClock, timer := project.OnClock()
+	if !onClock {
+		return nil, nil
+	}
+	dirs := db.GitRepositoriesFor(project)
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+	var author string
+	if person := db.CurrentPerson(); person != nil {
+		author = person.Email
+	}
+	return Subjects(ctx, dirs, timer.StartedTime(), until, author)
+}
+
+// Description joins commit subjects into an entry description.
+func Description(subjects []string) string {
+	return strings.Join(subjects, "; ")
+}
@@ -1,19 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/cli"
+	"github.com/nexneo/samay/daemon"
 	"github.com/nexneo/samay/data"
 	"github.com/nexneo/samay/tui"
 	"github.com/nexneo/samay/util/version"
+	"github.com/nexneo/samay/webhook"
 )
 
 func main() {
 	showVersion := flag.Bool("version", false, "print the samay version and exit")
 	dbOverride := flag.String("database", "", "override the database location for this run")
+	flag.Usage = func() {
+		cli.PrintUsage(flag.CommandLine.Output())
+		_, _ = fmt.Fprintln(flag.CommandLine.Output(), "\nFlags:")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	if *showVersion {
@@ -21,11 +32,45 @@ func main() {
 		return
 	}
 
+	if cli.Standalone(flag.Args()) {
+		// These run often, from shell prompts, and must neither block on
+		// setup questions nor pay for opening the database.
+		path, err := data.ConfiguredDatabasePath(*dbOverride)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "resolve database path: %v\n", err)
+			os.Exit(1)
+		}
+		cli.SetDatabasePath(path)
+		if err := cli.Run(os.Stdout, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "samay: %v\n", err)
+			if errors.Is(err, cli.ErrUsage) {
+				os.Exit(2)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	dbPath, err := data.ResolveDatabasePathWithOverride(*dbOverride)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "resolve database path: %v\n", err)
 		os.Exit(1)
 	}
+	if flag.NArg() > 0 {
+		// A running daemon already has the database open and ready.
+		if forwarded, err := cli.Forward(os.Stdout, flag.Args(), dbPath); forwarded {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "samay: %v\n", err)
+				if errors.Is(err, cli.ErrUsage) {
+					os.Exit(2)
+				}
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	_, daemonRunning := daemon.Connect(dbPath)
+
 	if err := data.OpenDatabase(dbPath); err != nil {
 		fmt.Fprintf(os.Stderr, "open database: %v\n", err)
 		os.Exit(1)
@@ -39,13 +84,86 @@ func main() {
 		}
 	}()
 
-	p := tea.NewProgram(tui.CreateApp())
+	cfg, err := data.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load config: %v\n", err)
+	}
+	cli.ApplyConfig(cfg, os.Stderr)
+	// With a daemon running, it delivers what this process queues. The daemon
+	// itself starts its own sender, since config.json may gain hooks while it runs.
+	stopWebhooks := func() {}
+	if !daemonRunning && flag.Arg(0) != "daemon" {
+		stopWebhooks = startWebhooks()
+	}
+	if retention := cfg.TrashRetention(); retention > 0 {
+		if err := data.DB.PurgeTrash(time.Now().Add(-retention)); err != nil {
+			fmt.Fprintf(os.Stderr, "purge trash: %v\n", err)
+		}
+	}
+
+	if flag.NArg() > 0 {
+		if err := cli.Run(os.Stdout, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "samay: %v\n", err)
+			code := 1
+			if errors.Is(err, cli.ErrUsage) {
+				code = 2
+			}
+			stopWebhooks()
+			_ = data.DB.Close()
+			os.Exit(code)
+		}
+		stopWebhooks()
+		return
+	}
+
+	if !daemonRunning {
+		if _, err := data.DB.RunRecurrences(time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "recurring entries: %v\n", err)
+		}
+	}
+
+	if err := tui.SetKeyMap(cfg.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+	}
+	if err := tui.SetTheme(cfg.Theme, cfg.Themes); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+	}
+	app := tui.CreateApp()
+	// The daemon serves commands, not the UI, which opened the database above.
+	if daemonRunning {
+		app.LeaveJobsToDaemon()
+	}
+	p := tea.NewProgram(app)
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Alas, there's been an error: %v\n", err)
 		os.Exit(1)
 	}
+	stopWebhooks()
 
 	if data.DB != nil && data.DB.Path() != "" {
 		fmt.Printf("Database located at: %s\n", data.DB.Path())
 	}
 }
+
+// startWebhooks sends queued webhook events in the background while samay
+// runs. The returned function stops that and makes one last, bounded attempt
+// to send what the run queued, so a quick `samay start` still notifies hooks.
+func startWebhooks() func() {
+	if len(data.DB.Webhooks()) == 0 {
+		return func() {}
+	}
+	sender := webhook.NewSender(data.DB, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sender.Run(ctx, 15*time.Second)
+	}()
+	return func() {
+		cancel()
+		<-done
+		flush, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFlush()
+		_, _, _ = sender.Deliver(flush)
+	}
+}
@@ -0,0 +1,150 @@
+// Package reminder nudges you to start a timer when none has run for a while
+// during the hours set in config.json.
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+// commandTimeout bounds how long a notification command may run.
+const commandTimeout = 10 * time.Second
+
+// Reminder is a nudge to start a timer.
+type Reminder struct {
+	At time.Time
+	// IdleFor is how long no timer has run, counted from the start of the
+	// reminder hours at the earliest.
+	IdleFor time.Duration
+}
+
+// Message is the reminder as a sentence.
+func (r Reminder) Message() string {
+	idle := fmt.Sprintf("%d minutes", int(r.IdleFor.Minutes()))
+	if r.IdleFor >= time.Hour {
+		idle = util.HmFromD(r.IdleFor).String() + " hours"
+	}
+	return fmt.Sprintf("No timer has run for %s. Time to start one?", idle)
+}
+
+// Notifier delivers reminders.
+type Notifier interface {
+	Notify(ctx context.Context, r Reminder) error
+}
+
+// Bell rings the terminal bell by writing BEL to W.
+type Bell struct {
+	W io.Writer
+}
+
+// Notify rings the bell.
+func (b Bell) Notify(context.Context, Reminder) error {
+	_, err := io.WriteString(b.W, "\a")
+	return err
+}
+
+// Command runs a program for each reminder, such as notify-send. {message}
+// and {minutes} in Args are replaced with the reminder's message and how
+// many minutes no timer has run.
+type Command struct {
+	Args []string
+}
+
+// Notify runs the command.
+func (c Command) Notify(ctx context.Context, r Reminder) error {
+	if len(c.Args) == 0 {
+		return errors.New("reminder command is empty")
+	}
+	replacer := strings.NewReplacer(
+		"{message}", r.Message(),
+		"{minutes}", strconv.Itoa(int(r.IdleFor.Minutes())),
+	)
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = replacer.Replace(arg)
+	}
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("reminder command %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("reminder command %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// Notifiers returns the notifiers schedule asks for: the bell on terminal,
+// when it is not nil, and the command.
+func Notifiers(schedule data.ReminderSchedule, terminal io.Writer) []Notifier {
+	var notifiers []Notifier
+	if schedule.Bell && terminal != nil {
+		notifiers = append(notifiers, Bell{W: terminal})
+	}
+	if len(schedule.Command) > 0 {
+		notifiers = append(notifiers, Command{Args: schedule.Command})
+	}
+	return notifiers
+}
+
+// Checker gives reminders on db's schedule through its notifiers.
+type Checker struct {
+	db        *data.Database
+	notifiers []Notifier
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewChecker returns a checker that delivers reminders through notifiers.
+func NewChecker(db *data.Database, notifiers ...Notifier) *Checker {
+	return &Checker{db: db, notifiers: notifiers}
+}
+
+// Check gives a reminder when one is due at now and returns it, or nil.
+// Every notifier is tried; their failures are joined in the error.
+func (c *Checker) Check(ctx context.Context, now time.Time) (*Reminder, error) {
+	schedule, ok := c.db.Reminders()
+	if !ok {
+		return nil, nil
+	}
+	idleSince, idle, err := c.db.IdleSince()
+	if err != nil || !idle {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if !schedule.Due(now, idleSince, c.last) {
+		c.mu.Unlock()
+		return nil, nil
+	}
+	c.last = now
+	c.mu.Unlock()
+
+	start, _ := schedule.WorkingSince(now)
+	if idleSince.After(start) {
+		start = idleSince
+	}
+	r := &Reminder{At: now, IdleFor: now.Sub(start)}
+	var errs []error
+	for _, notifier := range c.notifiers {
+		if err := notifier.Notify(ctx, *r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return r, errors.Join(errs...)
+}
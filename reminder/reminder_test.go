@@ -0,0 +1,129 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "samay-reminder-test-*")
+	if err != nil {
+		panic(fmt.Sprintf("create temp dir: %v", err))
+	}
+	if err := data.OpenDatabase(filepath.Join(dir, "test.db")); err != nil {
+		panic(fmt.Sprintf("open database: %v", err))
+	}
+
+	code := m.Run()
+
+	if data.DB != nil {
+		_ = data.DB.Close()
+	}
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+type recorder struct {
+	reminders []Reminder
+	err       error
+}
+
+func (r *recorder) Notify(_ context.Context, reminder Reminder) error {
+	r.reminders = append(r.reminders, reminder)
+	return r.err
+}
+
+func TestCheckerRemindsOnSchedule(t *testing.T) {
+	if err := data.DB.SetReminders(&data.ReminderSettings{
+		AfterMinutes: 20,
+		EveryMinutes: 60,
+		Days:         map[string][]string{"daily": {"09:00-17:00"}},
+	}); err != nil {
+		t.Fatalf("set reminders: %v", err)
+	}
+	t.Cleanup(func() { _ = data.DB.SetReminders(nil) })
+
+	year, month, day := time.Now().Date()
+	at := func(hour, minute int) time.Time { return time.Date(year, month, day, hour, minute, 0, 0, time.Local) }
+	record := &recorder{}
+	failing := &recorder{err: errors.New("no display")}
+	checker := NewChecker(data.DB, record, failing)
+	ctx := context.Background()
+
+	if r, err := checker.Check(ctx, at(9, 10)); r != nil || err != nil {
+		t.Fatalf("expected nothing due ten minutes into the day, got %+v, %v", r, err)
+	}
+	r, err := checker.Check(ctx, at(9, 25))
+	if r == nil || r.IdleFor != 25*time.Minute || r.Message() != "No timer has run for 25 minutes. Time to start one?" {
+		t.Fatalf("expected a reminder after 25 minutes, got %+v", r)
+	}
+	if err == nil || !strings.Contains(err.Error(), "no display") {
+		t.Fatalf("expected the failing notifier to be reported, got %v", err)
+	}
+	if len(record.reminders) != 1 || len(failing.reminders) != 1 {
+		t.Fatalf("expected every notifier to be tried, got %d and %d", len(record.reminders), len(failing.reminders))
+	}
+	if r, _ := checker.Check(ctx, at(9, 30)); r != nil {
+		t.Fatalf("expected no repeat within the hour, got %+v", r)
+	}
+	if r, _ := checker.Check(ctx, at(10, 25)); r == nil || r.Message() != "No timer has run for 1:25 hours. Time to start one?" {
+		t.Fatalf("expected the reminder to repeat an hour later, got %+v", r)
+	}
+
+	project, err := data.DB.CreateProject("Reminded")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	t.Cleanup(func() { _, _ = project.StopTimer("", true, data.EntryTypeWork) })
+	if r, err := checker.Check(ctx, at(12, 0)); r != nil || err != nil {
+		t.Fatalf("expected no reminder while a timer runs, got %+v, %v", r, err)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "notified")
+	schedule, err := data.ReminderSettings{
+		Command: []string{"sh", "-c", `printf '%s|%s' "$1" "$2" > "$3"`, "sh", "{message}", "{minutes}", out},
+	}.Schedule()
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	var terminal bytes.Buffer
+	notifiers := Notifiers(schedule, &terminal)
+	if len(notifiers) != 2 {
+		t.Fatalf("expected the bell and the command, got %v", notifiers)
+	}
+	r := Reminder{IdleFor: 42 * time.Minute}
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(context.Background(), r); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+	}
+	if terminal.String() != "\a" {
+		t.Fatalf("expected the bell, got %q", terminal.String())
+	}
+	got, err := os.ReadFile(out)
+	if err != nil || string(got) != r.Message()+"|42" {
+		t.Fatalf("expected the command to get the message and minutes, got %q, %v", got, err)
+	}
+
+	if len(Notifiers(schedule, nil)) != 1 {
+		t.Fatal("expected no bell without a terminal")
+	}
+	failing := Command{Args: []string{"sh", "-c", "echo broken >&2; exit 1"}}
+	if err := failing.Notify(context.Background(), r); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected the command's error output, got %v", err)
+	}
+}
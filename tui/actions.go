@@ -1,8 +1,8 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
@@ -52,7 +52,8 @@ func (a *app) RemoveEntryUI() {
 		a.state = stateEntryList
 	}
 	// Provide soft confirmation to the user
-	a.errorMessage = "Entry deleted"
+	a.errorMessage = "Entry deleted" + undoNote()
+	a.refreshUndoHint()
 }
 
 // RemoveProjectUI retains CLI project removal capability within the TUI.
@@ -72,17 +73,50 @@ func (a *app) RemoveProjectUI() {
 		return
 	}
 
-	if a.project != nil && a.project.GetName() == project.GetName() {
+	if a.project != nil && a.project.ID == project.ID {
 		a.project = nil
 	}
 	a.refreshProjectList()
 	a.state = stateProjectList
-	a.errorMessage = fmt.Sprintf("Project '%s' deleted", project.GetName())
+	a.errorMessage = fmt.Sprintf("Project '%s' deleted%s", project.GetName(), undoNote())
+	a.refreshUndoHint()
 	a.confirmProject = nil
 	a.confirmAction = confirmNone
 	a.confirmMessage = ""
 }
 
+// UndoUI reverts the most recent destructive change recorded by the data layer.
+func (a *app) UndoUI() {
+	action, err := data.DB.Undo()
+	if errors.Is(err, data.ErrNothingToUndo) {
+		a.errorMessage = "Nothing to undo."
+		return
+	}
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error undoing: %v", err)
+		a.refreshUndoHint()
+		return
+	}
+
+	a.refreshProjectList()
+	if a.state == stateEntryList {
+		a.refreshEntryList()
+	}
+	a.refreshUndoHint()
+	a.errorMessage = fmt.Sprintf("Undid %s", action.Description)
+}
+
+// refreshUndoHint caches the description of the next undoable action for footers.
+func (a *app) refreshUndoHint() {
+	a.undoHint = ""
+	if data.DB == nil {
+		return
+	}
+	if action, err := data.DB.LastUndo(); err == nil && action != nil {
+		a.undoHint = action.Description
+	}
+}
+
 // CreateProjectUI adds a new project from the TUI.
 func (a *app) CreateProjectUI() {
 	name := strings.TrimSpace(a.createInput.Value())
@@ -91,13 +125,6 @@ func (a *app) CreateProjectUI() {
 		return
 	}
 
-	for _, p := range data.DB.Projects() {
-		if strings.EqualFold(p.GetName(), name) {
-			a.errorMessage = "A project with that name already exists."
-			return
-		}
-	}
-
 	project, err := data.DB.CreateProject(name)
 	if err != nil {
 		a.errorMessage = fmt.Sprintf("Error creating project: %v", err)
@@ -106,20 +133,17 @@ func (a *app) CreateProjectUI() {
 
 	a.createInput.Blur()
 	a.createInput.SetValue("")
+	if project.ParentID != nil && a.collapsedProjects != nil {
+		delete(a.collapsedProjects, *project.ParentID)
+	}
 	a.refreshProjectList()
 
 	// Attempt to focus the newly created project in the list.
-	items := a.projects.Items()
-	for idx, listItem := range items {
-		if projectItem, ok := listItem.(item); ok && string(projectItem) == project.Name {
-			a.projects.Select(idx)
-			break
-		}
-	}
+	a.selectProjectInList(project.ID)
 	a.updateProjectSelectionFromList()
 	a.previousState = stateProjectMenu
 	a.state = stateProjectMenu
-	a.errorMessage = fmt.Sprintf("Project '%s' created", project.Name)
+	a.errorMessage = fmt.Sprintf("Project '%s' created", project.Path())
 }
 
 // MoveEntryUI retains CLI entry move capability within the TUI.
@@ -146,7 +170,8 @@ func (a *app) MoveEntryUI() {
 		return
 	}
 
-	a.errorMessage = fmt.Sprintf("Entry moved to '%s'", a.moveTargetProject.GetName())
+	a.errorMessage = fmt.Sprintf("Entry moved to '%s'%s", a.moveTargetProject.Path(), undoNote())
+	a.refreshUndoHint()
 	a.moveTargetProject = nil
 	a.selectedEntry = nil
 	a.refreshEntryList()
@@ -171,20 +196,12 @@ func (a *app) MoveProjectUI() {
 		return
 	}
 
-	if strings.EqualFold(newName, a.project.GetName()) {
+	if strings.EqualFold(newName, a.project.GetName()) || strings.EqualFold(newName, a.project.Path()) {
 		a.renameInput.Blur()
 		a.state = stateProjectMenu
 		return
 	}
 
-	// Prevent name collisions.
-	for _, p := range data.DB.Projects() {
-		if p.GetName() == newName {
-			a.errorMessage = "A project with that name already exists."
-			return
-		}
-	}
-
 	if err := a.project.Rename(newName); err != nil {
 		a.errorMessage = fmt.Sprintf("Error renaming project: %v", err)
 		return
@@ -192,7 +209,8 @@ func (a *app) MoveProjectUI() {
 
 	a.renameInput.Blur()
 	a.refreshProjectList()
-	a.errorMessage = fmt.Sprintf("Project renamed to '%s'", newName)
+	a.errorMessage = fmt.Sprintf("Project renamed to '%s'%s", a.project.Path(), undoNote())
+	a.refreshUndoHint()
 	a.state = stateProjectMenu
 }
 
@@ -209,7 +227,6 @@ func (a *app) ReportViewUI() {
 	end := start.AddDate(0, 1, 0)
 
 	type reportRow struct {
-		name        string
 		total       time.Duration
 		billable    time.Duration
 		entries     int
@@ -217,18 +234,20 @@ func (a *app) ReportViewUI() {
 		clockAmount time.Duration
 	}
 
-	rows := make([]reportRow, 0)
+	own := make(map[int64]reportRow)
+	byType := make(map[data.EntryType]time.Duration)
+	byPerson := make(map[int64]time.Duration)
 	var overall, overallBillable time.Duration
 
-	for _, project := range data.DB.Projects() {
+	projects := data.DB.Projects()
+	for _, project := range projects {
 		var row reportRow
-		row.name = project.GetName()
 		for _, entry := range project.Entries() {
 			ended, err := entry.EndedTime()
 			if err != nil || ended == nil {
 				continue
 			}
-			if ended.Before(start) || !ended.Before(end) {
+			if ended.Before(start) || !ended.Before(end) || !a.reportIncludes(entry) {
 				continue
 			}
 			dur := time.Duration(entry.GetDuration())
@@ -236,6 +255,8 @@ func (a *app) ReportViewUI() {
 			if entry.GetBillable() {
 				row.billable += dur
 			}
+			byType[entry.Type] += dur
+			byPerson[creatorKey(entry)] += dur
 			row.entries++
 		}
 		if row.entries == 0 && row.total == 0 {
@@ -245,14 +266,22 @@ func (a *app) ReportViewUI() {
 			row.isOnClock = true
 			row.clockAmount = timer.Duration()
 		}
-		rows = append(rows, row)
+		own[project.ID] = row
 		overall += row.total
 		overallBillable += row.billable
 	}
 
-	sort.Slice(rows, func(i, j int) bool {
-		return rows[i].total > rows[j].total
-	})
+	// Parents report their own time plus everything logged to their sub-projects.
+	tree := data.BuildProjectTree(projects)
+	rows := rollupProjectRows(tree, own,
+		func(total *reportRow, child reportRow) {
+			total.total += child.total
+			total.billable += child.billable
+			total.entries += child.entries
+		},
+		func(row reportRow) bool { return row.entries > 0 || row.total > 0 },
+		func(a, b reportRow) bool { return a.total > b.total },
+	)
 
 	var sb strings.Builder
 	sb.WriteString(detailSectionStyle.Render(fmt.Sprintf("%s – %s", start.Format("2006-01-02"), end.Add(-time.Second).Format("2006-01-02"))))
@@ -262,14 +291,15 @@ func (a *app) ReportViewUI() {
 	sb.WriteString(detailSectionStyle.Render(strings.Repeat("-", 68)))
 	sb.WriteString("\n")
 
-	for _, row := range rows {
+	for _, rolled := range rows {
+		row := rolled.row
 		total := util.HmFromD(row.total).String()
 		billable := util.HmFromD(row.billable).String()
 		onClock := ""
 		if row.isOnClock {
 			onClock = fmt.Sprintf("running (%s)", util.HmFromD(row.clockAmount))
 		}
-		line := fmt.Sprintf("%-28s %10s %10s %8d %s", row.name, total, billable, row.entries, onClock)
+		line := fmt.Sprintf("%-28s %10s %10s %8d %s", rolled.label(), total, billable, row.entries, onClock)
 		sb.WriteString(detailRowStyle.Render(line))
 		sb.WriteString("\n")
 	}
@@ -279,12 +309,53 @@ func (a *app) ReportViewUI() {
 	sb.WriteString("\n")
 	sb.WriteString(detailLine("Billable:", util.HmFromD(overallBillable).String()))
 	sb.WriteString("\n")
+	if lines := typeTotalLines(byType); len(lines) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(detailSectionStyle.Render("By type"))
+		sb.WriteString("\n")
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	if lines := personTotalLines(byPerson); len(lines) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(detailSectionStyle.Render("By person"))
+		sb.WriteString("\n")
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
 
 	a.reportViewport.SetContent(sb.String())
-	a.previousState = a.state
+	if a.state != stateReportView {
+		a.previousState = a.state
+	}
 	a.state = stateReportView
 }
 
+// typeTotalLines renders the time tracked per entry type with its share of the
+// total, or nothing when no time was tracked.
+func typeTotalLines(totals map[data.EntryType]time.Duration) []string {
+	var overall time.Duration
+	for _, d := range totals {
+		overall += d
+	}
+	if overall <= 0 {
+		return nil
+	}
+	var lines []string
+	for _, t := range data.EntryTypes {
+		if totals[t] == 0 {
+			continue
+		}
+		percent := float64(totals[t]) / float64(overall) * 100
+		lines = append(lines, detailRowStyle.Render(fmt.Sprintf("%-8s %8s %5.0f%%", t.Label(), util.HmFromD(totals[t]), percent)))
+	}
+	return lines
+}
+
 // ProjectLogUI retains CLI log presentation capability within the TUI.
 func (a *app) ProjectLogUI() {
 	if a.project == nil {
@@ -306,7 +377,6 @@ func (a *app) WebReplacementUI() {
 	now := time.Now()
 	weekStart := now.AddDate(0, 0, -6)
 	type overview struct {
-		name     string
 		week     time.Duration
 		month    time.Duration
 		billable time.Duration
@@ -314,19 +384,32 @@ func (a *app) WebReplacementUI() {
 		clock    time.Duration
 	}
 
-	rows := make([]overview, 0, len(projects))
-	var maxWeek time.Duration
+	own := make(map[int64]overview, len(projects))
+	weekByType := make(map[data.EntryType]time.Duration)
+	weekByPerson := make(map[int64]time.Duration)
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	var weekPomodoros, todayPomodoros int
+	var weekFocused time.Duration
 	for _, project := range projects {
 		var row overview
-		row.name = project.GetName()
 		for _, entry := range project.Entries() {
 			ended, err := entry.EndedTime()
-			if err != nil || ended == nil {
+			if err != nil || ended == nil || !a.reportIncludes(entry) {
 				continue
 			}
 			dur := time.Duration(entry.GetDuration())
 			if ended.After(weekStart) {
 				row.week += dur
+				weekByType[entry.Type] += dur
+				weekByPerson[creatorKey(entry)] += dur
+				if hasTag(entry, data.PomodoroTag) {
+					weekPomodoros++
+					weekFocused += dur
+					if !ended.Before(today) {
+						todayPomodoros++
+					}
+				}
 			}
 			if ended.Year() == now.Year() && ended.Month() == now.Month() {
 				row.month += dur
@@ -339,14 +422,27 @@ func (a *app) WebReplacementUI() {
 			row.onClock = true
 			row.clock = timer.Duration()
 		}
-		if row.week > maxWeek {
-			maxWeek = row.week
+		own[project.ID] = row
+	}
+
+	// Parents report their own time plus everything logged to their sub-projects.
+	tree := data.BuildProjectTree(projects)
+	rows := rollupProjectRows(tree, own,
+		func(total *overview, child overview) {
+			total.week += child.week
+			total.month += child.month
+			total.billable += child.billable
+		},
+		func(overview) bool { return true },
+		func(a, b overview) bool { return a.week > b.week },
+	)
+	var maxWeek time.Duration
+	for _, rolled := range rows {
+		if rolled.row.week > maxWeek {
+			maxWeek = rolled.row.week
 		}
-		rows = append(rows, row)
 	}
 
-	sort.Slice(rows, func(i, j int) bool { return rows[i].week > rows[j].week })
-
 	barWidth := 24
 	if a.width > 0 {
 		barWidth = a.width / 3
@@ -356,14 +452,15 @@ func (a *app) WebReplacementUI() {
 	}
 
 	var sb strings.Builder
-	sb.WriteString(projectLabelStyle.PaddingLeft(2).Render(fmt.Sprintf("Weekly overview (since %s)", weekStart.Format("2006-01-02"))))
+	sb.WriteString(projectLabelStyle.PaddingLeft(2).Render(fmt.Sprintf("Weekly overview (since %s) for %s", weekStart.Format("2006-01-02"), a.reportPersonLabel())))
 	sb.WriteString("\n\n")
 	sb.WriteString(detailSectionStyle.Render(fmt.Sprintf("%-20s %-8s %-8s %-8s %s", "Project", "7d", "Month", "Billable", "Activity")))
 	sb.WriteString("\n")
 	sb.WriteString(detailSectionStyle.Render(strings.Repeat("-", 20+1+8+1+8+1+8+1+barWidth)))
 	sb.WriteString("\n")
 
-	for _, row := range rows {
+	for _, rolled := range rows {
+		row := rolled.row
 		bar := ""
 		if maxWeek > 0 {
 			ratio := float64(row.week) / float64(maxWeek)
@@ -381,14 +478,14 @@ func (a *app) WebReplacementUI() {
 			activity = fmt.Sprintf("running %s", util.HmFromD(row.clock))
 		}
 		line := fmt.Sprintf("%-20s %-8s %-8s %-8s %s %s",
-			row.name,
+			rolled.label(),
 			util.HmFromD(row.week),
 			util.HmFromD(row.month),
 			util.HmFromD(row.billable),
 			activity,
 			bar,
 		)
-		if a.project != nil && strings.EqualFold(row.name, a.project.GetName()) {
+		if a.project != nil && rolled.project.ID == a.project.ID {
 			sb.WriteString(detailHighlightStyle.Render(line))
 		} else {
 			sb.WriteString(detailRowStyle.Render(line))
@@ -396,7 +493,57 @@ func (a *app) WebReplacementUI() {
 		sb.WriteString("\n")
 	}
 
+	if lines := typeTotalLines(weekByType); len(lines) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(detailSectionStyle.Render("By type (7d)"))
+		sb.WriteString("\n")
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	if lines := personTotalLines(weekByPerson); len(lines) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(detailSectionStyle.Render("By person (7d)"))
+		sb.WriteString("\n")
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	if weekPomodoros > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(detailSectionStyle.Render("Pomodoros (7d)"))
+		sb.WriteString("\n")
+		sb.WriteString(detailRowStyle.Render(fmt.Sprintf("%d completed, %d today, %s focused", weekPomodoros, todayPomodoros, util.HmFromD(weekFocused))))
+		sb.WriteString("\n")
+	}
+
+	if goals := goalOverviewLines(barWidth); len(goals) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(detailSectionStyle.Render("Goals"))
+		sb.WriteString("\n")
+		for _, line := range goals {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	if budgets := budgetOverviewLines(tree, a.project, barWidth); len(budgets) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(detailSectionStyle.Render("Budgets"))
+		sb.WriteString("\n")
+		for _, line := range budgets {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
 	a.dashboardViewport.SetContent(sb.String())
-	a.previousState = a.state
+	if a.state != stateDashboard {
+		a.previousState = a.state
+	}
 	a.state = stateDashboard
 }
@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+)
+
+// budgetStyle colours budget usage by how close it is to running out.
+func budgetStyle(status *data.BudgetStatus) lipgloss.Style {
+	switch {
+	case status.Over():
+		return budgetOverStyle
+	case status.Near():
+		return budgetNearStyle
+	default:
+		return projectLabelStyle
+	}
+}
+
+// budgetBar draws a burn-down bar of width cells, capped at a full bar once over budget.
+func budgetBar(status *data.BudgetStatus, width int) string {
+	filled := int(status.Percent() / 100 * float64(width))
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > width {
+		filled = width
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("·", width-filled)
+}
+
+// budgetWarning describes a project that is over or close to its budget, or
+// returns "" when there is nothing to warn about.
+func budgetWarning(project *data.Project) string {
+	if project == nil {
+		return ""
+	}
+	status, err := project.BudgetStatus(time.Now())
+	if err != nil || status == nil {
+		return ""
+	}
+	switch {
+	case status.Over():
+		return fmt.Sprintf("'%s' is over budget: %s", project.Path(), status)
+	case status.Near():
+		return fmt.Sprintf("'%s' is nearly over budget: %s", project.Path(), status)
+	}
+	return ""
+}
+
+// BudgetUI opens the budget editor for the selected project.
+func (a *app) BudgetUI() {
+	if a.project == nil {
+		a.errorMessage = "No project selected"
+		return
+	}
+	a.budgetInput.SetValue("")
+	budget, err := a.project.Budget()
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading budget: %v", err)
+		return
+	}
+	if budget != nil {
+		a.budgetInput.SetValue(budget.String())
+	}
+	a.state = stateEditBudget
+	a.budgetInput.Focus()
+}
+
+// SaveBudgetUI stores the typed budget, clearing it when the input is empty.
+func (a *app) SaveBudgetUI() {
+	if a.project == nil {
+		a.errorMessage = "No project selected."
+		a.state = stateProjectList
+		return
+	}
+
+	spec := strings.TrimSpace(a.budgetInput.Value())
+	if spec == "" {
+		if err := a.project.ClearBudget(); err != nil {
+			a.errorMessage = fmt.Sprintf("Error clearing budget: %v", err)
+			return
+		}
+		a.budgetInput.Blur()
+		a.errorMessage = fmt.Sprintf("Budget cleared for '%s'", a.project.Path())
+		a.state = stateProjectMenu
+		return
+	}
+
+	budget, err := data.ParseBudget(spec)
+	if err != nil {
+		a.errorMessage = err.Error()
+		return
+	}
+	if err := a.project.SetBudget(budget); err != nil {
+		a.errorMessage = fmt.Sprintf("Error saving budget: %v", err)
+		return
+	}
+	a.budgetInput.Blur()
+	a.errorMessage = budgetWarning(a.project)
+	a.state = stateProjectMenu
+}
+
+func (a *app) handleKeypressEditBudget(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.FormCancel):
+		a.budgetInput.Blur()
+		a.state = stateProjectMenu
+		return a, nil
+	case key.Matches(msg, keys.FormSubmit):
+		a.SaveBudgetUI()
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.budgetInput, cmd = a.budgetInput.Update(msg)
+	return a, cmd
+}
+
+func (a app) budgetView() string {
+	var lines []string
+	lines = append(lines, titleStyle.MarginTop(1).Render("Project budget"))
+	if a.project != nil {
+		lines = append(lines, itemStyle.Render(fmt.Sprintf("Project: %s", a.project.Path())))
+		if status, err := a.project.BudgetStatus(time.Now()); err == nil && status != nil {
+			lines = append(lines, itemStyle.Render("Used: "+budgetStyle(status).Render(status.String())))
+		}
+	}
+	lines = append(lines, "")
+	lines = append(lines, inputPromptStyle.Render(a.budgetInput.View()))
+	lines = append(lines, "")
+	lines = append(lines, itemStyle.Render("Hours: 40h, 10h/week, 20h/month. Money: $5000 @150, $2000/month @95."))
+	lines = append(lines, itemStyle.Render("Money budgets are spent by billable time at the hourly rate. Leave empty to remove."))
+	lines = append(lines, "")
+	lines = append(lines, helpView(describe(keys.FormSubmit, "save"), keys.FormCancel, keys.ForceQuit))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// budgetOverviewLines renders a burn-down row for every project with a budget.
+func budgetOverviewLines(tree []*data.ProjectNode, current *data.Project, barWidth int) []string {
+	now := time.Now()
+	var lines []string
+	data.WalkProjectTree(tree, func(node *data.ProjectNode) bool {
+		status, err := node.Project.BudgetStatus(now)
+		if err != nil || status == nil {
+			return true
+		}
+		label := truncateString(strings.Repeat("  ", node.Depth)+node.Project.Name, 20)
+		bar := budgetBar(status, barWidth)
+		if status.Over() || status.Near() {
+			bar = budgetStyle(status).Render(bar)
+		}
+		line := fmt.Sprintf("%-20s %s %s", label, bar, status)
+		style := detailRowStyle
+		if current != nil && node.Project.ID == current.ID {
+			style = detailHighlightStyle
+		}
+		lines = append(lines, style.Render(line))
+		return true
+	})
+	return lines
+}
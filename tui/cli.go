@@ -5,40 +5,15 @@ import (
 	"strings"
 	"time" // Import the time package
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport" // Import viewport for scrolling logs
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/reminder"
 	"github.com/nexneo/samay/util"
-	"github.com/samber/lo"
-)
-
-var (
-	titleStyle           = lipgloss.NewStyle().MarginLeft(2).Bold(true)
-	itemStyle            = lipgloss.NewStyle().PaddingLeft(4)
-	projectActionStyle   = lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("250"))
-	projectShortcutStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
-	projectShortcutSlot  = lipgloss.NewStyle().Width(3)
-	projectLabelStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
-	selectedItemStyle    = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("170"))
-	paginationStyle      = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
-	helpStyle            = list.DefaultStyles().HelpStyle.PaddingLeft(4).PaddingBottom(1).Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
-	inputPromptStyle     = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("109")) // Style for input prompt
-	errorStyle           = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).PaddingLeft(2) // Style for error messages
-	logHeaderStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("37")).Bold(true)      // Style for log date headers
-	logTotalStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("32")).Bold(true)      // Style for log totals
-	logEntryStyle        = lipgloss.NewStyle()                                                  // Style for individual log entries
-	logTitleStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true)      // Style for the main log title
-	onClockStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("78"))                 // Cool green for "on clock" status
-	detailLabelStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("109")).Bold(true).PaddingLeft(2)
-	detailValueStyle     = lipgloss.NewStyle().PaddingLeft(1).Foreground(lipgloss.Color("252"))
-	detailSectionStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("111")).Bold(true).PaddingLeft(2)
-	detailRowStyle       = detailValueStyle.PaddingLeft(2)
-	detailHighlightStyle = detailRowStyle.Foreground(lipgloss.Color("213")).Bold(true)
-	columnStyle          = lipgloss.NewStyle().Padding(0, 1)
-	dividerStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 )
 
 // Define different states for the application
@@ -57,6 +32,16 @@ const (
 	stateRenameProject                // Renaming/moving a project
 	stateReportView                   // Monthly report view
 	stateDashboard                    // Overview/dashboard view
+	stateTrash                        // Trash bin of deleted projects and entries
+	stateEditBudget                   // Setting a project's budget
+	stateHeatmap                      // Calendar heatmap of tracked time
+	stateHeatmapFilter                // Choosing a project or tag for the heatmap
+	stateHeatmapDay                   // Entries on the day picked in the heatmap
+	stateTimeline                     // One day's entries across projects on a timeline
+	stateResolveOverlap               // Trimming, shifting or merging an entry that overlaps others
+	stateSplitEntry                   // Choosing where to cut an entry in two
+	stateTemplates                    // Picking an entry template to log
+	statePickPerson                   // Choosing who an entry is credited to
 )
 
 // Define focus states for manual entry
@@ -64,15 +49,20 @@ type manualFocus int
 
 const (
 	focusTime manualFocus = iota
+	focusWhen
 	focusMessage
+	focusType
 	focusBillable
+	manualFocusCount
 )
 
 type stopFocus int
 
 const (
 	focusStopMessage stopFocus = iota
+	focusStopType
 	focusStopBillable
+	stopFocusCount
 )
 
 type confirmAction int
@@ -81,21 +71,26 @@ const (
 	confirmNone confirmAction = iota
 	confirmDeleteEntry
 	confirmDeleteProject
+	confirmPurgeTrashItem
+	confirmEmptyTrash
 )
 
 type app struct {
 	project             *data.Project
 	projects            list.Model
 	entries             list.Model
-	choices             [][2]string
 	state               state
 	stopMessageInput    textinput.Model // Renamed for clarity
 	manualTimeInput     textinput.Model // Input for manual entry time
+	manualWhenInput     textinput.Model // Input for the day or start time of a manual entry
 	manualMsgInput      textinput.Model // Input for manual entry message
 	manualEntryFocus    manualFocus     // Which input is focused in manual entry
 	manualBillable      bool
+	manualType          data.EntryType
 	stopBillable        bool
+	stopType            data.EntryType
 	stopEntryFocus      stopFocus
+	stopSuggestions     []string       // Commit subjects made while the timer ran
 	logViewport         viewport.Model // Viewport for scrolling logs
 	reportViewport      viewport.Model // Viewport for report output
 	dashboardViewport   viewport.Model // Viewport for dashboard output
@@ -110,6 +105,7 @@ type app struct {
 	moveTargetProject   *data.Project
 	moveProjects        list.Model
 	renameInput         textinput.Model
+	budgetInput         textinput.Model
 	createInput         textinput.Model
 	reportMonth         time.Month
 	reportYear          int
@@ -117,6 +113,29 @@ type app struct {
 	previousState       state
 	numericSelectBuffer string
 	numericSelectLast   time.Time
+	undoHint            string // Description of the next undoable action, if any
+	trash               list.Model
+	confirmTrash        trashItem
+	collapsedProjects   map[int64]bool // Parent projects whose sub-projects are folded away
+	heatmapDay          time.Time      // Day selected in the heatmap, at local midnight
+	heatmapFilter       data.EntryFilter
+	heatmapTotals       map[time.Time]time.Duration
+	heatmapInput        textinput.Model
+	dayEntries          list.Model
+	timeline            *data.DayTimeline
+	timelineReturn      state // Where esc leaves the timeline to
+	overlapEntry        *data.Entry
+	overlapConflicts    []*data.Entry
+	overlapIndex        int // Which conflicting entry is selected
+	splitEntry          *data.Entry
+	splitInputs         [splitFieldCount]textinput.Model
+	splitFocus          int
+	templates           list.Model
+	people              list.Model   // People an entry can be credited to
+	reportPerson        *data.Person // Whose time the reports show; nil means everyone
+	reminders           *reminder.Checker
+	reminderBanner      string           // Reminder to start a timer, shown until one runs
+	pomodoro            *pomodoroSession // Pomodoro mode in progress, if any
 }
 
 func CreateApp() *app {
@@ -125,9 +144,7 @@ func CreateApp() *app {
 	if len(projects) > 0 {
 		currentProject = projects[0]
 	}
-	items := lo.Map(projects, func(p *data.Project, _ int) list.Item {
-		return item(p.Name)
-	})
+	items := projectListItems(data.BuildProjectTree(projects), nil)
 	const defaultWidth = 20
 	listHeight := len(items)*2 + 5 // Adjust height based on items
 
@@ -137,9 +154,8 @@ func CreateApp() *app {
 	l.SetFilteringEnabled(false)
 	l.SetShowHelp(false)
 	l.Styles.TitleBar.Padding(3)
-	l.Styles.Title = titleStyle
-	l.Styles.PaginationStyle = paginationStyle
-	l.Styles.HelpStyle = helpStyle
+	activeTheme.applyToList(&l)
+	keys.applyToList(&l)
 
 	// Check if any project has a running timer
 	for _, p := range projects {
@@ -152,15 +168,20 @@ func CreateApp() *app {
 
 	// text input model for stopping timer
 	stopTI := textinput.New()
-	stopTI.Placeholder = "Enter stop message (optional, press Enter to submit, Esc to cancel)"
+	stopTI.Placeholder = fmt.Sprintf("Enter stop message (optional, press %s to submit, %s to cancel)", keys.FormSubmit.Help().Key, keys.FormCancel.Help().Key)
 	stopTI.CharLimit = 156
 	stopTI.Width = 50 // Adjust width as needed
 
 	// text input models for manual entry
 	manualTimeTI := textinput.New()
-	manualTimeTI.Placeholder = "e.g., 1h30m, 45m"
-	manualTimeTI.CharLimit = 20
-	manualTimeTI.Width = 20
+	manualTimeTI.Placeholder = "1h30m, 1.5h, 90 or 9:00-10:45"
+	manualTimeTI.CharLimit = 60
+	manualTimeTI.Width = 40
+
+	manualWhenTI := textinput.New()
+	manualWhenTI.Placeholder = "now, yesterday, 2024-05-12 or monday 9am"
+	manualWhenTI.CharLimit = 60
+	manualWhenTI.Width = 40
 
 	manualMsgTI := textinput.New()
 	manualMsgTI.Placeholder = "Description of the work done"
@@ -168,12 +189,22 @@ func CreateApp() *app {
 	manualMsgTI.Width = 50
 
 	renameTI := textinput.New()
-	renameTI.Placeholder = "Enter new project name"
+	renameTI.Placeholder = "Enter new project name, or parent/child to move it"
 	renameTI.CharLimit = 120
 	renameTI.Width = 50
 
+	budgetTI := textinput.New()
+	budgetTI.Placeholder = "e.g., 40h, 10h/week, $5000 @150"
+	budgetTI.CharLimit = 60
+	budgetTI.Width = 50
+
+	heatmapTI := textinput.New()
+	heatmapTI.Placeholder = "Project name or path, or #tag"
+	heatmapTI.CharLimit = 120
+	heatmapTI.Width = 50
+
 	createTI := textinput.New()
-	createTI.Placeholder = "Enter project name"
+	createTI.Placeholder = "Enter project name, or parent/child for a sub-project"
 	createTI.CharLimit = 120
 	createTI.Width = 50
 
@@ -187,6 +218,9 @@ func CreateApp() *app {
 
 	dashboardVP := viewport.New(defaultWidth, 20)
 	dashboardVP.SetContent("Dashboard coming soon")
+	for _, v := range []*viewport.Model{&vp, &reportVP, &dashboardVP} {
+		keys.applyToViewport(v)
+	}
 
 	initialState := stateProjectList
 	if currentProject != nil {
@@ -199,52 +233,55 @@ func CreateApp() *app {
 		state:             initialState,
 		stopMessageInput:  stopTI,
 		manualTimeInput:   manualTimeTI,
+		manualWhenInput:   manualWhenTI,
 		manualMsgInput:    manualMsgTI,
 		manualEntryFocus:  focusTime,
 		manualBillable:    true,
+		manualType:        data.EntryTypeWork,
 		stopBillable:      true,
+		stopType:          data.EntryTypeWork,
 		stopEntryFocus:    focusStopMessage,
 		logViewport:       vp,
 		reportViewport:    reportVP,
 		dashboardViewport: dashboardVP,
-		choices: [][2]string{
-			{"s", "Start timer"},
-			{"p", "End timer"},
-			{"e", "Enter manually"},
-			{"l", "Show logs"},
-			{"v", "Entries"},
-			{"D", "Delete project"},
-			{"R", "Rename project"},
-		},
-		renameInput:   renameTI,
-		createInput:   createTI,
-		reportMonth:   time.Now().Month(),
-		reportYear:    time.Now().Year(),
-		previousState: initialState,
+		renameInput:       renameTI,
+		budgetInput:       budgetTI,
+		heatmapInput:      heatmapTI,
+		createInput:       createTI,
+		reportMonth:       time.Now().Month(),
+		reportYear:        time.Now().Year(),
+		previousState:     initialState,
+		reminders:         newReminderChecker(false),
 	}
 
 	if currentProject != nil {
-		for idx, p := range projects {
-			if p.GetName() == currentProject.GetName() {
-				a.projects.Select(idx)
-				break
-			}
-		}
+		a.selectProjectInList(currentProject.ID)
 	}
 
 	a.updateProjectSelectionFromList()
+	a.refreshUndoHint()
 
 	return a
 }
 
 func (a app) Init() tea.Cmd {
-	return tea.ClearScreen
+	return tea.Batch(tea.ClearScreen, a.reminderTick())
 }
 
 func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd // Slice to hold commands
 
+	// Ticks must not clear the error a user is reading.
+	switch tick := msg.(type) {
+	case reminderTickMsg:
+		return a.handleReminderTick(tick)
+	case reminderCheckedMsg:
+		return a.handleReminderChecked(tick)
+	case pomodoroTickMsg:
+		return a.handlePomodoroTick(tick)
+	}
+
 	// Clear error message on any key press or resize, unless we are showing logs
 	// where the error might be relevant to the log fetching itself.
 	if a.state != stateShowLogs {
@@ -276,7 +313,14 @@ func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(a.moveProjects.Items()) > 0 {
 			a.moveProjects.SetSize(msg.Width, msg.Height-6)
 		}
+		if len(a.trash.Items()) > 0 {
+			a.trash.SetSize(msg.Width, msg.Height-6)
+		}
+		if len(a.dayEntries.Items()) > 0 {
+			a.dayEntries.SetSize(msg.Width, msg.Height-8)
+		}
 		a.renameInput.Width = msg.Width - 10
+		a.budgetInput.Width = msg.Width - 10
 		// Adjust input widths dynamically if desired
 		// a.stopMessageInput.Width = msg.Width - 10
 		// a.manualMsgInput.Width = msg.Width - 30
@@ -325,6 +369,36 @@ func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case stateDashboard:
 			m, c := a.handleKeypressDashboard(msg)
 			return m, c
+		case stateEditBudget:
+			m, c := a.handleKeypressEditBudget(msg)
+			return m, c
+		case stateHeatmap:
+			m, c := a.handleKeypressHeatmap(msg)
+			return m, c
+		case stateHeatmapFilter:
+			m, c := a.handleKeypressHeatmapFilter(msg)
+			return m, c
+		case stateHeatmapDay:
+			m, c := a.handleKeypressHeatmapDay(msg)
+			return m, c
+		case stateTimeline:
+			m, c := a.handleKeypressTimeline(msg)
+			return m, c
+		case stateResolveOverlap:
+			m, c := a.handleKeypressResolveOverlap(msg)
+			return m, c
+		case stateSplitEntry:
+			m, c := a.handleKeypressSplitEntry(msg)
+			return m, c
+		case stateTemplates:
+			m, c := a.handleKeypressTemplates(msg)
+			return m, c
+		case statePickPerson:
+			m, c := a.handleKeypressPickPerson(msg)
+			return m, c
+		case stateTrash:
+			m, c := a.handleKeypressTrash(msg)
+			return m, c
 		}
 	}
 
@@ -346,6 +420,9 @@ func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case focusTime:
 			a.manualTimeInput, cmd = a.manualTimeInput.Update(msg)
 			cmds = append(cmds, cmd)
+		case focusWhen:
+			a.manualWhenInput, cmd = a.manualWhenInput.Update(msg)
+			cmds = append(cmds, cmd)
 		case focusMessage:
 			a.manualMsgInput, cmd = a.manualMsgInput.Update(msg)
 			cmds = append(cmds, cmd)
@@ -369,6 +446,27 @@ func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateDashboard:
 		a.dashboardViewport, cmd = a.dashboardViewport.Update(msg)
 		cmds = append(cmds, cmd)
+	case stateTrash:
+		a.trash, cmd = a.trash.Update(msg)
+		cmds = append(cmds, cmd)
+	case statePickPerson:
+		a.people, cmd = a.people.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateEditBudget:
+		a.budgetInput, cmd = a.budgetInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateHeatmapFilter:
+		a.heatmapInput, cmd = a.heatmapInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateHeatmapDay:
+		a.dayEntries, cmd = a.dayEntries.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateSplitEntry:
+		a.splitInputs[a.splitFocus], cmd = a.splitInputs[a.splitFocus].Update(msg)
+		cmds = append(cmds, cmd)
+	case stateTemplates:
+		a.templates, cmd = a.templates.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return a, tea.Batch(cmds...) // Batch commands
@@ -390,10 +488,14 @@ func (a *app) refreshEntryList() {
 
 func (a *app) exitConfirmation() {
 	a.state = a.previousState
+	if a.confirmAction == confirmPurgeTrashItem || a.confirmAction == confirmEmptyTrash {
+		a.state = stateTrash
+	}
 	a.confirmMessage = ""
 	a.confirmAction = confirmNone
 	a.confirmEntry = nil
 	a.confirmProject = nil
+	a.confirmTrash = trashItem{}
 }
 
 func (a *app) adjustReportMonth(delta int) {
@@ -450,15 +552,22 @@ func (a *app) entryDetailView(entry *data.Entry) string {
 	if entry.GetBillable() {
 		billableStr = "Yes"
 	}
+	creator := "—"
+	if name := personName(entry.CreatorID); name != "" {
+		creator = name
+	}
 	tags := "—"
 	if len(entry.GetTags()) > 0 {
 		tags = "#" + strings.Join(entry.GetTags(), " #")
 	}
 
 	lines = append(lines,
+		detailLine("ID:", shortID(entry.ID)),
 		detailLine("Started:", startedStr),
 		detailLine("Ended:", endedStr),
 		detailLine("Duration:", duration.String()),
+		detailLine("Type:", entry.Type.Label()),
+		detailLine("By:", creator),
 		detailLine("Billable:", billableStr),
 		detailLine("Tags:", tags),
 	)
@@ -474,10 +583,60 @@ func (a *app) entryDetailView(entry *data.Entry) string {
 		lines = append(lines, detailValueStyle.PaddingLeft(2).Render(line))
 	}
 
+	if history := entryHistoryLines(entry.ID, historyPreviewLimit); len(history) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, detailSectionStyle.Render("History"))
+		for _, line := range history {
+			lines = append(lines, detailValueStyle.PaddingLeft(2).Render(line))
+		}
+	}
+
 	lines = append(lines, "")
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
+const historyPreviewLimit = 5
+
+// entryHistoryLines summarizes the most recent changes recorded for an entry, newest first.
+func entryHistoryLines(id string, limit int) []string {
+	changes, err := data.DB.EntryHistory(id)
+	if err != nil || len(changes) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, limit)
+	for i := len(changes) - 1; i >= 0 && len(lines) < limit; i-- {
+		change := changes[i]
+		line := fmt.Sprintf("%s  %-7s", change.CreatedAt.In(time.Local).Format("Jan 02 15:04"), change.Action)
+		if change.Action == data.ChangeUpdate || change.Action == data.ChangeMove {
+			fields := make([]string, 0)
+			for _, field := range change.Fields() {
+				fields = append(fields, field.Field)
+			}
+			line += " " + strings.Join(fields, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func shortID(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
+
+// withUndoHint appends the pending undo action to a footer's help text.
+func withUndoHint(help, hint string) string {
+	if hint == "" {
+		return help
+	}
+	if !keys.Undo.Enabled() {
+		return help
+	}
+	return help + " | " + keys.Undo.Help().Key + ": undo " + truncateString(hint, 40)
+}
+
 func detailLine(label, value string) string {
 	if value == "" {
 		value = "—"
@@ -487,18 +646,20 @@ func detailLine(label, value string) string {
 }
 
 func (a *app) handleKeypressConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c":
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc", "n":
+	case key.Matches(msg, keys.Back, keys.ConfirmNo):
 		a.exitConfirmation()
 		return a, nil
-	case "enter", "y":
+	case key.Matches(msg, keys.ConfirmYes):
 		switch a.confirmAction {
 		case confirmDeleteEntry:
 			a.RemoveEntryUI()
 		case confirmDeleteProject:
 			a.RemoveProjectUI()
+		case confirmPurgeTrashItem, confirmEmptyTrash:
+			a.PurgeTrashUI()
 		}
 		return a, nil
 	}
@@ -506,22 +667,28 @@ func (a *app) handleKeypressConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (a *app) handleKeypressMoveEntryTarget(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c", "q":
+	if a.moveProjects.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		a.moveProjects, cmd = a.moveProjects.Update(msg)
+		return a, cmd
+	}
+
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.Back):
 		a.state = a.previousState
 		return a, nil
-	case "enter":
+	case key.Matches(msg, keys.Select):
 		selected := a.moveProjects.SelectedItem()
-		if projectItem, ok := selected.(item); ok {
-			projects := lo.Filter(data.DB.Projects(), func(p *data.Project, _ int) bool {
-				return p.Name == string(projectItem)
-			})
-			if len(projects) > 0 {
-				a.moveTargetProject = projects[0]
-				a.MoveEntryUI()
+		if target, ok := selected.(item); ok {
+			project, err := data.DB.FindProject(string(target))
+			if err != nil {
+				a.errorMessage = fmt.Sprintf("Error finding project: %v", err)
+				return a, nil
 			}
+			a.moveTargetProject = project
+			a.MoveEntryUI()
 		}
 		return a, nil
 	}
@@ -532,13 +699,17 @@ func (a *app) handleKeypressMoveEntryTarget(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 }
 
 func (a *app) handleKeypressDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.Back):
 		a.state = a.previousState
 		return a, nil
-	case "r":
+	case key.Matches(msg, keys.OverviewRefresh):
+		a.WebReplacementUI()
+		return a, nil
+	case key.Matches(msg, keys.ReportPerson):
+		a.cycleReportPerson()
 		a.WebReplacementUI()
 		return a, nil
 	}
@@ -549,14 +720,14 @@ func (a *app) handleKeypressDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (a *app) handleKeypressRenameProject(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.FormCancel):
 		a.renameInput.Blur()
 		a.state = stateProjectMenu
 		return a, nil
-	case "enter":
+	case key.Matches(msg, keys.FormSubmit):
 		a.MoveProjectUI()
 		return a, nil
 	}
@@ -567,10 +738,10 @@ func (a *app) handleKeypressRenameProject(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (a *app) handleKeypressCreateProject(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.FormCancel):
 		a.createInput.Blur()
 		target := a.previousState
 		if target != stateProjectMenu && target != stateProjectList {
@@ -579,7 +750,7 @@ func (a *app) handleKeypressCreateProject(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		a.state = target
 		a.updateProjectSelectionFromList()
 		return a, nil
-	case "enter":
+	case key.Matches(msg, keys.FormSubmit):
 		a.CreateProjectUI()
 		return a, nil
 	}
@@ -602,32 +773,38 @@ func (a app) View() string {
 		lines = append(lines, "")
 		lines = append(lines, inputPromptStyle.Render(a.createInput.View()))
 		lines = append(lines, "")
-		lines = append(lines, helpStyle.Render("enter: create | esc: cancel | ctrl+c: quit"))
+		lines = append(lines, helpView(describe(keys.FormSubmit, "create"), keys.FormCancel, keys.ForceQuit))
 		viewContent = lipgloss.JoinVertical(lipgloss.Left, lines...)
 
 	case stateStoppingTimer:
 		var lines []string
 		projectName := ""
 		if a.project != nil {
-			projectName = a.project.Name
+			projectName = a.project.Path()
 		}
 		promptText := "Enter message for stopping timer (Project: " + projectName + ")"
 		lines = append(lines, titleStyle.MarginTop(1).Render(promptText))
 		lines = append(lines, "")
 		lines = append(lines, inputPromptStyle.Render(a.stopMessageInput.View()))
 		lines = append(lines, "")
+		stopTypeStyle := itemStyle
+		if a.stopEntryFocus == focusStopType {
+			stopTypeStyle = activeTheme.focusStyle(stopTypeStyle)
+		}
+		lines = append(lines, stopTypeStyle.Render(fmt.Sprintf("Type: %s (%s to change)", a.stopType.Label(), keys.FormToggle.Help().Key)))
 		stopBillableLabel := "Yes"
 		if !a.stopBillable {
 			stopBillableLabel = "No"
 		}
-		stopBillableText := fmt.Sprintf("Billable: %s (space to toggle)", stopBillableLabel)
+		stopBillableText := fmt.Sprintf("Billable: %s (%s to toggle)", stopBillableLabel, keys.FormToggle.Help().Key)
 		stopBillableStyle := itemStyle
 		if a.stopEntryFocus == focusStopBillable {
-			stopBillableStyle = stopBillableStyle.Foreground(lipgloss.Color("170")).Bold(true)
+			stopBillableStyle = activeTheme.focusStyle(stopBillableStyle)
 		}
 		lines = append(lines, stopBillableStyle.Render(stopBillableText))
 		lines = append(lines, "")
-		helpText := helpStyle.Render("enter: submit | tab/↑/↓: switch field | esc: cancel | ctrl+c: quit")
+		lines = append(lines, a.commitSuggestionLines()...)
+		helpText := helpView(keys.FormSubmit, keyGroup("switch field", keys.FormNext, keys.FormPrevious), keys.FormCancel, keys.ForceQuit)
 		lines = append(lines, helpText)
 		viewContent = lipgloss.JoinVertical(lipgloss.Left, lines...)
 
@@ -635,30 +812,38 @@ func (a app) View() string {
 		var lines []string
 		projectName := ""
 		if a.project != nil {
-			projectName = a.project.Name
+			projectName = a.project.Path()
 		}
 		promptText := "Manually enter time for project: " + projectName
 		lines = append(lines, titleStyle.MarginTop(1).Render(promptText))
 		lines = append(lines, "")
-		lines = append(lines, inputPromptStyle.Render("Duration (e.g., 1h30m):"))
+		lines = append(lines, inputPromptStyle.Render("Duration or time range (e.g., 1h30m, 1:30, 9:00-10:45, yesterday 3pm to 5pm):"))
 		fieldStyle := itemStyle.PaddingLeft(2)
 		lines = append(lines, fieldStyle.Render(a.manualTimeInput.View()))
 		lines = append(lines, "")
+		lines = append(lines, inputPromptStyle.Render("When (a day or start time; empty for now):"))
+		lines = append(lines, fieldStyle.Render(a.manualWhenInput.View()))
+		lines = append(lines, "")
 		lines = append(lines, inputPromptStyle.Render("Message:"))
 		lines = append(lines, fieldStyle.Render(a.manualMsgInput.View()))
 		lines = append(lines, "")
+		manualTypeStyle := fieldStyle
+		if a.manualEntryFocus == focusType {
+			manualTypeStyle = activeTheme.focusStyle(manualTypeStyle)
+		}
+		lines = append(lines, manualTypeStyle.Render(fmt.Sprintf("Type: %s (%s to change)", a.manualType.Label(), keys.FormToggle.Help().Key)))
 		manualBillableLabel := "Yes"
 		if !a.manualBillable {
 			manualBillableLabel = "No"
 		}
-		manualBillableText := fmt.Sprintf("Billable: %s (space to toggle)", manualBillableLabel)
+		manualBillableText := fmt.Sprintf("Billable: %s (%s to toggle)", manualBillableLabel, keys.FormToggle.Help().Key)
 		manualBillableStyle := fieldStyle
 		if a.manualEntryFocus == focusBillable {
-			manualBillableStyle = manualBillableStyle.Foreground(lipgloss.Color("170")).Bold(true)
+			manualBillableStyle = activeTheme.focusStyle(manualBillableStyle)
 		}
 		lines = append(lines, manualBillableStyle.Render(manualBillableText))
 		lines = append(lines, "")
-		helpText := helpStyle.Render("enter: submit | tab/↑/↓: switch | esc: cancel | ctrl+c: quit")
+		helpText := helpView(keys.FormSubmit, keyGroup("switch", keys.FormNext, keys.FormPrevious), keys.FormCancel, keys.ForceQuit)
 		lines = append(lines, helpText)
 		viewContent = lipgloss.JoinVertical(lipgloss.Left, lines...)
 
@@ -677,10 +862,14 @@ func (a app) View() string {
 		if a.project == nil {
 			viewContent = errorStyle.Render("No project selected")
 		} else {
-			projectName := a.project.Name
+			projectName := a.project.Path()
 			header := titleStyle.MarginTop(1).Render(fmt.Sprintf("Project: %s", projectName))
 			entriesTitle := titleStyle.Render("Entries")
-			help := helpStyle.Render("↑/↓: navigate | m: move entry | d: delete | esc: back | q: quit")
+			help := helpStyle.Render(withUndoHint(helpLine(
+				keyGroup("navigate", keys.Up, keys.Down),
+				keys.EntryMove, keys.EntrySplit, keys.EntryMark, keys.EntryMerge, keys.EntryOverlaps, keys.EntryCredit, keys.EntryDelete,
+				keys.Back, keys.Quit,
+			), a.undoHint))
 			entry := entryFromListItem(a.entries.SelectedItem())
 			detail := a.entryDetailView(entry)
 			viewContent = lipgloss.JoinVertical(lipgloss.Left,
@@ -698,31 +887,31 @@ func (a app) View() string {
 		lines = append(lines, "")
 		lines = append(lines, itemStyle.Render(a.confirmMessage))
 		lines = append(lines, "")
-		lines = append(lines, helpStyle.Render("y: confirm | n/esc: cancel | ctrl+c: quit"))
+		lines = append(lines, helpView(keys.ConfirmYes, keyGroup("cancel", keys.ConfirmNo, keys.Back), keys.ForceQuit))
 		viewContent = lipgloss.JoinVertical(lipgloss.Left, lines...)
 
 	case stateMoveEntryTarget:
 		viewContent = lipgloss.JoinVertical(lipgloss.Left,
 			titleStyle.MarginTop(1).Render("Move entry to which project?"),
 			a.moveProjects.View(),
-			helpStyle.Render("enter: move | esc: back | q: quit"),
+			helpView(describe(keys.Select, "move"), keys.Back, keys.Quit),
 		)
 
 	case stateRenameProject:
 		var lines []string
 		lines = append(lines, titleStyle.MarginTop(1).Render("Rename project"))
 		if a.project != nil {
-			lines = append(lines, itemStyle.Render(fmt.Sprintf("Current name: %s", a.project.Name)))
+			lines = append(lines, itemStyle.Render(fmt.Sprintf("Current name: %s", a.project.Path())))
 		}
 		lines = append(lines, "")
 		lines = append(lines, inputPromptStyle.Render(a.renameInput.View()))
 		lines = append(lines, "")
-		lines = append(lines, helpStyle.Render("enter: save | esc: cancel | ctrl+c: quit"))
+		lines = append(lines, helpView(describe(keys.FormSubmit, "save"), keys.FormCancel, keys.ForceQuit))
 		viewContent = lipgloss.JoinVertical(lipgloss.Left, lines...)
 
 	case stateReportView:
-		title := titleStyle.MarginTop(1).Render(fmt.Sprintf("Monthly report: %s %d", a.reportMonth, a.reportYear))
-		controls := helpStyle.Render("←/h: previous month | →/l: next month | r: reset | esc: back | q: quit")
+		title := titleStyle.MarginTop(1).Render(fmt.Sprintf("Monthly report: %s %d (%s)", a.reportMonth, a.reportYear, a.reportPersonLabel()))
+		controls := helpView(keys.ReportPrevious, keys.ReportNext, keys.ReportThisMonth, keys.ReportPerson, keys.Back, keys.Quit)
 		viewContent = lipgloss.JoinVertical(lipgloss.Left,
 			title,
 			a.reportViewport.View(),
@@ -731,13 +920,43 @@ func (a app) View() string {
 
 	case stateDashboard:
 		title := titleStyle.MarginTop(1).Render("")
-		controls := helpStyle.Render("r: refresh | esc: back | q: quit")
+		controls := helpView(keys.OverviewRefresh, keys.ReportPerson, keys.Back, keys.Quit)
 		viewContent = lipgloss.JoinVertical(lipgloss.Left,
 			title,
 			a.dashboardViewport.View(),
 			controls,
 		)
 
+	case stateTrash:
+		viewContent = a.trashView()
+
+	case stateTemplates:
+		viewContent = a.templatesView()
+
+	case statePickPerson:
+		viewContent = a.pickPersonView()
+
+	case stateEditBudget:
+		viewContent = a.budgetView()
+
+	case stateHeatmap:
+		viewContent = a.heatmapView()
+
+	case stateHeatmapFilter:
+		viewContent = a.heatmapFilterView()
+
+	case stateHeatmapDay:
+		viewContent = a.heatmapDayView()
+
+	case stateTimeline:
+		viewContent = a.timelineView()
+
+	case stateResolveOverlap:
+		viewContent = a.resolveOverlapView()
+
+	case stateSplitEntry:
+		viewContent = a.splitEntryView()
+
 	default:
 		viewContent = errorStyle.Render("Unknown state")
 	}
@@ -756,5 +975,12 @@ func (a app) View() string {
 
 	}
 
+	if a.pomodoro != nil {
+		viewContent = lipgloss.JoinVertical(lipgloss.Left, a.pomodoroView(time.Now()), viewContent)
+	}
+	if a.reminderBanner != "" {
+		viewContent = lipgloss.JoinVertical(lipgloss.Left, a.reminderBannerView(), viewContent)
+	}
+
 	return viewContent
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nexneo/samay/data"
@@ -13,7 +14,8 @@ import (
 )
 
 type entryItem struct {
-	entry *data.Entry
+	entry  *data.Entry
+	marked bool // Marked for merging
 }
 
 func (i entryItem) FilterValue() string {
@@ -55,7 +57,14 @@ func (d entryItemDelegate) Render(w io.Writer, m list.Model, index int, listItem
 		desc = desc[:maxDesc-3] + "..."
 	}
 
-	line := fmt.Sprintf("%2d %s %s", index+1, hm, desc)
+	marker := " "
+	if it.marked {
+		marker = "●"
+	}
+	line := fmt.Sprintf("%s%2d %s %s", marker, index+1, hm, desc)
+	if entry.Type != "" && entry.Type != data.EntryTypeWork {
+		line += " [" + strings.ToLower(string(entry.Type)) + "]"
+	}
 
 	if index == m.Index() {
 		highlight := selectedItemStyle.PaddingLeft(4)
@@ -84,8 +93,8 @@ func buildEntryList(project *data.Project, width, height int) list.Model {
 	l.SetShowHelp(false)
 	l.SetShowPagination(true)
 	l.SetShowStatusBar(false)
-	l.Styles.Title = titleStyle
-	l.Styles.PaginationStyle = paginationStyle
+	activeTheme.applyToList(&l)
+	keys.applyToList(&l)
 	return l
 }
 
@@ -97,14 +106,14 @@ func entryFromListItem(i list.Item) *data.Entry {
 }
 
 func (a *app) handleKeypressEntryList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.Back):
 		a.state = stateProjectMenu
 		a.errorMessage = ""
 		return a, nil
-	case "m":
+	case key.Matches(msg, keys.EntryMove):
 		entry := entryFromListItem(a.entries.SelectedItem())
 		if entry == nil {
 			return a, nil
@@ -118,7 +127,25 @@ func (a *app) handleKeypressEntryList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		a.previousState = stateEntryList
 		a.state = stateMoveEntryTarget
 		return a, nil
-	case "d":
+	case key.Matches(msg, keys.EntryOverlaps):
+		a.OverlapUI(entryFromListItem(a.entries.SelectedItem()))
+		return a, nil
+	case key.Matches(msg, keys.EntrySplit):
+		a.SplitUI(entryFromListItem(a.entries.SelectedItem()))
+		return a, nil
+	case key.Matches(msg, keys.EntryCredit):
+		a.PickPersonUI(entryFromListItem(a.entries.SelectedItem()))
+		return a, nil
+	case key.Matches(msg, keys.EntryMark):
+		a.toggleEntryMark()
+		return a, nil
+	case key.Matches(msg, keys.EntryMerge):
+		a.MergeUI()
+		return a, nil
+	case key.Matches(msg, keys.Undo):
+		a.UndoUI()
+		return a, nil
+	case key.Matches(msg, keys.EntryDelete):
 		entry := entryFromListItem(a.entries.SelectedItem())
 		if entry != nil {
 			a.selectedEntry = entry
@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/git"
+)
+
+// commitSuggestionsShown caps how many commit subjects the stop view lists.
+const commitSuggestionsShown = 5
+
+// loadCommitSuggestions reads the commits made in the project's repositories
+// since its timer started, for the stop view to offer as the description.
+func (a *app) loadCommitSuggestions() {
+	a.stopSuggestions = nil
+	if a.project == nil || len(data.DB.GitRepositories()) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// A repository that cannot be read just offers nothing.
+	a.stopSuggestions, _ = git.TimerSubjects(ctx, data.DB, a.project, time.Now())
+}
+
+// commitSuggestionLines renders the offered commit subjects for the stop view.
+func (a *app) commitSuggestionLines() []string {
+	if len(a.stopSuggestions) == 0 {
+		return nil
+	}
+	lines := []string{itemStyle.Render(fmt.Sprintf("Commits since the timer started (%s to use):", keys.FormCommits.Help().Key))}
+	for i, subject := range a.stopSuggestions {
+		if i == commitSuggestionsShown {
+			lines = append(lines, helpStyle.PaddingLeft(4).Render("…and more"))
+			break
+		}
+		lines = append(lines, helpStyle.PaddingLeft(4).Render("• "+subject))
+	}
+	return append(lines, "")
+}
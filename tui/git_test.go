@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStopTimerOffersCommitSubjects(t *testing.T) {
+	a := newTestApp(t, []string{"Committed"})
+	a.stopMessageInput = textinput.New()
+	a.state = stateStoppingTimer
+	a.stopEntryFocus = focusStopBillable
+
+	a.handleKeypressStoppingTimer(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if a.stopMessageInput.Value() != "" {
+		t.Fatal("expected ctrl+g to do nothing without suggestions")
+	}
+	if strings.Contains(a.View(), "ctrl+g") {
+		t.Fatal("expected no suggestions to be shown")
+	}
+
+	a.stopSuggestions = []string{"fix login redirect", "add login tests"}
+	if view := a.View(); !strings.Contains(view, "• fix login redirect") || !strings.Contains(view, "ctrl+g to use") {
+		t.Fatalf("expected the stop view to list the commits, got:\n%s", view)
+	}
+	a.handleKeypressStoppingTimer(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if got := a.stopMessageInput.Value(); got != "fix login redirect; add login tests" || a.stopEntryFocus != focusStopMessage {
+		t.Fatalf("expected ctrl+g to fill the description, got %q (focus %v)", got, a.stopEntryFocus)
+	}
+
+	a.handleKeypressStoppingTimer(tea.KeyMsg{Type: tea.KeyEsc})
+	if a.stopSuggestions != nil {
+		t.Fatal("expected cancelling to clear the suggestions")
+	}
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+// goalOverviewLines renders today's or this week's progress and the streak for every goal.
+func goalOverviewLines(barWidth int) []string {
+	goals, err := data.DB.Goals()
+	if err != nil || len(goals) == 0 {
+		return nil
+	}
+	now := time.Now()
+	lines := make([]string, 0, len(goals))
+	for _, goal := range goals {
+		progress, err := data.DB.GoalProgress(goal, now, 1)
+		if err != nil {
+			continue
+		}
+		latest := progress.Latest()
+		filled := int(latest.Percent(goal.Target) / 100 * float64(barWidth))
+		filled = max(0, min(filled, barWidth))
+		bar := strings.Repeat("█", filled) + strings.Repeat("·", barWidth-filled)
+		if latest.Met {
+			bar = onClockStyle.Render(bar)
+		}
+		label := truncateString(goal.Scope(data.DB), 20)
+		line := fmt.Sprintf("%-20s %-8s %s %s of %s, streak %d",
+			label,
+			goal.Period.Label(),
+			bar,
+			util.HmFromD(latest.Tracked),
+			util.HmFromD(goal.Target),
+			progress.Streak,
+		)
+		lines = append(lines, detailRowStyle.Render(line))
+	}
+	return lines
+}
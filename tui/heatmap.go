@@ -0,0 +1,382 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+// heatmapWeeks is how far back the heatmap reaches: the current week plus the 52 before it.
+const heatmapWeeks = 53
+
+// heatmapShade picks a shade for tracked relative to the busiest day.
+func heatmapShade(tracked, busiest time.Duration) int {
+	if tracked <= 0 || busiest <= 0 {
+		return 0
+	}
+	levels := len(heatmapShades) - 1
+	shade := int(float64(tracked)/float64(busiest)*float64(levels) + 0.999)
+	return max(1, min(shade, levels))
+}
+
+// heatmapShadeView draws one day in the given shade.
+func heatmapShadeView(shade int) string {
+	return heatmapShades[shade].Render(heatmapGlyphs[shade])
+}
+
+// HeatmapUI opens the activity heatmap on today.
+func (a *app) HeatmapUI() {
+	a.heatmapDay = data.StartOfDay(time.Now())
+	if err := a.refreshHeatmap(); err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading heatmap: %v", err)
+		return
+	}
+	if a.state != stateHeatmap {
+		a.previousState = a.state
+	}
+	a.state = stateHeatmap
+}
+
+func (a *app) refreshHeatmap() error {
+	today := data.StartOfDay(time.Now())
+	start := data.StartOfWeek(today).AddDate(0, 0, -7*(heatmapWeeks-1))
+	totals, err := data.DB.DailyTotals(start, today.AddDate(0, 0, 1), a.heatmapFilter)
+	if err != nil {
+		return err
+	}
+	a.heatmapTotals = totals
+	return nil
+}
+
+// moveHeatmapDay shifts the selected day, staying within the heatmap's range.
+func (a *app) moveHeatmapDay(days int) {
+	today := data.StartOfDay(time.Now())
+	first := data.StartOfWeek(today).AddDate(0, 0, -7*(heatmapWeeks-1))
+	day := data.StartOfDay(a.heatmapDay.AddDate(0, 0, days))
+	if day.Before(first) || day.After(today) {
+		return
+	}
+	a.heatmapDay = day
+}
+
+func (a *app) handleKeypressHeatmap(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.Back):
+		a.state = a.previousState
+		return a, nil
+	case key.Matches(msg, keys.HeatmapWeekBack):
+		a.moveHeatmapDay(-7)
+	case key.Matches(msg, keys.HeatmapWeekForward):
+		a.moveHeatmapDay(7)
+	case key.Matches(msg, keys.HeatmapDayBack):
+		a.moveHeatmapDay(-1)
+	case key.Matches(msg, keys.HeatmapDayForward):
+		a.moveHeatmapDay(1)
+	case key.Matches(msg, keys.Select):
+		a.openHeatmapDay()
+	case key.Matches(msg, keys.HeatmapTimeline):
+		a.TimelineUI(a.heatmapDay)
+	case key.Matches(msg, keys.HeatmapFilter):
+		a.heatmapInput.SetValue("")
+		if !a.heatmapFilter.IsZero() {
+			a.heatmapInput.SetValue(filterSpec(a.heatmapFilter))
+		}
+		a.heatmapInput.Focus()
+		a.state = stateHeatmapFilter
+		return a, textinput.Blink
+	case key.Matches(msg, keys.HeatmapProject):
+		if a.project == nil {
+			a.errorMessage = "No project selected"
+			return a, nil
+		}
+		a.heatmapFilter = data.EntryFilter{ProjectID: &a.project.ID}
+		if err := a.refreshHeatmap(); err != nil {
+			a.errorMessage = fmt.Sprintf("Error loading heatmap: %v", err)
+		}
+	case key.Matches(msg, keys.HeatmapClear):
+		a.heatmapFilter = data.EntryFilter{}
+		if err := a.refreshHeatmap(); err != nil {
+			a.errorMessage = fmt.Sprintf("Error loading heatmap: %v", err)
+		}
+	}
+	return a, nil
+}
+
+// filterSpec renders a filter the way ParseEntryFilter reads it.
+func filterSpec(filter data.EntryFilter) string {
+	if filter.Tag != "" {
+		return "#" + filter.Tag
+	}
+	return filter.Label(data.DB)
+}
+
+func (a *app) handleKeypressHeatmapFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.FormCancel):
+		a.heatmapInput.Blur()
+		a.state = stateHeatmap
+		return a, nil
+	case key.Matches(msg, keys.FormSubmit):
+		filter, err := data.DB.ParseEntryFilter(a.heatmapInput.Value())
+		if err != nil {
+			a.errorMessage = err.Error()
+			return a, nil
+		}
+		previous := a.heatmapFilter
+		a.heatmapFilter = filter
+		if err := a.refreshHeatmap(); err != nil {
+			a.heatmapFilter = previous
+			a.errorMessage = fmt.Sprintf("Error loading heatmap: %v", err)
+			return a, nil
+		}
+		a.heatmapInput.Blur()
+		a.state = stateHeatmap
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.heatmapInput, cmd = a.heatmapInput.Update(msg)
+	return a, cmd
+}
+
+func (a app) heatmapView() string {
+	today := data.StartOfDay(time.Now())
+	weeks := heatmapWeeks
+	if a.width > 0 {
+		// Two cells per week plus the weekday labels; drop the oldest weeks on narrow terminals.
+		weeks = max(4, min(weeks, (a.width-8)/2))
+	}
+	firstWeek := data.StartOfWeek(today).AddDate(0, 0, -7*(weeks-1))
+
+	var busiest, total time.Duration
+	activeDays := 0
+	for day, tracked := range a.heatmapTotals {
+		if day.Before(firstWeek) {
+			continue
+		}
+		busiest = max(busiest, tracked)
+		total += tracked
+		activeDays++
+	}
+
+	// Label each month above the first week that starts in it, skipping labels that would overlap.
+	months := []rune(strings.Repeat(" ", 6+2*weeks))
+	free := 0
+	for w := 0; w < weeks; w++ {
+		week := firstWeek.AddDate(0, 0, 7*w)
+		if w > 0 && week.Month() == week.AddDate(0, 0, -7).Month() {
+			continue
+		}
+		pos := 6 + 2*w
+		label := week.Format("Jan")
+		if pos < free || pos+len(label) > len(months) {
+			continue
+		}
+		copy(months[pos:], []rune(label))
+		free = pos + len(label) + 1
+	}
+
+	lines := []string{
+		titleStyle.MarginTop(1).Render(fmt.Sprintf("Activity heatmap: %s", a.heatmapFilter.Label(data.DB))),
+		"",
+		heatmapLabelStyle.Render(string(months)),
+	}
+	weekdayLabels := []string{"Mon", "", "Wed", "", "Fri", "", ""}
+	for row := 0; row < 7; row++ {
+		var sb strings.Builder
+		sb.WriteString(heatmapLabelStyle.Render(fmt.Sprintf("  %-4s", weekdayLabels[row])))
+		for w := 0; w < weeks; w++ {
+			day := firstWeek.AddDate(0, 0, 7*w+row)
+			switch {
+			case day.After(today):
+				sb.WriteString("  ")
+			case day.Equal(a.heatmapDay):
+				sb.WriteString(heatmapCursorStyle.Render("▣") + " ")
+			default:
+				sb.WriteString(heatmapShadeView(heatmapShade(a.heatmapTotals[day], busiest)) + " ")
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+
+	legend := make([]string, 0, len(heatmapShades))
+	for shade := range heatmapShades {
+		legend = append(legend, heatmapShadeView(shade))
+	}
+	lines = append(lines, "",
+		heatmapLabelStyle.Render("      Less ")+strings.Join(legend, " ")+heatmapLabelStyle.Render(" More"),
+		"",
+		detailLine("Selected:", fmt.Sprintf("%s  %s tracked", a.heatmapDay.Format("Mon 2006-01-02"), util.HmFromD(a.heatmapTotals[a.heatmapDay]))),
+		detailLine("Total:", fmt.Sprintf("%s over %d days since %s", util.HmFromD(total), activeDays, firstWeek.Format("2006-01-02"))),
+		"",
+		helpView(
+			keyGroup("week", keys.HeatmapWeekBack, keys.HeatmapWeekForward),
+			keyGroup("day", keys.HeatmapDayBack, keys.HeatmapDayForward),
+			describe(keys.Select, "day's entries"),
+			keys.HeatmapTimeline, keys.HeatmapFilter, keys.HeatmapProject, keys.HeatmapClear, keys.Back, keys.Quit,
+		),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (a app) heatmapFilterView() string {
+	lines := []string{
+		titleStyle.MarginTop(1).Render("Filter heatmap"),
+		"",
+		inputPromptStyle.Render(a.heatmapInput.View()),
+		"",
+		itemStyle.Render("Type a project name or path, or #tag. Leave empty to show all projects."),
+		"",
+		helpView(describe(keys.FormSubmit, "apply"), keys.FormCancel, keys.ForceQuit),
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// dayEntryItem is an entry from any project in the day list.
+type dayEntryItem struct {
+	entry *data.Entry
+}
+
+func (i dayEntryItem) FilterValue() string {
+	return strings.ToLower(i.entry.GetContent())
+}
+
+type dayEntryDelegate struct{}
+
+func (d dayEntryDelegate) Height() int                             { return 1 }
+func (d dayEntryDelegate) Spacing() int                            { return 0 }
+func (d dayEntryDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d dayEntryDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	it, ok := listItem.(dayEntryItem)
+	if !ok || it.entry == nil {
+		return
+	}
+	entry := it.entry
+	span := "     "
+	if entry.StartedAt != nil {
+		span = entry.StartedAt.In(time.Local).Format("15:04")
+	}
+	if entry.EndedAt != nil {
+		span += "-" + entry.EndedAt.In(time.Local).Format("15:04")
+	}
+	desc := strings.TrimSpace(strings.ReplaceAll(entry.GetContent(), "\n", " "))
+	if desc == "" {
+		desc = "(no description)"
+	}
+	line := fmt.Sprintf("%s %6s  %-20s %s",
+		span,
+		util.HmFromD(time.Duration(entry.GetDuration())),
+		truncateString(entry.Project.Path(), 20),
+		truncateString(desc, max(20, m.Width()-44)),
+	)
+	if index == m.Index() {
+		_, _ = fmt.Fprint(w, selectedItemStyle.PaddingLeft(4).Render(line))
+		return
+	}
+	_, _ = fmt.Fprint(w, itemStyle.Render(line))
+}
+
+// openHeatmapDay lists every entry on the selected day that matches the filter.
+func (a *app) openHeatmapDay() {
+	entries, err := data.DB.EntriesInRange(a.heatmapDay, a.heatmapDay.AddDate(0, 0, 1), a.heatmapFilter)
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading entries: %v", err)
+		return
+	}
+	if len(entries) == 0 {
+		a.errorMessage = fmt.Sprintf("Nothing tracked on %s", a.heatmapDay.Format("Mon 2006-01-02"))
+		return
+	}
+	items := make([]list.Item, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, dayEntryItem{entry: entry})
+	}
+	width, height := a.width, a.height-8
+	if width <= 0 {
+		width = 80
+	}
+	if height < 5 {
+		height = 10
+	}
+	l := list.New(items, dayEntryDelegate{}, width, height)
+	l.SetShowTitle(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	activeTheme.applyToList(&l)
+	keys.applyToList(&l)
+	a.dayEntries = l
+	a.state = stateHeatmapDay
+}
+
+func (a *app) handleKeypressHeatmapDay(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.Back):
+		a.state = stateHeatmap
+		return a, nil
+	case key.Matches(msg, keys.HeatmapTimeline):
+		a.TimelineUI(a.heatmapDay)
+		return a, nil
+	case key.Matches(msg, keys.Select):
+		it, ok := a.dayEntries.SelectedItem().(dayEntryItem)
+		if !ok {
+			return a, nil
+		}
+		a.openEntryInProject(it.entry)
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.dayEntries, cmd = a.dayEntries.Update(msg)
+	return a, cmd
+}
+
+// openEntryInProject switches to the entry's project and selects it in the entry list.
+func (a *app) openEntryInProject(entry *data.Entry) {
+	a.project = entry.Project
+	a.selectProjectInList(entry.Project.ID)
+	a.refreshEntryList()
+	for i, item := range a.entries.Items() {
+		if e := entryFromListItem(item); e != nil && e.ID == entry.ID {
+			a.entries.Select(i)
+			a.selectedEntry = e
+			break
+		}
+	}
+	a.state = stateEntryList
+}
+
+func (a app) heatmapDayView() string {
+	var tracked time.Duration
+	for _, item := range a.dayEntries.Items() {
+		if it, ok := item.(dayEntryItem); ok {
+			tracked += time.Duration(it.entry.GetDuration())
+		}
+	}
+	title := fmt.Sprintf("%s: %s tracked", a.heatmapDay.Format("Monday 2006-01-02"), util.HmFromD(tracked))
+	if !a.heatmapFilter.IsZero() {
+		title += fmt.Sprintf(" (%s)", a.heatmapFilter.Label(data.DB))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.MarginTop(1).Render(title),
+		"",
+		a.dayEntries.View(),
+		helpView(keyGroup("navigate", keys.Up, keys.Down), describe(keys.Select, "open in project"), keys.HeatmapTimeline, describe(keys.Back, "back to heatmap"), keys.Quit),
+	)
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+func TestHeatmapFilterAndDayEntries(t *testing.T) {
+	a := newTestApp(t, []string{"Heat One", "Heat Two"})
+	one, err := data.DB.FindProject("Heat One")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	two, err := data.DB.FindProject("Heat Two")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	if _, err := one.CreateEntryWithDuration("writing #docs", 2*time.Hour, true, data.EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	entry, err := two.CreateEntryWithDuration("review", 30*time.Minute, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	a.HeatmapUI()
+	if a.state != stateHeatmap {
+		t.Fatalf("expected heatmap state, got %v (%s)", a.state, a.errorMessage)
+	}
+	today := data.StartOfDay(time.Now())
+	if got := a.heatmapTotals[today]; got != 150*time.Minute {
+		t.Fatalf("expected 2:30 tracked today, got %v", got)
+	}
+
+	a.heatmapFilter, err = data.DB.ParseEntryFilter("#docs")
+	if err != nil {
+		t.Fatalf("parse filter: %v", err)
+	}
+	if err := a.refreshHeatmap(); err != nil {
+		t.Fatalf("refresh heatmap: %v", err)
+	}
+	if got := a.heatmapTotals[today]; got != 2*time.Hour {
+		t.Fatalf("expected only tagged time, got %v", got)
+	}
+
+	a.moveHeatmapDay(1)
+	if !a.heatmapDay.Equal(today) {
+		t.Fatalf("expected the cursor to stop at today, got %v", a.heatmapDay)
+	}
+
+	a.heatmapFilter = data.EntryFilter{}
+	a.openHeatmapDay()
+	if a.state != stateHeatmapDay || len(a.dayEntries.Items()) != 2 {
+		t.Fatalf("expected both of today's entries, got state %v with %d items", a.state, len(a.dayEntries.Items()))
+	}
+
+	a.dayEntries.Select(1)
+	a.openEntryInProject(a.dayEntries.SelectedItem().(dayEntryItem).entry)
+	if a.state != stateEntryList || a.project.ID != two.ID || a.selectedEntry == nil || a.selectedEntry.ID != entry.ID {
+		t.Fatalf("expected to land on the entry in its project, got state %v project %v", a.state, a.project)
+	}
+
+	clearProjects(t)
+}
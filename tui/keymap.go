@@ -0,0 +1,518 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/nexneo/samay/data"
+)
+
+// keyMap holds every key binding of the interactive UI. Bindings shared by
+// several views come first, then those of each view.
+type keyMap struct {
+	Quit      key.Binding
+	ForceQuit key.Binding
+	Back      key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Select    key.Binding
+	Undo      key.Binding
+
+	// Project list and project menu
+	Fold          key.Binding
+	Unfold        key.Binding
+	ToggleFold    key.Binding
+	NewProject    key.Binding
+	Report        key.Binding
+	Overview      key.Binding
+	Heatmap       key.Binding
+	Timeline      key.Binding
+	Trash         key.Binding
+	Templates     key.Binding
+	Start         key.Binding
+	Stop          key.Binding
+	Manual        key.Binding
+	Logs          key.Binding
+	Entries       key.Binding
+	DeleteProject key.Binding
+	RenameProject key.Binding
+	Budget        key.Binding
+	Pomodoro      key.Binding
+
+	LogsToggleAll key.Binding
+
+	EntryMove     key.Binding
+	EntrySplit    key.Binding
+	EntryMark     key.Binding
+	EntryMerge    key.Binding
+	EntryOverlaps key.Binding
+	EntryCredit   key.Binding
+	EntryDelete   key.Binding
+
+	ReportPrevious  key.Binding
+	ReportNext      key.Binding
+	ReportThisMonth key.Binding
+	ReportPerson    key.Binding
+	OverviewRefresh key.Binding
+
+	HeatmapWeekBack    key.Binding
+	HeatmapWeekForward key.Binding
+	HeatmapDayBack     key.Binding
+	HeatmapDayForward  key.Binding
+	HeatmapTimeline    key.Binding
+	HeatmapFilter      key.Binding
+	HeatmapProject     key.Binding
+	HeatmapClear       key.Binding
+
+	TimelinePrevious key.Binding
+	TimelineNext     key.Binding
+	TimelineToday    key.Binding
+
+	TrashRestore key.Binding
+	TrashPurge   key.Binding
+	TrashEmpty   key.Binding
+
+	OverlapTrim  key.Binding
+	OverlapShift key.Binding
+	OverlapMerge key.Binding
+
+	ConfirmYes key.Binding
+	ConfirmNo  key.Binding
+
+	// Forms with text fields
+	FormSubmit   key.Binding
+	FormCancel   key.Binding
+	FormNext     key.Binding
+	FormPrevious key.Binding
+	FormToggle   key.Binding
+	FormCommits  key.Binding
+
+	// numbers pick projects and templates by their place in the list. They
+	// cannot be rebound, but other bindings must keep clear of them.
+	numbers key.Binding
+}
+
+// keys is the key map in use.
+var keys = defaultKeyMap()
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:      newBinding("quit", "q"),
+		ForceQuit: newBinding("quit", "ctrl+c"),
+		Back:      newBinding("back", "esc"),
+		Up:        newBinding("up", "up", "k"),
+		Down:      newBinding("down", "down", "j"),
+		Select:    newBinding("choose", "enter"),
+		Undo:      newBinding("undo", "u"),
+
+		Fold:          newBinding("fold", "left"),
+		Unfold:        newBinding("unfold", "right"),
+		ToggleFold:    newBinding("fold/unfold", " "),
+		NewProject:    newBinding("new project", "n"),
+		Report:        newBinding("monthly report (list)", "r"),
+		Overview:      newBinding("weekly overview", "o"),
+		Heatmap:       newBinding("heatmap", "c"),
+		Timeline:      newBinding("day timeline", "d"),
+		Trash:         newBinding("trash", "t"),
+		Templates:     newBinding("templates", "T"),
+		Start:         newBinding("Start timer", "s"),
+		Stop:          newBinding("End timer", "p"),
+		Manual:        newBinding("Enter manually", "e"),
+		Logs:          newBinding("Show logs", "l"),
+		Entries:       newBinding("Entries", "v"),
+		DeleteProject: newBinding("Delete project", "D"),
+		RenameProject: newBinding("Rename project", "R"),
+		Budget:        newBinding("Set budget", "B"),
+		Pomodoro:      newBinding("Pomodoro (start/stop)", "P"),
+
+		LogsToggleAll: newBinding("toggle range", "a"),
+
+		EntryMove:     newBinding("move entry", "m"),
+		EntrySplit:    newBinding("split", "x"),
+		EntryMark:     newBinding("mark", " "),
+		EntryMerge:    newBinding("merge marked", "M"),
+		EntryOverlaps: newBinding("resolve overlaps", "o"),
+		EntryCredit:   newBinding("credit to", "w"),
+		EntryDelete:   newBinding("delete", "d"),
+
+		ReportPrevious:  newBinding("previous month", "left", "h"),
+		ReportNext:      newBinding("next month", "right", "l"),
+		ReportThisMonth: newBinding("reset", "r"),
+		ReportPerson:    newBinding("person", "w"),
+		OverviewRefresh: newBinding("refresh", "r"),
+
+		HeatmapWeekBack:    newBinding("previous week", "left", "h"),
+		HeatmapWeekForward: newBinding("next week", "right", "l"),
+		HeatmapDayBack:     newBinding("previous day", "up", "k"),
+		HeatmapDayForward:  newBinding("next day", "down", "j"),
+		HeatmapTimeline:    newBinding("timeline", "t"),
+		HeatmapFilter:      newBinding("filter project/#tag", "f", "/"),
+		HeatmapProject:     newBinding("this project", "p"),
+		HeatmapClear:       newBinding("clear filter", "c"),
+
+		TimelinePrevious: newBinding("previous day", "left", "h"),
+		TimelineNext:     newBinding("next day", "right", "l"),
+		TimelineToday:    newBinding("today", "t"),
+
+		TrashRestore: newBinding("restore", "enter", "r"),
+		TrashPurge:   newBinding("delete permanently", "x"),
+		TrashEmpty:   newBinding("empty trash", "E"),
+
+		OverlapTrim:  newBinding("trim", "t"),
+		OverlapShift: newBinding("shift", "s"),
+		OverlapMerge: newBinding("merge", "m"),
+
+		ConfirmYes: newBinding("confirm", "y", "enter"),
+		ConfirmNo:  newBinding("cancel", "n"),
+
+		FormSubmit:   newBinding("submit", "enter"),
+		FormCancel:   newBinding("cancel", "esc"),
+		FormNext:     newBinding("next field", "tab", "down"),
+		FormPrevious: newBinding("previous field", "shift+tab", "up"),
+		FormToggle:   newBinding("change", " "),
+		FormCommits:  newBinding("use commits", "ctrl+g"),
+
+		numbers: newBinding("pick by number", "0", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+	}
+}
+
+// keyPresets rebind actions the way the named style of editor would.
+var keyPresets = map[string]map[string][]string{
+	"default": nil,
+	"vim": {
+		"project.fold":   {"h", "left"},
+		"project.unfold": {"l", "right"},
+		"project.logs":   {"L"},
+		"form.next":      {"tab", "down", "ctrl+j"},
+		"form.previous":  {"shift+tab", "up", "ctrl+k"},
+	},
+	"emacs": {
+		"up":                   {"up", "ctrl+p"},
+		"down":                 {"down", "ctrl+n"},
+		"project.fold":         {"left", "ctrl+b"},
+		"project.unfold":       {"right", "ctrl+f"},
+		"report.previous":      {"left", "ctrl+b"},
+		"report.next":          {"right", "ctrl+f"},
+		"heatmap.week_back":    {"left", "ctrl+b"},
+		"heatmap.week_forward": {"right", "ctrl+f"},
+		"heatmap.day_back":     {"up", "ctrl+p"},
+		"heatmap.day_forward":  {"down", "ctrl+n"},
+		"timeline.previous":    {"left", "ctrl+b"},
+		"timeline.next":        {"right", "ctrl+f"},
+		"back":                 {"esc", "ctrl+g"},
+		"form.cancel":          {"esc", "ctrl+g"},
+		"form.next":            {"tab", "down", "ctrl+n"},
+		"form.previous":        {"shift+tab", "up", "ctrl+p"},
+		"form.commits":         {"alt+g"},
+	},
+}
+
+// keyAction is a binding by the name config.json knows it by.
+type keyAction struct {
+	name    string
+	binding *key.Binding
+}
+
+func (k *keyMap) actions() []keyAction {
+	return []keyAction{
+		{"quit", &k.Quit},
+		{"force_quit", &k.ForceQuit},
+		{"back", &k.Back},
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"select", &k.Select},
+		{"undo", &k.Undo},
+		{"project.fold", &k.Fold},
+		{"project.unfold", &k.Unfold},
+		{"project.toggle_fold", &k.ToggleFold},
+		{"project.new", &k.NewProject},
+		{"project.report", &k.Report},
+		{"project.overview", &k.Overview},
+		{"project.heatmap", &k.Heatmap},
+		{"project.timeline", &k.Timeline},
+		{"project.trash", &k.Trash},
+		{"project.templates", &k.Templates},
+		{"project.start", &k.Start},
+		{"project.stop", &k.Stop},
+		{"project.manual", &k.Manual},
+		{"project.logs", &k.Logs},
+		{"project.entries", &k.Entries},
+		{"project.delete", &k.DeleteProject},
+		{"project.rename", &k.RenameProject},
+		{"project.budget", &k.Budget},
+		{"project.pomodoro", &k.Pomodoro},
+		{"logs.toggle_all", &k.LogsToggleAll},
+		{"entries.move", &k.EntryMove},
+		{"entries.split", &k.EntrySplit},
+		{"entries.mark", &k.EntryMark},
+		{"entries.merge", &k.EntryMerge},
+		{"entries.overlaps", &k.EntryOverlaps},
+		{"entries.credit", &k.EntryCredit},
+		{"entries.delete", &k.EntryDelete},
+		{"report.previous", &k.ReportPrevious},
+		{"report.next", &k.ReportNext},
+		{"report.this_month", &k.ReportThisMonth},
+		{"report.person", &k.ReportPerson},
+		{"overview.refresh", &k.OverviewRefresh},
+		{"heatmap.week_back", &k.HeatmapWeekBack},
+		{"heatmap.week_forward", &k.HeatmapWeekForward},
+		{"heatmap.day_back", &k.HeatmapDayBack},
+		{"heatmap.day_forward", &k.HeatmapDayForward},
+		{"heatmap.timeline", &k.HeatmapTimeline},
+		{"heatmap.filter", &k.HeatmapFilter},
+		{"heatmap.project", &k.HeatmapProject},
+		{"heatmap.clear", &k.HeatmapClear},
+		{"timeline.previous", &k.TimelinePrevious},
+		{"timeline.next", &k.TimelineNext},
+		{"timeline.today", &k.TimelineToday},
+		{"trash.restore", &k.TrashRestore},
+		{"trash.purge", &k.TrashPurge},
+		{"trash.empty", &k.TrashEmpty},
+		{"overlap.trim", &k.OverlapTrim},
+		{"overlap.shift", &k.OverlapShift},
+		{"overlap.merge", &k.OverlapMerge},
+		{"confirm.yes", &k.ConfirmYes},
+		{"confirm.no", &k.ConfirmNo},
+		{"form.submit", &k.FormSubmit},
+		{"form.cancel", &k.FormCancel},
+		{"form.next", &k.FormNext},
+		{"form.previous", &k.FormPrevious},
+		{"form.toggle", &k.FormToggle},
+		{"form.commits", &k.FormCommits},
+	}
+}
+
+// keyScope is a set of bindings that are live at the same time and so must
+// not share keys. Keys of a typing scope reach text fields, so they cannot be
+// plain characters.
+type keyScope struct {
+	name     string
+	bindings []*key.Binding
+	typing   bool
+}
+
+func (k *keyMap) scopes() []keyScope {
+	view := func(name string, bindings ...*key.Binding) keyScope {
+		return keyScope{name: name, bindings: append([]*key.Binding{&k.Quit, &k.ForceQuit, &k.Back}, bindings...)}
+	}
+	return []keyScope{
+		view("project", &k.Up, &k.Down, &k.Undo, &k.numbers,
+			&k.Fold, &k.Unfold, &k.ToggleFold, &k.NewProject, &k.Report, &k.Overview, &k.Heatmap, &k.Timeline, &k.Trash, &k.Templates,
+			&k.Start, &k.Stop, &k.Manual, &k.Logs, &k.Entries, &k.DeleteProject, &k.RenameProject, &k.Budget, &k.Pomodoro),
+		view("logs", &k.Up, &k.Down, &k.LogsToggleAll),
+		view("entries", &k.Up, &k.Down, &k.Undo,
+			&k.EntryMove, &k.EntrySplit, &k.EntryMark, &k.EntryMerge, &k.EntryOverlaps, &k.EntryCredit, &k.EntryDelete),
+		view("report", &k.Up, &k.Down, &k.ReportPrevious, &k.ReportNext, &k.ReportThisMonth, &k.ReportPerson),
+		view("overview", &k.Up, &k.Down, &k.OverviewRefresh, &k.ReportPerson),
+		view("heatmap", &k.Select, &k.HeatmapWeekBack, &k.HeatmapWeekForward, &k.HeatmapDayBack, &k.HeatmapDayForward,
+			&k.HeatmapTimeline, &k.HeatmapFilter, &k.HeatmapProject, &k.HeatmapClear),
+		view("heatmap day", &k.Up, &k.Down, &k.Select, &k.HeatmapTimeline),
+		view("timeline", &k.TimelinePrevious, &k.TimelineNext, &k.TimelineToday),
+		view("trash", &k.Up, &k.Down, &k.TrashRestore, &k.TrashPurge, &k.TrashEmpty),
+		view("templates", &k.Up, &k.Down, &k.Select, &k.numbers),
+		view("list", &k.Up, &k.Down, &k.Select),
+		view("overlap", &k.Up, &k.Down, &k.OverlapTrim, &k.OverlapShift, &k.OverlapMerge),
+		{name: "confirm", bindings: []*key.Binding{&k.ForceQuit, &k.Back, &k.ConfirmYes, &k.ConfirmNo}},
+		{name: "form", bindings: []*key.Binding{&k.ForceQuit, &k.FormSubmit, &k.FormCancel, &k.FormNext, &k.FormPrevious, &k.FormCommits}, typing: true},
+		// Space toggles the fields that take no text.
+		{name: "form", bindings: []*key.Binding{&k.ForceQuit, &k.FormSubmit, &k.FormCancel, &k.FormNext, &k.FormPrevious, &k.FormToggle}},
+	}
+}
+
+// newKeyMap returns the key map settings ask for: a preset, and then the
+// keys of single actions. An action given no keys is turned off.
+func newKeyMap(settings *data.KeySettings) (keyMap, error) {
+	k := defaultKeyMap()
+	if settings == nil {
+		return k, nil
+	}
+	name := strings.ToLower(strings.TrimSpace(settings.Preset))
+	if name == "" {
+		name = "default"
+	}
+	preset, ok := keyPresets[name]
+	if !ok {
+		return keyMap{}, fmt.Errorf("keys: unknown preset %q; use default, vim or emacs", settings.Preset)
+	}
+	for action, bound := range preset {
+		if err := k.rebind(action, bound); err != nil {
+			return keyMap{}, err
+		}
+	}
+	for action, bound := range settings.Bindings {
+		if err := k.rebind(action, bound); err != nil {
+			return keyMap{}, err
+		}
+	}
+	if err := k.check(); err != nil {
+		return keyMap{}, err
+	}
+	return k, nil
+}
+
+// SetKeyMap makes settings the key map of the interactive UI. When they are
+// invalid or make two actions of a view share a key, the error says so and
+// the key map is left as it was.
+func SetKeyMap(settings *data.KeySettings) error {
+	k, err := newKeyMap(settings)
+	if err != nil {
+		return err
+	}
+	keys = k
+	return nil
+}
+
+func (k *keyMap) rebind(name string, bound []string) error {
+	i := slices.IndexFunc(k.actions(), func(a keyAction) bool { return a.name == name })
+	if i < 0 {
+		return fmt.Errorf("keys: unknown action %q", name)
+	}
+	binding := k.actions()[i].binding
+	desc := binding.Help().Desc
+	normalized := make([]string, 0, len(bound))
+	for _, b := range bound {
+		switch b {
+		case "":
+			return fmt.Errorf("keys: %s has an empty key", name)
+		case "space":
+			b = " "
+		}
+		normalized = append(normalized, b)
+	}
+	if len(normalized) == 0 {
+		*binding = key.NewBinding(key.WithHelp("", desc), key.WithDisabled())
+		return nil
+	}
+	*binding = newBinding(desc, normalized...)
+	return nil
+}
+
+// check finds keys that two actions share in one view, and plain characters
+// bound where text is typed.
+func (k *keyMap) check() error {
+	names := make(map[*key.Binding]string)
+	for _, a := range k.actions() {
+		names[a.binding] = a.name
+	}
+	names[&k.numbers] = "the number keys"
+	for _, scope := range k.scopes() {
+		owners := make(map[string]*key.Binding)
+		for _, binding := range scope.bindings {
+			if !binding.Enabled() {
+				continue
+			}
+			for _, bound := range binding.Keys() {
+				if scope.typing && utf8.RuneCountInString(bound) == 1 {
+					return fmt.Errorf("keys: %s cannot be %q, which is typed into text fields", names[binding], bound)
+				}
+				if owner, ok := owners[bound]; ok && owner != binding {
+					return fmt.Errorf("keys: %q is bound to both %s and %s in the %s view", bound, names[owner], names[binding], scope.name)
+				}
+				owners[bound] = binding
+			}
+		}
+	}
+	return nil
+}
+
+func newBinding(desc string, bound ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(bound...), key.WithHelp(helpKeys(bound), desc))
+}
+
+// helpKeys shows keys the way the footers do, such as ←/h.
+func helpKeys(bound []string) string {
+	shown := make([]string, 0, len(bound))
+	for _, b := range bound {
+		// A slash would read as a separator.
+		if b == "/" && len(bound) > 1 {
+			continue
+		}
+		shown = append(shown, keySymbol(b))
+	}
+	return strings.Join(shown, "/")
+}
+
+func keySymbol(bound string) string {
+	switch bound {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case " ":
+		return "space"
+	}
+	return bound
+}
+
+// keyGroup shows bindings as one help item, such as ↑/↓ for up and down,
+// by the first key of each.
+func keyGroup(desc string, bindings ...key.Binding) key.Binding {
+	var bound, shown []string
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		bound = append(bound, b.Keys()...)
+		shown = append(shown, keySymbol(b.Keys()[0]))
+	}
+	if len(bound) == 0 {
+		return key.NewBinding(key.WithDisabled())
+	}
+	return key.NewBinding(key.WithKeys(bound...), key.WithHelp(strings.Join(shown, "/"), desc))
+}
+
+// describe returns b with its help saying desc instead.
+func describe(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// helpLine is the help text of bindings, such as "esc: back | q: quit".
+// Bindings that are turned off are left out.
+func helpLine(bindings ...key.Binding) string {
+	items := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		items = append(items, b.Help().Key+": "+b.Help().Desc)
+	}
+	return strings.Join(items, " | ")
+}
+
+// helpView is helpLine in the footer style.
+func helpView(bindings ...key.Binding) string {
+	return helpStyle.Render(helpLine(bindings...))
+}
+
+// undoNote tells how to undo what was just done, for status messages.
+func undoNote() string {
+	if !keys.Undo.Enabled() {
+		return ""
+	}
+	return fmt.Sprintf(" (%s: undo)", keys.Undo.Help().Key)
+}
+
+// applyToList has l move its cursor with the key map's keys. Quitting is left
+// to the views, which know when a key is meant for a filter instead.
+func (k keyMap) applyToList(l *list.Model) {
+	l.KeyMap.CursorUp = k.Up
+	l.KeyMap.CursorDown = k.Down
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+}
+
+// applyToViewport has v scroll with the key map's keys.
+func (k keyMap) applyToViewport(v *viewport.Model) {
+	v.KeyMap.Up = k.Up
+	v.KeyMap.Down = k.Down
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+)
+
+func TestKeyPresetsHaveNoConflicts(t *testing.T) {
+	for name := range keyPresets {
+		if _, err := newKeyMap(&data.KeySettings{Preset: name}); err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+	k, err := newKeyMap(&data.KeySettings{Preset: "Emacs"})
+	if err != nil {
+		t.Fatalf("emacs preset: %v", err)
+	}
+	if got := k.Up.Keys(); len(got) != 2 || got[1] != "ctrl+p" {
+		t.Fatalf("expected ctrl+p to move up in the emacs preset, got %v", got)
+	}
+}
+
+func TestKeySettingsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		settings data.KeySettings
+		want     string
+	}{
+		{"unknown preset", data.KeySettings{Preset: "nano"}, `unknown preset "nano"`},
+		{"unknown action", data.KeySettings{Bindings: map[string][]string{"project.launch": {"x"}}}, `unknown action "project.launch"`},
+		{"empty key", data.KeySettings{Bindings: map[string][]string{"quit": {""}}}, "quit has an empty key"},
+		{"conflict", data.KeySettings{Bindings: map[string][]string{"project.start": {"l"}}},
+			`"l" is bound to both project.start and project.logs in the project view`},
+		{"preset conflict", data.KeySettings{Preset: "vim", Bindings: map[string][]string{"project.start": {"h"}}},
+			`"h" is bound to both project.fold and project.start in the project view`},
+		{"number keys", data.KeySettings{Bindings: map[string][]string{"project.budget": {"1"}}}, "the number keys"},
+		{"typed key", data.KeySettings{Bindings: map[string][]string{"form.cancel": {"q"}}}, `form.cancel cannot be "q"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newKeyMap(&tc.settings)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestRebindingUpdatesHandlersAndHelp(t *testing.T) {
+	a := newTestApp(t, []string{"Rebound"})
+	previous := keys
+	t.Cleanup(func() { keys = previous })
+	if err := SetKeyMap(&data.KeySettings{Bindings: map[string][]string{
+		"project.start":    {"S"},
+		"project.overview": {"W"},
+		"project.pomodoro": {},
+		"undo":             {"ctrl+z"},
+	}}); err != nil {
+		t.Fatalf("set key map: %v", err)
+	}
+
+	view := a.View()
+	for _, want := range []string{"S: Start timer", "W: weekly overview"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the view:\n%s", want, view)
+		}
+	}
+	for _, gone := range []string{"s: Start timer", "o: weekly overview", "Pomodoro"} {
+		if strings.Contains(view, gone) {
+			t.Errorf("expected no %q in the view:\n%s", gone, view)
+		}
+	}
+
+	a.handleKeypressProjectMenu(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if onClock, _ := a.project.OnClock(); onClock {
+		t.Fatal("expected s to do nothing once start is bound to S")
+	}
+	a.handleKeypressProjectMenu(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if onClock, _ := a.project.OnClock(); !onClock {
+		t.Fatal("expected S to start the timer")
+	}
+	t.Cleanup(func() { _, _ = a.project.StopTimer("", true, data.EntryTypeWork) })
+
+	a.undoHint = "start timer"
+	if footer := a.projectFooterView(); !strings.Contains(footer, "ctrl+z: undo start timer") {
+		t.Fatalf("expected the undo hint to show ctrl+z, got %q", footer)
+	}
+	if note := undoNote(); note != " (ctrl+z: undo)" {
+		t.Fatalf("expected the undo note to show ctrl+z, got %q", note)
+	}
+
+	if err := SetKeyMap(&data.KeySettings{Bindings: map[string][]string{"project.start": {"p"}}}); err == nil {
+		t.Fatal("expected a conflict with the stop key")
+	}
+	if !strings.Contains(helpLine(keys.Start), "S: Start timer") {
+		t.Fatal("expected a rejected key map to leave the one in use")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nexneo/samay/data"
 	"github.com/nexneo/samay/util"
@@ -15,13 +16,13 @@ func (a app) logTitleView() string {
 	if a.project == nil {
 		return "" // No title if no project
 	}
-	title := fmt.Sprintf("Logs for Project: %s", a.project.Name)
+	title := fmt.Sprintf("Logs for Project: %s", a.project.Path())
 	return titleStyle.Render(title)
 }
 
 // Helper view for log help
 func (a app) logHelpView() string {
-	return helpStyle.Render("↑/↓/j/k: scroll | a: toggle range | esc: back | q: quit")
+	return helpView(keyGroup("scroll", keys.Up, keys.Down), keys.LogsToggleAll, keys.Back, keys.Quit)
 }
 
 // formatProjectLogs generates the log string for the viewport
@@ -182,14 +183,14 @@ func (a *app) formatProjectLogs(project *data.Project, width int) string {
 func (a *app) handleKeypressShowLogs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.Back):
 		a.state = stateProjectMenu
 		a.errorMessage = "" // Clear log-related errors
 		return a, nil
-	case "a":
+	case key.Matches(msg, keys.LogsToggleAll):
 		a.logShowAll = !a.logShowAll
 		a.ProjectLogUI()
 		return a, nil
@@ -4,55 +4,79 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
 )
 
+// focusManualField moves the cursor to one field of the manual entry form.
+func (a *app) focusManualField(field manualFocus) {
+	a.manualEntryFocus = field
+	inputs := map[manualFocus]*textinput.Model{
+		focusTime:    &a.manualTimeInput,
+		focusWhen:    &a.manualWhenInput,
+		focusMessage: &a.manualMsgInput,
+	}
+	for f, input := range inputs {
+		if f == field {
+			input.Focus()
+		} else {
+			input.Blur()
+		}
+	}
+}
+
 // when asking for manual entry details
 func (a *app) handleKeypressManualEntry(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
-	if msg.Type == tea.KeySpace && a.manualEntryFocus == focusBillable {
+	if key.Matches(msg, keys.FormToggle) && a.manualEntryFocus == focusBillable {
 		a.manualBillable = !a.manualBillable
 		return a, textinput.Blink
 	}
+	if key.Matches(msg, keys.FormToggle) && a.manualEntryFocus == focusType {
+		a.manualType = a.manualType.Next()
+		return a, textinput.Blink
+	}
 
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c":
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.FormCancel):
 		a.state = stateProjectMenu
+		a.focusManualField(focusTime)
 		a.manualTimeInput.Blur()
-		a.manualMsgInput.Blur()
-		a.manualEntryFocus = focusTime
 		a.manualBillable = true
+		a.manualType = data.EntryTypeWork
 		return a, nil
-	case "enter":
+	case key.Matches(msg, keys.FormSubmit):
 		durationStr := a.manualTimeInput.Value()
 		message := a.manualMsgInput.Value()
 
 		if durationStr == "" {
 			a.errorMessage = "Error: Duration cannot be empty."
-			a.manualEntryFocus = focusTime
-			a.manualTimeInput.Focus()
-			a.manualMsgInput.Blur()
+			a.focusManualField(focusTime)
+			return a, textinput.Blink
+		}
+
+		if _, err := util.ParseInterval(durationStr, time.Now()); err != nil {
+			a.errorMessage = fmt.Sprintf("Error: %v", err)
+			a.focusManualField(focusTime)
 			return a, textinput.Blink
 		}
 
-		duration, err := time.ParseDuration(durationStr)
+		interval, err := util.ParseIntervalAt(a.manualWhenInput.Value(), durationStr, time.Now())
 		if err != nil {
-			a.errorMessage = fmt.Sprintf("Error parsing duration: %v", err)
-			a.manualEntryFocus = focusTime
-			a.manualTimeInput.Focus()
-			a.manualMsgInput.Blur()
+			a.errorMessage = fmt.Sprintf("Error: %v", err)
+			a.focusManualField(focusWhen)
 			return a, textinput.Blink
 		}
 
 		if message == "" {
 			a.errorMessage = "Error: Message cannot be empty."
-			a.manualEntryFocus = focusMessage
-			a.manualTimeInput.Blur()
-			a.manualMsgInput.Focus()
+			a.focusManualField(focusMessage)
 			return a, textinput.Blink
 		}
 
@@ -62,43 +86,30 @@ func (a *app) handleKeypressManualEntry(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 
-		if _, err := a.project.CreateEntryWithDuration(message, duration, a.manualBillable); err != nil {
+		entry, err := a.project.CreateEntryAt(message, interval.Start, interval.Length, a.manualBillable, a.manualType)
+		if err != nil {
 			a.errorMessage = fmt.Sprintf("Error saving entry: %v", err)
 			return a, nil
 		}
+		a.errorMessage = overlapWarning(entry)
+		if a.errorMessage == "" && !data.StartOfDay(interval.Start).Equal(data.StartOfDay(time.Now())) {
+			a.errorMessage = "Entry logged on " + interval.Start.In(time.Local).Format("Mon Jan 2")
+		}
 
 		a.refreshEntryList()
 		a.state = stateProjectMenu
+		a.focusManualField(focusTime)
 		a.manualTimeInput.Blur()
-		a.manualMsgInput.Blur()
-		a.manualEntryFocus = focusTime
 		a.manualBillable = true
+		a.manualType = data.EntryTypeWork
 		return a, nil
-	case "tab", "shift+tab", "up", "down":
-		const manualFocusCount = 3
-		var delta int
-		switch keypress {
-		case "tab", "down":
-			delta = 1
-		case "shift+tab", "up":
+	case key.Matches(msg, keys.FormNext, keys.FormPrevious):
+		delta := 1
+		if key.Matches(msg, keys.FormPrevious) {
 			delta = -1
-		default:
-			return a, nil
 		}
 
-		a.manualEntryFocus = manualFocus((int(a.manualEntryFocus) + delta + manualFocusCount) % manualFocusCount)
-
-		switch a.manualEntryFocus {
-		case focusTime:
-			a.manualTimeInput.Focus()
-			a.manualMsgInput.Blur()
-		case focusMessage:
-			a.manualTimeInput.Blur()
-			a.manualMsgInput.Focus()
-		case focusBillable:
-			a.manualTimeInput.Blur()
-			a.manualMsgInput.Blur()
-		}
+		a.focusManualField((a.manualEntryFocus + manualFocus(delta) + manualFocusCount) % manualFocusCount)
 		// Always blink cursor on focus change
 		return a, textinput.Blink
 
@@ -107,6 +118,9 @@ func (a *app) handleKeypressManualEntry(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case focusTime:
 			a.manualTimeInput, cmd = a.manualTimeInput.Update(msg)
 			return a, cmd
+		case focusWhen:
+			a.manualWhenInput, cmd = a.manualWhenInput.Update(msg)
+			return a, cmd
 		case focusMessage:
 			a.manualMsgInput, cmd = a.manualMsgInput.Update(msg)
 			return a, cmd
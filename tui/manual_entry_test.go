@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+)
+
+func TestManualEntryAcceptsRangesAndDays(t *testing.T) {
+	a := newTestApp(t, []string{"Manual"})
+	project, err := data.DB.FindProject("Manual")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	a.project = project
+	a.manualTimeInput = textinput.New()
+	a.manualWhenInput = textinput.New()
+	a.manualMsgInput = textinput.New()
+	a.state = stateManualEntry
+
+	a.manualTimeInput.SetValue("1.5")
+	a.manualMsgInput.SetValue("standup")
+	a.handleKeypressManualEntry(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.state != stateManualEntry || !strings.Contains(a.errorMessage, "no unit") {
+		t.Fatalf("expected an ambiguous duration to be rejected, got state %v (%s)", a.state, a.errorMessage)
+	}
+
+	a.manualTimeInput.SetValue("yesterday 3pm to 5pm")
+	a.handleKeypressManualEntry(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.state != stateProjectMenu {
+		t.Fatalf("expected the entry to save, got state %v (%s)", a.state, a.errorMessage)
+	}
+	entries := project.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	yesterday := data.StartOfDay(time.Now()).AddDate(0, 0, -1)
+	if !entries[0].StartedAt.Equal(yesterday.Add(15*time.Hour)) || entries[0].DurationMs != (2*time.Hour).Milliseconds() {
+		t.Fatalf("expected 15:00–17:00 yesterday, got %v for %dms", entries[0].StartedAt, entries[0].DurationMs)
+	}
+
+	a.state = stateManualEntry
+	a.manualTimeInput.SetValue("2h")
+	a.manualWhenInput.SetValue("someday")
+	a.handleKeypressManualEntry(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.state != stateManualEntry || a.manualEntryFocus != focusWhen {
+		t.Fatalf("expected an unreadable day to focus the when field, got state %v (%s)", a.state, a.errorMessage)
+	}
+	a.manualWhenInput.SetValue("2024-02-01 8am")
+	a.handleKeypressManualEntry(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.state != stateProjectMenu || !strings.Contains(a.errorMessage, "Thu Feb 1") {
+		t.Fatalf("expected the entry to be logged on Feb 1, got state %v (%s)", a.state, a.errorMessage)
+	}
+	if entries := project.Entries(); len(entries) != 2 || entries[len(entries)-1].StartedAt.In(time.Local).Format("2006-01-02 15:04") != "2024-02-01 08:00" {
+		t.Fatalf("expected a second entry at 08:00 on 2024-02-01, got %d entries", len(entries))
+	}
+}
+
+func TestManualEntryAndStopTimerRecordType(t *testing.T) {
+	a := newTestApp(t, []string{"Typed"})
+	project, err := data.DB.FindProject("Typed")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	a.project = project
+	a.manualTimeInput = textinput.New()
+	a.manualWhenInput = textinput.New()
+	a.manualMsgInput = textinput.New()
+	a.state = stateManualEntry
+
+	a.manualTimeInput.SetValue("30m")
+	a.manualMsgInput.SetValue("laundry")
+	a.focusManualField(focusType)
+	a.handleKeypressManualEntry(tea.KeyMsg{Type: tea.KeySpace})
+	if a.manualType != data.EntryTypeChore {
+		t.Fatalf("expected space to switch the type to chore, got %q", a.manualType)
+	}
+	a.handleKeypressManualEntry(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.state != stateProjectMenu || a.manualType != data.EntryTypeWork {
+		t.Fatalf("expected the entry to save and the form to reset, got state %v (%s)", a.state, a.errorMessage)
+	}
+	entries := project.Entries()
+	if len(entries) != 1 || entries[0].Type != data.EntryTypeChore {
+		t.Fatalf("expected one chore entry, got %+v", entries)
+	}
+
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	a.stopMessageInput = textinput.New()
+	a.state = stateStoppingTimer
+	a.stopType = data.EntryTypeWork
+	a.stopEntryFocus = focusStopType
+	a.handleKeypressStoppingTimer(tea.KeyMsg{Type: tea.KeySpace})
+	a.handleKeypressStoppingTimer(tea.KeyMsg{Type: tea.KeySpace})
+	if a.stopType != data.EntryTypeFun {
+		t.Fatalf("expected two presses to reach fun, got %q", a.stopType)
+	}
+	a.stopEntryFocus = focusStopMessage
+	a.stopMessageInput.SetValue("board games")
+	a.handleKeypressStoppingTimer(tea.KeyMsg{Type: tea.KeyEnter})
+	var found bool
+	for _, entry := range project.Entries() {
+		found = found || (entry.Content == "board games" && entry.Type == data.EntryTypeFun)
+	}
+	if !found {
+		t.Fatalf("expected the stopped timer to log a fun entry")
+	}
+}
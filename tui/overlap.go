@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+// OverlapUI lists the entries that overlap entry so the clash can be trimmed, shifted or merged.
+func (a *app) OverlapUI(entry *data.Entry) {
+	if entry == nil {
+		a.errorMessage = "No entry chosen."
+		return
+	}
+	conflicts, err := entry.Overlapping()
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error finding overlaps: %v", err)
+		return
+	}
+	if len(conflicts) == 0 {
+		a.errorMessage = "This entry does not overlap any other."
+		return
+	}
+	a.overlapEntry = entry
+	a.overlapConflicts = conflicts
+	a.overlapIndex = 0
+	a.state = stateResolveOverlap
+}
+
+// overlapWarning tells the user a saved entry overlaps others, or returns "" when it does not.
+func overlapWarning(entry *data.Entry) string {
+	if entry == nil || len(entry.Conflicts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Saved, but it overlaps %s (o in the entry list resolves it)", data.DescribeConflicts(entry.Conflicts))
+}
+
+func (a *app) handleKeypressResolveOverlap(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var how data.OverlapResolution
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.Back):
+		a.openEntryInProject(a.overlapEntry)
+		return a, nil
+	case key.Matches(msg, keys.Up):
+		a.overlapIndex = max(0, a.overlapIndex-1)
+		return a, nil
+	case key.Matches(msg, keys.Down):
+		a.overlapIndex = min(len(a.overlapConflicts)-1, a.overlapIndex+1)
+		return a, nil
+	case key.Matches(msg, keys.OverlapTrim):
+		how = data.OverlapTrim
+	case key.Matches(msg, keys.OverlapShift):
+		how = data.OverlapShift
+	case key.Matches(msg, keys.OverlapMerge):
+		how = data.OverlapMerge
+	default:
+		return a, nil
+	}
+
+	entry := a.overlapEntry
+	if err := entry.ResolveOverlap(a.overlapConflicts[a.overlapIndex], how); err != nil {
+		a.errorMessage = fmt.Sprintf("Error resolving overlap: %v", err)
+		return a, nil
+	}
+	a.refreshUndoHint()
+	conflicts, err := entry.Overlapping()
+	if err != nil || len(conflicts) == 0 {
+		a.openEntryInProject(entry)
+		a.errorMessage = fmt.Sprintf("Overlap resolved by %s%s", overlapVerb(how), undoNote())
+		return a, nil
+	}
+	a.overlapConflicts = conflicts
+	a.overlapIndex = 0
+	a.errorMessage = fmt.Sprintf("Resolved by %s; %d overlap(s) left", overlapVerb(how), len(conflicts))
+	return a, nil
+}
+
+func overlapVerb(how data.OverlapResolution) string {
+	switch how {
+	case data.OverlapTrim:
+		return "trimming"
+	case data.OverlapShift:
+		return "shifting"
+	default:
+		return "merging"
+	}
+}
+
+// overlapEntryLine renders an entry's time, project and description on one line.
+func overlapEntryLine(entry *data.Entry) string {
+	line := entry.Project.Path()
+	if span, ok := data.EntrySpan(entry); ok {
+		start, end := span.Start.In(time.Local), span.End.In(time.Local)
+		line = fmt.Sprintf("%s %s–%s  %s  %s", start.Format("Jan 02"), start.Format("15:04"), end.Format("15:04"),
+			util.HmFromD(span.Duration()), line)
+	}
+	if content := oneLineContent(entry.GetContent()); content != "" {
+		line += ": " + truncateString(content, 40)
+	}
+	return line
+}
+
+func (a app) resolveOverlapView() string {
+	entry := a.overlapEntry
+	if entry == nil {
+		return errorStyle.Render("No entry chosen")
+	}
+	lines := []string{
+		titleStyle.MarginTop(1).Render("Resolve overlap"),
+		detailLine("Entry:", overlapEntryLine(entry)),
+		"",
+		titleStyle.Render("Overlaps with"),
+	}
+	span, _ := data.EntrySpan(entry)
+	for i, other := range a.overlapConflicts {
+		line := overlapEntryLine(other)
+		if otherSpan, ok := data.EntrySpan(other); ok {
+			shared := minTime(span.End, otherSpan.End).Sub(maxTime(span.Start, otherSpan.Start))
+			line += fmt.Sprintf("  (%s shared)", util.HmFromD(shared))
+		}
+		if i == a.overlapIndex {
+			lines = append(lines, selectedItemStyle.PaddingLeft(4).Render(line))
+			continue
+		}
+		lines = append(lines, itemStyle.Render(line))
+	}
+	lines = append(lines,
+		"",
+		itemStyle.Render("t: trim this entry so it stops where the other starts, or starts where it ends"),
+		itemStyle.Render("s: shift this entry to start when the other ends"),
+		itemStyle.Render("m: merge the other entry into this one"),
+		"",
+		helpView(keyGroup("choose entry", keys.Up, keys.Down), keys.OverlapTrim, keys.OverlapShift, keys.OverlapMerge, keys.Back, keys.Quit),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
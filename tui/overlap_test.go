@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+)
+
+func TestResolveOverlapFromEntryList(t *testing.T) {
+	a := newTestApp(t, []string{"Overlap"})
+	project, err := data.DB.FindProject("Overlap")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	if _, err := project.CreateEntryWithDuration("workshop", 2*time.Hour, true, data.EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	call, err := project.CreateEntryWithDuration("call", 30*time.Minute, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if warning := overlapWarning(call); !strings.Contains(warning, "Overlap") {
+		t.Fatalf("expected a warning naming the project, got %q", warning)
+	}
+
+	a.openEntryInProject(call)
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	a.handleKeypressEntryList(key("o"))
+	if a.state != stateResolveOverlap || len(a.overlapConflicts) != 1 {
+		t.Fatalf("expected the overlap screen with one conflict, got state %v (%s)", a.state, a.errorMessage)
+	}
+	if view := a.resolveOverlapView(); !strings.Contains(view, "workshop") || !strings.Contains(view, "0:30 shared") {
+		t.Fatalf("unexpected overlap view:\n%s", view)
+	}
+
+	// The call sits at the end of the workshop, so trimming would leave nothing.
+	a.handleKeypressResolveOverlap(key("t"))
+	if a.state != stateResolveOverlap || !strings.Contains(a.errorMessage, "trimming would leave nothing") {
+		t.Fatalf("expected trimming to fail, got state %v (%s)", a.state, a.errorMessage)
+	}
+
+	a.handleKeypressResolveOverlap(key("s"))
+	if a.state != stateEntryList || a.selectedEntry == nil || a.selectedEntry.ID != call.ID {
+		t.Fatalf("expected to return to the shifted entry, got state %v (%s)", a.state, a.errorMessage)
+	}
+	if conflicts, err := a.selectedEntry.Overlapping(); err != nil || len(conflicts) != 0 {
+		t.Fatalf("expected no overlaps after shifting, got %v, %v", conflicts, err)
+	}
+
+	a.OverlapUI(a.selectedEntry)
+	if a.state != stateEntryList || a.errorMessage == "" {
+		t.Fatalf("expected a message that nothing overlaps, got state %v", a.state)
+	}
+
+	clearProjects(t)
+}
@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+type personItem struct {
+	person *data.Person // nil clears the entry's creator
+}
+
+func (i personItem) FilterValue() string {
+	if i.person == nil {
+		return ""
+	}
+	return strings.ToLower(i.person.String())
+}
+
+type personItemDelegate struct{}
+
+func (d personItemDelegate) Height() int                             { return 1 }
+func (d personItemDelegate) Spacing() int                            { return 0 }
+func (d personItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d personItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	it, ok := listItem.(personItem)
+	if !ok {
+		return
+	}
+	line := "(nobody)"
+	if it.person != nil {
+		line = it.person.String()
+	}
+	if index == m.Index() {
+		_, _ = fmt.Fprint(w, selectedItemStyle.PaddingLeft(4).Render(line))
+		return
+	}
+	_, _ = fmt.Fprint(w, itemStyle.Render(line))
+}
+
+// personName returns the name of the person with the given id, or "" for nobody.
+func personName(id *int64) string {
+	if id == nil || data.DB == nil {
+		return ""
+	}
+	person, err := data.DB.Person(*id)
+	if err != nil {
+		return fmt.Sprintf("person %d", *id)
+	}
+	return person.Name
+}
+
+// PickPersonUI lists everyone in the database so the entry can be credited to one of them.
+func (a *app) PickPersonUI(entry *data.Entry) {
+	if entry == nil {
+		return
+	}
+	people, err := data.DB.People()
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading people: %v", err)
+		return
+	}
+	if len(people) == 0 {
+		a.errorMessage = "No people yet. Set who you are with: samay whoami -email you@example.com"
+		return
+	}
+	items := []list.Item{personItem{}}
+	selected := 0
+	for i, person := range people {
+		items = append(items, personItem{person: person})
+		if entry.CreatorID != nil && *entry.CreatorID == person.ID {
+			selected = i + 1
+		}
+	}
+	width := a.width
+	if width == 0 {
+		width = 80
+	}
+	l := list.New(items, personItemDelegate{}, width, max(a.height-6, 10))
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(true)
+	activeTheme.applyToList(&l)
+	keys.applyToList(&l)
+	l.Select(selected)
+	a.people = l
+	a.selectedEntry = entry
+	a.state = statePickPerson
+}
+
+func (a *app) handleKeypressPickPerson(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.people.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		a.people, cmd = a.people.Update(msg)
+		return a, cmd
+	}
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.Back):
+		a.state = stateEntryList
+		return a, nil
+	case key.Matches(msg, keys.Select):
+		it, ok := a.people.SelectedItem().(personItem)
+		if !ok || a.selectedEntry == nil {
+			return a, nil
+		}
+		if err := a.selectedEntry.SetCreator(context.Background(), it.person); err != nil {
+			a.errorMessage = fmt.Sprintf("Error updating entry: %v", err)
+			return a, nil
+		}
+		a.refreshEntryList()
+		a.refreshUndoHint()
+		a.errorMessage = "Entry credited to nobody" + undoNote()
+		if it.person != nil {
+			a.errorMessage = fmt.Sprintf("Entry credited to %s%s", it.person.Name, undoNote())
+		}
+		a.state = stateEntryList
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.people, cmd = a.people.Update(msg)
+	return a, cmd
+}
+
+func (a app) pickPersonView() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.MarginTop(1).Render("Credit entry to whom?"),
+		a.people.View(),
+		helpView(keys.Select, describe(a.people.KeyMap.Filter, "filter"), keys.Back, keys.Quit),
+	)
+}
+
+// cycleReportPerson narrows the reports to the next person, wrapping back to everyone.
+func (a *app) cycleReportPerson() {
+	people, err := data.DB.People()
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading people: %v", err)
+		return
+	}
+	if len(people) == 0 {
+		a.errorMessage = "No people yet. Set who you are with: samay whoami -email you@example.com"
+		return
+	}
+	next := people[0]
+	if a.reportPerson != nil {
+		next = nil
+		for i, person := range people {
+			if person.ID == a.reportPerson.ID && i+1 < len(people) {
+				next = people[i+1]
+			}
+		}
+	}
+	a.reportPerson = next
+}
+
+// reportIncludes reports whether entry counts towards the reports under the person filter.
+func (a app) reportIncludes(entry *data.Entry) bool {
+	if a.reportPerson == nil {
+		return true
+	}
+	return entry.CreatorID != nil && *entry.CreatorID == a.reportPerson.ID
+}
+
+// reportPersonLabel names whose time the reports show.
+func (a app) reportPersonLabel() string {
+	if a.reportPerson == nil {
+		return "everyone"
+	}
+	return a.reportPerson.Name
+}
+
+// personTotalLines renders the time each person tracked, with entries nobody
+// is credited for under key zero, or nothing when there is only one row to show.
+func personTotalLines(totals map[int64]time.Duration) []string {
+	people, err := data.DB.People()
+	if err != nil || len(people) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, person := range people {
+		if totals[person.ID] > 0 {
+			lines = append(lines, detailRowStyle.Render(fmt.Sprintf("%-24s %8s", person.Name, util.HmFromD(totals[person.ID]))))
+		}
+	}
+	if totals[0] > 0 {
+		lines = append(lines, detailRowStyle.Render(fmt.Sprintf("%-24s %8s", "(unattributed)", util.HmFromD(totals[0]))))
+	}
+	if len(lines) < 2 {
+		return nil
+	}
+	return lines
+}
+
+// creatorKey returns the id personTotalLines groups entry under.
+func creatorKey(entry *data.Entry) int64 {
+	if entry.CreatorID == nil {
+		return 0
+	}
+	return *entry.CreatorID
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+)
+
+func TestCreditEntryAndFilterReportsByPerson(t *testing.T) {
+	a := newTestApp(t, []string{"Team"})
+	project, err := data.DB.FindProject("Team")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("pairing", time.Hour, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	bea, err := data.DB.SetIdentity("Bea", "bea@example.com")
+	if err != nil {
+		t.Fatalf("set identity: %v", err)
+	}
+
+	a.project = project
+	a.entries = buildEntryList(project, 80, 20)
+	a.state = stateEntryList
+	a.handleKeypressEntryList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if a.state != statePickPerson {
+		t.Fatalf("expected the person picker, got state %v (%s)", a.state, a.errorMessage)
+	}
+	for i, item := range a.people.Items() {
+		if it := item.(personItem); it.person != nil && it.person.ID == bea.ID {
+			a.people.Select(i)
+		}
+	}
+	a.handleKeypressPickPerson(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.state != stateEntryList {
+		t.Fatalf("expected to return to the entry list, got state %v (%s)", a.state, a.errorMessage)
+	}
+	reloaded, err := data.DB.Entry(entry.ID)
+	if err != nil || reloaded.CreatorID == nil || *reloaded.CreatorID != bea.ID {
+		t.Fatalf("expected the entry to be credited to Bea, got %+v, %v", reloaded, err)
+	}
+
+	for a.reportPerson == nil || a.reportPerson.ID != bea.ID {
+		a.cycleReportPerson()
+	}
+	if !a.reportIncludes(reloaded) || a.reportIncludes(&data.Entry{}) {
+		t.Fatalf("expected the report filter to keep only Bea's entries")
+	}
+	for a.reportPerson != nil {
+		a.cycleReportPerson()
+	}
+	if a.reportPersonLabel() != "everyone" || !a.reportIncludes(&data.Entry{}) {
+		t.Fatalf("expected cycling to wrap back to everyone, got %q", a.reportPersonLabel())
+	}
+}
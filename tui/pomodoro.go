@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+)
+
+type pomodoroPhase int
+
+const (
+	pomodoroWork pomodoroPhase = iota
+	pomodoroShortBreak
+	pomodoroLongBreak
+)
+
+// pomodoroSession is a run of pomodoros on one project. The project's timer
+// runs while working and is stopped for the breaks; each finished pomodoro
+// becomes an entry tagged #pomodoro.
+type pomodoroSession struct {
+	id        int // tells the ticks of this session from those of earlier ones
+	project   *data.Project
+	rhythm    data.Pomodoro
+	cycle     int // the pomodoro being worked, or the one just finished during a break
+	phase     pomodoroPhase
+	started   time.Time // when the current phase began
+	completed int
+}
+
+type pomodoroTickMsg struct {
+	id int
+	at time.Time
+}
+
+// pomodoroSessions counts the sessions started, to number them.
+var pomodoroSessions int
+
+func (s *pomodoroSession) length() time.Duration {
+	switch s.phase {
+	case pomodoroShortBreak:
+		return s.rhythm.ShortBreak
+	case pomodoroLongBreak:
+		return s.rhythm.LongBreak
+	}
+	return s.rhythm.Work
+}
+
+func (s *pomodoroSession) remaining(now time.Time) time.Duration {
+	return max(s.started.Add(s.length()).Sub(now), 0)
+}
+
+func pomodoroTick(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return pomodoroTickMsg{id: id, at: t} })
+}
+
+// togglePomodoro starts Pomodoro mode on the selected project, or ends the
+// session in progress. A timer already running is stopped and logged first,
+// so the first pomodoro starts now and none of its time is lost. Ending a
+// session leaves a running timer running.
+func (a *app) togglePomodoro() tea.Cmd {
+	if a.pomodoro != nil {
+		a.pomodoro = nil
+		a.errorMessage = "Pomodoro mode off."
+		return nil
+	}
+	if a.project == nil {
+		return nil
+	}
+	if onClock, _ := a.project.OnClock(); onClock {
+		billable, entryType := a.project.LastEntrySettings()
+		if _, err := a.project.StopTimer("", billable, entryType); err != nil {
+			a.errorMessage = fmt.Sprintf("Error stopping timer: %v", err)
+			return nil
+		}
+		a.refreshEntryList()
+		a.refreshUndoHint()
+	}
+	if err := a.project.StartTimer(); err != nil {
+		a.errorMessage = fmt.Sprintf("Error starting timer: %v", err)
+		return nil
+	}
+	a.reminderBanner = ""
+	_, timer := a.project.OnClock()
+	if timer == nil {
+		return nil
+	}
+	pomodoroSessions++
+	a.pomodoro = &pomodoroSession{
+		id:      pomodoroSessions,
+		project: a.project,
+		rhythm:  data.DB.Pomodoro(),
+		cycle:   1,
+		phase:   pomodoroWork,
+		started: timer.StartedAt,
+	}
+	return pomodoroTick(a.pomodoro.id)
+}
+
+// handlePomodoroTick moves the session on once the current phase is over:
+// a finished pomodoro is logged and a break begins, and after a short break
+// the next pomodoro starts. The session ends after the long break, or when
+// its timer is stopped some other way.
+func (a app) handlePomodoroTick(msg pomodoroTickMsg) (tea.Model, tea.Cmd) {
+	if a.pomodoro == nil || a.pomodoro.id != msg.id {
+		return a, nil
+	}
+	session := *a.pomodoro
+	a.pomodoro = &session
+	end := session.started.Add(session.length())
+
+	switch session.phase {
+	case pomodoroWork:
+		onClock, timer := session.project.OnClock()
+		if !onClock || !timer.StartedAt.Equal(session.started) {
+			a.pomodoro = nil
+			return a, nil
+		}
+		if msg.at.Before(end) {
+			break
+		}
+		content := fmt.Sprintf("Pomodoro %d/%d #%s", session.cycle, session.rhythm.Cycles, data.PomodoroTag)
+		billable, entryType := session.project.LastEntrySettings()
+		if _, err := session.project.StopTimerAt(content, billable, entryType, end); err != nil {
+			a.errorMessage = fmt.Sprintf("Error logging pomodoro: %v", err)
+			a.pomodoro = nil
+			return a, nil
+		}
+		session.completed++
+		session.phase = pomodoroShortBreak
+		if session.cycle >= session.rhythm.Cycles {
+			session.phase = pomodoroLongBreak
+		}
+		session.started = end
+		a.refreshEntryList()
+		a.refreshUndoHint()
+		return a, tea.Batch(ringBell, pomodoroTick(session.id))
+
+	case pomodoroShortBreak, pomodoroLongBreak:
+		if msg.at.Before(end) {
+			break
+		}
+		if session.phase == pomodoroLongBreak {
+			a.errorMessage = fmt.Sprintf("Pomodoro session done: %d completed.", session.completed)
+			a.pomodoro = nil
+			return a, ringBell
+		}
+		if err := session.project.StartTimer(); err != nil {
+			a.errorMessage = fmt.Sprintf("Error starting timer: %v", err)
+			a.pomodoro = nil
+			return a, ringBell
+		}
+		_, timer := session.project.OnClock()
+		if timer == nil {
+			a.pomodoro = nil
+			return a, ringBell
+		}
+		session.cycle++
+		session.phase = pomodoroWork
+		session.started = timer.StartedAt
+		return a, tea.Batch(ringBell, pomodoroTick(session.id))
+	}
+	return a, pomodoroTick(session.id)
+}
+
+// bellOutput is where ringBell writes; tests swap it for a buffer.
+var bellOutput io.Writer = os.Stdout
+
+// ringBell is a command rather than a write inside Update, so the bell never
+// reaches the terminal behind the renderer's back.
+func ringBell() tea.Msg {
+	_, _ = io.WriteString(bellOutput, "\a")
+	return nil
+}
+
+// pomodoroView is the countdown of the session in progress.
+func (a app) pomodoroView(now time.Time) string {
+	s := a.pomodoro
+	left := s.remaining(now)
+	countdown := fmt.Sprintf("%d:%02d left", int(left.Minutes()), int(left.Seconds())%60)
+	var text string
+	switch s.phase {
+	case pomodoroWork:
+		text = fmt.Sprintf("🍅 Pomodoro %d/%d on %s · %s", s.cycle, s.rhythm.Cycles, s.project.Path(), countdown)
+	case pomodoroShortBreak:
+		text = fmt.Sprintf("☕ Short break · %s, then pomodoro %d/%d", countdown, s.cycle+1, s.rhythm.Cycles)
+	case pomodoroLongBreak:
+		text = fmt.Sprintf("☕ Long break · %s, then the session is done", countdown)
+	}
+	return pomodoroStyle.Render(text)
+}
+
+// hasTag reports whether entry carries tag, in any case.
+func hasTag(entry *data.Entry, tag string) bool {
+	return slices.ContainsFunc(entry.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
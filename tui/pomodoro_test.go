@@ -0,0 +1,185 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/data/sqlc"
+)
+
+func TestPomodoroSession(t *testing.T) {
+	a := newTestApp(t, []string{"Deep Work"})
+	project := a.project
+	t.Cleanup(func() {
+		if onClock, _ := project.OnClock(); onClock {
+			_, _ = project.StopTimer("", true, data.EntryTypeWork)
+		}
+	})
+
+	if cmd := a.togglePomodoro(); cmd == nil {
+		t.Fatal("expected the countdown to start")
+	}
+	if onClock, _ := project.OnClock(); !onClock {
+		t.Fatal("expected Pomodoro mode to start the timer")
+	}
+	if !strings.Contains(a.View(), "🍅 Pomodoro 1/4 on Deep Work") {
+		t.Fatalf("expected the countdown in the view, got %q", a.View())
+	}
+	// Real lengths would keep the test waiting; a second of work will do.
+	a.pomodoro.rhythm = data.Pomodoro{Work: time.Second, ShortBreak: time.Minute, LongBreak: time.Minute, Cycles: 2}
+	tick := func(a app, at time.Time) app {
+		t.Helper()
+		model, _ := a.Update(pomodoroTickMsg{id: a.pomodoro.id, at: at})
+		return model.(app)
+	}
+
+	got := tick(*a, time.Now())
+	if got.pomodoro.phase != pomodoroWork || len(project.Entries()) != 0 {
+		t.Fatal("expected the pomodoro to go on before its end")
+	}
+	workEnd := got.pomodoro.started.Add(time.Second)
+	time.Sleep(time.Until(workEnd) + 10*time.Millisecond)
+	got = tick(got, workEnd)
+	if got.pomodoro.phase != pomodoroShortBreak || got.pomodoro.completed != 1 {
+		t.Fatalf("expected a short break after the first pomodoro, got %+v", got.pomodoro)
+	}
+	if onClock, _ := project.OnClock(); onClock {
+		t.Fatal("expected the timer to stop for the break")
+	}
+	entries := project.Entries()
+	if len(entries) != 1 || time.Duration(entries[0].GetDuration()) != time.Second || !hasTag(entries[0], data.PomodoroTag) {
+		t.Fatalf("expected a one second #pomodoro entry, got %+v", entries)
+	}
+
+	got = tick(got, workEnd.Add(time.Minute))
+	if got.pomodoro.phase != pomodoroWork || got.pomodoro.cycle != 2 {
+		t.Fatalf("expected the second pomodoro after the break, got %+v", got.pomodoro)
+	}
+	if onClock, _ := project.OnClock(); !onClock {
+		t.Fatal("expected the timer to run again")
+	}
+
+	got = tick(got, got.pomodoro.started.Add(time.Second))
+	if got.pomodoro.phase != pomodoroLongBreak || got.pomodoro.completed != 2 {
+		t.Fatalf("expected the long break after the last pomodoro, got %+v", got.pomodoro)
+	}
+	var bell strings.Builder
+	bellOutput = &bell
+	t.Cleanup(func() { bellOutput = os.Stdout })
+	model, cmd := got.Update(pomodoroTickMsg{id: got.pomodoro.id, at: time.Now().Add(time.Hour)})
+	got = model.(app)
+	if cmd == nil {
+		t.Fatal("expected the end of the session to ring the bell")
+	}
+	cmd()
+	if bell.String() != "\a" {
+		t.Fatalf("expected the bell from the command, got %q", bell.String())
+	}
+	if got.pomodoro != nil || got.errorMessage != "Pomodoro session done: 2 completed." {
+		t.Fatalf("expected the session to end after the long break, got %q", got.errorMessage)
+	}
+	got.dashboardViewport.Width, got.dashboardViewport.Height = 100, 60
+	got.WebReplacementUI()
+	if !strings.Contains(got.dashboardViewport.View(), "2 completed, 2 today") {
+		t.Fatal("expected the overview to count the pomodoros")
+	}
+}
+
+func TestPomodoroEndsWhenTimerStops(t *testing.T) {
+	a := newTestApp(t, []string{"Interrupted"})
+	a.togglePomodoro()
+	if _, err := a.project.StopTimer("", true, data.EntryTypeWork); err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	model, cmd := a.Update(pomodoroTickMsg{id: a.pomodoro.id, at: time.Now()})
+	*a = model.(app)
+	if a.pomodoro != nil || cmd != nil {
+		t.Fatal("expected the session to end with its timer")
+	}
+
+	a.togglePomodoro()
+	a.togglePomodoro()
+	if a.pomodoro != nil || a.errorMessage != "Pomodoro mode off." {
+		t.Fatalf("expected toggling to end the session, got %q", a.errorMessage)
+	}
+	if onClock, _ := a.project.OnClock(); !onClock {
+		t.Fatal("expected the timer to keep running after Pomodoro mode is off")
+	}
+	_, _ = a.project.StopTimer("", true, data.EntryTypeWork)
+}
+
+func TestPomodoroKeepsTheRunningTimersTime(t *testing.T) {
+	a := newTestApp(t, []string{"Running"})
+	project := a.project
+	started := time.Now().Add(-40 * time.Minute).UTC().Truncate(time.Second)
+	if _, err := data.DB.Queries().UpsertTimer(context.Background(), sqlc.UpsertTimerParams{ProjectID: project.ID, StartedAt: started.Unix()}); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	t.Cleanup(func() {
+		if onClock, _ := project.OnClock(); onClock {
+			_, _ = project.StopTimer("", true, data.EntryTypeWork)
+		}
+	})
+
+	if cmd := a.togglePomodoro(); cmd == nil {
+		t.Fatalf("expected the countdown to start, got %q", a.errorMessage)
+	}
+	entries := project.Entries()
+	if len(entries) != 1 || !entries[0].StartedAt.Equal(started) || time.Duration(entries[0].GetDuration()) < 40*time.Minute {
+		t.Fatalf("expected the 40 minutes already tracked to be logged, got %+v", entries)
+	}
+	_, timer := project.OnClock()
+	if timer == nil || time.Since(timer.StartedAt) > time.Minute || !a.pomodoro.started.Equal(timer.StartedAt) {
+		t.Fatalf("expected the first pomodoro to start now, got %+v", timer)
+	}
+}
+
+func TestOverviewSumsPomodoroDurations(t *testing.T) {
+	a := newTestApp(t, []string{"Focus"})
+	now := time.Now()
+	for _, p := range []struct {
+		start    time.Time
+		duration time.Duration
+	}{
+		{now.Add(-3 * time.Hour), 50 * time.Minute},
+		{now.Add(-time.Hour), 10 * time.Minute},
+	} {
+		if _, err := a.project.CreateEntryAt("Pomodoro #pomodoro", p.start, p.duration, true, data.EntryTypeWork); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+	a.dashboardViewport.Width, a.dashboardViewport.Height = 100, 60
+	a.WebReplacementUI()
+	if view := a.dashboardViewport.View(); !strings.Contains(view, "2 completed") || !strings.Contains(view, "1:00 focused") {
+		t.Fatalf("expected the pomodoros' own lengths to add up to an hour, got:\n%s", view)
+	}
+}
+
+func TestPomodorosKeepTheProjectsSettings(t *testing.T) {
+	a := newTestApp(t, []string{"Side Project"})
+	project := a.project
+	if _, err := project.CreateEntryWithDuration("sketching", time.Minute, false, data.EntryTypeFun); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	t.Cleanup(func() {
+		if onClock, _ := project.OnClock(); onClock {
+			_, _ = project.StopTimer("", true, data.EntryTypeWork)
+		}
+	})
+
+	a.togglePomodoro()
+	a.pomodoro.rhythm = data.Pomodoro{Work: time.Second, ShortBreak: time.Minute, LongBreak: time.Minute, Cycles: 2}
+	workEnd := a.pomodoro.started.Add(time.Second)
+	time.Sleep(time.Until(workEnd) + 10*time.Millisecond)
+	model, _ := a.Update(pomodoroTickMsg{id: a.pomodoro.id, at: workEnd})
+	*a = model.(app)
+
+	entries := project.Entries()
+	if len(entries) != 2 || !hasTag(entries[0], data.PomodoroTag) || entries[0].Billable || entries[0].Type != data.EntryTypeFun {
+		t.Fatalf("expected a non-billable fun pomodoro like the project's other entries, got %+v", entries)
+	}
+}
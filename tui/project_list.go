@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,12 +29,17 @@ func (d itemDelegate) Height() int                             { return 1 }
 func (d itemDelegate) Spacing() int                            { return 0 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
-	i, ok := listItem.(item)
-	if !ok {
+	var label string
+	switch i := listItem.(type) {
+	case item:
+		label = string(i)
+	case projectItem:
+		label = i.label()
+	default:
 		return
 	}
 
-	str := fmt.Sprintf("%d. %s", index+1, i)
+	str := fmt.Sprintf("%d. %s", index+1, label)
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -45,6 +51,44 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	_, _ = fmt.Fprint(w, fn(str))
 }
 
+// projectItem is a row in the project tree shown on the project list.
+type projectItem struct {
+	project   *data.Project
+	depth     int
+	children  int
+	collapsed bool
+}
+
+func (i projectItem) FilterValue() string { return "" }
+
+func (i projectItem) label() string {
+	name := strings.Repeat("  ", i.depth) + i.project.GetName()
+	switch {
+	case i.children > 0 && i.collapsed:
+		return fmt.Sprintf("%s ▸ (%d)", name, i.children)
+	case i.children > 0:
+		return name + " ▾"
+	}
+	return name
+}
+
+// projectListItems flattens the project tree into list rows, skipping the
+// sub-projects of collapsed parents.
+func projectListItems(tree []*data.ProjectNode, collapsed map[int64]bool) []list.Item {
+	items := make([]list.Item, 0)
+	data.WalkProjectTree(tree, func(node *data.ProjectNode) bool {
+		isCollapsed := collapsed[node.Project.ID] && len(node.Children) > 0
+		items = append(items, projectItem{
+			project:   node.Project,
+			depth:     node.Depth,
+			children:  len(node.Children),
+			collapsed: isCollapsed,
+		})
+		return !isCollapsed
+	})
+	return items
+}
+
 func (a *app) prepareMoveProjectList() {
 	width := a.width
 	if width == 0 {
@@ -55,30 +99,28 @@ func (a *app) prepareMoveProjectList() {
 		height = 10
 	}
 	items := make([]list.Item, 0)
-	for _, project := range data.DB.Projects() {
-		if a.project != nil && project.GetName() == a.project.GetName() {
-			continue
+	data.WalkProjectTree(data.DB.ProjectTree(), func(node *data.ProjectNode) bool {
+		if a.project == nil || node.Project.ID != a.project.ID {
+			items = append(items, item(node.Path))
 		}
-		items = append(items, item(project.Name))
-	}
+		return true
+	})
 	l := list.New(items, itemDelegate{}, width, height)
 	l.Title = "Select target project"
 	l.SetShowStatusBar(false)
 	l.SetShowPagination(true)
 	l.SetFilteringEnabled(true)
-	l.Styles.Title = titleStyle
-	l.Styles.PaginationStyle = paginationStyle
-	l.Styles.HelpStyle = helpStyle
+	activeTheme.applyToList(&l)
+	keys.applyToList(&l)
 	a.moveProjects = l
 }
 
 func (a *app) refreshProjectList() {
-	projects := data.DB.Projects()
-	items := make([]list.Item, 0, len(projects))
-	for _, p := range projects {
-		items = append(items, item(p.Name))
-	}
+	items := projectListItems(data.DB.ProjectTree(), a.collapsedProjects)
 	a.projects.SetItems(items)
+	if a.project != nil {
+		a.selectProjectInList(a.project.ID)
+	}
 	height := len(items)*2 + 5
 	if height < 10 {
 		height = 10
@@ -92,6 +134,54 @@ func (a *app) refreshProjectList() {
 	a.updateProjectSelectionFromList()
 }
 
+// selectProjectInList highlights the row for projectID if it is visible.
+func (a *app) selectProjectInList(projectID int64) bool {
+	for idx, listItem := range a.projects.Items() {
+		if it, ok := listItem.(projectItem); ok && it.project.ID == projectID {
+			a.projects.Select(idx)
+			return true
+		}
+	}
+	return false
+}
+
+// toggleProjectCollapse folds or unfolds the highlighted project's sub-projects.
+// Collapsing from a sub-project folds its parent and moves the highlight there.
+func (a *app) toggleProjectCollapse(expand bool) {
+	it, ok := a.projects.SelectedItem().(projectItem)
+	if !ok {
+		return
+	}
+	if a.collapsedProjects == nil {
+		a.collapsedProjects = make(map[int64]bool)
+	}
+	target := it.project.ID
+	switch {
+	case it.children > 0:
+		if a.collapsedProjects[target] == !expand {
+			return
+		}
+		a.collapsedProjects[target] = !expand
+	case !expand && it.project.ParentID != nil:
+		target = *it.project.ParentID
+		a.collapsedProjects[target] = true
+	default:
+		return
+	}
+	a.refreshProjectList()
+	a.selectProjectInList(target)
+	a.updateProjectSelectionFromList()
+}
+
+// toggleSelectedProjectCollapse flips the fold state of the highlighted parent project.
+func (a *app) toggleSelectedProjectCollapse() {
+	it, ok := a.projects.SelectedItem().(projectItem)
+	if !ok || it.children == 0 {
+		return
+	}
+	a.toggleProjectCollapse(it.collapsed)
+}
+
 func (a *app) resetNumericProjectSelection() {
 	a.numericSelectBuffer = ""
 	a.numericSelectLast = time.Time{}
@@ -177,8 +267,12 @@ func (a app) projectColumnWidths(totalWidth int) (int, int) {
 }
 
 func (a app) projectFooterView() string {
-	baseControls := []string{"↑/↓: navigate", "n: new project", "r: monthly report (list)", "o: weekly overview", "q: quit"}
-	return helpStyle.Render(strings.Join(baseControls, " | "))
+	help := helpLine(
+		keyGroup("navigate", keys.Up, keys.Down),
+		keyGroup("fold", keys.Fold, keys.Unfold, keys.ToggleFold),
+		keys.NewProject, keys.Report, keys.Overview, keys.Heatmap, keys.Timeline, keys.Trash, keys.Templates, keys.Quit,
+	)
+	return helpStyle.Render(withUndoHint(help, a.undoHint))
 }
 
 func (a app) projectActionsView(width int) string {
@@ -190,13 +284,13 @@ func (a app) projectActionsView(width int) string {
 			titleStyle.Render("Project actions"),
 			"",
 			projectActionStyle.Render("Select a project to see available actions."),
-			projectActionStyle.Render("Press 'n' to create a new project."),
+			projectActionStyle.Render(fmt.Sprintf("Press '%s' to create a new project.", keys.NewProject.Help().Key)),
 		}
 		return lipgloss.NewStyle().Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
 	}
 
 	onclock, timer := a.project.OnClock()
-	projectName := "project: " + a.project.Name
+	projectName := "project: " + a.project.Path()
 	if onclock {
 		var duration string
 		if timer != nil {
@@ -208,25 +302,23 @@ func (a app) projectActionsView(width int) string {
 	}
 	lines := []string{
 		titleStyle.Render(projectName),
-		"",
 	}
-	for _, choice := range a.choices {
-		if onclock && choice[0] == "s" {
-			continue
-		}
-		if !onclock && choice[0] == "p" {
+	if status, err := a.project.BudgetStatus(time.Now()); err == nil && status != nil {
+		lines = append(lines, projectActionStyle.Render("budget: "+budgetStyle(status).Render(status.String())))
+	}
+	lines = append(lines, "")
+	timerChoice := keys.Start
+	if onclock {
+		timerChoice = keys.Stop
+	}
+	choices := []key.Binding{timerChoice, keys.Manual, keys.Logs, keys.Entries, keys.DeleteProject, keys.RenameProject, keys.Budget, keys.Pomodoro}
+	for _, choice := range choices {
+		if !choice.Enabled() {
 			continue
 		}
-		var choiceText string
-		if choice[0] != "" {
-			shortcut := projectShortcutStyle.Render(fmt.Sprintf("%s: ", choice[0]))
-			label := projectLabelStyle.Render(choice[1])
-			choiceText = lipgloss.JoinHorizontal(lipgloss.Left, shortcut, label)
-		} else {
-			label := projectLabelStyle.Render(choice[1])
-			choiceText = lipgloss.JoinHorizontal(lipgloss.Left, projectShortcutSlot.Render(""), " ", label)
-		}
-		lines = append(lines, projectActionStyle.Render(choiceText))
+		shortcut := projectShortcutStyle.Render(fmt.Sprintf("%s: ", choice.Help().Key))
+		label := projectLabelStyle.Render(choice.Help().Desc)
+		lines = append(lines, projectActionStyle.Render(lipgloss.JoinHorizontal(lipgloss.Left, shortcut, label)))
 	}
 	return lipgloss.NewStyle().Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
 }
@@ -257,11 +349,11 @@ func (a *app) updateProjectSelectionFromList() {
 	}
 
 	selected := a.projects.SelectedItem()
-	selectedItem, ok := selected.(item)
+	selectedItem, ok := selected.(projectItem)
 	if !ok {
 		a.projects.Select(0)
 		selected = a.projects.SelectedItem()
-		selectedItem, ok = selected.(item)
+		selectedItem, ok = selected.(projectItem)
 		if !ok {
 			a.project = nil
 			a.state = stateProjectList
@@ -269,9 +361,8 @@ func (a *app) updateProjectSelectionFromList() {
 		}
 	}
 
-	name := string(selectedItem)
 	if project, found := lo.Find(data.DB.Projects(), func(p *data.Project) bool {
-		return p.Name == name
+		return p.ID == selectedItem.project.ID
 	}); found {
 		a.project = project
 		if a.state == stateProjectList {
@@ -280,14 +371,14 @@ func (a *app) updateProjectSelectionFromList() {
 		return
 	}
 
-	firstItem, ok := items[0].(item)
+	firstItem, ok := items[0].(projectItem)
 	if !ok {
 		a.project = nil
 		a.state = stateProjectList
 		return
 	}
 	if project, found := lo.Find(data.DB.Projects(), func(p *data.Project) bool {
-		return p.Name == string(firstItem)
+		return p.ID == firstItem.project.ID
 	}); found {
 		a.projects.Select(0)
 		a.project = project
@@ -301,33 +392,56 @@ func (a *app) updateProjectSelectionFromList() {
 	a.state = stateProjectList
 }
 
-// when the project list is active
-func (a *app) handleKeypressProjectList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	keypress := msg.String()
-
-	if a.handleNumericProjectSelection(keypress) {
-		return a, nil
-	}
-
-	a.resetNumericProjectSelection()
-
-	switch keypress {
-	case "ctrl+c", "q":
-		return a, tea.Quit
-	case "n":
+// handleProjectKeys handles the keys the project list and the project menu
+// share, and reports whether msg was one of them.
+func (a *app) handleProjectKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return tea.Quit, true
+	case key.Matches(msg, keys.NewProject):
 		a.previousState = a.state
 		a.createInput.SetValue("")
 		a.createInput.Focus()
 		a.state = stateCreateProject
-		return a, textinput.Blink
-	case "r":
+		return textinput.Blink, true
+	case key.Matches(msg, keys.Report):
 		a.ReportViewUI()
-		return a, nil
-	case "o":
+	case key.Matches(msg, keys.Overview):
 		a.WebReplacementUI()
+	case key.Matches(msg, keys.Undo):
+		a.UndoUI()
+	case key.Matches(msg, keys.Trash):
+		a.TrashUI()
+	case key.Matches(msg, keys.Templates):
+		a.TemplatesUI()
+	case key.Matches(msg, keys.Heatmap):
+		a.HeatmapUI()
+	case key.Matches(msg, keys.Timeline):
+		a.TimelineUI(time.Now())
+	case key.Matches(msg, keys.Fold):
+		a.toggleProjectCollapse(false)
+	case key.Matches(msg, keys.Unfold):
+		a.toggleProjectCollapse(true)
+	case key.Matches(msg, keys.ToggleFold):
+		a.toggleSelectedProjectCollapse()
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// when the project list is active
+func (a *app) handleKeypressProjectList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.handleNumericProjectSelection(msg.String()) {
 		return a, nil
 	}
 
+	a.resetNumericProjectSelection()
+
+	if cmd, ok := a.handleProjectKeys(msg); ok {
+		return a, cmd
+	}
+
 	// Default list navigation
 	var cmd tea.Cmd
 	a.projects, cmd = a.projects.Update(msg)
@@ -342,92 +456,90 @@ func (a *app) handleKeypressProjectMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
-	keypress := msg.String()
-
-	if a.handleNumericProjectSelection(keypress) {
+	if a.handleNumericProjectSelection(msg.String()) {
 		return a, nil
 	}
 
 	a.resetNumericProjectSelection()
 
+	if cmd, ok := a.handleProjectKeys(msg); ok {
+		return a, cmd
+	}
+
 	onclock, _ := a.project.OnClock()
 
-	switch keypress {
-	case "ctrl+c", "q":
-		return a, tea.Quit
-	case "esc": // Go back to project list
+	switch {
+	case key.Matches(msg, keys.Back): // Go back to project list
 		a.project = nil
 		a.state = stateProjectList
 		return a, nil
-	case "n":
-		a.previousState = a.state
-		a.createInput.SetValue("")
-		a.createInput.Focus()
-		a.state = stateCreateProject
-		return a, textinput.Blink
-	case "r":
-		a.ReportViewUI()
-		return a, nil
-	case "o":
-		a.WebReplacementUI()
-		return a, nil
-	case "s": // Start Timer
+	case key.Matches(msg, keys.Start):
 		if !onclock {
 			err := a.project.StartTimer()
 			if err != nil {
 				a.errorMessage = fmt.Sprintf("Error starting timer: %v", err)
+			} else {
+				a.errorMessage = budgetWarning(a.project)
+				a.reminderBanner = ""
 			}
 		}
 		return a, nil
-	case "p": // End Timer (Prepare)
+	case key.Matches(msg, keys.Stop): // End Timer (Prepare)
 		if onclock {
 			a.state = stateStoppingTimer
 			a.stopEntryFocus = focusStopMessage
 			a.stopBillable = true
+			a.stopType = data.EntryTypeWork
 			a.stopMessageInput.Focus()
 			a.stopMessageInput.SetValue("")
+			a.loadCommitSuggestions()
 			return a, textinput.Blink
 		}
 		return a, nil
-	case "e": // Enter Manually (Prepare)
+	case key.Matches(msg, keys.Manual): // Enter Manually (Prepare)
 		a.state = stateManualEntry
-		a.manualEntryFocus = focusTime
 		a.manualBillable = true
-		a.manualTimeInput.Focus()
-		a.manualMsgInput.Blur()
+		a.manualType = data.EntryTypeWork
 		a.manualTimeInput.SetValue("")
+		a.manualWhenInput.SetValue("")
 		a.manualMsgInput.SetValue("")
+		a.focusManualField(focusTime)
 		return a, textinput.Blink
-	case "l": // Show Logs
+	case key.Matches(msg, keys.Logs):
 		a.state = stateShowLogs
 		// Format logs and set viewport content
 		a.logViewport.SetContent(a.formatProjectLogs(a.project, a.logViewport.Width)) // Pass width
 		a.logViewport.GotoTop()                                                       // Scroll to top initially
 		a.errorMessage = ""                                                           // Clear previous errors
 		return a, nil
-	case "v":
+	case key.Matches(msg, keys.Entries):
 		a.refreshEntryList()
 		a.selectedEntry = nil
 		a.state = stateEntryList
 		a.errorMessage = ""
 		return a, nil
-	case "D", "shift+d":
+	case key.Matches(msg, keys.DeleteProject):
 		a.confirmAction = confirmDeleteProject
 		a.confirmProject = a.project
 		a.confirmEntry = nil
 		if a.project != nil {
-			a.confirmMessage = fmt.Sprintf("Delete project '%s'? This removes all entries.", a.project.Name)
+			a.confirmMessage = fmt.Sprintf("Delete project '%s' and its entries? It stays in the trash until purged.", a.project.Name)
 		}
 		a.previousState = stateProjectMenu
 		a.state = stateConfirm
 		return a, nil
-	case "R", "shift+r":
+	case key.Matches(msg, keys.RenameProject):
 		if a.project != nil {
-			a.renameInput.SetValue(a.project.Name)
+			a.renameInput.SetValue(a.project.Path())
 		}
 		a.state = stateRenameProject
 		a.renameInput.Focus()
 		return a, textinput.Blink
+	case key.Matches(msg, keys.Budget):
+		a.BudgetUI()
+		return a, textinput.Blink
+	case key.Matches(msg, keys.Pomodoro):
+		return a, a.togglePomodoro()
 	}
 
 	var cmd tea.Cmd
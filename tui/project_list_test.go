@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/nexneo/samay/data"
@@ -82,15 +83,13 @@ func newTestApp(t *testing.T, names []string) *app {
 
 	clearProjects(t)
 
-	for _, name := range names {
-		if _, err := data.DB.CreateProject(name); err != nil {
-			t.Fatalf("create project %q: %v", name, err)
-		}
-	}
-
 	items := make([]list.Item, len(names))
 	for i, name := range names {
-		items[i] = item(name)
+		project, err := data.DB.CreateProject(name)
+		if err != nil {
+			t.Fatalf("create project %q: %v", name, err)
+		}
+		items[i] = projectItem{project: project}
 	}
 
 	height := len(items)*2 + 5
@@ -114,10 +113,58 @@ func newTestApp(t *testing.T, names []string) *app {
 
 func clearProjects(t *testing.T) {
 	t.Helper()
-	projects := data.DB.Projects()
+	// Sub-projects have to go before their parents.
+	var projects []*data.Project
+	data.WalkProjectTree(data.DB.ProjectTree(), func(node *data.ProjectNode) bool {
+		projects = append([]*data.Project{node.Project}, projects...)
+		return true
+	})
 	for _, project := range projects {
 		if err := project.Delete(); err != nil {
 			t.Fatalf("delete project %q: %v", project.Name, err)
 		}
 	}
 }
+
+func TestProjectTreeCollapseAndRollup(t *testing.T) {
+	clearProjects(t)
+
+	child, err := data.DB.CreateProject("Tree Client/Tree Stream")
+	if err != nil {
+		t.Fatalf("create nested project: %v", err)
+	}
+	if _, err := child.CreateEntryWithDuration("stream work", 90*time.Minute, true, data.EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	parent, err := data.DB.FindProject("Tree Client")
+	if err != nil {
+		t.Fatalf("find parent: %v", err)
+	}
+
+	tree := data.DB.ProjectTree()
+	if items := projectListItems(tree, nil); len(items) != 2 {
+		t.Fatalf("expected expanded tree to list 2 rows, got %d", len(items))
+	}
+	items := projectListItems(tree, map[int64]bool{parent.ID: true})
+	if len(items) != 1 {
+		t.Fatalf("expected collapsed tree to list 1 row, got %d", len(items))
+	}
+	if it := items[0].(projectItem); !it.collapsed || it.children != 1 {
+		t.Fatalf("expected collapsed parent with one child, got %+v", it)
+	}
+
+	own := map[int64]time.Duration{child.ID: 90 * time.Minute}
+	rows := rollupProjectRows(tree, own,
+		func(total *time.Duration, c time.Duration) { *total += c },
+		func(d time.Duration) bool { return d > 0 },
+		func(a, b time.Duration) bool { return a > b },
+	)
+	if len(rows) != 2 || rows[0].project.ID != parent.ID || rows[0].row != 90*time.Minute {
+		t.Fatalf("expected parent to roll up child time, got %+v", rows)
+	}
+	if rows[1].label() != "  Tree Stream" {
+		t.Fatalf("expected indented child label, got %q", rows[1].label())
+	}
+
+	clearProjects(t)
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/reminder"
+)
+
+// reminderInterval is how often the UI checks whether a reminder is due.
+const reminderInterval = 30 * time.Second
+
+type reminderTickMsg time.Time
+
+// newReminderChecker rings the terminal bell for reminders and, unless a
+// daemon already does, runs the reminder command. It is nil when reminders
+// are off.
+func newReminderChecker(daemon bool) *reminder.Checker {
+	schedule, ok := data.DB.Reminders()
+	if !ok {
+		return nil
+	}
+	if daemon {
+		schedule.Command = nil
+	}
+	return reminder.NewChecker(data.DB, reminder.Notifiers(schedule, os.Stdout)...)
+}
+
+// LeaveJobsToDaemon skips the scheduled work a running daemon already does,
+// so nothing happens twice. It is all the UI hands the daemon.
+func (a *app) LeaveJobsToDaemon() {
+	a.reminders = newReminderChecker(true)
+}
+
+func (a app) reminderTick() tea.Cmd {
+	if a.reminders == nil {
+		return nil
+	}
+	return tea.Tick(reminderInterval, func(t time.Time) tea.Msg { return reminderTickMsg(t) })
+}
+
+// reminderCheckedMsg carries what a reminder check found.
+type reminderCheckedMsg struct {
+	reminder *reminder.Reminder
+	err      error
+}
+
+// handleReminderTick runs the check as a command: it may ring the bell and
+// run the reminder command, neither of which belongs inside Update.
+func (a app) handleReminderTick(msg reminderTickMsg) (tea.Model, tea.Cmd) {
+	checker := a.reminders
+	return a, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		r, err := checker.Check(ctx, time.Time(msg))
+		return reminderCheckedMsg{reminder: r, err: err}
+	}
+}
+
+// handleReminderChecked puts up the reminder banner when a reminder is due
+// and takes it down once a timer runs.
+func (a app) handleReminderChecked(msg reminderCheckedMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.reminder != nil:
+		a.reminderBanner = msg.reminder.Message()
+		if msg.err != nil {
+			a.reminderBanner += " (" + msg.err.Error() + ")"
+		}
+	default:
+		if _, idle, err := data.DB.IdleSince(); err == nil && !idle {
+			a.reminderBanner = ""
+		}
+	}
+	return a, a.reminderTick()
+}
+
+func (a app) reminderBannerView() string {
+	return reminderStyle.Render("⏰ " + a.reminderBanner)
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/reminder"
+)
+
+func TestReminderBanner(t *testing.T) {
+	a := newTestApp(t, []string{"Forgotten"})
+	if err := data.DB.SetReminders(&data.ReminderSettings{
+		AfterMinutes: 1,
+		Days:         map[string][]string{"daily": {"00:00-24:00"}},
+	}); err != nil {
+		t.Fatalf("set reminders: %v", err)
+	}
+	t.Cleanup(func() { _ = data.DB.SetReminders(nil) })
+	a.reminders = reminder.NewChecker(data.DB)
+	a.errorMessage = "still reading this"
+
+	check := func(a app, at time.Time) (app, tea.Cmd) {
+		t.Helper()
+		model, cmd := a.Update(reminderTickMsg(at))
+		if cmd == nil {
+			t.Fatal("expected the check to run as a command")
+		}
+		model, cmd = model.(app).Update(cmd())
+		return model.(app), cmd
+	}
+
+	got, cmd := check(*a, time.Now().Add(time.Hour))
+	if cmd == nil {
+		t.Fatal("expected the next check to be scheduled")
+	}
+	if !strings.HasPrefix(got.reminderBanner, "No timer has run for") || !strings.Contains(got.View(), "⏰ No timer has run") {
+		t.Fatalf("expected the reminder banner, got %q", got.reminderBanner)
+	}
+	if got.errorMessage != "still reading this" {
+		t.Fatalf("expected the check to leave the error alone, got %q", got.errorMessage)
+	}
+
+	if err := got.project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+	t.Cleanup(func() { _, _ = got.project.StopTimer("", true, data.EntryTypeWork) })
+	got, _ = check(got, time.Now().Add(2*time.Hour))
+	if banner := got.reminderBanner; banner != "" {
+		t.Fatalf("expected a running timer to take the banner down, got %q", banner)
+	}
+}
@@ -1,34 +1,88 @@
 package tui
 
 import (
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
 )
 
+// projectRollup is a report row for one project, positioned in the project tree.
+type projectRollup[T any] struct {
+	project *data.Project
+	depth   int
+	row     T
+}
+
+func (r projectRollup[T]) label() string {
+	return strings.Repeat("  ", r.depth) + r.project.GetName()
+}
+
+// rollupProjectRows folds each project's own row into its ancestors and returns
+// the rows in tree order, siblings sorted by less. Subtrees whose combined row
+// fails keep are dropped.
+func rollupProjectRows[T any](tree []*data.ProjectNode, own map[int64]T, add func(total *T, child T), keep func(T) bool, less func(a, b T) bool) []projectRollup[T] {
+	type subtree struct {
+		head projectRollup[T]
+		rows []projectRollup[T]
+	}
+	var visit func(nodes []*data.ProjectNode) []subtree
+	visit = func(nodes []*data.ProjectNode) []subtree {
+		out := make([]subtree, 0, len(nodes))
+		for _, node := range nodes {
+			total := own[node.Project.ID]
+			var rows []projectRollup[T]
+			for _, child := range visit(node.Children) {
+				add(&total, child.head.row)
+				rows = append(rows, child.head)
+				rows = append(rows, child.rows...)
+			}
+			if !keep(total) {
+				continue
+			}
+			head := projectRollup[T]{project: node.Project, depth: node.Depth, row: total}
+			out = append(out, subtree{head: head, rows: rows})
+		}
+		sort.SliceStable(out, func(i, j int) bool { return less(out[i].head.row, out[j].head.row) })
+		return out
+	}
+
+	var rows []projectRollup[T]
+	for _, tree := range visit(tree) {
+		rows = append(rows, tree.head)
+		rows = append(rows, tree.rows...)
+	}
+	return rows
+}
+
 func (a *app) handleKeypressReportView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
 		return a, tea.Quit
-	case "esc":
+	case key.Matches(msg, keys.Back):
 		a.state = a.previousState
 		return a, nil
-	case "left", "h":
+	case key.Matches(msg, keys.ReportPrevious):
 		a.adjustReportMonth(-1)
 		a.ReportViewUI()
 		return a, nil
-	case "right", "l":
+	case key.Matches(msg, keys.ReportNext):
 		a.adjustReportMonth(1)
 		a.ReportViewUI()
 		return a, nil
-	case "r":
+	case key.Matches(msg, keys.ReportPerson):
+		a.cycleReportPerson()
+		a.ReportViewUI()
+		return a, nil
+	case key.Matches(msg, keys.ReportThisMonth):
 		now := time.Now()
 		a.reportMonth = now.Month()
 		a.reportYear = now.Year()
 		a.ReportViewUI()
 		return a, nil
-	case "enter":
-		return a, nil
 	}
 
 	var cmd tea.Cmd
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+)
+
+// Fields of the split form, in tab order.
+const (
+	splitPointField = iota
+	splitFirstField
+	splitSecondField
+	splitFieldCount
+)
+
+var splitFieldLabels = [splitFieldCount]string{
+	"Split at (a time such as 14:30 or 3pm, or a duration such as 45m after the start):",
+	"Description before the split:",
+	"Description after the split:",
+}
+
+// SplitUI opens the form for cutting entry in two.
+func (a *app) SplitUI(entry *data.Entry) {
+	if entry == nil {
+		a.errorMessage = "No entry chosen."
+		return
+	}
+	if _, ok := data.EntrySpan(entry); !ok {
+		a.errorMessage = "Only finished entries can be split."
+		return
+	}
+	for i := range a.splitInputs {
+		input := textinput.New()
+		input.CharLimit = 250
+		input.Width = 50
+		a.splitInputs[i] = input
+	}
+	a.splitInputs[splitPointField].Placeholder = "14:30 or 45m"
+	a.splitInputs[splitFirstField].SetValue(oneLineContent(entry.GetContent()))
+	a.splitInputs[splitSecondField].SetValue(oneLineContent(entry.GetContent()))
+	a.splitEntry = entry
+	a.focusSplitField(splitPointField)
+	a.state = stateSplitEntry
+}
+
+func (a *app) focusSplitField(field int) {
+	a.splitFocus = field
+	for i := range a.splitInputs {
+		if i == field {
+			a.splitInputs[i].Focus()
+		} else {
+			a.splitInputs[i].Blur()
+		}
+	}
+}
+
+func (a *app) handleKeypressSplitEntry(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.FormCancel):
+		a.openEntryInProject(a.splitEntry)
+		return a, nil
+	case key.Matches(msg, keys.FormNext):
+		a.focusSplitField((a.splitFocus + 1) % splitFieldCount)
+		return a, textinput.Blink
+	case key.Matches(msg, keys.FormPrevious):
+		a.focusSplitField((a.splitFocus + splitFieldCount - 1) % splitFieldCount)
+		return a, textinput.Blink
+	case key.Matches(msg, keys.FormSubmit):
+		a.SaveSplitUI()
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.splitInputs[a.splitFocus], cmd = a.splitInputs[a.splitFocus].Update(msg)
+	return a, cmd
+}
+
+// SaveSplitUI splits the entry as the form describes and returns to the entry list.
+func (a *app) SaveSplitUI() {
+	entry := a.splitEntry
+	at, err := entry.SplitPoint(a.splitInputs[splitPointField].Value())
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error: %v", err)
+		a.focusSplitField(splitPointField)
+		return
+	}
+	if _, err := entry.SplitAt(at, a.splitInputs[splitFirstField].Value(), a.splitInputs[splitSecondField].Value()); err != nil {
+		a.errorMessage = fmt.Sprintf("Error splitting entry: %v", err)
+		return
+	}
+	a.refreshUndoHint()
+	a.openEntryInProject(entry)
+	a.errorMessage = fmt.Sprintf("Entry split at %s%s", at.In(time.Local).Format("15:04"), undoNote())
+}
+
+func (a app) splitEntryView() string {
+	entry := a.splitEntry
+	if entry == nil {
+		return errorStyle.Render("No entry chosen")
+	}
+	lines := []string{
+		titleStyle.MarginTop(1).Render("Split entry"),
+		detailLine("Entry:", overlapEntryLine(entry)),
+	}
+	fieldStyle := itemStyle.PaddingLeft(2)
+	for i, input := range a.splitInputs {
+		lines = append(lines, "", inputPromptStyle.Render(splitFieldLabels[i]), fieldStyle.Render(input.View()))
+	}
+	lines = append(lines, "", helpView(describe(keys.FormSubmit, "split"), keyGroup("switch", keys.FormNext, keys.FormPrevious), keys.FormCancel, keys.ForceQuit))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// toggleEntryMark marks or unmarks the highlighted entry for merging.
+func (a *app) toggleEntryMark() {
+	it, ok := a.entries.SelectedItem().(entryItem)
+	if !ok {
+		return
+	}
+	it.marked = !it.marked
+	a.entries.SetItem(a.entries.GlobalIndex(), it)
+}
+
+// MergeUI merges the marked entries into the highlighted one.
+func (a *app) MergeUI() {
+	target := entryFromListItem(a.entries.SelectedItem())
+	if target == nil {
+		a.errorMessage = "No entry chosen."
+		return
+	}
+	var others []*data.Entry
+	for _, item := range a.entries.Items() {
+		if it, ok := item.(entryItem); ok && it.marked && it.entry.ID != target.ID {
+			others = append(others, it.entry)
+		}
+	}
+	if len(others) == 0 {
+		a.errorMessage = fmt.Sprintf("Mark the entries to merge with %s, then press %s on the one to keep.", keys.EntryMark.Help().Key, keys.EntryMerge.Help().Key)
+		return
+	}
+	if err := target.Merge(others...); err != nil {
+		a.errorMessage = fmt.Sprintf("Error merging entries: %v", err)
+		return
+	}
+	a.refreshUndoHint()
+	a.openEntryInProject(target)
+	a.errorMessage = fmt.Sprintf("Merged %d entries%s", len(others)+1, undoNote())
+	if warning := overlapWarning(target); warning != "" {
+		a.errorMessage = warning
+	}
+}
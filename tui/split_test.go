@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+)
+
+func TestSplitAndMergeFromEntryList(t *testing.T) {
+	a := newTestApp(t, []string{"Split UI"})
+	project, err := data.DB.FindProject("Split UI")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	entry, err := project.CreateEntryWithDuration("migration", time.Hour, true, data.EntryTypeWork)
+	if err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	a.openEntryInProject(entry)
+	a.handleKeypressEntryList(key("x"))
+	if a.state != stateSplitEntry {
+		t.Fatalf("expected the split form, got state %v (%s)", a.state, a.errorMessage)
+	}
+	a.splitInputs[splitPointField].SetValue("25m")
+	a.splitInputs[splitSecondField].SetValue("cleanup #ops")
+	a.handleKeypressSplitEntry(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.state != stateEntryList || len(a.entries.Items()) != 2 || !strings.Contains(a.errorMessage, "split") {
+		t.Fatalf("expected two entries after splitting, got state %v with %d items (%s)", a.state, len(a.entries.Items()), a.errorMessage)
+	}
+
+	a.handleKeypressEntryList(key("M"))
+	if !strings.Contains(a.errorMessage, "Mark the entries") {
+		t.Fatalf("expected a hint when nothing is marked, got %q", a.errorMessage)
+	}
+	a.handleKeypressEntryList(key(" "))
+	a.entries.Select(1 - a.entries.Index())
+	a.handleKeypressEntryList(key("M"))
+	if len(a.entries.Items()) != 1 || !strings.Contains(a.errorMessage, "Merged 2 entries") {
+		t.Fatalf("expected the entries to merge back into one, got %d items (%s)", len(a.entries.Items()), a.errorMessage)
+	}
+	if merged := a.selectedEntry; merged == nil || merged.DurationMs != time.Hour.Milliseconds() {
+		t.Fatalf("expected the merged entry to last an hour, got %+v", merged)
+	}
+
+	clearProjects(t)
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+type templateItem struct {
+	template *data.Template
+}
+
+func (i templateItem) FilterValue() string { return strings.ToLower(i.template.Name) }
+
+type templateItemDelegate struct{}
+
+func (d templateItemDelegate) Height() int                             { return 1 }
+func (d templateItemDelegate) Spacing() int                            { return 0 }
+func (d templateItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d templateItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	it, ok := listItem.(templateItem)
+	if !ok {
+		return
+	}
+	t := it.template
+	number := " "
+	if index < 9 {
+		number = strconv.Itoa(index + 1)
+	}
+	line := fmt.Sprintf("%s  %-16s %6s  %-20s %s", number, truncateString(t.Name, 16), util.HmFromD(t.Duration),
+		truncateString(t.Project.Path(), 20), truncateString(oneLineContent(t.Content), 40))
+	if !t.Repeat.IsZero() {
+		line += "  (" + t.Repeat.String() + ")"
+	}
+
+	if index == m.Index() {
+		_, _ = fmt.Fprint(w, selectedItemStyle.PaddingLeft(4).Render(line))
+		return
+	}
+	_, _ = fmt.Fprint(w, itemStyle.Render(line))
+}
+
+// TemplatesUI lists the entry templates so one can be logged with a single key.
+func (a *app) TemplatesUI() {
+	templates, err := data.DB.Templates()
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading templates: %v", err)
+		return
+	}
+	items := make([]list.Item, 0, len(templates))
+	for _, t := range templates {
+		items = append(items, templateItem{template: t})
+	}
+	width := a.width
+	if width == 0 {
+		width = 80
+	}
+	l := list.New(items, templateItemDelegate{}, width, max(a.height-6, 10))
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(false)
+	activeTheme.applyToList(&l)
+	keys.applyToList(&l)
+	a.templates = l
+	if a.state != stateTemplates {
+		a.previousState = a.state
+	}
+	a.state = stateTemplates
+}
+
+func (a *app) handleKeypressTemplates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.Back):
+		a.state = a.previousState
+		return a, nil
+	case key.Matches(msg, keys.Select):
+		if it, ok := a.templates.SelectedItem().(templateItem); ok {
+			a.applyTemplate(it.template)
+		}
+		return a, nil
+	case key.Matches(msg, keys.numbers):
+		index := int(msg.String()[0] - '1')
+		if items := a.templates.Items(); index >= 0 && index < len(items) {
+			a.applyTemplate(items[index].(templateItem).template)
+		}
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.templates, cmd = a.templates.Update(msg)
+	return a, cmd
+}
+
+// applyTemplate logs the template as an entry ending now and shows it in its project.
+func (a *app) applyTemplate(t *data.Template) {
+	entry, err := t.Apply(time.Now())
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error logging %s: %v", t.Name, err)
+		return
+	}
+	a.refreshProjectList()
+	a.openEntryInProject(entry)
+	a.errorMessage = fmt.Sprintf("Logged %s (%s)", t.Name, util.HmFromD(t.Duration))
+	if warning := overlapWarning(entry); warning != "" {
+		a.errorMessage = warning
+	}
+}
+
+func (a app) templatesView() string {
+	lines := []string{titleStyle.MarginTop(1).Render("Templates")}
+	if len(a.templates.Items()) == 0 {
+		lines = append(lines, "", itemStyle.Render("No templates yet. Add one with: samay template add -project <path> -duration 15m <name> <description>"), "")
+	} else {
+		lines = append(lines, a.templates.View())
+	}
+	logNow := key.NewBinding(key.WithKeys(keys.Select.Keys()...), key.WithHelp("1-9/"+keys.Select.Help().Key, "log it now"))
+	lines = append(lines, helpView(logNow, keyGroup("move", keys.Up, keys.Down), keys.Back, keys.Quit))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+)
+
+func TestTemplatesLogWithOneKey(t *testing.T) {
+	a := newTestApp(t, []string{"Rituals UI"})
+	project, err := data.DB.FindProject("Rituals UI")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	template := &data.Template{Name: "review", Project: project, Content: "code review", Duration: 20 * time.Minute, Billable: true}
+	if err := data.DB.SaveTemplate(template); err != nil {
+		t.Fatalf("save template: %v", err)
+	}
+	t.Cleanup(func() { _ = data.DB.DeleteTemplate("review") })
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	a.handleKeypressProjectList(key("T"))
+	if a.state != stateTemplates || !strings.Contains(a.templatesView(), "review") {
+		t.Fatalf("expected the template list, got state %v (%s)", a.state, a.errorMessage)
+	}
+	a.handleKeypressTemplates(key("1"))
+	if a.state != stateEntryList || !strings.Contains(a.errorMessage, "Logged review") {
+		t.Fatalf("expected the logged entry in its project, got state %v (%s)", a.state, a.errorMessage)
+	}
+	entries := project.Entries()
+	if len(entries) != 1 || entries[0].Content != "code review" || entries[0].DurationMs != (20*time.Minute).Milliseconds() {
+		t.Fatalf("expected one 20 minute review entry, got %d entries", len(entries))
+	}
+}
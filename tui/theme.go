@@ -0,0 +1,356 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+)
+
+// theme holds the colours of the interactive UI by the role they play, so
+// one role looks the same in every view.
+type theme struct {
+	Accent    lipgloss.TerminalColor // selection, shortcuts and focused fields
+	Text      lipgloss.TerminalColor // project names and detail values
+	Muted     lipgloss.TerminalColor // project actions
+	Help      lipgloss.TerminalColor // key help in the footers
+	Faint     lipgloss.TerminalColor // heatmap and timeline labels
+	Border    lipgloss.TerminalColor // dividers
+	Label     lipgloss.TerminalColor // input prompts and detail labels
+	Heading   lipgloss.TerminalColor // log date headers
+	Total     lipgloss.TerminalColor // log totals
+	Title     lipgloss.TerminalColor // log titles
+	Running   lipgloss.TerminalColor // timers on the clock
+	Section   lipgloss.TerminalColor // report and dashboard sections
+	Highlight lipgloss.TerminalColor // the highlighted report row
+	Warning   lipgloss.TerminalColor // reminders, near budgets and untracked time
+	Error     lipgloss.TerminalColor // errors, spent budgets and overlaps
+	Pomodoro  lipgloss.TerminalColor // the Pomodoro banner
+
+	// Heatmap runs from an empty day to the busiest days.
+	Heatmap [5]lipgloss.TerminalColor
+	// Palette colours the timeline's projects in turn.
+	Palette []lipgloss.TerminalColor
+	// plain themes have no colours, so the heatmap shows its shades with
+	// glyphs instead.
+	plain bool
+}
+
+var (
+	titleStyle           lipgloss.Style
+	itemStyle            lipgloss.Style
+	projectActionStyle   lipgloss.Style
+	projectShortcutStyle lipgloss.Style
+	projectLabelStyle    lipgloss.Style
+	selectedItemStyle    lipgloss.Style
+	paginationStyle      lipgloss.Style
+	helpStyle            lipgloss.Style
+	inputPromptStyle     lipgloss.Style // Style for input prompt
+	errorStyle           lipgloss.Style // Style for error messages
+	logHeaderStyle       lipgloss.Style // Style for log date headers
+	logTotalStyle        lipgloss.Style // Style for log totals
+	logEntryStyle        lipgloss.Style // Style for individual log entries
+	logTitleStyle        lipgloss.Style // Style for the main log title
+	onClockStyle         lipgloss.Style // Style for "on clock" status
+	detailLabelStyle     lipgloss.Style
+	detailValueStyle     lipgloss.Style
+	detailSectionStyle   lipgloss.Style
+	detailRowStyle       lipgloss.Style
+	detailHighlightStyle lipgloss.Style
+	columnStyle          lipgloss.Style
+	dividerStyle         lipgloss.Style
+	reminderStyle        lipgloss.Style
+	pomodoroStyle        lipgloss.Style
+
+	budgetNearStyle lipgloss.Style
+	budgetOverStyle lipgloss.Style
+
+	// heatmapShades runs from an empty day to the busiest days, GitHub style.
+	heatmapShades      []lipgloss.Style
+	heatmapGlyphs      []string
+	heatmapCursorStyle lipgloss.Style
+	heatmapLabelStyle  lipgloss.Style
+
+	timelineGapStyle     lipgloss.Style
+	timelineOverlapStyle lipgloss.Style
+)
+
+// activeTheme is the theme the styles above were built from.
+var activeTheme theme
+
+func init() {
+	t := builtinThemes()["dark"]
+	if noColor() {
+		t = builtinThemes()["no-color"]
+	}
+	applyTheme(t)
+}
+
+// builtinThemes returns the themes Samay ships with, by name.
+func builtinThemes() map[string]theme {
+	c := func(s string) lipgloss.TerminalColor { return lipgloss.Color(s) }
+	colors := func(values ...string) []lipgloss.TerminalColor {
+		out := make([]lipgloss.TerminalColor, len(values))
+		for i, v := range values {
+			out[i] = c(v)
+		}
+		return out
+	}
+	none := lipgloss.NoColor{}
+	return map[string]theme{
+		"dark": {
+			Accent: c("170"), Text: c("252"), Muted: c("250"),
+			Help:  lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"},
+			Faint: c("245"), Border: c("240"), Label: c("109"),
+			Heading: c("37"), Total: c("32"), Title: c("33"), Running: c("78"),
+			Section: c("111"), Highlight: c("213"), Warning: c("214"), Error: c("196"), Pomodoro: c("203"),
+			Heatmap: [5]lipgloss.TerminalColor{c("238"), c("22"), c("28"), c("34"), c("46")},
+			Palette: colors("39", "78", "213", "208", "111", "186", "141", "73"),
+		},
+		"light": {
+			Accent: c("127"), Text: c("235"), Muted: c("238"),
+			Help:  c("243"),
+			Faint: c("242"), Border: c("250"), Label: c("24"),
+			Heading: c("30"), Total: c("25"), Title: c("26"), Running: c("28"),
+			Section: c("25"), Highlight: c("125"), Warning: c("166"), Error: c("160"), Pomodoro: c("124"),
+			Heatmap: [5]lipgloss.TerminalColor{c("254"), c("151"), c("114"), c("71"), c("28")},
+			Palette: colors("25", "28", "127", "166", "31", "94", "91", "30"),
+		},
+		// high-contrast keeps to the Okabe-Ito colours, which stay apart
+		// for the common kinds of colour blindness, and a viridis heatmap.
+		"high-contrast": {
+			Accent: c("#F0E442"), Text: c("#FFFFFF"), Muted: c("#E0E0E0"),
+			Help:  c("#C0C0C0"),
+			Faint: c("#BBBBBB"), Border: c("#A0A0A0"), Label: c("#56B4E9"),
+			Heading: c("#E69F00"), Total: c("#FFFFFF"), Title: c("#56B4E9"), Running: c("#009E73"),
+			Section: c("#E69F00"), Highlight: c("#CC79A7"), Warning: c("#E69F00"), Error: c("#D55E00"), Pomodoro: c("#D55E00"),
+			Heatmap: [5]lipgloss.TerminalColor{c("#3A3A3A"), c("#3B528B"), c("#21918C"), c("#5EC962"), c("#FDE725")},
+			Palette: colors("#E69F00", "#56B4E9", "#009E73", "#F0E442", "#0072B2", "#D55E00", "#CC79A7", "#FFFFFF"),
+		},
+		"no-color": {
+			Accent: none, Text: none, Muted: none, Help: none, Faint: none, Border: none, Label: none,
+			Heading: none, Total: none, Title: none, Running: none,
+			Section: none, Highlight: none, Warning: none, Error: none, Pomodoro: none,
+			Heatmap: [5]lipgloss.TerminalColor{none, none, none, none, none},
+			Palette: []lipgloss.TerminalColor{none},
+			plain:   true,
+		},
+	}
+}
+
+// themeRole is a colour by the name config.json knows it by.
+type themeRole struct {
+	name  string
+	color *lipgloss.TerminalColor
+}
+
+func (t *theme) roles() []themeRole {
+	return []themeRole{
+		{"accent", &t.Accent},
+		{"text", &t.Text},
+		{"muted", &t.Muted},
+		{"help", &t.Help},
+		{"faint", &t.Faint},
+		{"border", &t.Border},
+		{"label", &t.Label},
+		{"heading", &t.Heading},
+		{"total", &t.Total},
+		{"title", &t.Title},
+		{"running", &t.Running},
+		{"section", &t.Section},
+		{"highlight", &t.Highlight},
+		{"warning", &t.Warning},
+		{"error", &t.Error},
+		{"pomodoro", &t.Pomodoro},
+	}
+}
+
+// noColor reports whether the NO_COLOR convention asks for no colours.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// newTheme returns the theme called name: a built-in one or one of themes,
+// which start from a built-in theme and change some of its colours.
+func newTheme(name string, themes map[string]data.ThemeSettings) (theme, error) {
+	builtin := builtinThemes()
+	for custom := range themes {
+		if _, ok := builtin[strings.ToLower(strings.TrimSpace(custom))]; ok {
+			return theme{}, fmt.Errorf("theme: %q is a built-in theme and cannot be redefined", custom)
+		}
+	}
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" {
+		key = "dark"
+	}
+	if t, ok := builtin[key]; ok {
+		return t, nil
+	}
+	for custom, settings := range themes {
+		if strings.ToLower(strings.TrimSpace(custom)) == key {
+			return customTheme(custom, settings)
+		}
+	}
+	return theme{}, fmt.Errorf("theme: unknown theme %q; use dark, light, high-contrast, no-color or one defined in themes", name)
+}
+
+func customTheme(name string, settings data.ThemeSettings) (theme, error) {
+	base := strings.ToLower(strings.TrimSpace(settings.Base))
+	if base == "" {
+		base = "dark"
+	}
+	t, ok := builtinThemes()[base]
+	if !ok {
+		return theme{}, fmt.Errorf("theme %s: unknown base %q; use dark, light, high-contrast or no-color", name, settings.Base)
+	}
+	roles := t.roles()
+	for role, value := range settings.Colors {
+		i := slices.IndexFunc(roles, func(r themeRole) bool { return r.name == role })
+		if i < 0 {
+			return theme{}, fmt.Errorf("theme %s: unknown colour %q", name, role)
+		}
+		color, err := parseColor(value)
+		if err != nil {
+			return theme{}, fmt.Errorf("theme %s: %s: %w", name, role, err)
+		}
+		*roles[i].color = color
+	}
+	if settings.Heatmap != nil {
+		if len(settings.Heatmap) != len(t.Heatmap) {
+			return theme{}, fmt.Errorf("theme %s: heatmap needs %d colours, got %d", name, len(t.Heatmap), len(settings.Heatmap))
+		}
+		for i, value := range settings.Heatmap {
+			color, err := parseColor(value)
+			if err != nil {
+				return theme{}, fmt.Errorf("theme %s: heatmap: %w", name, err)
+			}
+			t.Heatmap[i] = color
+		}
+	}
+	if settings.Palette != nil {
+		if len(settings.Palette) == 0 {
+			return theme{}, fmt.Errorf("theme %s: palette needs at least one colour", name)
+		}
+		palette := make([]lipgloss.TerminalColor, 0, len(settings.Palette))
+		for _, value := range settings.Palette {
+			color, err := parseColor(value)
+			if err != nil {
+				return theme{}, fmt.Errorf("theme %s: palette: %w", name, err)
+			}
+			palette = append(palette, color)
+		}
+		t.Palette = palette
+	}
+	return t, nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseColor reads an ANSI colour number from 0 to 255 or a #rgb or #rrggbb
+// hex colour.
+func parseColor(value string) (lipgloss.TerminalColor, error) {
+	value = strings.TrimSpace(value)
+	if hexColor.MatchString(value) {
+		return lipgloss.Color(value), nil
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(value), nil
+	}
+	return nil, fmt.Errorf("%q is not an ANSI colour from 0 to 255 or a hex colour such as #ff8700", value)
+}
+
+// SetTheme makes the theme called name, built in or one of themes, the
+// colours of the interactive UI. When the theme is unknown or invalid, the
+// error says so and the colours are left as they were. NO_COLOR turns the
+// colours off whatever the theme.
+func SetTheme(name string, themes map[string]data.ThemeSettings) error {
+	t, err := newTheme(name, themes)
+	if err != nil {
+		return err
+	}
+	if noColor() {
+		t = builtinThemes()["no-color"]
+	}
+	applyTheme(t)
+	return nil
+}
+
+// applyTheme builds every style of the interactive UI from t.
+func applyTheme(t theme) {
+	activeTheme = t
+	fg := func(c lipgloss.TerminalColor) lipgloss.Style { return lipgloss.NewStyle().Foreground(c) }
+
+	titleStyle = lipgloss.NewStyle().MarginLeft(2).Bold(true)
+	itemStyle = lipgloss.NewStyle().PaddingLeft(4)
+	projectActionStyle = fg(t.Muted).PaddingLeft(4)
+	projectShortcutStyle = fg(t.Accent)
+	projectLabelStyle = fg(t.Text)
+	selectedItemStyle = fg(t.Accent).PaddingLeft(2)
+	paginationStyle = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
+	helpStyle = list.DefaultStyles().HelpStyle.PaddingLeft(4).PaddingBottom(1).Foreground(t.Help)
+	inputPromptStyle = fg(t.Label).PaddingLeft(2)
+	errorStyle = fg(t.Error).PaddingLeft(2)
+	logHeaderStyle = fg(t.Heading).Bold(true)
+	logTotalStyle = fg(t.Total).Bold(true)
+	logEntryStyle = lipgloss.NewStyle()
+	logTitleStyle = fg(t.Title).Bold(true)
+	onClockStyle = fg(t.Running)
+	detailLabelStyle = fg(t.Label).Bold(true).PaddingLeft(2)
+	detailValueStyle = fg(t.Text).PaddingLeft(1)
+	detailSectionStyle = fg(t.Section).Bold(true).PaddingLeft(2)
+	detailRowStyle = detailValueStyle.PaddingLeft(2)
+	detailHighlightStyle = detailRowStyle.Foreground(t.Highlight).Bold(true)
+	columnStyle = lipgloss.NewStyle().Padding(0, 1)
+	dividerStyle = fg(t.Border)
+	reminderStyle = fg(t.Warning).Bold(true).PaddingLeft(2).MarginTop(1)
+	pomodoroStyle = fg(t.Pomodoro).Bold(true).PaddingLeft(2).MarginTop(1)
+
+	budgetNearStyle = fg(t.Warning)
+	budgetOverStyle = fg(t.Error).Bold(true)
+
+	heatmapShades = make([]lipgloss.Style, len(t.Heatmap))
+	for i, c := range t.Heatmap {
+		heatmapShades[i] = fg(c)
+	}
+	heatmapGlyphs = []string{"■", "■", "■", "■", "■"}
+	if t.plain {
+		heatmapGlyphs = []string{"·", "░", "▒", "▓", "█"}
+	}
+	heatmapCursorStyle = fg(t.Accent).Bold(true)
+	heatmapLabelStyle = fg(t.Faint)
+
+	timelineGapStyle = fg(t.Warning)
+	timelineOverlapStyle = fg(t.Error).Bold(true)
+}
+
+// focusStyle marks the form field that has the focus.
+func (t theme) focusStyle(s lipgloss.Style) lipgloss.Style {
+	return s.Foreground(t.Accent).Bold(true)
+}
+
+// projectColor is the timeline colour of the project with the given id.
+func (t theme) projectColor(id int64) lipgloss.TerminalColor {
+	return t.Palette[int(id)%len(t.Palette)]
+}
+
+// applyToList gives l the theme's title, pagination, filter and help styles.
+func (t theme) applyToList(l *list.Model) {
+	l.Styles.Title = titleStyle
+	l.Styles.PaginationStyle = paginationStyle
+	l.Styles.HelpStyle = helpStyle
+	l.Styles.FilterPrompt = l.Styles.FilterPrompt.Foreground(t.Accent)
+	l.Styles.FilterCursor = l.Styles.FilterCursor.Foreground(t.Accent)
+	l.Styles.ActivePaginationDot = l.Styles.ActivePaginationDot.Foreground(t.Text)
+	l.Styles.InactivePaginationDot = l.Styles.InactivePaginationDot.Foreground(t.Border)
+	l.Styles.NoItems = l.Styles.NoItems.Foreground(t.Help)
+	l.Styles.StatusBar = l.Styles.StatusBar.Foreground(t.Help)
+	l.Help.Styles.ShortKey = l.Help.Styles.ShortKey.Foreground(t.Muted)
+	l.Help.Styles.ShortDesc = l.Help.Styles.ShortDesc.Foreground(t.Help)
+	l.Help.Styles.ShortSeparator = l.Help.Styles.ShortSeparator.Foreground(t.Border)
+}
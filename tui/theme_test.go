@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+)
+
+func TestBuiltinThemesColourEveryRole(t *testing.T) {
+	for name, th := range builtinThemes() {
+		for _, role := range th.roles() {
+			if *role.color == nil {
+				t.Errorf("theme %s has no %s colour", name, role.name)
+			}
+		}
+		for i, c := range th.Heatmap {
+			if c == nil {
+				t.Errorf("theme %s has no heatmap shade %d", name, i)
+			}
+		}
+		if len(th.Palette) == 0 {
+			t.Errorf("theme %s has no palette", name)
+		}
+	}
+}
+
+func TestThemeSettingsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		theme    string
+		settings data.ThemeSettings
+		want     string
+	}{
+		{"unknown theme", "solarized", data.ThemeSettings{}, `unknown theme "solarized"`},
+		{"unknown base", "mine", data.ThemeSettings{Base: "sepia"}, `unknown base "sepia"`},
+		{"unknown role", "mine", data.ThemeSettings{Colors: map[string]string{"link": "33"}}, `unknown colour "link"`},
+		{"bad colour", "mine", data.ThemeSettings{Colors: map[string]string{"error": "red"}}, `error: "red" is not an ANSI colour`},
+		{"out of range", "mine", data.ThemeSettings{Colors: map[string]string{"accent": "256"}}, `"256" is not an ANSI colour`},
+		{"short heatmap", "mine", data.ThemeSettings{Heatmap: []string{"1", "2"}}, "heatmap needs 5 colours, got 2"},
+		{"empty palette", "mine", data.ThemeSettings{Palette: []string{}}, "palette needs at least one colour"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTheme(tc.theme, map[string]data.ThemeSettings{"mine": tc.settings})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+	if _, err := newTheme("dark", map[string]data.ThemeSettings{"Light": {}}); err == nil {
+		t.Fatal("expected a user theme named after a built-in one to be rejected")
+	}
+}
+
+func TestSetThemeAppliesUserTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	previous := activeTheme
+	t.Cleanup(func() { applyTheme(previous) })
+
+	if err := SetTheme("Mine", map[string]data.ThemeSettings{"mine": {
+		Base:    "light",
+		Colors:  map[string]string{"accent": "#ff8700", "error": "9"},
+		Palette: []string{"#abc"},
+	}}); err != nil {
+		t.Fatalf("set theme: %v", err)
+	}
+	if got := selectedItemStyle.GetForeground(); got != lipgloss.Color("#ff8700") {
+		t.Fatalf("expected the accent to colour the selection, got %v", got)
+	}
+	if got := budgetOverStyle.GetForeground(); got != lipgloss.Color("9") {
+		t.Fatalf("expected the error colour on spent budgets, got %v", got)
+	}
+	if got := logHeaderStyle.GetForeground(); got != builtinThemes()["light"].Heading {
+		t.Fatalf("expected the light theme's other colours, got %v", got)
+	}
+	if got := activeTheme.projectColor(7); got != lipgloss.Color("#abc") {
+		t.Fatalf("expected the palette to colour the timeline, got %v", got)
+	}
+
+	if err := SetTheme("sepia", nil); err == nil {
+		t.Fatal("expected an unknown theme to be rejected")
+	}
+	if got := selectedItemStyle.GetForeground(); got != lipgloss.Color("#ff8700") {
+		t.Fatal("expected a rejected theme to leave the one in use")
+	}
+}
+
+func TestNoColorTurnsColoursOff(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	previous := activeTheme
+	t.Cleanup(func() { applyTheme(previous) })
+
+	if err := SetTheme("high-contrast", nil); err != nil {
+		t.Fatalf("set theme: %v", err)
+	}
+	for _, style := range []lipgloss.Style{errorStyle, selectedItemStyle, heatmapShades[4], timelineGapStyle} {
+		if _, ok := style.GetForeground().(lipgloss.NoColor); !ok {
+			t.Fatalf("expected no colour under NO_COLOR, got %v", style.GetForeground())
+		}
+	}
+	var shades []string
+	for shade := range heatmapShades {
+		shades = append(shades, heatmapShadeView(shade))
+	}
+	if got := strings.Join(shades, ""); got != "·░▒▓█" {
+		t.Fatalf("expected the heatmap to tell shades apart by glyph, got %q", got)
+	}
+}
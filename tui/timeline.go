@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+const timelineLabelWidth = 22
+
+// TimelineUI opens the day timeline on day.
+func (a *app) TimelineUI(day time.Time) {
+	timeline, err := data.DB.DayTimeline(day)
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading timeline: %v", err)
+		return
+	}
+	a.timeline = timeline
+	if a.state != stateTimeline {
+		a.timelineReturn = a.state
+	}
+	a.state = stateTimeline
+}
+
+func (a *app) handleKeypressTimeline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.Back):
+		a.state = a.timelineReturn
+	case key.Matches(msg, keys.TimelinePrevious):
+		a.TimelineUI(a.timeline.Day.AddDate(0, 0, -1))
+	case key.Matches(msg, keys.TimelineNext):
+		a.TimelineUI(a.timeline.Day.AddDate(0, 0, 1))
+	case key.Matches(msg, keys.TimelineToday):
+		a.TimelineUI(time.Now())
+	}
+	return a, nil
+}
+
+// timelineHours picks the hours to draw: the working day, widened to fit every entry.
+func timelineHours(timeline *data.DayTimeline) (int, int) {
+	first, last := 8, 18
+	for _, entry := range timeline.Entries {
+		first = min(first, entry.Start.In(time.Local).Hour())
+		end := entry.End.Sub(timeline.Day)
+		last = max(last, int((end+time.Hour-1)/time.Hour))
+	}
+	return first, min(last, 24)
+}
+
+func (a app) timelineView() string {
+	timeline := a.timeline
+	if timeline == nil {
+		return errorStyle.Render("No timeline loaded")
+	}
+
+	width := a.width
+	if width <= 0 {
+		width = 80
+	}
+	firstHour, lastHour := timelineHours(timeline)
+	axisStart := timeline.Day.Add(time.Duration(firstHour) * time.Hour)
+	axisEnd := timeline.Day.Add(time.Duration(lastHour) * time.Hour)
+	columns := max(24, width-timelineLabelWidth-4)
+	perColumn := axisEnd.Sub(axisStart) / time.Duration(columns)
+	column := func(t time.Time) int {
+		return max(0, min(columns, int(t.Sub(axisStart)/perColumn)))
+	}
+	// bar draws spans on an empty track; every span gets at least one cell.
+	bar := func(spans []data.Span, glyph string, style lipgloss.Style) string {
+		cells := make([]string, columns)
+		for i := range cells {
+			cells[i] = " "
+		}
+		for _, span := range spans {
+			from, to := column(span.Start), column(span.End)
+			if to <= from {
+				to = min(from+1, columns)
+			}
+			for i := from; i < to; i++ {
+				cells[i] = style.Render(glyph)
+			}
+		}
+		return strings.Join(cells, "")
+	}
+
+	var gapTotal, overlapTotal time.Duration
+	for _, gap := range timeline.Gaps {
+		gapTotal += gap.Duration()
+	}
+	overlapSpans := make([]data.Span, 0, len(timeline.Overlaps))
+	for _, overlap := range timeline.Overlaps {
+		overlapTotal += overlap.Duration()
+		overlapSpans = append(overlapSpans, overlap.Span)
+	}
+
+	title := fmt.Sprintf("Timeline: %s", timeline.Day.Format("Monday 2006-01-02"))
+	lines := []string{
+		titleStyle.MarginTop(1).Render(title),
+		detailLine("Tracked:", fmt.Sprintf("%s  gaps %s  overlaps %s",
+			util.HmFromD(timeline.Tracked()), util.HmFromD(gapTotal), util.HmFromD(overlapTotal))),
+		"",
+	}
+
+	ruler := []rune(strings.Repeat(" ", columns+6))
+	step := 1
+	for (lastHour-firstHour)/step*6 > columns {
+		step++
+	}
+	for hour := firstHour; hour <= lastHour; hour += step {
+		label := fmt.Sprintf("%02d", hour%24)
+		pos := column(timeline.Day.Add(time.Duration(hour) * time.Hour))
+		copy(ruler[pos:], []rune(label))
+	}
+	lines = append(lines, heatmapLabelStyle.Render(strings.Repeat(" ", timelineLabelWidth+2)+strings.TrimRight(string(ruler), " ")))
+
+	if len(timeline.Entries) == 0 {
+		lines = append(lines, itemStyle.Render("Nothing tracked on this day."))
+	}
+	for _, entry := range timeline.Entries {
+		project := entry.Entry.Project
+		style := lipgloss.NewStyle().Foreground(activeTheme.projectColor(project.ID))
+		label := fmt.Sprintf("%-*s", timelineLabelWidth, truncateString(project.Path(), timelineLabelWidth))
+		lines = append(lines, "  "+projectLabelStyle.Render(label)+bar([]data.Span{entry.Span}, "█", style))
+	}
+	if len(timeline.Gaps) > 0 {
+		label := fmt.Sprintf("%-*s", timelineLabelWidth, "untracked")
+		lines = append(lines, "  "+timelineGapStyle.Render(label)+bar(timeline.Gaps, "░", timelineGapStyle))
+	}
+	if len(timeline.Overlaps) > 0 {
+		label := fmt.Sprintf("%-*s", timelineLabelWidth, "overlapping")
+		lines = append(lines, "  "+timelineOverlapStyle.Render(label)+bar(overlapSpans, "▓", timelineOverlapStyle))
+	}
+
+	clock := func(span data.Span) string {
+		return fmt.Sprintf("%s–%s (%s)", span.Start.In(time.Local).Format("15:04"), span.End.In(time.Local).Format("15:04"), util.HmFromD(span.Duration()))
+	}
+	if len(timeline.Gaps) > 0 || len(timeline.Overlaps) > 0 {
+		lines = append(lines, "")
+	}
+	for _, gap := range timeline.Gaps {
+		lines = append(lines, timelineGapStyle.PaddingLeft(2).Render("Gap      "+clock(gap)))
+	}
+	for _, overlap := range timeline.Overlaps {
+		names := make([]string, 0, len(overlap.Entries))
+		for _, entry := range overlap.Entries {
+			name := entry.Project.Path()
+			if content := oneLineContent(entry.GetContent()); content != "" {
+				name += ": " + truncateString(content, 30)
+			}
+			names = append(names, name)
+		}
+		lines = append(lines, timelineOverlapStyle.PaddingLeft(2).Render("Overlap  "+clock(overlap.Span)+"  "+strings.Join(names, " / ")))
+	}
+
+	lines = append(lines, "", helpView(keyGroup("previous/next day", keys.TimelinePrevious, keys.TimelineNext), keys.TimelineToday, keys.Back, keys.Quit))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func oneLineContent(content string) string {
+	return strings.Join(strings.Fields(content), " ")
+}
@@ -3,63 +3,75 @@ package tui
 import (
 	"fmt"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/git"
 )
 
 // when asking for stop message
 func (a *app) handleKeypressStoppingTimer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	if msg.Type == tea.KeySpace && a.stopEntryFocus == focusStopBillable {
+	if key.Matches(msg, keys.FormToggle) && a.stopEntryFocus == focusStopBillable {
 		a.stopBillable = !a.stopBillable
 		return a, textinput.Blink
 	}
+	if key.Matches(msg, keys.FormToggle) && a.stopEntryFocus == focusStopType {
+		a.stopType = a.stopType.Next()
+		return a, textinput.Blink
+	}
 
-	switch keypress := msg.String(); keypress {
-	case "ctrl+c":
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
 		return a, tea.Quit
-	case "enter", "tab", "shift+tab", "up", "down":
-		const stopFocusCount = 2
-		if keypress == "enter" && a.stopEntryFocus == focusStopMessage {
-			message := a.stopMessageInput.Value()
-			if a.project != nil {
-				if err := a.project.StopTimer(message, a.stopBillable); err != nil {
-					a.errorMessage = fmt.Sprintf("Error stopping timer: %v", err)
-				} else {
-					a.refreshEntryList()
-				}
-			}
-			a.state = stateProjectMenu
-			a.updateProjectSelectionFromList()
-			a.stopMessageInput.SetValue("")
-			a.stopMessageInput.Blur()
-			a.stopBillable = true
+	case key.Matches(msg, keys.FormCommits):
+		if len(a.stopSuggestions) > 0 {
+			a.stopMessageInput.SetValue(git.Description(a.stopSuggestions))
+			a.stopMessageInput.CursorEnd()
 			a.stopEntryFocus = focusStopMessage
-			return a, tea.ClearScreen
+			a.stopMessageInput.Focus()
 		}
-
-		var delta int
-		switch keypress {
-		case "tab", "enter", "down":
-			delta = 1
-		case "shift+tab", "up":
+		return a, textinput.Blink
+	case key.Matches(msg, keys.FormSubmit) && a.stopEntryFocus == focusStopMessage:
+		message := a.stopMessageInput.Value()
+		if a.project != nil {
+			if entry, err := a.project.StopTimer(message, a.stopBillable, a.stopType); err != nil {
+				a.errorMessage = fmt.Sprintf("Error stopping timer: %v", err)
+			} else {
+				a.refreshEntryList()
+				a.errorMessage = overlapWarning(entry)
+			}
+		}
+		a.state = stateProjectMenu
+		a.updateProjectSelectionFromList()
+		a.stopMessageInput.SetValue("")
+		a.stopMessageInput.Blur()
+		a.stopBillable = true
+		a.stopType = data.EntryTypeWork
+		a.stopEntryFocus = focusStopMessage
+		a.stopSuggestions = nil
+		return a, tea.ClearScreen
+	case key.Matches(msg, keys.FormSubmit, keys.FormNext, keys.FormPrevious):
+		// Enter on the other fields moves on, like tab.
+		delta := 1
+		if key.Matches(msg, keys.FormPrevious) {
 			delta = -1
-		default:
-			return a, nil
 		}
-
-		a.stopEntryFocus = stopFocus((int(a.stopEntryFocus) + delta + stopFocusCount) % stopFocusCount)
+		a.stopEntryFocus = (a.stopEntryFocus + stopFocus(delta) + stopFocusCount) % stopFocusCount
 		if a.stopEntryFocus == focusStopMessage {
 			a.stopMessageInput.Focus()
 		} else {
 			a.stopMessageInput.Blur()
 		}
 		return a, textinput.Blink
-	case "esc":
+	case key.Matches(msg, keys.FormCancel):
 		a.state = stateProjectMenu
 		a.stopMessageInput.SetValue("")
 		a.stopMessageInput.Blur()
 		a.stopBillable = true
+		a.stopType = data.EntryTypeWork
 		a.stopEntryFocus = focusStopMessage
+		a.stopSuggestions = nil
 		return a, tea.ClearScreen
 	}
 
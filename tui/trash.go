@@ -0,0 +1,236 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nexneo/samay/data"
+)
+
+// trashItem wraps either a deleted project or a deleted entry.
+type trashItem struct {
+	project *data.Project
+	entry   *data.Entry
+}
+
+func (i trashItem) FilterValue() string {
+	if i.project != nil {
+		return strings.ToLower(i.project.GetName())
+	}
+	return strings.ToLower(i.entry.GetContent())
+}
+
+func (i trashItem) label() string {
+	if i.project != nil {
+		return fmt.Sprintf("project '%s'", i.project.Path())
+	}
+	return fmt.Sprintf("entry '%s'", truncateString(i.entry.GetContent(), 40))
+}
+
+type trashItemDelegate struct{}
+
+func (d trashItemDelegate) Height() int                             { return 1 }
+func (d trashItemDelegate) Spacing() int                            { return 0 }
+func (d trashItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d trashItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	it, ok := listItem.(trashItem)
+	if !ok {
+		return
+	}
+
+	var kind, name string
+	var deletedAt *time.Time
+	if it.project != nil {
+		kind = "project"
+		name = it.project.Path()
+		deletedAt = it.project.DeletedAt
+	} else if it.entry != nil {
+		kind = "entry"
+		desc := strings.TrimSpace(strings.ReplaceAll(it.entry.GetContent(), "\n", " "))
+		if desc == "" {
+			desc = "(no description)"
+		}
+		name = fmt.Sprintf("%s: %s", it.entry.Project.Path(), truncateString(desc, 50))
+		deletedAt = it.entry.DeletedAt
+	} else {
+		return
+	}
+
+	deleted := ""
+	if deletedAt != nil {
+		deleted = deletedAt.In(time.Local).Format("Jan 02 15:04")
+	}
+	line := fmt.Sprintf("%-8s %-12s %s", kind, deleted, name)
+
+	if index == m.Index() {
+		_, _ = fmt.Fprint(w, selectedItemStyle.PaddingLeft(4).Render(line))
+		return
+	}
+	_, _ = fmt.Fprint(w, itemStyle.Render(line))
+}
+
+func (a *app) refreshTrashList() error {
+	projects, err := data.DB.DeletedProjects()
+	if err != nil {
+		return err
+	}
+	entries, err := data.DB.DeletedEntries()
+	if err != nil {
+		return err
+	}
+
+	items := make([]list.Item, 0, len(projects)+len(entries))
+	for _, p := range projects {
+		items = append(items, trashItem{project: p})
+	}
+	for _, e := range entries {
+		items = append(items, trashItem{entry: e})
+	}
+
+	width := a.width
+	if width == 0 {
+		width = 80
+	}
+	height := a.height - 6
+	if height < 10 {
+		height = 10
+	}
+	index := a.trash.Index()
+	l := list.New(items, trashItemDelegate{}, width, height)
+	l.Title = ""
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(true)
+	activeTheme.applyToList(&l)
+	keys.applyToList(&l)
+	if index < len(items) {
+		l.Select(index)
+	}
+	a.trash = l
+	return nil
+}
+
+// TrashUI opens the trash bin listing deleted projects and entries.
+func (a *app) TrashUI() {
+	if err := a.refreshTrashList(); err != nil {
+		a.errorMessage = fmt.Sprintf("Error loading trash: %v", err)
+		return
+	}
+	if a.state != stateTrash && a.state != stateConfirm {
+		a.previousState = a.state
+	}
+	a.state = stateTrash
+}
+
+func (a *app) restoreTrashItem(it trashItem) {
+	var err error
+	if it.project != nil {
+		err = it.project.Restore()
+	} else if it.entry != nil {
+		err = it.entry.Restore(context.Background())
+	}
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error restoring: %v", err)
+		return
+	}
+	a.refreshProjectList()
+	a.state = stateTrash
+	a.TrashUI()
+	a.errorMessage = fmt.Sprintf("Restored %s", it.label())
+}
+
+// PurgeTrashUI permanently deletes the confirmed trash item, or everything when emptying the trash.
+func (a *app) PurgeTrashUI() {
+	var err error
+	var message string
+	switch a.confirmAction {
+	case confirmPurgeTrashItem:
+		it := a.confirmTrash
+		if it.project != nil {
+			err = it.project.Purge()
+		} else if it.entry != nil {
+			err = it.entry.Purge(context.Background())
+		}
+		message = fmt.Sprintf("Permanently deleted %s", it.label())
+	case confirmEmptyTrash:
+		err = data.DB.EmptyTrash()
+		message = "Trash emptied"
+	}
+
+	a.confirmAction = confirmNone
+	a.confirmTrash = trashItem{}
+	a.confirmMessage = ""
+	a.state = stateTrash
+	if err != nil {
+		a.errorMessage = fmt.Sprintf("Error purging: %v", err)
+		return
+	}
+	a.TrashUI()
+	a.refreshUndoHint()
+	a.errorMessage = message
+}
+
+func (a *app) handleKeypressTrash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.trash.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		a.trash, cmd = a.trash.Update(msg)
+		return a, cmd
+	}
+
+	switch {
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return a, tea.Quit
+	case key.Matches(msg, keys.Back):
+		a.state = a.previousState
+		if a.state == stateTrash || a.state == stateConfirm {
+			a.state = stateProjectList
+		}
+		a.updateProjectSelectionFromList()
+		return a, nil
+	case key.Matches(msg, keys.TrashRestore):
+		if it, ok := a.trash.SelectedItem().(trashItem); ok {
+			a.restoreTrashItem(it)
+		}
+		return a, nil
+	case key.Matches(msg, keys.TrashPurge):
+		if it, ok := a.trash.SelectedItem().(trashItem); ok {
+			a.confirmTrash = it
+			a.confirmAction = confirmPurgeTrashItem
+			a.confirmMessage = fmt.Sprintf("Permanently delete %s? This cannot be undone.", it.label())
+			a.state = stateConfirm
+		}
+		return a, nil
+	case key.Matches(msg, keys.TrashEmpty):
+		if len(a.trash.Items()) == 0 {
+			a.errorMessage = "Trash is already empty."
+			return a, nil
+		}
+		a.confirmAction = confirmEmptyTrash
+		a.confirmMessage = fmt.Sprintf("Permanently delete all %d items in the trash? This cannot be undone.", len(a.trash.Items()))
+		a.state = stateConfirm
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.trash, cmd = a.trash.Update(msg)
+	return a, cmd
+}
+
+func (a app) trashView() string {
+	lines := []string{titleStyle.MarginTop(1).Render("Trash")}
+	if len(a.trash.Items()) == 0 {
+		lines = append(lines, "", itemStyle.Render("The trash is empty."), "")
+	} else {
+		lines = append(lines, a.trash.View())
+	}
+	lines = append(lines, helpView(keys.TrashRestore, keys.TrashPurge, keys.TrashEmpty, describe(a.trash.KeyMap.Filter, "filter"), keys.Back, keys.Quit))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,327 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval is a length of time typed by a user, optionally pinned to a start and end.
+type Interval struct {
+	Start  time.Time // zero when only a length was given
+	End    time.Time
+	Length time.Duration
+}
+
+// HasTimes reports whether the interval was given as a time range rather than a bare length.
+func (i Interval) HasTimes() bool {
+	return !i.Start.IsZero()
+}
+
+var durationUnits = map[string]time.Duration{
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+}
+
+var (
+	durationPart = regexp.MustCompile(`^(\d+(?:\.\d+)?|\.\d+)\s*([a-z]+)\s*`)
+	clockPattern = `(?:\d{1,2}(?::\d{2})?\s*(?:am|pm|a\.m\.|p\.m\.)?|noon|midnight)`
+	clockOnly    = regexp.MustCompile(`^` + clockPattern + `$`)
+	clockRange   = regexp.MustCompile(`^(` + clockPattern + `)\s*(?:-|–|—|\bto\b|\buntil\b|\btill\b)\s*(` + clockPattern + `)$`)
+	clockParts   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm|a\.m\.|p\.m\.)?$`)
+	daysAgo      = regexp.MustCompile(`^(\d+)\s+days?\s+ago$`)
+)
+
+// ParseDuration reads a length of time the way people type it: "90" (minutes),
+// "1.5h", "1h30m", "1h 30m", "1:30", "2 hours", "45 mins" or "1 hour and 15 minutes".
+func ParseDuration(s string) (time.Duration, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	if text == "" {
+		return 0, errors.New("duration is empty: try 1h30m, 1.5h, 1:30 or 90 (minutes)")
+	}
+
+	var d time.Duration
+	switch {
+	case isDigits(text):
+		minutes, _ := strconv.Atoi(text)
+		d = time.Duration(minutes) * time.Minute
+	case strings.Count(text, ":") == 1:
+		hours, minutes, _ := strings.Cut(text, ":")
+		h, herr := strconv.Atoi(hours)
+		m, merr := strconv.Atoi(minutes)
+		if herr != nil || merr != nil || len(minutes) != 2 || m >= 60 || h < 0 {
+			return 0, fmt.Errorf("cannot read %q as hours:minutes; try 1:30", s)
+		}
+		d = time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	default:
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return 0, fmt.Errorf("%q has no unit: write %sh for hours or a whole number of minutes", s, text)
+		}
+		rest := strings.NewReplacer(",", " ", " and ", " ").Replace(text)
+		for rest != "" {
+			match := durationPart.FindStringSubmatch(rest)
+			if match == nil {
+				return 0, fmt.Errorf("cannot read %q as a duration: try 1h30m, 1.5h, 1:30, 2 hours or 90 (minutes)", s)
+			}
+			unit, ok := durationUnits[match[2]]
+			if !ok {
+				return 0, fmt.Errorf("unknown unit %q in %q: use h, m or s, or hours, minutes or seconds", match[2], s)
+			}
+			amount, _ := strconv.ParseFloat(match[1], 64)
+			d += time.Duration(math.Round(amount * float64(unit)))
+			rest = strings.TrimSpace(rest[len(match[0]):])
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be longer than zero", s)
+	}
+	return d, nil
+}
+
+// ParseInterval reads either a duration (see ParseDuration) or a time range such as
+// "9:00-10:45", "9-10:30am", "yesterday 3pm to 5pm", "monday 14:00-15:30" or
+// "2024-05-12 9:00 to 12:00". Days and times are read relative to now, in now's
+// location; a range that ends before it starts runs past midnight.
+func ParseInterval(s string, now time.Time) (Interval, error) {
+	text := normalizeTimeText(s)
+	if text == "" {
+		return Interval{}, errors.New("enter a duration such as 1h30m or a time range such as 9:00-10:45")
+	}
+
+	day, rest, hasDay := splitDay(text, now)
+	if match := clockRange.FindStringSubmatch(rest); match != nil {
+		start, end, err := clockRangeOn(day, match[1], match[2])
+		if err != nil {
+			return Interval{}, fmt.Errorf("%q: %w", s, err)
+		}
+		return Interval{Start: start, End: end, Length: end.Sub(start)}, nil
+	}
+	if hasDay {
+		return Interval{}, fmt.Errorf("%q: give the time range on that day, such as 3pm to 5pm", s)
+	}
+
+	d, err := ParseDuration(s)
+	if err != nil {
+		if strings.ContainsAny(text, "-–") || strings.Contains(text, " to ") {
+			return Interval{}, fmt.Errorf("cannot read %q as a time range: try 9:00-10:45 or \"yesterday 3pm to 5pm\"", s)
+		}
+		return Interval{}, err
+	}
+	return Interval{Length: d}, nil
+}
+
+// ParseTime reads a point in time: "now", a clock time such as "14:30" or "3pm"
+// (today), a day such as "yesterday", "monday", "3 days ago" or "2024-05-12"
+// (at midnight), or a day and a clock time such as "yesterday 3pm".
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	t, _, err := parseTime(s, now)
+	return t, err
+}
+
+// ParseIntervalAt reads spec as ParseInterval does and places it in time using when,
+// which may be empty (now), a day such as "yesterday" or "2024-05-12", or a start
+// time such as "yesterday 9am". A time range lands on when's day. A bare duration
+// starts at when's clock time when it has one and otherwise ends on when's day at
+// the current time of day, the way it would have been logged that day.
+func ParseIntervalAt(when, spec string, now time.Time) (Interval, error) {
+	reference, hasClock := now, false
+	if strings.TrimSpace(when) != "" {
+		t, clock, err := parseTime(when, now)
+		if err != nil {
+			return Interval{}, err
+		}
+		reference, hasClock = t, clock
+		if !clock {
+			reference = atClock(t, now.Hour(), now.Minute()).Add(time.Duration(now.Second()) * time.Second)
+		}
+	}
+
+	interval, err := ParseInterval(spec, reference)
+	if err != nil {
+		return Interval{}, err
+	}
+	switch {
+	case interval.HasTimes() && hasClock:
+		return Interval{}, fmt.Errorf("%q already says when it ran; give a day such as yesterday instead of %q", spec, when)
+	case interval.HasTimes():
+		return interval, nil
+	case hasClock:
+		interval.Start = reference
+		interval.End = reference.Add(interval.Length)
+	default:
+		interval.End = reference
+		interval.Start = reference.Add(-interval.Length)
+	}
+	return interval, nil
+}
+
+// parseTime is ParseTime, also reporting whether s named a clock time rather than
+// a day or "now".
+func parseTime(s string, now time.Time) (time.Time, bool, error) {
+	text := normalizeTimeText(s)
+	if text == "now" {
+		return now, false, nil
+	}
+	if text == "" {
+		return time.Time{}, false, errors.New("enter a time such as 14:30, yesterday 3pm or 2024-05-12 9:00")
+	}
+	day, rest, hasDay := splitDay(text, now)
+	if rest == "" && hasDay {
+		return day, false, nil
+	}
+	if !clockOnly.MatchString(rest) {
+		return time.Time{}, false, fmt.Errorf("cannot read %q as a time: try 14:30, 3pm, yesterday 3pm or 2024-05-12 9:00", s)
+	}
+	hour, minute, meridiem, err := parseClock(rest)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q: %w", s, err)
+	}
+	if meridiem != "" {
+		hour = applyMeridiem(hour, meridiem)
+	}
+	return atClock(day, hour, minute), true, nil
+}
+
+func normalizeTimeText(s string) string {
+	text := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return strings.TrimPrefix(text, "on ")
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWeekday reads a day of the week such as "monday", "Mon" or "thurs".
+func ParseWeekday(s string) (time.Weekday, bool) {
+	weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]
+	return weekday, ok
+}
+
+// splitDay finds a day at the start or end of text and returns its midnight along
+// with the rest of the text. Without one, the day is today.
+func splitDay(text string, now time.Time) (time.Time, string, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	words := strings.Fields(text)
+	// Try the longest phrase first so "3 days ago" wins over "ago".
+	for n := min(3, len(words)); n >= 1; n-- {
+		if day, ok := parseDay(strings.Join(words[:n], " "), today); ok {
+			return day, strings.TrimPrefix(strings.Join(words[n:], " "), "at "), true
+		}
+		if day, ok := parseDay(strings.Join(words[len(words)-n:], " "), today); ok {
+			return day, strings.TrimSuffix(strings.Join(words[:len(words)-n], " "), " on"), true
+		}
+	}
+	return today, text, false
+}
+
+func parseDay(phrase string, today time.Time) (time.Time, bool) {
+	switch phrase {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+	if match := daysAgo.FindStringSubmatch(phrase); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		return today.AddDate(0, 0, -n), true
+	}
+	if date, err := time.ParseInLocation("2006-01-02", phrase, today.Location()); err == nil {
+		return date, true
+	}
+	last := strings.HasPrefix(phrase, "last ")
+	if weekday, ok := weekdays[strings.TrimPrefix(phrase, "last ")]; ok {
+		back := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if back == 0 && last {
+			back = 7
+		}
+		return today.AddDate(0, 0, -back), true
+	}
+	return time.Time{}, false
+}
+
+// parseClock splits a clock time such as "9", "09:30", "3pm" or "noon" into its parts.
+func parseClock(s string) (hour, minute int, meridiem string, err error) {
+	switch s {
+	case "noon":
+		return 12, 0, "", nil
+	case "midnight":
+		return 0, 0, "", nil
+	}
+	match := clockParts.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, 0, "", fmt.Errorf("cannot read %q as a time of day", s)
+	}
+	hour, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	meridiem = strings.ReplaceAll(match[3], ".", "")
+	if minute >= 60 || hour > 23 || (meridiem != "" && (hour == 0 || hour > 12)) {
+		return 0, 0, "", fmt.Errorf("%q is not a time of day", s)
+	}
+	return hour, minute, meridiem, nil
+}
+
+func applyMeridiem(hour int, meridiem string) int {
+	hour %= 12
+	if meridiem == "pm" {
+		hour += 12
+	}
+	return hour
+}
+
+// clockRangeOn resolves a range of two clock times on day. A start without am/pm
+// borrows the end's when that keeps it before the end, so "3-5pm" is 15:00-17:00.
+func clockRangeOn(day time.Time, from, to string) (time.Time, time.Time, error) {
+	startHour, startMinute, startMeridiem, err := parseClock(from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endHour, endMinute, endMeridiem, err := parseClock(to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if endMeridiem != "" {
+		endHour = applyMeridiem(endHour, endMeridiem)
+	}
+	switch {
+	case startMeridiem != "":
+		startHour = applyMeridiem(startHour, startMeridiem)
+	case endMeridiem != "" && startHour <= 12:
+		if borrowed := applyMeridiem(startHour, endMeridiem); borrowed*60+startMinute <= endHour*60+endMinute {
+			startHour = borrowed
+		}
+	}
+	start := atClock(day, startHour, startMinute)
+	end := atClock(day, endHour, endMinute)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+func atClock(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
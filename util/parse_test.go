@@ -0,0 +1,211 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{in: "90", want: 90 * time.Minute},
+		{in: "1.5h", want: 90 * time.Minute},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "1h 30m", want: 90 * time.Minute},
+		{in: "1:30", want: 90 * time.Minute},
+		{in: "0:45", want: 45 * time.Minute},
+		{in: "2 hours", want: 2 * time.Hour},
+		{in: "1 hour and 15 minutes", want: 75 * time.Minute},
+		{in: "1 hr, 5 mins", want: 65 * time.Minute},
+		{in: "45 MIN", want: 45 * time.Minute},
+		{in: ".5h", want: 30 * time.Minute},
+		{in: "90s", want: 90 * time.Second},
+		{in: "  20m  ", want: 20 * time.Minute},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseDuration(tc.in)
+			if err != nil || got != tc.want {
+				t.Fatalf("ParseDuration(%q) = %v, %v; want %v", tc.in, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseDurationErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		message string
+	}{
+		{in: "", message: "empty"},
+		{in: "1.5", message: "no unit"},
+		{in: "0", message: "longer than zero"},
+		{in: "0h", message: "longer than zero"},
+		{in: "1:75", message: "hours:minutes"},
+		{in: "1:5", message: "hours:minutes"},
+		{in: "3 days", message: "unknown unit"},
+		{in: "soon", message: "cannot read"},
+		{in: "-1h", message: "cannot read"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			_, err := ParseDuration(tc.in)
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Fatalf("ParseDuration(%q) error = %v, want one mentioning %q", tc.in, err, tc.message)
+			}
+		})
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	// A Wednesday afternoon.
+	now := time.Date(2024, time.May, 15, 16, 20, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.May, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		in         string
+		start, end time.Time
+		length     time.Duration
+	}{
+		{in: "1h30m", length: 90 * time.Minute},
+		{in: "90", length: 90 * time.Minute},
+		{in: "9:00-10:45", start: at(15, 9, 0), end: at(15, 10, 45)},
+		{in: "9:00 – 10:45", start: at(15, 9, 0), end: at(15, 10, 45)},
+		{in: "9 to 11", start: at(15, 9, 0), end: at(15, 11, 0)},
+		{in: "3-5pm", start: at(15, 15, 0), end: at(15, 17, 0)},
+		{in: "11-1pm", start: at(15, 11, 0), end: at(15, 13, 0)},
+		{in: "11am-1pm", start: at(15, 11, 0), end: at(15, 13, 0)},
+		{in: "yesterday 3pm to 5pm", start: at(14, 15, 0), end: at(14, 17, 0)},
+		{in: "3pm to 5pm yesterday", start: at(14, 15, 0), end: at(14, 17, 0)},
+		{in: "on monday 14:00-15:30", start: at(13, 14, 0), end: at(13, 15, 30)},
+		{in: "last wednesday 9-10", start: at(8, 9, 0), end: at(8, 10, 0)},
+		{in: "wednesday 9-10", start: at(15, 9, 0), end: at(15, 10, 0)},
+		{in: "2 days ago 10:00 until noon", start: at(13, 10, 0), end: at(13, 12, 0)},
+		{in: "2024-05-01 9:00 to 12:00", start: at(1, 9, 0), end: at(1, 12, 0)},
+		{in: "22:00-1:00", start: at(15, 22, 0), end: at(16, 1, 0)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseInterval(tc.in, now)
+			if err != nil {
+				t.Fatalf("ParseInterval(%q): %v", tc.in, err)
+			}
+			if tc.start.IsZero() {
+				if got.HasTimes() || got.Length != tc.length {
+					t.Fatalf("ParseInterval(%q) = %+v, want a bare %v", tc.in, got, tc.length)
+				}
+				return
+			}
+			if !got.HasTimes() || !got.Start.Equal(tc.start) || !got.End.Equal(tc.end) || got.Length != tc.end.Sub(tc.start) {
+				t.Fatalf("ParseInterval(%q) = %v–%v (%v), want %v–%v", tc.in, got.Start, got.End, got.Length, tc.start, tc.end)
+			}
+		})
+	}
+}
+
+func TestParseIntervalErrors(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 16, 20, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		message string
+	}{
+		{in: "", message: "enter a duration"},
+		{in: "yesterday", message: "time range on that day"},
+		{in: "9:00-25:00", message: "not a time of day"},
+		{in: "13pm-2pm", message: "not a time of day"},
+		{in: "a while", message: "cannot read"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			_, err := ParseInterval(tc.in, now)
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Fatalf("ParseInterval(%q) error = %v, want one mentioning %q", tc.in, err, tc.message)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 16, 20, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.May, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{in: "now", want: now},
+		{in: "14:30", want: at(15, 14, 30)},
+		{in: "9", want: at(15, 9, 0)},
+		{in: "3pm", want: at(15, 15, 0)},
+		{in: "3:30 PM", want: at(15, 15, 30)},
+		{in: "12am", want: at(15, 0, 0)},
+		{in: "12pm", want: at(15, 12, 0)},
+		{in: "noon", want: at(15, 12, 0)},
+		{in: "yesterday", want: at(14, 0, 0)},
+		{in: "yesterday 3pm", want: at(14, 15, 0)},
+		{in: "yesterday at 3pm", want: at(14, 15, 0)},
+		{in: "3pm yesterday", want: at(14, 15, 0)},
+		{in: "tomorrow 9:15", want: at(16, 9, 15)},
+		{in: "fri 10am", want: at(10, 10, 0)},
+		{in: "last wed", want: at(8, 0, 0)},
+		{in: "3 days ago", want: at(12, 0, 0)},
+		{in: "1 day ago 8:00", want: at(14, 8, 0)},
+		{in: "2024-05-01 9:00", want: at(1, 9, 0)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseTime(tc.in, now)
+			if err != nil || !got.Equal(tc.want) {
+				t.Fatalf("ParseTime(%q) = %v, %v; want %v", tc.in, got, err, tc.want)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "later", "25:00", "9:61", "0pm", "yesterday soon"} {
+		if _, err := ParseTime(in, now); err == nil {
+			t.Fatalf("expected ParseTime(%q) to fail", in)
+		}
+	}
+}
+
+func TestParseIntervalAt(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 16, 20, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.May, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		when, spec string
+		start, end time.Time
+	}{
+		{when: "", spec: "1h", start: at(15, 15, 20), end: at(15, 16, 20)},
+		{when: "now", spec: "30m", start: at(15, 15, 50), end: at(15, 16, 20)},
+		{when: "yesterday", spec: "2h", start: at(14, 14, 20), end: at(14, 16, 20)},
+		{when: "yesterday 9am", spec: "1:30", start: at(14, 9, 0), end: at(14, 10, 30)},
+		{when: "2024-05-01", spec: "9:00-10:45", start: at(1, 9, 0), end: at(1, 10, 45)},
+		{when: "", spec: "yesterday 3pm to 5pm", start: at(14, 15, 0), end: at(14, 17, 0)},
+		{when: "14:00", spec: "45m", start: at(15, 14, 0), end: at(15, 14, 45)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.when+" "+tc.spec, func(t *testing.T) {
+			got, err := ParseIntervalAt(tc.when, tc.spec, now)
+			if err != nil {
+				t.Fatalf("ParseIntervalAt(%q, %q): %v", tc.when, tc.spec, err)
+			}
+			if !got.Start.Equal(tc.start) || !got.End.Equal(tc.end) || got.Length != tc.end.Sub(tc.start) {
+				t.Fatalf("ParseIntervalAt(%q, %q) = %v–%v, want %v–%v", tc.when, tc.spec, got.Start, got.End, tc.start, tc.end)
+			}
+		})
+	}
+
+	if _, err := ParseIntervalAt("yesterday 9am", "9-10", now); err == nil || !strings.Contains(err.Error(), "already says when") {
+		t.Fatalf("expected a start time and a range together to fail, got %v", err)
+	}
+	if _, err := ParseIntervalAt("someday", "1h", now); err == nil {
+		t.Fatalf("expected an unreadable date to fail")
+	}
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+func entryPath(entry *data.Entry) string {
+	return "/entries/" + entry.ID
+}
+
+func (s *Server) entry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.db.Entry(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	// Only other projects are offered as move targets.
+	var targets []*data.ProjectNode
+	data.WalkProjectTree(s.db.ProjectTree(), func(node *data.ProjectNode) bool {
+		if node.Project.ID != entry.ProjectID {
+			targets = append(targets, node)
+		}
+		return true
+	})
+	var creator string
+	if entry.CreatorID != nil {
+		if person, err := s.db.Person(*entry.CreatorID); err == nil {
+			creator = person.String()
+		}
+	}
+	s.render(w, "entry.html", struct {
+		layout
+		Entry   *data.Entry
+		Creator string
+		Targets []*data.ProjectNode
+	}{s.newLayout(r, "Entry"), entry, creator, targets})
+}
+
+// editEntry saves the edit form. A new duration keeps the start time and moves
+// the end time with it.
+func (s *Server) editEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.db.Entry(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	description, billable, entryType, err := entryForm(r)
+	if err != nil {
+		failTo(w, r, entryPath(entry), err)
+		return
+	}
+	if spec := strings.TrimSpace(r.FormValue("duration")); spec != "" {
+		d, err := util.ParseDuration(spec)
+		if err != nil {
+			failTo(w, r, entryPath(entry), err)
+			return
+		}
+		if d <= 0 {
+			failTo(w, r, entryPath(entry), errors.New("duration must be positive"))
+			return
+		}
+		entry.DurationMs = d.Milliseconds()
+		if entry.StartedAt != nil {
+			ended := entry.StartedAt.Add(d)
+			entry.EndedAt = &ended
+		}
+	}
+	entry.SetContent(description)
+	entry.Billable = billable
+	entry.Type = entryType
+	if err := entry.Update(r.Context()); err != nil {
+		failTo(w, r, entryPath(entry), err)
+		return
+	}
+	redirect(w, r, entryPath(entry), "Saved")
+}
+
+func (s *Server) moveEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.db.Entry(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	id, err := strconv.ParseInt(r.FormValue("project"), 10, 64)
+	if err != nil {
+		failTo(w, r, entryPath(entry), errors.New("choose a project to move the entry to"))
+		return
+	}
+	target, err := s.db.Project(id)
+	if err != nil || target.DeletedAt != nil {
+		failTo(w, r, entryPath(entry), data.ErrProjectNotFound)
+		return
+	}
+	if target.ID != entry.ProjectID {
+		if err := entry.MoveTo(r.Context(), target); err != nil {
+			failTo(w, r, entryPath(entry), err)
+			return
+		}
+	}
+	redirect(w, r, entryPath(entry), "Moved to "+target.Path())
+}
+
+func (s *Server) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.db.Entry(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err := entry.Delete(r.Context()); err != nil {
+		failTo(w, r, entryPath(entry), err)
+		return
+	}
+	redirect(w, r, "/projects/"+strconv.FormatInt(entry.ProjectID, 10), "Deleted entry")
+}
@@ -0,0 +1,257 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+)
+
+// entriesPerPage is how many entries a project page lists at a time.
+const entriesPerPage = 50
+
+// projectRow is a project as listed on the index page.
+type projectRow struct {
+	Node    *data.ProjectNode
+	Running *time.Time
+	Week    time.Duration
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	tree := s.db.ProjectTree()
+	today := data.StartOfDay(time.Now())
+	entries, err := s.db.EntriesInRange(today.AddDate(0, 0, -6), today.AddDate(0, 0, 1), data.EntryFilter{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	week := make(map[int64]time.Duration)
+	for _, entry := range entries {
+		week[entry.ProjectID] += duration(entry)
+	}
+
+	var rows []projectRow
+	data.WalkProjectTree(tree, func(node *data.ProjectNode) bool {
+		row := projectRow{Node: node, Week: week[node.Project.ID]}
+		if onClock, timer := node.Project.OnClock(); onClock {
+			started := timer.StartedTime()
+			row.Running = &started
+		}
+		rows = append(rows, row)
+		return true
+	})
+	s.render(w, "index.html", struct {
+		layout
+		Projects []projectRow
+	}{s.newLayout(r, "Projects"), rows})
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		failTo(w, r, "/", errors.New("a project needs a name"))
+		return
+	}
+	project, err := s.db.CreateProject(name)
+	if err != nil {
+		failTo(w, r, "/", err)
+		return
+	}
+	redirect(w, r, projectPath(project), "Created "+project.Path())
+}
+
+// project resolves the project named by the id path value.
+func (s *Server) project(r *http.Request) (*data.Project, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", data.ErrProjectNotFound, r.PathValue("id"))
+	}
+	project, err := s.db.Project(id)
+	if err != nil || project.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: %d", data.ErrProjectNotFound, id)
+	}
+	return project, nil
+}
+
+func projectPath(project *data.Project) string {
+	return fmt.Sprintf("/projects/%d", project.ID)
+}
+
+func (s *Server) projectPage(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	entries := matching(project.Entries(), query)
+	var total time.Duration
+	for _, entry := range entries {
+		total += duration(entry)
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pages := max(1, (len(entries)+entriesPerPage-1)/entriesPerPage)
+	page = min(max(page, 1), pages)
+	shown := entries[(page-1)*entriesPerPage : min(page*entriesPerPage, len(entries))]
+
+	var running *time.Time
+	if onClock, timer := project.OnClock(); onClock {
+		started := timer.StartedTime()
+		running = &started
+	}
+	s.render(w, "project.html", struct {
+		layout
+		Project *data.Project
+		Running *time.Time
+		Entries []*data.Entry
+		Matched int
+		Total   time.Duration
+		Query   string
+		Page    int
+		Pages   int
+	}{s.newLayout(r, project.Path()), project, running, shown, len(entries), total, query, page, pages})
+}
+
+// matching keeps the entries whose description contains query. A query
+// starting with # matches a tag instead.
+func matching(entries []*data.Entry, query string) []*data.Entry {
+	if query == "" {
+		return entries
+	}
+	kept := make([]*data.Entry, 0, len(entries))
+	if tag, ok := strings.CutPrefix(query, "#"); ok {
+		for _, entry := range entries {
+			for _, t := range entry.GetTags() {
+				if strings.EqualFold(t, tag) {
+					kept = append(kept, entry)
+					break
+				}
+			}
+		}
+		return kept
+	}
+	query = strings.ToLower(query)
+	for _, entry := range entries {
+		if strings.Contains(strings.ToLower(entry.GetContent()), query) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// entryForm reads the description, billable and type fields shared by the
+// stop, manual entry and edit forms.
+func entryForm(r *http.Request) (string, bool, data.EntryType, error) {
+	entryType, err := data.ParseEntryType(r.FormValue("type"))
+	return strings.TrimSpace(r.FormValue("description")), r.FormValue("billable") != "", entryType, err
+}
+
+func (s *Server) startTimer(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if onClock, _ := project.OnClock(); onClock {
+		failTo(w, r, projectPath(project), errors.New("a timer is already running for this project"))
+		return
+	}
+	if err := project.StartTimer(); err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	redirect(w, r, projectPath(project), "Timer started")
+}
+
+func (s *Server) stopTimer(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	description, billable, entryType, err := entryForm(r)
+	if err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	if onClock, _ := project.OnClock(); !onClock {
+		failTo(w, r, projectPath(project), errors.New("no timer is running for this project"))
+		return
+	}
+	entry, err := project.StopTimer(description, billable, entryType)
+	if err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	redirect(w, r, projectPath(project), "Logged "+entry.HoursMins().String())
+}
+
+func (s *Server) addEntry(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	description, billable, entryType, err := entryForm(r)
+	if err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	if description == "" {
+		failTo(w, r, projectPath(project), errors.New("a manual entry needs a description"))
+		return
+	}
+	spec := strings.TrimSpace(r.FormValue("duration"))
+	if spec == "" {
+		failTo(w, r, projectPath(project), errors.New("a manual entry needs a duration such as 45m or 9:00-10:30"))
+		return
+	}
+	interval, err := util.ParseIntervalAt(r.FormValue("when"), spec, time.Now())
+	if err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	entry, err := project.CreateEntryAt(description, interval.Start, interval.Length, billable, entryType)
+	if err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	notice := "Logged " + entry.HoursMins().String()
+	if len(entry.Conflicts) > 0 {
+		notice += fmt.Sprintf(" (overlaps %d other entries)", len(entry.Conflicts))
+	}
+	redirect(w, r, projectPath(project), notice)
+}
+
+func (s *Server) renameProject(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err := project.Rename(r.FormValue("name")); err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	redirect(w, r, projectPath(project), "Renamed to "+project.Path())
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	project, err := s.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	path := project.Path()
+	if err := project.Delete(); err != nil {
+		failTo(w, r, projectPath(project), err)
+		return
+	}
+	redirect(w, r, "/", "Deleted "+path)
+}
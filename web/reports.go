@@ -0,0 +1,159 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+// monthLayout is how the monthly report names a month in its query.
+const monthLayout = "2006-01"
+
+// reportRow totals one project's entries. Each project counts only its own
+// entries, not those of its sub-projects.
+type reportRow struct {
+	Project  *data.Project
+	Week     time.Duration
+	Month    time.Duration
+	Billable time.Duration
+	Entries  int
+	Running  *time.Time
+}
+
+// reportRows counts each entry into its project's row with add and keeps the
+// projects that have time or a running timer.
+func (s *Server) reportRows(entries []*data.Entry, add func(row *reportRow, entry *data.Entry)) []*reportRow {
+	rows := make(map[int64]*reportRow)
+	for _, project := range s.db.Projects() {
+		row := &reportRow{Project: project}
+		if onClock, timer := project.OnClock(); onClock {
+			started := timer.StartedTime()
+			row.Running = &started
+		}
+		rows[project.ID] = row
+	}
+	for _, entry := range entries {
+		if row, ok := rows[entry.ProjectID]; ok {
+			add(row, entry)
+		}
+	}
+	out := make([]*reportRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Entries > 0 || row.Month > 0 || row.Running != nil {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+func duration(entry *data.Entry) time.Duration {
+	return time.Duration(entry.DurationMs) * time.Millisecond
+}
+
+// weekly shows the trailing seven days and the month to date per project,
+// busiest first.
+func (s *Server) weekly(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	today := data.StartOfDay(now)
+	weekStart := today.AddDate(0, 0, -6)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	from := weekStart
+	if monthStart.Before(from) {
+		from = monthStart
+	}
+	entries, err := s.db.EntriesInRange(from, today.AddDate(0, 0, 1), data.EntryFilter{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var total, billable time.Duration
+	rows := s.reportRows(entries, func(row *reportRow, entry *data.Entry) {
+		started := entry.StartedAt
+		if started == nil {
+			started = &entry.CreatedAt
+		}
+		if !started.Before(monthStart) {
+			row.Month += duration(entry)
+		}
+		if started.Before(weekStart) {
+			return
+		}
+		row.Week += duration(entry)
+		row.Entries++
+		total += duration(entry)
+		if entry.Billable {
+			row.Billable += duration(entry)
+			billable += duration(entry)
+		}
+	})
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Week != rows[j].Week {
+			return rows[i].Week > rows[j].Week
+		}
+		return rows[i].Project.Path() < rows[j].Project.Path()
+	})
+	s.render(w, "weekly.html", struct {
+		layout
+		From     time.Time
+		Rows     []*reportRow
+		Total    time.Duration
+		Billable time.Duration
+	}{s.newLayout(r, "Last 7 days"), weekStart, rows, total, billable})
+}
+
+// monthly totals a calendar month per project, the current one unless the
+// month query names another as 2024-05.
+func (s *Server) monthly(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if value := r.URL.Query().Get("month"); value != "" {
+		month, err := time.ParseInLocation(monthLayout, value, time.Local)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("month must look like 2024-05, got %q", value), http.StatusBadRequest)
+			return
+		}
+		start = month
+	}
+	end := start.AddDate(0, 1, 0)
+	entries, err := s.db.EntriesInRange(start, end, data.EntryFilter{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var total, billable time.Duration
+	rows := s.reportRows(entries, func(row *reportRow, entry *data.Entry) {
+		row.Month += duration(entry)
+		row.Entries++
+		total += duration(entry)
+		if entry.Billable {
+			row.Billable += duration(entry)
+			billable += duration(entry)
+		}
+	})
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Month != rows[j].Month {
+			return rows[i].Month > rows[j].Month
+		}
+		return rows[i].Project.Path() < rows[j].Project.Path()
+	})
+	s.render(w, "monthly.html", struct {
+		layout
+		Month    time.Time
+		Previous string
+		Next     string
+		Current  bool
+		Rows     []*reportRow
+		Total    time.Duration
+		Billable time.Duration
+	}{
+		s.newLayout(r, start.Format("January 2006")),
+		start,
+		start.AddDate(0, -1, 0).Format(monthLayout),
+		end.Format(monthLayout),
+		start.Year() == now.Year() && start.Month() == now.Month(),
+		rows, total, billable,
+	})
+}
@@ -0,0 +1,24 @@
+// Keeps running timers ticking and asks before destructive forms submit.
+(function () {
+  function pad(n) {
+    return n < 10 ? "0" + n : String(n);
+  }
+
+  function tick() {
+    var now = Math.floor(Date.now() / 1000);
+    document.querySelectorAll("[data-started]").forEach(function (el) {
+      var secs = Math.max(0, now - Number(el.dataset.started));
+      el.textContent = Math.floor(secs / 3600) + ":" + pad(Math.floor(secs / 60) % 60) + ":" + pad(secs % 60);
+    });
+  }
+
+  document.addEventListener("submit", function (event) {
+    var message = event.target.dataset.confirm;
+    if (message && !window.confirm(message)) {
+      event.preventDefault();
+    }
+  });
+
+  tick();
+  setInterval(tick, 1000);
+})();
@@ -0,0 +1,178 @@
+// Package web serves a small browser UI for samay. Its templates and static
+// assets are embedded in the binary and it reads and writes the same database
+// as the TUI through the data package.
+package web
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util"
+	"github.com/nexneo/samay/util/version"
+)
+
+//go:embed templates static
+var files embed.FS
+
+// pages are the templates under templates/, each rendered inside layout.html.
+var pages = []string{"index.html", "project.html", "entry.html", "weekly.html", "monthly.html"}
+
+// Server renders the web UI for one database.
+type Server struct {
+	db    *data.Database
+	mux   *http.ServeMux
+	pages map[string]*template.Template
+}
+
+// layout holds what every page shows around its content.
+type layout struct {
+	Title   string
+	Notice  string
+	Error   string
+	Version string
+}
+
+var funcs = template.FuncMap{
+	"hm": func(d time.Duration) string { return util.HmFromD(d).String() },
+	"ms": func(ms int64) string { return util.HmFromD(time.Duration(ms) * time.Millisecond).String() },
+	"when": func(t *time.Time) string {
+		if t == nil {
+			return "—"
+		}
+		return t.In(time.Local).Format("Mon Jan 2 2006 15:04")
+	},
+	"unix":   func(t time.Time) int64 { return t.Unix() },
+	"add":    func(a, b int) int { return a + b },
+	"indent": func(depth int) string { return strings.Repeat("   ", depth) },
+	"types":  func() []data.EntryType { return data.EntryTypes },
+}
+
+// New parses the embedded templates and returns a server for db.
+func New(db *data.Database) (*Server, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	s := &Server{db: db, mux: http.NewServeMux(), pages: make(map[string]*template.Template)}
+	for _, page := range pages {
+		t, err := template.New(page).Funcs(funcs).ParseFS(files, "templates/layout.html", "templates/"+page)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", page, err)
+		}
+		s.pages[page] = t
+	}
+
+	static, err := fs.Sub(files, "static")
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+
+	s.mux.HandleFunc("GET /{$}", s.index)
+	s.mux.HandleFunc("POST /projects", s.createProject)
+	s.mux.HandleFunc("GET /projects/{id}", s.projectPage)
+	s.mux.HandleFunc("POST /projects/{id}/start", s.startTimer)
+	s.mux.HandleFunc("POST /projects/{id}/stop", s.stopTimer)
+	s.mux.HandleFunc("POST /projects/{id}/entries", s.addEntry)
+	s.mux.HandleFunc("POST /projects/{id}/rename", s.renameProject)
+	s.mux.HandleFunc("POST /projects/{id}/delete", s.deleteProject)
+	s.mux.HandleFunc("GET /entries/{id}", s.entry)
+	s.mux.HandleFunc("POST /entries/{id}", s.editEntry)
+	s.mux.HandleFunc("POST /entries/{id}/move", s.moveEntry)
+	s.mux.HandleFunc("POST /entries/{id}/delete", s.deleteEntry)
+	s.mux.HandleFunc("GET /reports/weekly", s.weekly)
+	s.mux.HandleFunc("GET /reports/monthly", s.monthly)
+	return s, nil
+}
+
+// ServeHTTP refuses form posts from other sites, so a page open in the same
+// browser cannot change the database behind the user's back. It also refuses
+// requests for any host but this machine, so a site whose name resolves to
+// 127.0.0.1 (DNS rebinding) cannot pass as the UI's own origin.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !localHost(r) {
+		http.Error(w, "unknown host refused", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && !sameOrigin(r) {
+		http.Error(w, "cross-site request refused", http.StatusForbidden)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// localHost reports whether r is addressed to localhost or a loopback or
+// listening address by IP, on the port the connection came in on.
+func localHost(r *http.Request) bool {
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host, port = r.Host, "80"
+	}
+	host = strings.ToLower(host)
+	if local, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		listenHost, listenPort, err := net.SplitHostPort(local.String())
+		if err != nil || port != listenPort {
+			return false
+		}
+		if host == listenHost {
+			return true
+		}
+	}
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+func sameOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin" || site == "none"
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (s *Server) render(w http.ResponseWriter, page string, data any) {
+	var sb strings.Builder
+	if err := s.pages[page].ExecuteTemplate(&sb, "layout", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+func (s *Server) newLayout(r *http.Request, title string) layout {
+	return layout{
+		Title:   title,
+		Notice:  r.URL.Query().Get("notice"),
+		Error:   r.URL.Query().Get("error"),
+		Version: version.String(),
+	}
+}
+
+// redirect sends the browser to path after a form post, with a notice to show there.
+func redirect(w http.ResponseWriter, r *http.Request, path, notice string) {
+	if notice != "" {
+		path += "?" + url.Values{"notice": {notice}}.Encode()
+	}
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
+// failTo sends the browser back to path with err shown as an error.
+func failTo(w http.ResponseWriter, r *http.Request, path string, err error) {
+	http.Redirect(w, r, path+"?"+url.Values{"error": {err.Error()}}.Encode(), http.StatusSeeOther)
+}
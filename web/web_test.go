@@ -0,0 +1,264 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "samay-web-test-*")
+	if err != nil {
+		panic(fmt.Sprintf("create temp dir: %v", err))
+	}
+	if err := data.OpenDatabase(filepath.Join(dir, "test.db")); err != nil {
+		panic(fmt.Sprintf("open database: %v", err))
+	}
+
+	code := m.Run()
+
+	if data.DB != nil {
+		_ = data.DB.Close()
+	}
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := New(data.DB)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	return s
+}
+
+// newRequest builds a request the way a browser on this machine sends it to
+// the UI listening on 127.0.0.1:7412.
+func newRequest(method, path string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, path, body)
+	req.Host = "localhost:7412"
+	local := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7412}
+	return req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, local))
+}
+
+func get(t *testing.T, s *Server, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, newRequest(http.MethodGet, path, nil))
+	return rec.Code, rec.Body.String()
+}
+
+// post submits a form and returns where the server redirected to.
+func post(t *testing.T, s *Server, path string, form url.Values) *url.URL {
+	t.Helper()
+	req := newRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Sec-Fetch-Site", "same-origin")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("POST %s: expected a redirect, got %d %s", path, rec.Code, rec.Body.String())
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("POST %s: bad redirect: %v", path, err)
+	}
+	return location
+}
+
+func TestProjectTimerAndEntryForms(t *testing.T) {
+	s := newTestServer(t)
+
+	created := post(t, s, "/projects", url.Values{"name": {"Web/Site"}})
+	if !strings.HasPrefix(created.Path, "/projects/") || created.Query().Get("notice") != "Created Web/Site" {
+		t.Fatalf("unexpected redirect after create: %s", created)
+	}
+	project, err := data.DB.FindProject("Web/Site")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	base := created.Path
+
+	post(t, s, base+"/start", nil)
+	if onClock, _ := project.OnClock(); !onClock {
+		t.Fatal("expected the timer to be running")
+	}
+	if code, body := get(t, s, base); code != http.StatusOK || !strings.Contains(body, "data-started=") {
+		t.Fatalf("expected the project page to show the running timer, got %d", code)
+	}
+	if again := post(t, s, base+"/start", nil); again.Query().Get("error") == "" {
+		t.Fatal("expected a second start to report an error")
+	}
+	post(t, s, base+"/stop", url.Values{"description": {"layout #css"}, "type": {"chore"}})
+	if onClock, _ := project.OnClock(); onClock {
+		t.Fatal("expected the timer to be stopped")
+	}
+
+	rejected := post(t, s, base+"/entries", url.Values{"description": {"review"}, "duration": {"1.5"}})
+	if !strings.Contains(rejected.Query().Get("error"), "no unit") {
+		t.Fatalf("expected an ambiguous duration to be rejected, got %s", rejected)
+	}
+	post(t, s, base+"/entries", url.Values{"description": {"review #pr"}, "duration": {"9:00-10:30"}, "when": {"yesterday"}, "billable": {"on"}})
+
+	entries := project.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	var stopped, manual *data.Entry
+	for _, entry := range entries {
+		if entry.DurationMs == (90 * time.Minute).Milliseconds() {
+			manual = entry
+		} else {
+			stopped = entry
+		}
+	}
+	if manual == nil || stopped == nil {
+		t.Fatalf("expected a stopped and a manual entry, got %+v", entries)
+	}
+	if stopped.Type != data.EntryTypeChore || stopped.Billable || manual.Type != data.EntryTypeWork || !manual.Billable {
+		t.Fatalf("unexpected entries: stopped %+v, manual %+v", stopped, manual)
+	}
+
+	if _, body := get(t, s, base+"?q=%23pr"); !strings.Contains(body, "review #pr") || strings.Contains(body, "layout #css") {
+		t.Fatal("expected the tag filter to keep only the manual entry")
+	}
+
+	entryPath := "/entries/" + manual.ID
+	if code, body := get(t, s, entryPath); code != http.StatusOK || !strings.Contains(body, "1:30") {
+		t.Fatalf("expected the entry page to show its duration, got %d", code)
+	}
+	post(t, s, entryPath, url.Values{"description": {"code review #pr #web"}, "duration": {"45m"}, "type": {"fun"}})
+	edited, err := data.DB.Entry(manual.ID)
+	if err != nil {
+		t.Fatalf("load entry: %v", err)
+	}
+	if edited.DurationMs != (45*time.Minute).Milliseconds() || edited.Type != data.EntryTypeFun || edited.Billable || len(edited.Tags) != 2 {
+		t.Fatalf("unexpected edited entry %+v", edited)
+	}
+	if edited.EndedAt.Sub(*edited.StartedAt) != 45*time.Minute {
+		t.Fatalf("expected the end time to follow the new duration, got %v to %v", edited.StartedAt, edited.EndedAt)
+	}
+
+	parent, err := data.DB.FindProject("Web")
+	if err != nil {
+		t.Fatalf("find parent: %v", err)
+	}
+	post(t, s, entryPath+"/move", url.Values{"project": {fmt.Sprint(parent.ID)}})
+	if moved, _ := data.DB.Entry(manual.ID); moved.ProjectID != parent.ID {
+		t.Fatalf("expected the entry to move to Web, got project %d", moved.ProjectID)
+	}
+	back := post(t, s, entryPath+"/delete", nil)
+	if back.Path != fmt.Sprintf("/projects/%d", parent.ID) {
+		t.Fatalf("expected to return to the project after deleting, got %s", back)
+	}
+	if code, _ := get(t, s, entryPath); code != http.StatusNotFound {
+		t.Fatalf("expected the deleted entry to be gone, got %d", code)
+	}
+
+	if kept := post(t, s, "/projects/"+fmt.Sprint(parent.ID)+"/delete", nil); kept.Query().Get("error") == "" {
+		t.Fatal("expected deleting a project with sub-projects to fail")
+	}
+	post(t, s, base+"/rename", url.Values{"name": {"Pages"}})
+	if renamed, _ := data.DB.Project(project.ID); renamed.Name != "Pages" {
+		t.Fatalf("expected the project to be renamed, got %q", renamed.Name)
+	}
+	if home := post(t, s, base+"/delete", nil); home.Path != "/" {
+		t.Fatalf("expected to return home after deleting a project, got %s", home)
+	}
+	if code, _ := get(t, s, base); code != http.StatusNotFound {
+		t.Fatalf("expected the deleted project to be gone, got %d", code)
+	}
+}
+
+func TestReportsAndIndex(t *testing.T) {
+	s := newTestServer(t)
+	project, err := data.DB.CreateProject("Reports Web")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if _, err := project.CreateEntryWithDuration("planning", 2*time.Hour, true, data.EntryTypeWork); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	for _, path := range []string{"/", "/reports/weekly", "/reports/monthly"} {
+		code, body := get(t, s, path)
+		if code != http.StatusOK || !strings.Contains(body, "Reports Web") || !strings.Contains(body, "2:00") {
+			t.Fatalf("GET %s: expected the project and its time, got %d", path, code)
+		}
+	}
+	if _, body := get(t, s, "/reports/monthly?month=1999-01"); strings.Contains(body, "Reports Web") {
+		t.Fatal("expected an old month to leave out this month's work")
+	}
+	if code, _ := get(t, s, "/reports/monthly?month=soon"); code != http.StatusBadRequest {
+		t.Fatalf("expected a bad month to be rejected, got %d", code)
+	}
+	if code, body := get(t, s, "/static/app.js"); code != http.StatusOK || !strings.Contains(body, "data-started") {
+		t.Fatalf("expected the embedded script to be served, got %d", code)
+	}
+}
+
+func TestCrossSitePostsAreRefused(t *testing.T) {
+	s := newTestServer(t)
+	for name, header := range map[string][2]string{
+		"fetch metadata": {"Sec-Fetch-Site", "cross-site"},
+		"origin":         {"Origin", "https://evil.example"},
+	} {
+		req := newRequest(http.MethodPost, "/projects", strings.NewReader("name=Evil"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(header[0], header[1])
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: expected 403, got %d", name, rec.Code)
+		}
+	}
+	if _, err := data.DB.FindProject("Evil"); err == nil {
+		t.Fatal("expected no project to be created")
+	}
+}
+
+func TestForeignHostsAreRefused(t *testing.T) {
+	s := newTestServer(t)
+	for host, want := range map[string]int{
+		"localhost:7412":         http.StatusOK,
+		"127.0.0.1:7412":         http.StatusOK,
+		"[::1]:7412":             http.StatusOK,
+		"rebind.example:7412":    http.StatusForbidden,
+		"localhost.example:7412": http.StatusForbidden,
+		"localhost:8080":         http.StatusForbidden,
+		"127.0.0.1.nip.io:7412":  http.StatusForbidden,
+	} {
+		req := newRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Host %s: expected %d, got %d", host, want, rec.Code)
+		}
+	}
+
+	req := newRequest(http.MethodPost, "/projects", strings.NewReader("name=Rebound"))
+	req.Host = "rebind.example:7412"
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "http://rebind.example:7412")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected a same-origin post from a rebound name to be refused, got %d", rec.Code)
+	}
+	if _, err := data.DB.FindProject("Rebound"); err == nil {
+		t.Fatal("expected no project to be created")
+	}
+}
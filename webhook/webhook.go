@@ -0,0 +1,135 @@
+// Package webhook sends the events queued in the database's webhook outbox
+// to the hooks they were queued for.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nexneo/samay/data"
+	"github.com/nexneo/samay/util/version"
+)
+
+// Headers sent with every delivery.
+const (
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body,
+	// keyed with the hook's secret. It is left out for hooks without one.
+	SignatureHeader = "X-Samay-Signature"
+	EventHeader     = "X-Samay-Event"
+	// DeliveryHeader identifies the delivery; retries of it repeat the value.
+	DeliveryHeader = "X-Samay-Delivery"
+)
+
+// batchSize is how many deliveries are claimed from the outbox at a time.
+const batchSize = 20
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sender posts outbox deliveries to their hooks.
+type Sender struct {
+	db     *data.Database
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender returns a sender for db's outbox. A nil client uses one with a
+// ten second timeout.
+func NewSender(db *data.Database, client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{db: db, client: client, now: time.Now}
+}
+
+// Deliver sends every delivery that is due and returns how many were sent
+// and how many failed. Failed deliveries are retried by a later call.
+func (s *Sender) Deliver(ctx context.Context) (sent, failed int, err error) {
+	for ctx.Err() == nil {
+		deliveries, err := s.db.ClaimWebhookDeliveries(s.now(), batchSize)
+		if err != nil {
+			return sent, failed, err
+		}
+		if len(deliveries) == 0 {
+			break
+		}
+		for _, delivery := range deliveries {
+			hook, ok := s.db.Webhook(delivery.URL)
+			if !ok {
+				// The hook was removed from the config; nobody wants this any more.
+				if err := s.db.RemoveWebhookDelivery(delivery.ID); err != nil {
+					return sent, failed, err
+				}
+				continue
+			}
+			postErr := s.post(ctx, hook, delivery)
+			switch {
+			case postErr == nil:
+				sent++
+				if err := s.db.RemoveWebhookDelivery(delivery.ID); err != nil {
+					return sent, failed, err
+				}
+			case ctx.Err() != nil:
+				// Cancelled mid-flight: the claim runs out and another call retries it.
+				return sent, failed, ctx.Err()
+			default:
+				failed++
+				if err := s.db.WebhookFailed(delivery, postErr, s.now()); err != nil {
+					return sent, failed, err
+				}
+			}
+		}
+	}
+	return sent, failed, ctx.Err()
+}
+
+func (s *Sender) post(ctx context.Context, hook data.Webhook, delivery data.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.String())
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, delivery.Payload))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", hook.URL, resp.Status)
+	}
+	return nil
+}
+
+// Run delivers whatever is due every interval until ctx is done. Failures
+// stay in the outbox, where samay webhooks shows them.
+func (s *Sender) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, _, _ = s.Deliver(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nexneo/samay/data"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "samay-webhook-test-*")
+	if err != nil {
+		panic(fmt.Sprintf("create temp dir: %v", err))
+	}
+	if err := data.OpenDatabase(filepath.Join(dir, "test.db")); err != nil {
+		panic(fmt.Sprintf("open database: %v", err))
+	}
+
+	code := m.Run()
+
+	if data.DB != nil {
+		_ = data.DB.Close()
+	}
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+type received struct {
+	event     string
+	delivery  string
+	signature string
+	body      []byte
+}
+
+func TestDeliverSignsAndRetries(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []received
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, received{r.Header.Get(EventHeader), r.Header.Get(DeliveryHeader), r.Header.Get(SignatureHeader), body})
+		if len(calls) == 1 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	const secret = "shh"
+	if err := data.DB.SetWebhooks([]data.Webhook{{URL: srv.URL, Secret: secret, Events: []string{data.EventTimerStarted}}}); err != nil {
+		t.Fatalf("set webhooks: %v", err)
+	}
+	t.Cleanup(func() { _ = data.DB.SetWebhooks(nil) })
+	project, err := data.DB.CreateProject("Webhook Sender")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := project.StartTimer(); err != nil {
+		t.Fatalf("start timer: %v", err)
+	}
+
+	now := time.Now()
+	sender := NewSender(data.DB, srv.Client())
+	sender.now = func() time.Time { return now }
+	if sent, failed, err := sender.Deliver(context.Background()); err != nil || sent != 0 || failed != 1 {
+		t.Fatalf("first delivery: sent %d, failed %d, %v", sent, failed, err)
+	}
+	if sent, failed, _ := sender.Deliver(context.Background()); sent+failed != 0 {
+		t.Fatal("expected the retry to wait for its backoff")
+	}
+	now = now.Add(time.Minute)
+	if sent, failed, err := sender.Deliver(context.Background()); err != nil || sent != 1 || failed != 0 {
+		t.Fatalf("retry: sent %d, failed %d, %v", sent, failed, err)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+	last := calls[1]
+	if last.event != data.EventTimerStarted || last.delivery != calls[0].delivery || last.signature != Sign(secret, last.body) {
+		t.Fatalf("unexpected delivery headers %+v", last)
+	}
+	var event data.WebhookEvent
+	if err := json.Unmarshal(last.body, &event); err != nil || event.Event != data.EventTimerStarted {
+		t.Fatalf("unexpected body %s (%v)", last.body, err)
+	}
+	if statuses, err := data.DB.WebhookStatus(); err != nil || len(statuses) != 0 {
+		t.Fatalf("expected the outbox to be empty, got %+v (%v)", statuses, err)
+	}
+}
+
+func TestDeliverDropsUnconfiguredHooks(t *testing.T) {
+	if err := data.DB.SetWebhooks([]data.Webhook{{URL: "http://127.0.0.1:1/gone"}}); err != nil {
+		t.Fatalf("set webhooks: %v", err)
+	}
+	if _, err := data.DB.CreateProject("Webhook Gone"); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if err := data.DB.SetWebhooks(nil); err != nil {
+		t.Fatalf("clear webhooks: %v", err)
+	}
+	if sent, failed, err := NewSender(data.DB, nil).Deliver(context.Background()); err != nil || sent+failed != 0 {
+		t.Fatalf("expected nothing to be sent, got sent %d, failed %d, %v", sent, failed, err)
+	}
+	if statuses, err := data.DB.WebhookStatus(); err != nil || len(statuses) != 0 {
+		t.Fatalf("expected the delivery to be dropped, got %+v (%v)", statuses, err)
+	}
+}